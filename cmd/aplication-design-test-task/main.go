@@ -2,18 +2,35 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // hotel timezones must resolve in containers without system zoneinfo
 
 	httpApi "aplication-design-test-task/internal/adapters/api/http"
+	"aplication-design-test-task/internal/adapters/gateway/fake"
+	"aplication-design-test-task/internal/adapters/gateway/localmail"
+	"aplication-design-test-task/internal/adapters/gateway/settlement"
+	"aplication-design-test-task/internal/adapters/gateway/smtp"
+	webhookSender "aplication-design-test-task/internal/adapters/gateway/webhook"
 	"aplication-design-test-task/internal/adapters/queue"
 	"aplication-design-test-task/internal/adapters/queue/gochanqueue"
 	"aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/port/gateway"
 	"aplication-design-test-task/internal/core/service/booking"
+	"aplication-design-test-task/internal/core/service/catalog"
+	"aplication-design-test-task/internal/core/service/invoice"
+	"aplication-design-test-task/internal/core/service/loyalty"
+	"aplication-design-test-task/internal/core/service/notification"
+	"aplication-design-test-task/internal/core/service/overbooking"
+	"aplication-design-test-task/internal/core/service/payment"
+	"aplication-design-test-task/internal/core/service/pricing"
+	"aplication-design-test-task/internal/core/service/promo"
+	"aplication-design-test-task/internal/core/service/webhook"
 	"aplication-design-test-task/internal/logger"
 	"aplication-design-test-task/migration"
 )
@@ -23,6 +40,7 @@ import (
 const (
 	addr                      = "localhost:8080" // todo move this to env or config
 	gracefullyShutdownTimeout = 5 * time.Second
+	webhookTimeout            = 10 * time.Second // of one webhook request to partner
 )
 
 func main() {
@@ -71,6 +89,13 @@ func main() {
 		os.Exit(2)
 	}
 
+	if path := os.Getenv("FX_RATES_FILE"); path != "" {
+		if err = migration.LoadFXRatesFile(ctx, store, path); err != nil {
+			log.Error("Failed to load FX rates from %s. err: %v ", path, err)
+			os.Exit(2)
+		}
+	}
+
 	bookingService, err := booking.New(log, q, store)
 	if err != nil {
 		log.Error("Failed to init BookingService. err: %v ", err)
@@ -82,7 +107,57 @@ func main() {
 		os.Exit(4)
 	}
 
-	httpServer := httpApi.NewServer(addr, log, q, bookingService)
+	// todo real gateway adapter, fake one declines and defers to callback payments of demo emails.
+	// Payments are charged one by one, so no demo latency: a slow payer would hold up every payment behind it.
+	paymentService := payment.New(log, q, store, fake.NewPaymentGateway(
+		fake.WithDecline("declined@example.com", "insufficient funds"),
+		fake.WithPending("callback@example.com"),
+	))
+	if err = paymentService.Run(ctx); err != nil {
+		log.Error("Failed to Run PaymentService. err: %v ", err)
+		os.Exit(5)
+	}
+
+	// todo reports are put to directory by hand, real provider delivers them by SFTP.
+	if dir := os.Getenv("SETTLEMENT_REPORTS_DIR"); dir != "" {
+		fix := os.Getenv("SETTLEMENT_REPORTS_FIX") == "true"
+		if err = settlement.NewJob(log, paymentService, dir, fix).Run(ctx); err != nil {
+			log.Error("Failed to Run settlement reports job. err: %v ", err)
+			os.Exit(10)
+		}
+	}
+
+	mailer, err := newMailer()
+	if err != nil {
+		log.Error("Failed to init Mailer. err: %v ", err)
+		os.Exit(6)
+	}
+
+	if err = notification.New(log, q, store, mailer).Run(ctx); err != nil {
+		log.Error("Failed to Run NotificationService. err: %v ", err)
+		os.Exit(7)
+	}
+
+	webhookService := webhook.New(log, q, store, webhookSender.NewSender(webhookTimeout))
+	if err = webhookService.Run(ctx); err != nil {
+		log.Error("Failed to Run WebhookService. err: %v ", err)
+		os.Exit(8)
+	}
+
+	promoService := promo.New(log, store)
+
+	httpServer := httpApi.NewServer(addr, log, q, httpApi.Services{
+		Booking: bookingService,
+		Catalog: catalog.New(store),
+		Promo:   promoService,
+		Pricing: pricing.New(store),
+		Loyalty: loyalty.New(store),
+		Payment: paymentService,
+		Webhook: webhookService,
+		Invoice: invoice.New(store),
+
+		Overbooking: overbooking.New(store),
+	}, secretFromEnv(log, "PAYMENT_CALLBACK_SECRET", "payment callbacks"), secretFromEnv(log, "ADMIN_TOKEN", "admin requests"))
 	if err := httpServer.Run(ctx, gracefullyShutdownTimeout); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Error("Failed to run HTTP server: %v", err)
 	}
@@ -90,3 +165,40 @@ func main() {
 
 	log.Info("App finished.")
 }
+
+// secretFromEnv - secret is taken from env variable. Without it a random secret is generated, so requests which
+// it authenticates are rejected until the secret is configured.
+func secretFromEnv(log logger.Logger, name, rejected string) []byte {
+	if secret := os.Getenv(name); secret != "" {
+		return []byte(secret)
+	}
+
+	log.Error("%s is not set, %s will be rejected", name, rejected)
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Error("Failed to generate %s. err: %v ", name, err)
+		os.Exit(9)
+	}
+
+	return secret
+}
+
+// newMailer - SMTP mailer if SMTP_ADDR is set, otherwise emails are written to MAIL_DIR for local runs.
+func newMailer() (gateway.Mailer, error) {
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		return smtp.New(smtp.Config{
+			Addr:     addr,
+			From:     os.Getenv("SMTP_FROM"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}), nil
+	}
+
+	dir := os.Getenv("MAIL_DIR")
+	if dir == "" {
+		dir = "mail"
+	}
+
+	return localmail.New(dir)
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+type allotmentRequest struct {
+	Code       string    `json:"code"`
+	Partner    string    `json:"partner"`
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`         // exclusive, like check-out date
+	Rooms      int       `json:"rooms"`      // rooms held for every night
+	ReleaseAt  time.Time `json:"release_at"` // unused rooms return to general sale at this moment
+}
+
+// admin handlers for partner allotments, in real life must be protected by auth.
+func registerAllotmentHandlers(
+	mux *http.ServeMux,
+	log logger.Logger,
+	q queue.Queue,
+	bookingService service.BookingService,
+	catalogService service.CatalogService,
+) {
+	mux.HandleFunc("POST /api/v1/allotment", postAllotmentHandler(log, q, bookingService, catalogService))
+	mux.HandleFunc("GET /api/v1/allotment/{code}", getAllotmentHandler(log, bookingService))
+
+	mux.HandleFunc("GET /api/v1/allotment", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		allotments, _ := bookingService.GetListAllotments(r.Context())
+		_ = json.NewEncoder(w).Encode(allotments)
+	})
+}
+
+// postAllotmentHandler - quota is held by booking worker, result can be checked by GET of allotment code.
+func postAllotmentHandler(
+	log logger.Logger,
+	q queue.Queue,
+	bookingService service.BookingService,
+	catalogService service.CatalogService,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postAllotmentHandler")
+
+		var request allotmentRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		allotment := events.AllotmentEvent{
+			Code:       model.NormalizeBlockCode(request.Code),
+			Partner:    request.Partner,
+			HotelID:    request.HotelID,
+			RoomTypeID: request.RoomTypeID,
+			From:       request.From,
+			To:         request.To,
+			Rooms:      request.Rooms,
+			ReleaseAt:  request.ReleaseAt,
+		}
+
+		if err := allotment.Validate(time.Now().UTC()); err != nil {
+			log.Error("Invalid allotment: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err := catalogService.ValidateOrder(r.Context(), model.Order{HotelID: allotment.HotelID, RoomTypeID: allotment.RoomTypeID})
+		switch {
+		case errors.Is(err, model.ErrUnknownHotel), errors.Is(err, model.ErrUnknownRoomType):
+			log.Error("Allotment does not match catalog: %v", err)
+			http.Error(w, "Invalid hotel or room type: "+err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			log.Error("Failed to validate allotment against catalog: %v", err)
+			http.Error(w, "Failed to validate allotment: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		_, err = bookingService.GetAllotment(r.Context(), allotment.Code)
+		switch {
+		case err == nil:
+			log.Error("Allotment %s already exists", allotment.Code)
+			http.Error(w, "Allotment already exists", http.StatusConflict)
+			return
+		case !errors.Is(err, storage.ErrNotFound):
+			log.Error("Failed to retrieve allotment: %v", err)
+			http.Error(w, "Failed to retrieve allotment", http.StatusInternalServerError)
+			return
+		}
+
+		if err = q.Publish(r.Context(), queue.InventoryUpdateRequest, allotment); err != nil {
+			log.Error("Failed to publish the allotment: %v", err)
+			http.Error(w, "Failed to publish the allotment: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"code":   allotment.Code,
+			"status": "received",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+func getAllotmentHandler(log logger.Logger, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getAllotmentHandler")
+
+		allotment, err := bookingService.GetAllotment(r.Context(), r.PathValue("code"))
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Allotment not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to retrieve allotment: %v", err)
+			http.Error(w, "Failed to retrieve allotment", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(allotment); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
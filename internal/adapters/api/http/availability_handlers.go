@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+func registerAvailabilityHandlers(mux *http.ServeMux, log logger.Logger, bookingService service.BookingService) {
+	mux.HandleFunc("GET /api/v1/availability", getAvailabilityHandler(log, bookingService))
+}
+
+// getAvailabilityHandler - search by `from` and `to` hotel local dates (YYYY-MM-DD), optional `rooms` (1 by default)
+// and `hotel_id` (all hotels by default).
+func getAvailabilityHandler(log logger.Logger, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getAvailabilityHandler")
+
+		params := r.URL.Query()
+		query := model.AvailabilityQuery{Rooms: 1}
+
+		var err error
+		if query.From, err = time.Parse(time.DateOnly, params.Get("from")); err != nil {
+			log.Error("Invalid from date: %s", params.Get("from"))
+			http.Error(w, "Invalid from date: expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+
+		if query.To, err = time.Parse(time.DateOnly, params.Get("to")); err != nil {
+			log.Error("Invalid to date: %s", params.Get("to"))
+			http.Error(w, "Invalid to date: expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+
+		if roomsStr := params.Get("rooms"); roomsStr != "" {
+			if query.Rooms, err = strconv.Atoi(roomsStr); err != nil {
+				log.Error("Invalid rooms: %s", roomsStr)
+				http.Error(w, "Invalid rooms: must be positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if hotelIDStr := params.Get("hotel_id"); hotelIDStr != "" {
+			if query.HotelID, err = strconv.Atoi(hotelIDStr); err != nil || query.HotelID <= 0 {
+				log.Error("Invalid hotel ID: %s", hotelIDStr)
+				http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		offers, err := bookingService.SearchAvailability(r.Context(), query)
+		if err != nil {
+			if errors.Is(err, model.ErrAvailabilityQueryInvalid) {
+				log.Error("Invalid availability query: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("Failed to search availability: %v", err)
+			http.Error(w, "Failed to search availability", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, offers)
+	}
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+// admin handlers for hotels and room types catalog, in real life must be protected by auth.
+func registerCatalogHandlers(mux *http.ServeMux, log logger.Logger, catalogService service.CatalogService) {
+	mux.HandleFunc("POST /api/v1/hotel", postHotelHandler(log, catalogService))
+	mux.HandleFunc("GET /api/v1/hotel/{id}", getHotelHandler(log, catalogService))
+	mux.HandleFunc("PUT /api/v1/hotel/{id}", putHotelHandler(log, catalogService))
+	mux.HandleFunc("DELETE /api/v1/hotel/{id}", deleteHotelHandler(log, catalogService))
+
+	mux.HandleFunc("GET /api/v1/hotel", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		hotels, _ := catalogService.GetListHotels(r.Context())
+		_ = json.NewEncoder(w).Encode(hotels)
+	})
+
+	mux.HandleFunc("POST /api/v1/hotel/{id}/room-type", postRoomTypeHandler(log, catalogService))
+	mux.HandleFunc("GET /api/v1/hotel/{id}/room-type", getListRoomTypesHandler(log, catalogService))
+	mux.HandleFunc("GET /api/v1/hotel/{id}/room-type/{room_type_id}", getRoomTypeHandler(log, catalogService))
+	mux.HandleFunc("PUT /api/v1/hotel/{id}/room-type/{room_type_id}", putRoomTypeHandler(log, catalogService))
+	mux.HandleFunc("DELETE /api/v1/hotel/{id}/room-type/{room_type_id}", deleteRoomTypeHandler(log, catalogService))
+}
+
+// catalogPathID - positive integer ID from path.
+func catalogPathID(r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	return id, err == nil && id > 0
+}
+
+// writeCatalogError - http status of catalog service error.
+func writeCatalogError(w http.ResponseWriter, log logger.Logger, err error, entity string) {
+	switch {
+	case errors.Is(err, model.ErrHotelInvalid), errors.Is(err, model.ErrRoomTypeInvalid):
+		log.Error("Invalid %s: %v", entity, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, entity+" not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrDuplicateConstraint):
+		http.Error(w, entity+" already exists", http.StatusConflict)
+	case errors.Is(err, model.ErrHotelInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Error("Failed to process %s: %v", entity, err)
+		http.Error(w, "Failed to process "+entity, http.StatusInternalServerError)
+	}
+}
+
+func postHotelHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postHotelHandler")
+
+		var hotel model.Hotel
+		if err := json.NewDecoder(r.Body).Decode(&hotel); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		if err := catalogService.CreateHotel(r.Context(), hotel); err != nil {
+			writeCatalogError(w, log, err, "Hotel")
+			return
+		}
+
+		writeJSON(w, log, http.StatusCreated, hotel)
+	}
+}
+
+func getHotelHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getHotelHandler")
+
+		hotelID, ok := catalogPathID(r, "id")
+		if !ok {
+			http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		hotel, err := catalogService.GetHotel(r.Context(), hotelID)
+		if err != nil {
+			writeCatalogError(w, log, err, "Hotel")
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, hotel)
+	}
+}
+
+func putHotelHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("putHotelHandler")
+
+		hotelID, ok := catalogPathID(r, "id")
+		if !ok {
+			http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		var hotel model.Hotel
+		if err := json.NewDecoder(r.Body).Decode(&hotel); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+		hotel.ID = hotelID
+
+		if err := catalogService.UpdateHotel(r.Context(), hotel); err != nil {
+			writeCatalogError(w, log, err, "Hotel")
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, hotel)
+	}
+}
+
+func deleteHotelHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("deleteHotelHandler")
+
+		hotelID, ok := catalogPathID(r, "id")
+		if !ok {
+			http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		if err := catalogService.DeleteHotel(r.Context(), hotelID); err != nil {
+			writeCatalogError(w, log, err, "Hotel")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func postRoomTypeHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postRoomTypeHandler")
+
+		hotelID, ok := catalogPathID(r, "id")
+		if !ok {
+			http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		var roomType model.RoomType
+		if err := json.NewDecoder(r.Body).Decode(&roomType); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+		roomType.HotelID = hotelID
+
+		if err := catalogService.CreateRoomType(r.Context(), roomType); err != nil {
+			writeCatalogError(w, log, err, "Room type")
+			return
+		}
+
+		writeJSON(w, log, http.StatusCreated, roomType)
+	}
+}
+
+func getListRoomTypesHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getListRoomTypesHandler")
+
+		hotelID, ok := catalogPathID(r, "id")
+		if !ok {
+			http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		roomTypes, err := catalogService.GetListRoomTypes(r.Context(), hotelID)
+		if err != nil {
+			writeCatalogError(w, log, err, "Hotel")
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, roomTypes)
+	}
+}
+
+func getRoomTypeHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getRoomTypeHandler")
+
+		hotelID, okHotel := catalogPathID(r, "id")
+		roomTypeID, okRoomType := catalogPathID(r, "room_type_id")
+		if !okHotel || !okRoomType {
+			http.Error(w, "Invalid hotel or room type ID: IDs must be positive integers", http.StatusBadRequest)
+			return
+		}
+
+		roomType, err := catalogService.GetRoomType(r.Context(), hotelID, roomTypeID)
+		if err != nil {
+			writeCatalogError(w, log, err, "Room type")
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, roomType)
+	}
+}
+
+func putRoomTypeHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("putRoomTypeHandler")
+
+		hotelID, okHotel := catalogPathID(r, "id")
+		roomTypeID, okRoomType := catalogPathID(r, "room_type_id")
+		if !okHotel || !okRoomType {
+			http.Error(w, "Invalid hotel or room type ID: IDs must be positive integers", http.StatusBadRequest)
+			return
+		}
+
+		var roomType model.RoomType
+		if err := json.NewDecoder(r.Body).Decode(&roomType); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+		roomType.HotelID, roomType.ID = hotelID, roomTypeID
+
+		if err := catalogService.UpdateRoomType(r.Context(), roomType); err != nil {
+			writeCatalogError(w, log, err, "Room type")
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, roomType)
+	}
+}
+
+func deleteRoomTypeHandler(log logger.Logger, catalogService service.CatalogService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("deleteRoomTypeHandler")
+
+		hotelID, okHotel := catalogPathID(r, "id")
+		roomTypeID, okRoomType := catalogPathID(r, "room_type_id")
+		if !okHotel || !okRoomType {
+			http.Error(w, "Invalid hotel or room type ID: IDs must be positive integers", http.StatusBadRequest)
+			return
+		}
+
+		if err := catalogService.DeleteRoomType(r.Context(), hotelID, roomTypeID); err != nil {
+			writeCatalogError(w, log, err, "Room type")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
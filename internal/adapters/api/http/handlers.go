@@ -1,11 +1,13 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/mail"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,27 +28,104 @@ type orderReservationRequest struct {
 	From       time.Time `json:"from"`
 	To         time.Time `json:"to"`
 	PromoCode  string    `json:"promo_code"`
+	RatePlanID int       `json:"rate_plan_id"` // 0 - best available rate
+	BlockCode  string    `json:"block_code"`   // book from partner allotment
+	Currency   string    `json:"currency"`     // display currency, currency of hotel if omitted
+
+	LoyaltyPoints int64 `json:"loyalty_points"` // points to redeem as discount
+	Waitlist      bool  `json:"waitlist"`       // join waitlist if there are no rooms
+
+	Guest            model.Guest     `json:"guest"`     // lead guest
+	Occupancy        model.Occupancy `json:"occupancy"` // one adult if omitted
+	AdditionalGuests []model.Guest   `json:"additional_guests"`
 	// todo other options...
 }
 
+// orderModificationRequest - omitted (zero) fields keep current values of the order.
+type orderModificationRequest struct {
+	RoomTypeID int       `json:"room_type_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+}
+
 type orderReservationResponse struct {
 	ID         uuid.UUID `json:"id"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	Status     string    `json:"status"`
+	Reason     string    `json:"failure_reason,omitempty"`
 	HotelID    int       `json:"hotel_id"`
 	RoomTypeID int       `json:"room_type_id"`
 	UserEmail  string    `json:"email"`
 	From       time.Time `json:"from"`
 	To         time.Time `json:"to"`
+	PromoCode  string    `json:"promo_code,omitempty"`
+	RatePlanID int       `json:"rate_plan_id,omitempty"`
+	BlockCode  string    `json:"block_code,omitempty"`
+	Currency   string    `json:"currency,omitempty"`
+
+	CheckInAt  time.Time `json:"check_in_at"`
+	CheckOutAt time.Time `json:"check_out_at"`
+
+	LoyaltyPoints int64 `json:"loyalty_points,omitempty"`
+	Waitlist      bool  `json:"waitlist,omitempty"`
+
+	Guest            model.Guest     `json:"guest"`
+	Occupancy        model.Occupancy `json:"occupancy"`
+	AdditionalGuests []model.Guest   `json:"additional_guests,omitempty"`
+
+	Price *model.Price `json:"price,omitempty"` // nil until order is processed
+
+	Conversion *model.CurrencyConversion `json:"conversion,omitempty"` // total in display currency
+
+	PaymentSchedule []model.Installment `json:"payment_schedule,omitempty"` // deposit and balance
+	PaidAmount      *model.Money        `json:"paid_amount,omitempty"`
+
+	Refunds        []model.Refund `json:"refunds,omitempty"`
+	RefundedAmount *model.Money   `json:"refunded_amount,omitempty"`
+
+	Changes []model.OrderChange `json:"changes,omitempty"`
+
+	Alternatives []model.Alternative `json:"alternatives,omitempty"` // offered when there are no rooms
+}
+
+// orderAvailabilityResponse - answer of synchronous order submission when stay can not be booked.
+type orderAvailabilityResponse struct {
+	Status            string      `json:"status"`
+	UnavailableNights []time.Time `json:"unavailable_nights"` // hotel local nights without rooms
 }
 
+const syncOrderPollInterval = 50 * time.Millisecond
+
 func validateEmail(email string) bool {
 	_, err := mail.ParseAddress(email) // https://pkg.go.dev/net/mail
 	return err == nil
 }
 
-func postReservationOrderHandler(log logger.Logger, q queue.Queue) func(http.ResponseWriter, *http.Request) {
+// isSyncOrderRequest - client asks to answer with outcome of the order instead of "received",
+// by `sync=true` query flag or `X-Sync: true` header.
+func isSyncOrderRequest(r *http.Request) bool {
+	for _, flag := range []string{r.URL.Query().Get("sync"), r.Header.Get("X-Sync")} {
+		if sync, err := strconv.ParseBool(flag); err == nil && sync {
+			return true
+		}
+	}
+
+	return false
+}
+
+// postReservationOrderHandler - order is processed by booking worker asynchronously.
+// In synchronous mode availability is pre-checked, so sold out stay is rejected with 409 and unavailable nights,
+// otherwise handler waits up to syncTimeout for the worker to process the order and answers with its final status.
+func postReservationOrderHandler(
+	log logger.Logger,
+	q queue.Queue,
+	bookingService service.BookingService,
+	catalogService service.CatalogService,
+	promoService service.PromoService,
+	loyaltyService service.LoyaltyService,
+	syncTimeout time.Duration,
+) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Info("postReservationOrderHandler")
 
@@ -72,12 +151,51 @@ func postReservationOrderHandler(log logger.Logger, q queue.Queue) func(http.Res
 			return
 		}
 
+		if orderRequest.RatePlanID < 0 {
+			log.Error("Invalid rate plan ID")
+			http.Error(w, "Invalid rate plan ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		if orderRequest.Currency != "" {
+			if err = model.ValidateCurrency(strings.ToUpper(orderRequest.Currency)); err != nil {
+				log.Error("Invalid currency: %v", err)
+				http.Error(w, "Invalid currency: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if orderRequest.LoyaltyPoints < 0 {
+			log.Error("Invalid loyalty points: %d", orderRequest.LoyaltyPoints)
+			http.Error(w, "Invalid loyalty points: must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		if orderRequest.LoyaltyPoints > 0 {
+			account, err := loyaltyService.GetAccount(r.Context(), orderRequest.UserEmail)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				log.Error("Failed to retrieve loyalty account: %v", err)
+				http.Error(w, "Failed to retrieve loyalty account", http.StatusInternalServerError)
+				return
+			}
+
+			if account.Balance < orderRequest.LoyaltyPoints {
+				log.Error("Insufficient loyalty points: %d < %d", account.Balance, orderRequest.LoyaltyPoints)
+				http.Error(w, "Invalid loyalty points: "+model.ErrInsufficientPoints.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
 		if !orderRequest.From.Before(orderRequest.To) {
 			log.Error("From date must be before To date")
 			http.Error(w, "From date must be before To date", http.StatusBadRequest)
 			return
 		}
 
+		if orderRequest.Occupancy == (model.Occupancy{}) {
+			orderRequest.Occupancy.Adults = 1
+		}
+
 		orderRequest.ID = uuid.New() // https://en.wikipedia.org/w/index.php?title=Universally_unique_identifier&oldid=755882275#Random_UUID_probability_of_duplicates
 
 		orderReservationEvent := events.ReservationOrderEvent{
@@ -89,6 +207,73 @@ func postReservationOrderHandler(log logger.Logger, q queue.Queue) func(http.Res
 			UserEmail:  orderRequest.UserEmail,
 			From:       orderRequest.From,
 			To:         orderRequest.To,
+			PromoCode:  model.NormalizePromoCode(orderRequest.PromoCode),
+			RatePlanID: orderRequest.RatePlanID,
+			BlockCode:  model.NormalizeBlockCode(orderRequest.BlockCode),
+
+			DisplayCurrency: strings.ToUpper(orderRequest.Currency),
+
+			LoyaltyPoints: orderRequest.LoyaltyPoints,
+			Waitlist:      orderRequest.Waitlist,
+
+			Guest:            orderRequest.Guest,
+			Occupancy:        orderRequest.Occupancy,
+			AdditionalGuests: orderRequest.AdditionalGuests,
+		}
+
+		if err = orderReservationEvent.ValidateGuests(); err != nil {
+			log.Error("Invalid guests: %v", err)
+			http.Error(w, "Invalid guests: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err = catalogService.ValidateOrder(r.Context(), orderReservationEvent); err != nil {
+			if errors.Is(err, model.ErrUnknownHotel) || errors.Is(err, model.ErrUnknownRoomType) {
+				log.Error("Order does not match catalog: %v", err)
+				http.Error(w, "Invalid hotel or room type: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if errors.Is(err, model.ErrOccupancyExceeded) {
+				log.Error("Guests do not fit room type: %v", err)
+				http.Error(w, "Invalid guests: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("Failed to validate order against catalog: %v", err)
+			http.Error(w, "Failed to validate order: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if orderReservationEvent.PromoCode != "" {
+			if _, err = promoService.ValidatePromoCode(r.Context(), orderReservationEvent); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					log.Error("Unknown promo code: %s", orderReservationEvent.PromoCode)
+					http.Error(w, "Invalid promo code: promo code not found", http.StatusBadRequest)
+					return
+				}
+
+				log.Error("Promo code %s can not be applied: %v", orderReservationEvent.PromoCode, err)
+				http.Error(w, "Invalid promo code: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		sync := isSyncOrderRequest(r)
+		if sync && !orderReservationEvent.Waitlist { // waitlisted order is accepted even without rooms
+			nights, err := bookingService.CheckAvailability(r.Context(), orderReservationEvent)
+			switch {
+			case err != nil:
+				// worker processes the order anyway and reports the reason in its status
+				log.Error("Failed to pre-check availability: %v", err)
+			case len(nights) > 0:
+				log.Info("No rooms for nights: %v", nights)
+				writeJSON(w, log, http.StatusConflict, orderAvailabilityResponse{
+					Status:            string(model.NoRooms),
+					UnavailableNights: nights,
+				})
+				return
+			}
 		}
 
 		err = q.Publish(r.Context(), queue.ReservedOrderRequest, orderReservationEvent)
@@ -98,6 +283,20 @@ func postReservationOrderHandler(log logger.Logger, q queue.Queue) func(http.Res
 			return
 		}
 
+		if sync {
+			if order, ok := waitOrderProcessed(r.Context(), log, bookingService, orderRequest.ID, syncTimeout); ok {
+				writeJSON(w, log, http.StatusOK, newOrderReservationResponse(order))
+				return
+			}
+
+			log.Info("Order %s is not processed in %s", orderRequest.ID, syncTimeout)
+			writeJSON(w, log, http.StatusAccepted, map[string]string{
+				"order_id": orderRequest.ID.String(),
+				"status":   "received",
+			})
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		err = json.NewEncoder(w).Encode(map[string]string{
@@ -111,6 +310,94 @@ func postReservationOrderHandler(log logger.Logger, q queue.Queue) func(http.Res
 	}
 }
 
+// waitOrderProcessed polls the order until booking worker moves it out of new status or timeout expires.
+func waitOrderProcessed(
+	ctx context.Context,
+	log logger.Logger,
+	bookingService service.BookingService,
+	id model.OrderID,
+	timeout time.Duration,
+) (model.Order, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(syncOrderPollInterval)
+	defer ticker.Stop()
+
+	for {
+		order, err := bookingService.GetOrder(ctx, id)
+		switch {
+		case err == nil && order.Status != model.New:
+			return order, true
+		case err != nil && !errors.Is(err, storage.ErrNotFound):
+			log.Error("Failed to retrieve order %s: %v", id, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return model.Order{}, false
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, log logger.Logger, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("Failed to encode the response: %v", err)
+	}
+}
+
+func newOrderReservationResponse(order model.Order) orderReservationResponse {
+	response := orderReservationResponse{
+		ID:         order.ID,
+		CreatedAt:  order.CreatedAt,
+		UpdatedAt:  order.UpdatedAt,
+		Status:     string(order.Status),
+		Reason:     order.FailureReason,
+		HotelID:    order.HotelID,
+		RoomTypeID: order.RoomTypeID,
+		UserEmail:  order.UserEmail,
+		From:       order.From,
+		To:         order.To,
+		PromoCode:  order.PromoCode,
+		RatePlanID: order.RatePlanID,
+		BlockCode:  order.BlockCode,
+		Currency:   order.DisplayCurrency,
+		CheckInAt:  order.CheckInAt,
+		CheckOutAt: order.CheckOutAt,
+		Changes:    order.Changes,
+
+		Alternatives: order.Alternatives,
+
+		Conversion:      order.Conversion,
+		PaymentSchedule: order.PaymentSchedule,
+		Refunds:         order.Refunds,
+
+		LoyaltyPoints: order.LoyaltyPoints,
+		Waitlist:      order.Waitlist,
+
+		Guest:            order.Guest,
+		Occupancy:        order.Occupancy,
+		AdditionalGuests: order.AdditionalGuests,
+	}
+
+	if len(order.Price.Nights) > 0 {
+		response.Price = &order.Price
+	}
+
+	if order.PaidAmount.Amount != 0 {
+		response.PaidAmount = &order.PaidAmount
+	}
+
+	if order.RefundedAmount.Amount != 0 {
+		response.RefundedAmount = &order.RefundedAmount
+	}
+
+	return response
+}
+
 func getReservationOrderHandler(log logger.Logger, bookingService service.BookingService) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Info("getReservationOrderHandler")
@@ -143,17 +430,7 @@ func getReservationOrderHandler(log logger.Logger, bookingService service.Bookin
 			return
 		}
 
-		response := orderReservationResponse{
-			ID:         order.ID,
-			CreatedAt:  order.CreatedAt,
-			UpdatedAt:  order.UpdatedAt,
-			Status:     string(order.Status),
-			HotelID:    order.HotelID,
-			RoomTypeID: order.RoomTypeID,
-			UserEmail:  order.UserEmail,
-			From:       order.From,
-			To:         order.To,
-		}
+		response := newOrderReservationResponse(order)
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -165,6 +442,145 @@ func getReservationOrderHandler(log logger.Logger, bookingService service.Bookin
 	}
 }
 
+func patchReservationOrderHandler(log logger.Logger, q queue.Queue, bookingService service.BookingService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("patchReservationOrderHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid Order ID format")
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		var modificationRequest orderModificationRequest
+		if err = json.NewDecoder(r.Body).Decode(&modificationRequest); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		if modificationRequest.RoomTypeID < 0 {
+			log.Error("Invalid room type ID")
+			http.Error(w, "Invalid room type ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		order, err := bookingService.GetOrder(r.Context(), orderID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Error("Order with id: `%s` not found", orderID)
+				http.Error(w, "Order not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to retrieve order")
+			http.Error(w, "Failed to retrieve order", http.StatusInternalServerError)
+			return
+		}
+
+		if !order.IsModifiable() {
+			log.Error("Order with id: `%s` can not be modified in status: %s", orderID, order.Status)
+			http.Error(w, "Order can not be modified in status: "+string(order.Status), http.StatusConflict)
+			return
+		}
+
+		// stored dates are hotel local, so current stay is requested by its check-in and check-out moments.
+		event := events.ModifyOrderEvent{
+			OrderID:    orderID,
+			CreatedAt:  time.Now().UTC(),
+			RoomTypeID: order.RoomTypeID,
+			From:       order.CheckInAt,
+			To:         order.CheckOutAt,
+		}
+		if modificationRequest.RoomTypeID != 0 {
+			event.RoomTypeID = modificationRequest.RoomTypeID
+		}
+		if !modificationRequest.From.IsZero() {
+			event.From = modificationRequest.From
+		}
+		if !modificationRequest.To.IsZero() {
+			event.To = modificationRequest.To
+		}
+
+		if !event.From.Before(event.To) {
+			log.Error("From date must be before To date")
+			http.Error(w, "From date must be before To date", http.StatusBadRequest)
+			return
+		}
+
+		if err = q.Publish(r.Context(), queue.ModifyOrderRequest, event); err != nil {
+			log.Error("Failed to publish the modify request: %v", err)
+			http.Error(w, "Failed to publish the modify request: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"order_id": orderID.String(),
+			"status":   "modification received",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+			http.Error(w, "Failed to encode the response", http.StatusInternalServerError)
+		}
+	}
+}
+
+func cancelReservationOrderHandler(log logger.Logger, q queue.Queue, bookingService service.BookingService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("cancelReservationOrderHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid Order ID format")
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		order, err := bookingService.GetOrder(r.Context(), orderID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Error("Order with id: `%s` not found", orderID)
+				http.Error(w, "Order not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to retrieve order")
+			http.Error(w, "Failed to retrieve order", http.StatusInternalServerError)
+			return
+		}
+
+		if !order.IsCancellable() {
+			log.Error("Order with id: `%s` can not be cancelled in status: %s", orderID, order.Status)
+			http.Error(w, "Order can not be cancelled in status: "+string(order.Status), http.StatusConflict)
+			return
+		}
+
+		err = q.Publish(r.Context(), queue.CancelOrderRequest, events.CancelOrderEvent{
+			OrderID:   orderID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Error("Failed to publish the cancel request: %v", err)
+			http.Error(w, "Failed to publish the cancel request: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"order_id": orderID.String(),
+			"status":   "cancellation received",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+			http.Error(w, "Failed to encode the response", http.StatusInternalServerError)
+		}
+	}
+}
+
 // debug handler, not for production, in real life use checking env.
 func registerDebugHandlers(mux *http.ServeMux, bookingService service.BookingService) {
 
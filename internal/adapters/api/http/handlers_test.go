@@ -19,6 +19,7 @@ import (
 	"aplication-design-test-task/internal/adapters/queue"
 	"aplication-design-test-task/internal/adapters/storage"
 	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
 	"aplication-design-test-task/internal/core/util"
 	"aplication-design-test-task/internal/logger"
 )
@@ -45,12 +46,21 @@ func TestPingHandler(t *testing.T) {
 func TestPostReservationOrderHandler(t *testing.T) {
 	log := logger.New()
 	queueMock := new(mock.MockQueue)
-	handler := postReservationOrderHandler(log, queueMock)
+	promoServiceMock := new(mock.MockPromoService)
+	loyaltyServiceMock := new(mock.MockLoyaltyService)
+	catalogServiceMock := new(mock.MockCatalogService)
+	catalogServiceMock.On("ValidateOrder", m.Anything, m.MatchedBy(func(o model.Order) bool { return o.HotelID == 404 })).
+		Return(fmt.Errorf("%w: 404", model.ErrUnknownHotel))
+	catalogServiceMock.On("ValidateOrder", m.Anything, m.MatchedBy(func(o model.Order) bool { return o.Occupancy.Guests() > 2 })).
+		Return(fmt.Errorf("%w: 4 guests, room type \"Standard\" fits 2", model.ErrOccupancyExceeded))
+	catalogServiceMock.On("ValidateOrder", m.Anything, m.Anything).Return(nil)
+	handler := postReservationOrderHandler(log, queueMock, new(mock.MockBookingService), catalogServiceMock, promoServiceMock, loyaltyServiceMock, time.Second)
 
 	validOrderRequest := orderReservationRequest{
 		HotelID:    1,
 		RoomTypeID: 1,
 		UserEmail:  "test@example.com",
+		Guest:      model.Guest{Name: "Test Guest"},
 		From:       util.NewDay(2024, 4, 1),
 		To:         util.NewDay(2024, 4, 7),
 	}
@@ -67,7 +77,10 @@ func TestPostReservationOrderHandler(t *testing.T) {
 			name:        "Valid Request",
 			requestBody: validOrderRequest,
 			prepareMock: func() {
-				queueMock.On("Publish", m.Anything, queue.ReservedOrderRequest, m.Anything).Return(nil)
+				queueMock.On("Publish", m.Anything, queue.ReservedOrderRequest, m.MatchedBy(func(msg any) bool {
+					event, ok := msg.(events.ReservationOrderEvent)
+					return ok && event.Guest.Name == "Test Guest" && event.Occupancy == model.Occupancy{Adults: 1}
+				})).Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   map[string]string{"order_id": "some", "status": "received"},
@@ -90,12 +103,158 @@ func TestPostReservationOrderHandler(t *testing.T) {
 				HotelID:    1,
 				RoomTypeID: 1,
 				UserEmail:  "test@example.com",
+				Guest:      model.Guest{Name: "Test Guest"},
 				From:       time.Now().Add(24 * time.Hour),
 				To:         time.Now(),
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "From date must be before To date",
 		},
+		{
+			name: "Unknown Hotel",
+			requestBody: orderReservationRequest{
+				HotelID:    404,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				Guest:      model.Guest{Name: "Test Guest"},
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid hotel or room type: unknown hotel",
+		},
+		{
+			name: "Invalid Currency",
+			requestBody: orderReservationRequest{
+				HotelID:    1,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+				Currency:   "euro",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid currency: invalid currency: \"EURO\" must be ISO 4217 code, e.g. EUR",
+		},
+		{
+			name: "Missing Lead Guest",
+			requestBody: orderReservationRequest{
+				HotelID:    1,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid guests: invalid guest: name is required",
+		},
+		{
+			name: "Too Many Additional Guests",
+			requestBody: orderReservationRequest{
+				HotelID:          1,
+				RoomTypeID:       1,
+				UserEmail:        "test@example.com",
+				From:             util.NewDay(2024, 4, 1),
+				To:               util.NewDay(2024, 4, 7),
+				Guest:            model.Guest{Name: "Test Guest"},
+				Occupancy:        model.Occupancy{Adults: 2},
+				AdditionalGuests: []model.Guest{{Name: "Second"}, {Name: "Third"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid guests: invalid occupancy",
+		},
+		{
+			name: "Occupancy Exceeds Room Type",
+			requestBody: orderReservationRequest{
+				HotelID:    1,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+				Guest:      model.Guest{Name: "Test Guest", Phone: "+49 30 123456"},
+				Occupancy:  model.Occupancy{Adults: 2, Children: 2},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid guests: occupancy exceeds room type capacity",
+		},
+		{
+			name: "Valid Promo Code",
+			requestBody: orderReservationRequest{
+				HotelID:    1,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				Guest:      model.Guest{Name: "Test Guest"},
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+				PromoCode:  "welcome10",
+			},
+			prepareMock: func() {
+				promoServiceMock.On("ValidatePromoCode", m.Anything, m.MatchedBy(func(o model.Order) bool {
+					return o.PromoCode == "WELCOME10"
+				})).Return(model.PromoCode{Code: "WELCOME10"}, nil)
+				queueMock.On("Publish", m.Anything, queue.ReservedOrderRequest, m.MatchedBy(func(msg any) bool {
+					event, ok := msg.(events.ReservationOrderEvent)
+					return ok && event.PromoCode == "WELCOME10"
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]string{"order_id": "some", "status": "received"},
+		},
+		{
+			name: "Unknown Promo Code",
+			requestBody: orderReservationRequest{
+				HotelID:    1,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				Guest:      model.Guest{Name: "Test Guest"},
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+				PromoCode:  "unknown",
+			},
+			prepareMock: func() {
+				promoServiceMock.On("ValidatePromoCode", m.Anything, m.MatchedBy(func(o model.Order) bool {
+					return o.PromoCode == "UNKNOWN"
+				})).Return(model.PromoCode{}, storage.ErrNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "promo code not found",
+		},
+		{
+			name: "Exhausted Promo Code",
+			requestBody: orderReservationRequest{
+				HotelID:    1,
+				RoomTypeID: 1,
+				UserEmail:  "test@example.com",
+				Guest:      model.Guest{Name: "Test Guest"},
+				From:       util.NewDay(2024, 4, 1),
+				To:         util.NewDay(2024, 4, 7),
+				PromoCode:  "SPRING",
+			},
+			prepareMock: func() {
+				promoServiceMock.On("ValidatePromoCode", m.Anything, m.MatchedBy(func(o model.Order) bool {
+					return o.PromoCode == "SPRING"
+				})).Return(model.PromoCode{}, model.ErrPromoUsageLimit)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  model.ErrPromoUsageLimit.Error(),
+		},
+		{
+			name: "Insufficient Loyalty Points",
+			requestBody: orderReservationRequest{
+				HotelID:       1,
+				RoomTypeID:    1,
+				UserEmail:     "poor@example.com",
+				From:          util.NewDay(2024, 4, 1),
+				To:            util.NewDay(2024, 4, 7),
+				LoyaltyPoints: 100,
+			},
+			prepareMock: func() {
+				loyaltyServiceMock.On("GetAccount", m.Anything, "poor@example.com").
+					Return(model.LoyaltyAccount{Balance: 99}, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  model.ErrInsufficientPoints.Error(),
+		},
 		// More test cases...
 	}
 
@@ -130,6 +289,104 @@ func TestPostReservationOrderHandler(t *testing.T) {
 				assert.Contains(t, string(responseBody), tc.expectedError)
 			}
 
+			queueMock.AssertExpectations(t)
+			promoServiceMock.AssertExpectations(t)
+			loyaltyServiceMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostReservationOrderHandler_Sync(t *testing.T) {
+	log := logger.New()
+
+	catalogServiceMock := new(mock.MockCatalogService)
+	catalogServiceMock.On("ValidateOrder", m.Anything, m.Anything).Return(nil)
+
+	orderRequest := func(email string, waitlist bool) string {
+		body, _ := json.Marshal(orderReservationRequest{
+			HotelID:    1,
+			RoomTypeID: 1,
+			UserEmail:  email,
+			Guest:      model.Guest{Name: "Test Guest"},
+			From:       util.NewDay(2024, 4, 1),
+			To:         util.NewDay(2024, 4, 4),
+			Waitlist:   waitlist,
+		})
+		return string(body)
+	}
+
+	byEmail := func(email string) any {
+		return m.MatchedBy(func(o model.Order) bool { return o.UserEmail == email })
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		header         string
+		body           string
+		orderStatus    model.Status // status of order stored by worker, empty if not published
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Sold out",
+			url:            "/api/v1/order/?sync=true",
+			body:           orderRequest("sold-out@example.com", false),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"unavailable_nights":["2024-04-02T00:00:00Z"]`,
+		},
+		{
+			name:           "Sold out with waitlist",
+			url:            "/api/v1/order/?sync=true",
+			body:           orderRequest("sold-out@example.com", true),
+			orderStatus:    model.NoRooms,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"no_rooms"`,
+		},
+		{
+			name:           "Booked",
+			url:            "/api/v1/order/",
+			header:         "true",
+			body:           orderRequest("test@example.com", false),
+			orderStatus:    model.Booked,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"booked"`,
+		},
+		{
+			name:           "Not processed in time",
+			url:            "/api/v1/order/?sync=1",
+			body:           orderRequest("test@example.com", false),
+			orderStatus:    model.New,
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"status":"received"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueMock := new(mock.MockQueue)
+			bookingServiceMock := new(mock.MockBookingService)
+			bookingServiceMock.On("CheckAvailability", m.Anything, byEmail("sold-out@example.com")).
+				Return([]time.Time{util.NewDay(2024, 4, 2)}, nil)
+			bookingServiceMock.On("CheckAvailability", m.Anything, m.Anything).Return([]time.Time(nil), nil)
+
+			if tt.orderStatus != "" {
+				queueMock.On("Publish", m.Anything, queue.ReservedOrderRequest, m.Anything).Return(nil)
+				bookingServiceMock.On("GetOrder", m.Anything, m.Anything).Return(model.Order{Status: tt.orderStatus}, nil)
+			}
+
+			handler := postReservationOrderHandler(log, queueMock, bookingServiceMock, catalogServiceMock, nil, nil, 200*time.Millisecond)
+
+			r := httptest.NewRequest("POST", tt.url, bytes.NewBufferString(tt.body))
+			if tt.header != "" {
+				r.Header.Set("X-Sync", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+
 			queueMock.AssertExpectations(t)
 		})
 	}
@@ -150,6 +407,12 @@ func TestGetReservationOrderHandler2(t *testing.T) {
 		Status:     "new",
 	}
 
+	restrictedUUID := uuid.New()
+	restricted := order
+	restricted.ID = restrictedUUID
+	restricted.Status = model.Restricted
+	restricted.FailureReason = "closed_to_arrival: arrival is not allowed on 2024-04-01"
+
 	bookingServiceMock := new(mock.MockBookingService)
 
 	tests := []struct {
@@ -175,6 +438,22 @@ func TestGetReservationOrderHandler2(t *testing.T) {
 				To:         order.To,
 				Status:     string(order.Status)},
 		},
+		{
+			name:    "Restricted Order",
+			orderID: restrictedUUID.String(),
+			prepareMock: func() {
+				bookingServiceMock.On("GetOrder", m.Anything, restrictedUUID).Return(restricted, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: &orderReservationResponse{ID: restrictedUUID,
+				HotelID:    restricted.HotelID,
+				RoomTypeID: restricted.RoomTypeID,
+				UserEmail:  restricted.UserEmail,
+				From:       restricted.From,
+				To:         restricted.To,
+				Status:     string(model.Restricted),
+				Reason:     restricted.FailureReason},
+		},
 		{
 			name:           "Order ID Missing",
 			orderID:        "",
@@ -234,3 +513,906 @@ func TestGetReservationOrderHandler2(t *testing.T) {
 		})
 	}
 }
+
+func TestCancelReservationOrderHandler(t *testing.T) {
+	log := logger.New()
+
+	bookedID := uuid.New()
+	cancelledID := uuid.New()
+	notFoundID := uuid.New()
+
+	bookingServiceMock := new(mock.MockBookingService)
+	bookingServiceMock.On("GetOrder", m.Anything, bookedID).Return(model.Order{ID: bookedID, Status: model.Booked}, nil)
+	bookingServiceMock.On("GetOrder", m.Anything, cancelledID).Return(model.Order{ID: cancelledID, Status: model.Cancelled}, nil)
+	bookingServiceMock.On("GetOrder", m.Anything, notFoundID).Return(model.Order{}, storage.ErrNotFound)
+
+	tests := []struct {
+		name           string
+		orderID        string
+		prepareMock    func(q *mock.MockQueue)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:    "Booked order",
+			orderID: bookedID.String(),
+			prepareMock: func(q *mock.MockQueue) {
+				q.On("Publish", m.Anything, queue.CancelOrderRequest, m.MatchedBy(func(msg any) bool {
+					event, ok := msg.(events.CancelOrderEvent)
+					return ok && event.OrderID == bookedID
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Already cancelled order",
+			orderID:        cancelledID.String(),
+			expectedStatus: http.StatusConflict,
+			expectedError:  "Order can not be cancelled",
+		},
+		{
+			name:           "Order not found",
+			orderID:        notFoundID.String(),
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "Order not found",
+		},
+		{
+			name:           "Invalid Order ID",
+			orderID:        "invalid-uuid",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid Order ID format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueMock := new(mock.MockQueue)
+			if tt.prepareMock != nil {
+				tt.prepareMock(queueMock)
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /api/v1/order/{id}/cancel", cancelReservationOrderHandler(log, queueMock, bookingServiceMock))
+
+			req := httptest.NewRequest("POST", "/api/v1/order/"+tt.orderID+"/cancel", nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			queueMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchReservationOrderHandler(t *testing.T) {
+	log := logger.New()
+
+	bookedID := uuid.New()
+	cancelledID := uuid.New()
+
+	booked := model.Order{
+		ID:         bookedID,
+		HotelID:    1,
+		RoomTypeID: 1,
+		From:       util.NewDay(2024, 4, 1),
+		To:         util.NewDay(2024, 4, 3),
+		CheckInAt:  time.Date(2024, 4, 1, 13, 0, 0, 0, time.UTC),
+		CheckOutAt: time.Date(2024, 4, 3, 9, 0, 0, 0, time.UTC),
+		Status:     model.Booked,
+	}
+
+	bookingServiceMock := new(mock.MockBookingService)
+	bookingServiceMock.On("GetOrder", m.Anything, bookedID).Return(booked, nil)
+	bookingServiceMock.On("GetOrder", m.Anything, cancelledID).Return(model.Order{ID: cancelledID, Status: model.Cancelled}, nil)
+
+	tests := []struct {
+		name           string
+		orderID        uuid.UUID
+		requestBody    any
+		prepareMock    func(q *mock.MockQueue)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:        "Change room type only",
+			orderID:     bookedID,
+			requestBody: orderModificationRequest{RoomTypeID: 2},
+			prepareMock: func(q *mock.MockQueue) {
+				q.On("Publish", m.Anything, queue.ModifyOrderRequest, m.MatchedBy(func(msg any) bool {
+					event, ok := msg.(events.ModifyOrderEvent)
+					return ok && event.OrderID == bookedID && event.RoomTypeID == 2 &&
+						event.From.Equal(booked.CheckInAt) && event.To.Equal(booked.CheckOutAt)
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "New from after current to",
+			orderID:        bookedID,
+			requestBody:    orderModificationRequest{From: util.NewDay(2024, 4, 5)},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "From date must be before To date",
+		},
+		{
+			name:           "Cancelled order",
+			orderID:        cancelledID,
+			requestBody:    orderModificationRequest{RoomTypeID: 2},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "Order can not be modified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueMock := new(mock.MockQueue)
+			if tt.prepareMock != nil {
+				tt.prepareMock(queueMock)
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("PATCH /api/v1/order/{id}", patchReservationOrderHandler(log, queueMock, bookingServiceMock))
+
+			bodyBytes, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest("PATCH", "/api/v1/order/"+tt.orderID.String(), bytes.NewBuffer(bodyBytes))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			queueMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoyaltyHandlers(t *testing.T) {
+	log := logger.New()
+
+	loyaltyServiceMock := new(mock.MockLoyaltyService)
+	loyaltyServiceMock.On("GetAccount", m.Anything, "guest@mail.ru").
+		Return(model.LoyaltyAccount{UserEmail: "guest@mail.ru", Tier: model.SilverTier, Balance: 1_500}, nil)
+	loyaltyServiceMock.On("GetAccount", m.Anything, "unknown@mail.ru").
+		Return(model.LoyaltyAccount{}, storage.ErrNotFound)
+	loyaltyServiceMock.On("GetLedger", m.Anything, "guest@mail.ru").
+		Return([]model.LoyaltyEntry{{Kind: model.EarnEntry, Points: 1_500}}, nil)
+
+	mux := http.NewServeMux()
+	registerLoyaltyHandlers(mux, log, loyaltyServiceMock)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Account", "/api/v1/loyalty/guest@mail.ru", http.StatusOK, `"tier":"silver"`},
+		{"Unknown account", "/api/v1/loyalty/unknown@mail.ru", http.StatusNotFound, "Loyalty account not found"},
+		{"Ledger", "/api/v1/loyalty/guest@mail.ru/ledger", http.StatusOK, `"kind":"earn"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestWaitlistHandlers(t *testing.T) {
+	log := logger.New()
+
+	noRoomsID := uuid.New()
+	bookedID := uuid.New()
+	notFoundID := uuid.New()
+
+	bookingServiceMock := new(mock.MockBookingService)
+	bookingServiceMock.On("GetOrder", m.Anything, noRoomsID).Return(model.Order{ID: noRoomsID, Status: model.NoRooms}, nil)
+	bookingServiceMock.On("GetOrder", m.Anything, bookedID).Return(model.Order{ID: bookedID, Status: model.Booked}, nil)
+	bookingServiceMock.On("GetOrder", m.Anything, notFoundID).Return(model.Order{}, storage.ErrNotFound)
+	bookingServiceMock.On("GetListWaitlist", m.Anything).
+		Return([]model.WaitlistEntry{{OrderID: noRoomsID, Status: model.WaitlistWaiting}}, nil)
+
+	queueMock := new(mock.MockQueue)
+	queueMock.On("Publish", m.Anything, queue.JoinWaitlistRequest, m.MatchedBy(func(msg any) bool {
+		event, ok := msg.(events.JoinWaitlistEvent)
+		return ok && event.OrderID == noRoomsID
+	})).Return(nil).Once()
+
+	mux := http.NewServeMux()
+	registerWaitlistHandlers(mux, log, queueMock, bookingServiceMock)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Join", "POST", "/api/v1/order/" + noRoomsID.String() + "/waitlist", http.StatusAccepted, "waitlist request received"},
+		{"Booked order", "POST", "/api/v1/order/" + bookedID.String() + "/waitlist", http.StatusConflict, "Order can not join waitlist"},
+		{"Order not found", "POST", "/api/v1/order/" + notFoundID.String() + "/waitlist", http.StatusNotFound, "Order not found"},
+		{"Invalid Order ID", "POST", "/api/v1/order/invalid-uuid/waitlist", http.StatusBadRequest, "Invalid Order ID format"},
+		{"List", "GET", "/api/v1/waitlist", http.StatusOK, noRoomsID.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+
+	queueMock.AssertExpectations(t)
+}
+
+func TestPostInventoryUpdateHandler(t *testing.T) {
+	log := logger.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		prepareMock    func(q *mock.MockQueue)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Quota increase",
+			body: `{"hotel_id":1,"room_type_id":2,"from":"2024-04-01T00:00:00Z","to":"2024-04-03T00:00:00Z","delta":2}`,
+			prepareMock: func(q *mock.MockQueue) {
+				q.On("Publish", m.Anything, queue.InventoryUpdateRequest, m.MatchedBy(func(msg any) bool {
+					event, ok := msg.(events.InventoryUpdateEvent)
+					return ok && event.HotelID == 1 && event.RoomTypeID == 2 && event.Delta == 2
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Zero delta",
+			body:           `{"hotel_id":1,"room_type_id":2,"from":"2024-04-01T00:00:00Z","to":"2024-04-03T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid quota delta",
+		},
+		{
+			name:           "Invalid period",
+			body:           `{"hotel_id":1,"room_type_id":2,"from":"2024-04-03T00:00:00Z","to":"2024-04-01T00:00:00Z","delta":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "From date must be before To date",
+		},
+		{
+			name:           "Invalid hotel",
+			body:           `{"room_type_id":2,"delta":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid hotel or room type ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueMock := new(mock.MockQueue)
+			if tt.prepareMock != nil {
+				tt.prepareMock(queueMock)
+			}
+
+			mux := http.NewServeMux()
+			registerInventoryHandlers(mux, log, queueMock)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/room/quota", bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			queueMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOverbookingHandlers(t *testing.T) {
+	log := logger.New()
+
+	ruleID := uuid.New()
+	rule := model.OverbookingRule{HotelID: 1, LimitType: model.PercentOverbooking, Limit: 5}
+
+	overbookingServiceMock := new(mock.MockOverbookingService)
+	overbookingServiceMock.On("CreateRule", m.Anything, rule).Return(model.OverbookingRule{
+		ID: ruleID, HotelID: 1, LimitType: model.PercentOverbooking, Limit: 5,
+	}, nil)
+	overbookingServiceMock.On("CreateRule", m.Anything, model.OverbookingRule{HotelID: 1}).
+		Return(model.OverbookingRule{}, fmt.Errorf("%w: unknown limit type", model.ErrOverbookingRuleInvalid))
+	overbookingServiceMock.On("DeleteRule", m.Anything, ruleID).Return(nil)
+	overbookingServiceMock.On("DeleteRule", m.Anything, m.Anything).Return(storage.ErrNotFound)
+	overbookingServiceMock.On("GetOversoldReport", m.Anything, 0).Return([]model.OversoldNight{
+		{HotelID: 2, RoomTypeID: 1, Date: util.NewDay(2024, 4, 2), Capacity: 10, Sold: 11, Oversold: 1},
+	}, nil)
+	overbookingServiceMock.On("GetOversoldReport", m.Anything, 1).Return([]model.OversoldNight{}, nil)
+
+	mux := http.NewServeMux()
+	registerOverbookingHandlers(mux, log, overbookingServiceMock)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Create rule", "POST", "/api/v1/overbooking", `{"hotel_id":1,"limit_type":"percent","limit":5}`, http.StatusCreated, ruleID.String()},
+		{"Invalid rule", "POST", "/api/v1/overbooking", `{"hotel_id":1}`, http.StatusBadRequest, "invalid overbooking rule"},
+		{"Delete rule", "DELETE", "/api/v1/overbooking/" + ruleID.String(), "", http.StatusNoContent, ""},
+		{"Delete unknown rule", "DELETE", "/api/v1/overbooking/" + uuid.NewString(), "", http.StatusNotFound, "Overbooking rule not found"},
+		{"Report", "GET", "/api/v1/overbooking/report", "", http.StatusOK, `"oversold":1`},
+		{"Report for hotel", "GET", "/api/v1/overbooking/report?hotel_id=1", "", http.StatusOK, "[]"},
+		{"Report for invalid hotel", "GET", "/api/v1/overbooking/report?hotel_id=x", "", http.StatusBadRequest, "Invalid hotel ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestPutStayRestrictionsHandler(t *testing.T) {
+	log := logger.New()
+
+	tests := []struct {
+		name           string
+		body           string
+		prepareMock    func(q *mock.MockQueue)
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name: "Weekend restrictions",
+			body: `{"hotel_id":1,"room_type_id":2,"from":"2024-04-05T00:00:00Z","to":"2024-04-06T00:00:00Z","min_stay":3,"closed_to_arrival":true}`,
+			prepareMock: func(q *mock.MockQueue) {
+				q.On("Publish", m.Anything, queue.InventoryUpdateRequest, m.MatchedBy(func(msg any) bool {
+					event, ok := msg.(events.StayRestrictionsUpdateEvent)
+					return ok && event.Restrictions == model.StayRestrictions{MinStay: 3, ClosedToArrival: true}
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Min stay greater than max stay",
+			body:           `{"hotel_id":1,"room_type_id":2,"from":"2024-04-05T00:00:00Z","to":"2024-04-06T00:00:00Z","min_stay":3,"max_stay":2}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid stay restrictions",
+		},
+		{
+			name:           "Invalid room type",
+			body:           `{"hotel_id":1,"min_stay":3}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid hotel or room type ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queueMock := new(mock.MockQueue)
+			if tt.prepareMock != nil {
+				tt.prepareMock(queueMock)
+			}
+
+			mux := http.NewServeMux()
+			registerInventoryHandlers(mux, log, queueMock)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/room/restrictions", bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			queueMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCatalogHandlers(t *testing.T) {
+	log := logger.New()
+
+	hotel := model.Hotel{ID: 3, Name: "Alster Lake", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00"}
+	roomType := model.RoomType{ID: 1, HotelID: 3, Name: "Standard", Capacity: 2}
+
+	catalogServiceMock := new(mock.MockCatalogService)
+	catalogServiceMock.On("CreateHotel", m.Anything, hotel).Return(nil).Once()
+	catalogServiceMock.On("CreateHotel", m.Anything, hotel).Return(storage.ErrDuplicateConstraint)
+	catalogServiceMock.On("CreateHotel", m.Anything, model.Hotel{ID: 4}).Return(fmt.Errorf("%w: name is required", model.ErrHotelInvalid))
+	catalogServiceMock.On("GetHotel", m.Anything, 3).Return(hotel, nil)
+	catalogServiceMock.On("GetHotel", m.Anything, 404).Return(model.Hotel{}, storage.ErrNotFound)
+	catalogServiceMock.On("DeleteHotel", m.Anything, 1).Return(model.ErrHotelInUse)
+	catalogServiceMock.On("CreateRoomType", m.Anything, roomType).Return(nil)
+	catalogServiceMock.On("GetListRoomTypes", m.Anything, 3).Return([]model.RoomType{roomType}, nil)
+	catalogServiceMock.On("UpdateRoomType", m.Anything, model.RoomType{ID: 2, HotelID: 3, Name: "Suite", Capacity: 4}).Return(storage.ErrNotFound)
+	catalogServiceMock.On("DeleteRoomType", m.Anything, 3, 1).Return(nil)
+
+	mux := http.NewServeMux()
+	registerCatalogHandlers(mux, log, catalogServiceMock)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Create hotel", "POST", "/api/v1/hotel", `{"id":3,"name":"Alster Lake","timezone":"Europe/Berlin","check_in_time":"15:00","check_out_time":"11:00"}`, http.StatusCreated, `"name":"Alster Lake"`},
+		{"Duplicate hotel", "POST", "/api/v1/hotel", `{"id":3,"name":"Alster Lake","timezone":"Europe/Berlin","check_in_time":"15:00","check_out_time":"11:00"}`, http.StatusConflict, "Hotel already exists"},
+		{"Invalid hotel", "POST", "/api/v1/hotel", `{"id":4}`, http.StatusBadRequest, "name is required"},
+		{"Get hotel", "GET", "/api/v1/hotel/3", "", http.StatusOK, `"timezone":"Europe/Berlin"`},
+		{"Get unknown hotel", "GET", "/api/v1/hotel/404", "", http.StatusNotFound, "Hotel not found"},
+		{"Get invalid hotel ID", "GET", "/api/v1/hotel/x", "", http.StatusBadRequest, "Invalid hotel ID"},
+		{"Delete hotel with room types", "DELETE", "/api/v1/hotel/1", "", http.StatusConflict, model.ErrHotelInUse.Error()},
+		{"Create room type", "POST", "/api/v1/hotel/3/room-type", `{"id":1,"name":"Standard","capacity":2}`, http.StatusCreated, `"hotel_id":3`},
+		{"List room types", "GET", "/api/v1/hotel/3/room-type", "", http.StatusOK, `"name":"Standard"`},
+		{"Update unknown room type", "PUT", "/api/v1/hotel/3/room-type/2", `{"name":"Suite","capacity":4}`, http.StatusNotFound, "Room type not found"},
+		{"Delete room type", "DELETE", "/api/v1/hotel/3/room-type/1", "", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestAllotmentHandlers(t *testing.T) {
+	log := logger.New()
+
+	from := util.ToDay(time.Now()).AddDate(0, 1, 0)
+	stay := fmt.Sprintf(`"from":%q,"to":%q,"rooms":5`, from.Format(time.RFC3339), from.AddDate(0, 0, 3).Format(time.RFC3339))
+	release := fmt.Sprintf(`"release_at":%q`, from.AddDate(0, 0, -7).Format(time.RFC3339))
+	period := `"hotel_id":1,"room_type_id":1,` + stay + `,` + release
+
+	bookingServiceMock := new(mock.MockBookingService)
+	bookingServiceMock.On("GetAllotment", m.Anything, "EXPO").Return(model.Allotment{}, storage.ErrNotFound)
+	bookingServiceMock.On("GetAllotment", m.Anything, "FAIR").
+		Return(model.Allotment{Code: "FAIR", Status: model.AllotmentActive}, nil)
+	bookingServiceMock.On("GetListAllotments", m.Anything).
+		Return([]model.Allotment{{Code: "FAIR", Status: model.AllotmentActive}}, nil)
+
+	queueMock := new(mock.MockQueue)
+	queueMock.On("Publish", m.Anything, queue.InventoryUpdateRequest, m.MatchedBy(func(msg any) bool {
+		event, ok := msg.(events.AllotmentEvent)
+		return ok && event.Code == "EXPO" && event.Rooms == 5
+	})).Return(nil)
+
+	catalogServiceMock := new(mock.MockCatalogService)
+	catalogServiceMock.On("ValidateOrder", m.Anything, model.Order{HotelID: 1, RoomTypeID: 4}).
+		Return(fmt.Errorf("%w: 4 in hotel 1", model.ErrUnknownRoomType))
+	catalogServiceMock.On("ValidateOrder", m.Anything, m.Anything).Return(nil)
+
+	mux := http.NewServeMux()
+	registerAllotmentHandlers(mux, log, queueMock, bookingServiceMock, catalogServiceMock)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Create", "POST", "/api/v1/allotment", `{"code":" expo ",` + period + `}`, http.StatusAccepted, `"code":"EXPO"`},
+		{"Duplicate", "POST", "/api/v1/allotment", `{"code":"fair",` + period + `}`, http.StatusConflict, "Allotment already exists"},
+		{"Invalid", "POST", "/api/v1/allotment", `{"code":"EXPO","hotel_id":1,"room_type_id":1}`, http.StatusBadRequest, "invalid allotment"},
+		{"Released in the past", "POST", "/api/v1/allotment", `{"code":"EXPO","hotel_id":1,"room_type_id":1,` + stay + `,"release_at":"2024-03-15T00:00:00Z"}`, http.StatusBadRequest, "release date must be in the future"},
+		{"Unknown room type", "POST", "/api/v1/allotment", `{"code":"EXPO","hotel_id":1,"room_type_id":4,` + stay + `,` + release + `}`, http.StatusBadRequest, "Invalid hotel or room type"},
+		{"Get", "GET", "/api/v1/allotment/FAIR", "", http.StatusOK, `"status":"active"`},
+		{"Not found", "GET", "/api/v1/allotment/EXPO", "", http.StatusNotFound, "Allotment not found"},
+		{"List", "GET", "/api/v1/allotment", "", http.StatusOK, `"code":"FAIR"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+
+	queueMock.AssertExpectations(t)
+}
+
+func TestGetAvailabilityHandler(t *testing.T) {
+	log := logger.New()
+
+	bookingServiceMock := new(mock.MockBookingService)
+	bookingServiceMock.On("SearchAvailability", m.Anything, model.AvailabilityQuery{
+		From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 3), Rooms: 1,
+	}).Return([]model.AvailabilityOffer{{HotelID: 1, RoomTypeID: 1, Available: 10, Bookable: true}}, nil)
+	bookingServiceMock.On("SearchAvailability", m.Anything, model.AvailabilityQuery{
+		HotelID: 2, From: util.NewDay(2024, 4, 3), To: util.NewDay(2024, 4, 1), Rooms: 2,
+	}).Return([]model.AvailabilityOffer(nil), fmt.Errorf("%w: from date must be before to date", model.ErrAvailabilityQueryInvalid))
+
+	mux := http.NewServeMux()
+	registerAvailabilityHandlers(mux, log, bookingServiceMock)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Search", "/api/v1/availability?from=2024-04-01&to=2024-04-03", http.StatusOK, `"available":10`},
+		{"Invalid period", "/api/v1/availability?from=2024-04-03&to=2024-04-01&rooms=2&hotel_id=2", http.StatusBadRequest, "from date must be before to date"},
+		{"Invalid date", "/api/v1/availability?from=01.04.2024&to=2024-04-03", http.StatusBadRequest, "Invalid from date"},
+		{"Invalid rooms", "/api/v1/availability?from=2024-04-01&to=2024-04-03&rooms=many", http.StatusBadRequest, "Invalid rooms"},
+		{"Invalid hotel", "/api/v1/availability?from=2024-04-01&to=2024-04-03&hotel_id=-1", http.StatusBadRequest, "Invalid hotel ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestPostPaymentCallbackHandler(t *testing.T) {
+	log := logger.New()
+	secret := []byte("secret")
+
+	paymentID, unknownID := uuid.New(), uuid.New()
+	paid := fmt.Sprintf(`{"event_id":"evt_1","payment_id":%q,"status":"succeeded","transaction_id":"psp_1"}`, paymentID)
+	unknown := fmt.Sprintf(`{"event_id":"evt_2","payment_id":%q,"status":"succeeded"}`, unknownID)
+	invalid := fmt.Sprintf(`{"event_id":"evt_3","payment_id":%q,"status":"refunded"}`, paymentID)
+	mismatch := fmt.Sprintf(`{"event_id":"evt_4","payment_id":%q,"status":"succeeded","amount":{"amount":1,"currency":"EUR"}}`, paymentID)
+
+	paymentServiceMock := new(mock.MockPaymentService)
+	paymentServiceMock.On("CallbackHandler", m.Anything, m.MatchedBy(func(c model.PaymentCallback) bool {
+		return c.EventID == "evt_4"
+	})).Return(fmt.Errorf("%w: callback evt_4 is for 0.01 EUR", model.ErrPaymentAmountMismatch))
+	paymentServiceMock.On("CallbackHandler", m.Anything, m.MatchedBy(func(c model.PaymentCallback) bool {
+		return c.PaymentID == paymentID && c.Status == model.PaymentSucceeded
+	})).Return(nil) // redelivery of processed callback is accepted by service as well
+	paymentServiceMock.On("CallbackHandler", m.Anything, m.MatchedBy(func(c model.PaymentCallback) bool {
+		return c.PaymentID == unknownID
+	})).Return(storage.ErrNotFound)
+	paymentServiceMock.On("CallbackHandler", m.Anything, m.MatchedBy(func(c model.PaymentCallback) bool {
+		return c.Status == "refunded"
+	})).Return(fmt.Errorf("%w: unknown status %q", model.ErrPaymentCallbackInvalid, "refunded"))
+
+	mux := http.NewServeMux()
+	registerPaymentHandlers(mux, log, paymentServiceMock, secret)
+
+	now := time.Now().Unix()
+	tests := []struct {
+		name           string
+		body           string
+		timestamp      int64
+		signature      string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Succeeded payment", paid, now, util.Sign(secret, now, []byte(paid)), http.StatusOK, ""},
+		{"Redelivered callback", paid, now, util.Sign(secret, now, []byte(paid)), http.StatusOK, ""},
+		{"Unknown payment", unknown, now, util.Sign(secret, now, []byte(unknown)), http.StatusNotFound, "Payment not found"},
+		{"Invalid callback", invalid, now, util.Sign(secret, now, []byte(invalid)), http.StatusBadRequest, "invalid payment callback"},
+		{"Amount mismatch", mismatch, now, util.Sign(secret, now, []byte(mismatch)), http.StatusConflict, "callback amount does not match payment"},
+		{"Invalid JSON", "{", now, util.Sign(secret, now, []byte("{")), http.StatusBadRequest, "Invalid request body"},
+		{"Wrong secret", paid, now, util.Sign([]byte("other"), now, []byte(paid)), http.StatusUnauthorized, "Invalid signature"},
+		{"Tampered body", unknown, now, util.Sign(secret, now, []byte(paid)), http.StatusUnauthorized, "Invalid signature"},
+		{"Replayed with new timestamp", paid, now + 1, util.Sign(secret, now, []byte(paid)), http.StatusUnauthorized, "Invalid signature"},
+		{"Replayed stale request", paid, now - 600, util.Sign(secret, now-600, []byte(paid)), http.StatusUnauthorized, "out of tolerance"},
+		{"Missing timestamp", paid, 0, "", http.StatusUnauthorized, "Invalid signature timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/payment/callback", bytes.NewBufferString(tt.body))
+			if tt.timestamp != 0 {
+				r.Header.Set(signatureTimestampHeader, fmt.Sprint(tt.timestamp))
+			}
+			r.Header.Set(signatureHeader, tt.signature)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+
+	paymentServiceMock.AssertNumberOfCalls(t, "CallbackHandler", 5)
+}
+
+func TestPricingHandlers(t *testing.T) {
+	log := logger.New()
+
+	plan := model.RatePlan{ID: 1, HotelID: 3, Code: "FLEX", Name: "Flexible", Refundable: true}
+
+	pricingServiceMock := new(mock.MockPricingService)
+	pricingServiceMock.On("GetListRatePlans", m.Anything, 3).Return([]model.RatePlan{plan}, nil)
+	pricingServiceMock.On("GetListRatePlans", m.Anything, 4).Return([]model.RatePlan{}, fmt.Errorf("storage is down"))
+
+	mux := http.NewServeMux()
+	registerPricingHandlers(mux, log, pricingServiceMock)
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"List rate plans", "/api/v1/hotel/3/rate-plan", http.StatusOK, `"code":"FLEX"`},
+		{"Failed to list", "/api/v1/hotel/4/rate-plan", http.StatusInternalServerError, "Failed to get rate plans"},
+		{"Invalid hotel ID", "/api/v1/hotel/x/rate-plan", http.StatusBadRequest, "Invalid hotel ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	log := logger.New()
+
+	subscriptionID, deliveryID := uuid.New(), uuid.New()
+	subscription := model.WebhookSubscription{Partner: "ota", URL: "https://ota.example.com/hooks", Statuses: []model.Status{model.Paid}}
+
+	webhookServiceMock := new(mock.MockWebhookService)
+	webhookServiceMock.On("CreateSubscription", m.Anything, subscription).Return(model.WebhookSubscription{
+		ID: subscriptionID, Partner: "ota", URL: subscription.URL, Secret: "whsec_1", Statuses: subscription.Statuses,
+	}, nil)
+	webhookServiceMock.On("CreateSubscription", m.Anything, model.WebhookSubscription{Partner: "ota"}).
+		Return(model.WebhookSubscription{}, fmt.Errorf("%w: url must be absolute http(s) URL", model.ErrWebhookSubscriptionInvalid))
+	webhookServiceMock.On("GetListSubscriptions", m.Anything).Return([]model.WebhookSubscription{
+		{ID: subscriptionID, Partner: "ota", URL: subscription.URL, Secret: "whsec_1"},
+	}, nil)
+	webhookServiceMock.On("DeleteSubscription", m.Anything, subscriptionID).Return(nil)
+	webhookServiceMock.On("DeleteSubscription", m.Anything, m.Anything).Return(storage.ErrNotFound)
+	webhookServiceMock.On("GetListDeliveries", m.Anything, subscriptionID).Return([]model.WebhookDelivery{
+		{ID: deliveryID, SubscriptionID: subscriptionID, Status: model.DeliveryFailed, Attempts: 6},
+	}, nil)
+	webhookServiceMock.On("GetListDeliveries", m.Anything, m.Anything).Return([]model.WebhookDelivery(nil), storage.ErrNotFound)
+	webhookServiceMock.On("Redeliver", m.Anything, deliveryID).Return(model.WebhookDelivery{
+		ID: uuid.New(), SubscriptionID: subscriptionID, RedeliveryOf: deliveryID, Status: model.DeliveryPending,
+	}, nil)
+	webhookServiceMock.On("Redeliver", m.Anything, m.Anything).Return(model.WebhookDelivery{}, storage.ErrNotFound)
+
+	mux := http.NewServeMux()
+	registerWebhookHandlers(mux, log, webhookServiceMock, []byte("admin-token"))
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"Create subscription", "POST", "/api/v1/webhook", `{"partner":"ota","url":"https://ota.example.com/hooks","statuses":["paid"]}`, http.StatusCreated, `"secret":"whsec_1"`},
+		{"Invalid subscription", "POST", "/api/v1/webhook", `{"partner":"ota"}`, http.StatusBadRequest, "invalid webhook subscription"},
+		{"Invalid JSON", "POST", "/api/v1/webhook", `{`, http.StatusBadRequest, "Invalid request body"},
+		{"List subscriptions", "GET", "/api/v1/webhook", "", http.StatusOK, subscriptionID.String()},
+		{"Delete subscription", "DELETE", "/api/v1/webhook/" + subscriptionID.String(), "", http.StatusNoContent, ""},
+		{"Delete unknown subscription", "DELETE", "/api/v1/webhook/" + uuid.NewString(), "", http.StatusNotFound, "Webhook subscription not found"},
+		{"Delivery log", "GET", "/api/v1/webhook/" + subscriptionID.String() + "/deliveries", "", http.StatusOK, `"status":"failed"`},
+		{"Delivery log of unknown subscription", "GET", "/api/v1/webhook/" + uuid.NewString() + "/deliveries", "", http.StatusNotFound, "Webhook subscription not found"},
+		{"Invalid subscription ID", "GET", "/api/v1/webhook/x/deliveries", "", http.StatusBadRequest, "Invalid subscription ID format"},
+		{"Redeliver", "POST", "/api/v1/webhook/delivery/" + deliveryID.String() + "/redeliver", "", http.StatusAccepted, deliveryID.String()},
+		{"Redeliver unknown delivery", "POST", "/api/v1/webhook/delivery/" + uuid.NewString() + "/redeliver", "", http.StatusNotFound, "not found"},
+	}
+
+	admin := func(req *http.Request) *http.Request {
+		req.Header.Set("Authorization", "Bearer admin-token")
+		return req
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, admin(httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, admin(httptest.NewRequest("GET", "/api/v1/webhook", nil)))
+	assert.NotContains(t, w.Body.String(), "whsec_1", "secret is shown only on creation")
+
+	for name, authorization := range map[string]string{"No token": "", "Wrong token": "Bearer other-token", "Not bearer": "admin-token"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/webhook", bytes.NewBufferString(`{"partner":"ota","url":"https://evil.example.com/hooks"}`))
+			req.Header.Set("Authorization", authorization)
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+	webhookServiceMock.AssertNumberOfCalls(t, "CreateSubscription", 2)
+}
+
+func TestInvoiceHandlers(t *testing.T) {
+	log := logger.New()
+
+	paidID, bookedID := uuid.New(), uuid.New()
+	invoice := model.Invoice{Number: "1-000001", HotelID: 1, OrderID: paidID, Total: model.Money{Amount: 200_00, Currency: "EUR"}}
+
+	invoiceServiceMock := new(mock.MockInvoiceService)
+	invoiceServiceMock.On("GetInvoice", m.Anything, paidID).Return(invoice, nil)
+	invoiceServiceMock.On("GetInvoice", m.Anything, bookedID).Return(model.Invoice{}, storage.ErrNotFound)
+	invoiceServiceMock.On("GetListOrderInvoices", m.Anything, paidID).Return([]model.Invoice{
+		{Number: "1-000001", Kind: model.InvoiceDocument, OrderID: paidID},
+		{Number: "1-000002", Kind: model.CreditNote, Corrects: "1-000001", OrderID: paidID},
+	}, nil)
+	invoiceServiceMock.On("RenderHTML", m.Anything, invoice).Run(func(args m.Arguments) {
+		_, _ = args.Get(0).(io.Writer).Write([]byte("<h1>Invoice 1-000001</h1>"))
+	}).Return(nil)
+
+	mux := http.NewServeMux()
+	registerInvoiceHandlers(mux, log, invoiceServiceMock)
+
+	tests := []struct {
+		name                string
+		url                 string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{"JSON", "/api/v1/order/" + paidID.String() + "/invoice", "", http.StatusOK, "application/json", `"number":"1-000001"`},
+		{"HTML by query", "/api/v1/order/" + paidID.String() + "/invoice?format=html", "", http.StatusOK, "text/html; charset=utf-8", "<h1>Invoice 1-000001</h1>"},
+		{"HTML by Accept header", "/api/v1/order/" + paidID.String() + "/invoice", "text/html,*/*", http.StatusOK, "text/html; charset=utf-8", "<h1>Invoice 1-000001</h1>"},
+		{"Not paid order", "/api/v1/order/" + bookedID.String() + "/invoice", "", http.StatusNotFound, "", "Invoice not found"},
+		{"Invalid Order ID", "/api/v1/order/x/invoice", "", http.StatusBadRequest, "", "Invalid Order ID format"},
+		{"Invoices and credit notes", "/api/v1/order/" + paidID.String() + "/invoices", "", http.StatusOK, "application/json", `"kind":"credit_note","corrects":"1-000001"`},
+		{"Invalid Order ID of invoices", "/api/v1/order/x/invoices", "", http.StatusBadRequest, "", "Invalid Order ID format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			if tt.expectedContentType != "" {
+				assert.Equal(t, tt.expectedContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestReconciliationHandlers(t *testing.T) {
+	log := logger.New()
+
+	reportID, unknownID := uuid.New(), uuid.New()
+	report := model.ReconciliationReport{ID: reportID, Source: "march.csv", Records: 1, Matched: 1, Discrepancies: []model.Discrepancy{}}
+
+	paymentServiceMock := new(mock.MockPaymentService)
+	paymentServiceMock.On("GetListReconciliationReports", m.Anything).Return([]model.ReconciliationReport{report}, nil)
+	paymentServiceMock.On("GetReconciliationReport", m.Anything, reportID).Return(report, nil)
+	paymentServiceMock.On("GetReconciliationReport", m.Anything, unknownID).Return(model.ReconciliationReport{}, storage.ErrNotFound)
+
+	mux := http.NewServeMux()
+	registerReconciliationHandlers(mux, log, paymentServiceMock)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"List", "GET", "/api/v1/payment/reconciliation", "", http.StatusOK, `"source":"march.csv"`},
+		{"Get", "GET", "/api/v1/payment/reconciliation/" + reportID.String(), "", http.StatusOK, `"records":1`},
+		{"Not found", "GET", "/api/v1/payment/reconciliation/" + unknownID.String(), "", http.StatusNotFound, "Reconciliation report not found"},
+		{"Invalid ID", "GET", "/api/v1/payment/reconciliation/x", "", http.StatusBadRequest, "Invalid report ID format"},
+		{"Upload is not allowed", "POST", "/api/v1/payment/reconciliation", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+
+	paymentServiceMock.AssertExpectations(t)
+}
+
+func TestSagaHandlers(t *testing.T) {
+	log := logger.New()
+
+	stuckID, runningID, notFoundID := uuid.New(), uuid.New(), uuid.New()
+	stuckSaga := model.Saga{OrderID: stuckID, Step: model.RefundStep, Status: model.SagaCompensating, StuckAt: time.Now()}
+	runningSaga := model.Saga{OrderID: runningID, Step: model.PaymentStep, Status: model.SagaRunning}
+
+	bookingServiceMock := new(mock.MockBookingService)
+	bookingServiceMock.On("GetSaga", m.Anything, stuckID).Return(stuckSaga, nil)
+	bookingServiceMock.On("GetSaga", m.Anything, runningID).Return(runningSaga, nil)
+	bookingServiceMock.On("GetSaga", m.Anything, notFoundID).Return(model.Saga{}, storage.ErrNotFound)
+	bookingServiceMock.On("GetListSagas", m.Anything, true).Return([]model.Saga{stuckSaga}, nil)
+	bookingServiceMock.On("GetListSagas", m.Anything, false).Return([]model.Saga{stuckSaga, runningSaga}, nil)
+
+	queueMock := new(mock.MockQueue)
+	queueMock.On("Publish", m.Anything, queue.ResumeSagaRequest, m.MatchedBy(func(msg any) bool {
+		event, ok := msg.(events.ResumeSagaEvent)
+		return ok && event.OrderID == stuckID
+	})).Return(nil).Once()
+
+	mux := http.NewServeMux()
+	registerSagaHandlers(mux, log, queueMock, bookingServiceMock)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"List", "GET", "/api/v1/saga", http.StatusOK, runningID.String()},
+		{"List stuck", "GET", "/api/v1/saga?stuck=true", http.StatusOK, stuckID.String()},
+		{"Invalid stuck", "GET", "/api/v1/saga?stuck=maybe", http.StatusBadRequest, "Invalid stuck parameter"},
+		{"Get", "GET", "/api/v1/saga/" + runningID.String(), http.StatusOK, `"step":"payment"`},
+		{"Not found", "GET", "/api/v1/saga/" + notFoundID.String(), http.StatusNotFound, "Saga not found"},
+		{"Invalid Order ID", "GET", "/api/v1/saga/invalid-uuid", http.StatusBadRequest, "Invalid Order ID format"},
+		{"Resume", "POST", "/api/v1/saga/" + stuckID.String() + "/resume", http.StatusAccepted, `"step":"refund"`},
+		{"Resume not stuck", "POST", "/api/v1/saga/" + runningID.String() + "/resume", http.StatusConflict, "Saga is not stuck"},
+		{"Resume not found", "POST", "/api/v1/saga/" + notFoundID.String() + "/resume", http.StatusNotFound, "Saga not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+
+	queueMock.AssertExpectations(t)
+}
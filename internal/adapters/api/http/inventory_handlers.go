@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/logger"
+)
+
+type inventoryUpdateRequest struct {
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`    // exclusive, like check-out date
+	Delta      int       `json:"delta"` // rooms to add (positive) or remove (negative) for every day of period
+}
+
+type stayRestrictionsUpdateRequest struct {
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"` // exclusive, like check-out date
+
+	model.StayRestrictions // replace current restrictions of every day of period
+}
+
+func registerInventoryHandlers(mux *http.ServeMux, log logger.Logger, q queue.Queue) {
+	mux.HandleFunc("POST /api/v1/room/quota", postInventoryUpdateHandler(log, q))
+	mux.HandleFunc("PUT /api/v1/room/restrictions", putStayRestrictionsHandler(log, q))
+}
+
+func postInventoryUpdateHandler(log logger.Logger, q queue.Queue) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postInventoryUpdateHandler")
+
+		var updateRequest inventoryUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		if updateRequest.HotelID <= 0 || updateRequest.RoomTypeID <= 0 {
+			log.Error("Invalid hotel or room type ID")
+			http.Error(w, "Invalid hotel or room type ID: IDs must be positive integers", http.StatusBadRequest)
+			return
+		}
+
+		if updateRequest.Delta == 0 {
+			log.Error("Invalid quota delta")
+			http.Error(w, "Invalid quota delta: must not be zero", http.StatusBadRequest)
+			return
+		}
+
+		if !updateRequest.From.Before(updateRequest.To) {
+			log.Error("From date must be before To date")
+			http.Error(w, "From date must be before To date", http.StatusBadRequest)
+			return
+		}
+
+		err := q.Publish(r.Context(), queue.InventoryUpdateRequest, events.InventoryUpdateEvent{
+			HotelID:    updateRequest.HotelID,
+			RoomTypeID: updateRequest.RoomTypeID,
+			From:       updateRequest.From,
+			To:         updateRequest.To,
+			Delta:      updateRequest.Delta,
+		})
+		if err != nil {
+			log.Error("Failed to publish the inventory update: %v", err)
+			http.Error(w, "Failed to publish the inventory update: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"status": "inventory update received",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+			http.Error(w, "Failed to encode the response", http.StatusInternalServerError)
+		}
+	}
+}
+
+func putStayRestrictionsHandler(log logger.Logger, q queue.Queue) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("putStayRestrictionsHandler")
+
+		var updateRequest stayRestrictionsUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		if updateRequest.HotelID <= 0 || updateRequest.RoomTypeID <= 0 {
+			log.Error("Invalid hotel or room type ID")
+			http.Error(w, "Invalid hotel or room type ID: IDs must be positive integers", http.StatusBadRequest)
+			return
+		}
+
+		if err := updateRequest.StayRestrictions.Validate(); err != nil {
+			log.Error("Invalid stay restrictions: %v", err)
+			http.Error(w, "Invalid stay restrictions: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !updateRequest.From.Before(updateRequest.To) {
+			log.Error("From date must be before To date")
+			http.Error(w, "From date must be before To date", http.StatusBadRequest)
+			return
+		}
+
+		err := q.Publish(r.Context(), queue.InventoryUpdateRequest, events.StayRestrictionsUpdateEvent{
+			HotelID:      updateRequest.HotelID,
+			RoomTypeID:   updateRequest.RoomTypeID,
+			From:         updateRequest.From,
+			To:           updateRequest.To,
+			Restrictions: updateRequest.StayRestrictions,
+		})
+		if err != nil {
+			log.Error("Failed to publish the stay restrictions update: %v", err)
+			http.Error(w, "Failed to publish the stay restrictions update: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"status": "stay restrictions update received",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+			http.Error(w, "Failed to encode the response", http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+func registerInvoiceHandlers(mux *http.ServeMux, log logger.Logger, invoiceService service.InvoiceService) {
+	mux.HandleFunc("GET /api/v1/order/{id}/invoice", getInvoiceHandler(log, invoiceService))
+	mux.HandleFunc("GET /api/v1/order/{id}/invoices", getListOrderInvoicesHandler(log, invoiceService))
+}
+
+// getInvoiceHandler - invoice of paid order as JSON, or as HTML page if it is requested by ?format=html
+// or by Accept header.
+func getInvoiceHandler(log logger.Logger, invoiceService service.InvoiceService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getInvoiceHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		invoice, err := invoiceService.GetInvoice(r.Context(), orderID)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "Invoice not found: it is issued when order is paid", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("Failed to retrieve invoice: %v", err)
+			http.Error(w, "Failed to retrieve invoice", http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "html" || strings.Contains(r.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusOK)
+			if err = invoiceService.RenderHTML(w, invoice); err != nil {
+				log.Error("Failed to render invoice: %v", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(invoice); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+// getListOrderInvoicesHandler - every invoice and credit note issued for the order: replaced invoices of changed order
+// with credit notes which cancel them, and credit notes of refunds.
+func getListOrderInvoicesHandler(log logger.Logger, invoiceService service.InvoiceService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getListOrderInvoicesHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		invoices, err := invoiceService.GetListOrderInvoices(r.Context(), orderID)
+		if err != nil {
+			log.Error("Failed to retrieve invoices: %v", err)
+			http.Error(w, "Failed to retrieve invoices", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(invoices); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
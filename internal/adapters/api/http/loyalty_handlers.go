@@ -0,0 +1,62 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+func registerLoyaltyHandlers(mux *http.ServeMux, log logger.Logger, loyaltyService service.LoyaltyService) {
+	mux.HandleFunc("GET /api/v1/loyalty/{email}", getLoyaltyAccountHandler(log, loyaltyService))
+	mux.HandleFunc("GET /api/v1/loyalty/{email}/ledger", getLoyaltyLedgerHandler(log, loyaltyService))
+}
+
+func getLoyaltyAccountHandler(log logger.Logger, loyaltyService service.LoyaltyService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getLoyaltyAccountHandler")
+
+		account, err := loyaltyService.GetAccount(r.Context(), r.PathValue("email"))
+		if err != nil {
+			writeLoyaltyError(w, log, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(account); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+func getLoyaltyLedgerHandler(log logger.Logger, loyaltyService service.LoyaltyService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getLoyaltyLedgerHandler")
+
+		entries, err := loyaltyService.GetLedger(r.Context(), r.PathValue("email"))
+		if err != nil {
+			writeLoyaltyError(w, log, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(entries); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+func writeLoyaltyError(w http.ResponseWriter, log logger.Logger, err error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Loyalty account not found", http.StatusNotFound)
+		return
+	}
+
+	log.Error("Failed to retrieve loyalty account: %v", err)
+	http.Error(w, "Failed to retrieve loyalty account", http.StatusInternalServerError)
+}
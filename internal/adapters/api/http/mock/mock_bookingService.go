@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -36,3 +37,38 @@ func (m *MockBookingService) GetListRooms(ctx context.Context) ([]model.RoomAvai
 	args := m.Called(ctx)
 	return args.Get(0).([]model.RoomAvailability), args.Error(1)
 }
+
+func (m *MockBookingService) CheckAvailability(ctx context.Context, order model.Order) ([]time.Time, error) {
+	args := m.Called(ctx, order)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockBookingService) SearchAvailability(ctx context.Context, query model.AvailabilityQuery) ([]model.AvailabilityOffer, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]model.AvailabilityOffer), args.Error(1)
+}
+
+func (m *MockBookingService) GetListWaitlist(ctx context.Context) ([]model.WaitlistEntry, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.WaitlistEntry), args.Error(1)
+}
+
+func (m *MockBookingService) GetAllotment(ctx context.Context, code string) (model.Allotment, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(model.Allotment), args.Error(1)
+}
+
+func (m *MockBookingService) GetListAllotments(ctx context.Context) ([]model.Allotment, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.Allotment), args.Error(1)
+}
+
+func (m *MockBookingService) GetSaga(ctx context.Context, orderID model.OrderID) (model.Saga, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(model.Saga), args.Error(1)
+}
+
+func (m *MockBookingService) GetListSagas(ctx context.Context, stuck bool) ([]model.Saga, error) {
+	args := m.Called(ctx, stuck)
+	return args.Get(0).([]model.Saga), args.Error(1)
+}
@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockCatalogService struct {
+	mock.Mock
+}
+
+func (m *MockCatalogService) CreateHotel(ctx context.Context, hotel model.Hotel) error {
+	args := m.Called(ctx, hotel)
+	return args.Error(0)
+}
+
+func (m *MockCatalogService) UpdateHotel(ctx context.Context, hotel model.Hotel) error {
+	args := m.Called(ctx, hotel)
+	return args.Error(0)
+}
+
+func (m *MockCatalogService) DeleteHotel(ctx context.Context, id model.HotelID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCatalogService) GetHotel(ctx context.Context, id model.HotelID) (model.Hotel, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.Hotel), args.Error(1)
+}
+
+func (m *MockCatalogService) GetListHotels(ctx context.Context) ([]model.Hotel, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.Hotel), args.Error(1)
+}
+
+func (m *MockCatalogService) CreateRoomType(ctx context.Context, roomType model.RoomType) error {
+	args := m.Called(ctx, roomType)
+	return args.Error(0)
+}
+
+func (m *MockCatalogService) UpdateRoomType(ctx context.Context, roomType model.RoomType) error {
+	args := m.Called(ctx, roomType)
+	return args.Error(0)
+}
+
+func (m *MockCatalogService) DeleteRoomType(ctx context.Context, hotelID model.HotelID, id model.RoomTypeID) error {
+	args := m.Called(ctx, hotelID, id)
+	return args.Error(0)
+}
+
+func (m *MockCatalogService) GetRoomType(ctx context.Context, hotelID model.HotelID, id model.RoomTypeID) (model.RoomType, error) {
+	args := m.Called(ctx, hotelID, id)
+	return args.Get(0).(model.RoomType), args.Error(1)
+}
+
+func (m *MockCatalogService) GetListRoomTypes(ctx context.Context, hotelID model.HotelID) ([]model.RoomType, error) {
+	args := m.Called(ctx, hotelID)
+	return args.Get(0).([]model.RoomType), args.Error(1)
+}
+
+func (m *MockCatalogService) ValidateOrder(ctx context.Context, order model.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
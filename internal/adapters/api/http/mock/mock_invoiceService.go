@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockInvoiceService struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceService) GetInvoice(ctx context.Context, orderID model.OrderID) (model.Invoice, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(model.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceService) GetListOrderInvoices(ctx context.Context, orderID model.OrderID) ([]model.Invoice, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).([]model.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceService) RenderHTML(w io.Writer, invoice model.Invoice) error {
+	args := m.Called(w, invoice)
+	return args.Error(0)
+}
@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockLoyaltyService struct {
+	mock.Mock
+}
+
+func (m *MockLoyaltyService) GetAccount(ctx context.Context, email string) (model.LoyaltyAccount, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(model.LoyaltyAccount), args.Error(1)
+}
+
+func (m *MockLoyaltyService) GetLedger(ctx context.Context, email string) ([]model.LoyaltyEntry, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).([]model.LoyaltyEntry), args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockOverbookingService struct {
+	mock.Mock
+}
+
+func (m *MockOverbookingService) CreateRule(ctx context.Context, rule model.OverbookingRule) (model.OverbookingRule, error) {
+	args := m.Called(ctx, rule)
+	return args.Get(0).(model.OverbookingRule), args.Error(1)
+}
+
+func (m *MockOverbookingService) DeleteRule(ctx context.Context, id model.OverbookingRuleID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOverbookingService) GetListRules(ctx context.Context) ([]model.OverbookingRule, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.OverbookingRule), args.Error(1)
+}
+
+func (m *MockOverbookingService) GetOversoldReport(ctx context.Context, hotelID int) ([]model.OversoldNight, error) {
+	args := m.Called(ctx, hotelID)
+	return args.Get(0).([]model.OversoldNight), args.Error(1)
+}
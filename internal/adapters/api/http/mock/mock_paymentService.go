@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) Run(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) CallbackHandler(ctx context.Context, callback model.PaymentCallback) error {
+	args := m.Called(ctx, callback)
+	return args.Error(0)
+}
+
+func (m *MockPaymentService) Reconcile(ctx context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error) {
+	args := m.Called(ctx, source, records, fix)
+	return args.Get(0).(model.ReconciliationReport), args.Error(1)
+}
+
+func (m *MockPaymentService) GetReconciliationReport(ctx context.Context, id uuid.UUID) (model.ReconciliationReport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(model.ReconciliationReport), args.Error(1)
+}
+
+func (m *MockPaymentService) GetListReconciliationReports(ctx context.Context) ([]model.ReconciliationReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.ReconciliationReport), args.Error(1)
+}
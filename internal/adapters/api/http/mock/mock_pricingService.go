@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockPricingService struct {
+	mock.Mock
+}
+
+func (m *MockPricingService) Quote(ctx context.Context, order model.Order) (model.Price, error) {
+	args := m.Called(ctx, order)
+	return args.Get(0).(model.Price), args.Error(1)
+}
+
+func (m *MockPricingService) GetListRatePlans(ctx context.Context, hotelID model.HotelID) ([]model.RatePlan, error) {
+	args := m.Called(ctx, hotelID)
+	return args.Get(0).([]model.RatePlan), args.Error(1)
+}
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockPromoService struct {
+	mock.Mock
+}
+
+func (m *MockPromoService) CreatePromoCode(ctx context.Context, promo model.PromoCode) error {
+	args := m.Called(ctx, promo)
+	return args.Error(0)
+}
+
+func (m *MockPromoService) GetPromoCode(ctx context.Context, code string) (model.PromoCode, error) {
+	args := m.Called(ctx, code)
+	return args.Get(0).(model.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoService) GetListPromoCodes(ctx context.Context) ([]model.PromoCode, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoService) ValidatePromoCode(ctx context.Context, order model.Order) (model.PromoCode, error) {
+	args := m.Called(ctx, order)
+	return args.Get(0).(model.PromoCode), args.Error(1)
+}
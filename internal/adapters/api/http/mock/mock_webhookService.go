@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Run(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	args := m.Called(ctx, subscription)
+	return args.Get(0).(model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteSubscription(ctx context.Context, id model.WebhookSubscriptionID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) GetListDeliveries(ctx context.Context, subscriptionID model.WebhookSubscriptionID) ([]model.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (model.WebhookDelivery, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).(model.WebhookDelivery), args.Error(1)
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+// admin handlers for overbooking rules and revenue reports, in real life must be protected by auth.
+func registerOverbookingHandlers(mux *http.ServeMux, log logger.Logger, overbookingService service.OverbookingService) {
+	mux.HandleFunc("POST /api/v1/overbooking", postOverbookingRuleHandler(log, overbookingService))
+	mux.HandleFunc("DELETE /api/v1/overbooking/{id}", deleteOverbookingRuleHandler(log, overbookingService))
+	mux.HandleFunc("GET /api/v1/overbooking/report", getOversoldReportHandler(log, overbookingService))
+
+	mux.HandleFunc("GET /api/v1/overbooking", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		rules, _ := overbookingService.GetListRules(r.Context())
+		_ = json.NewEncoder(w).Encode(rules)
+	})
+}
+
+func postOverbookingRuleHandler(log logger.Logger, overbookingService service.OverbookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postOverbookingRuleHandler")
+
+		var rule model.OverbookingRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		rule, err := overbookingService.CreateRule(r.Context(), rule)
+		switch {
+		case errors.Is(err, model.ErrOverbookingRuleInvalid):
+			log.Error("Invalid overbooking rule: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			log.Error("Failed to create overbooking rule: %v", err)
+			http.Error(w, "Failed to create overbooking rule", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err = json.NewEncoder(w).Encode(rule); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+func deleteOverbookingRuleHandler(log logger.Logger, overbookingService service.OverbookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("deleteOverbookingRuleHandler")
+
+		ruleID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid rule ID format")
+			http.Error(w, "Invalid rule ID format", http.StatusBadRequest)
+			return
+		}
+
+		if err = overbookingService.DeleteRule(r.Context(), ruleID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Overbooking rule not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to delete overbooking rule: %v", err)
+			http.Error(w, "Failed to delete overbooking rule", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func getOversoldReportHandler(log logger.Logger, overbookingService service.OverbookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getOversoldReportHandler")
+
+		hotelID := 0
+		if hotelIDStr := r.URL.Query().Get("hotel_id"); hotelIDStr != "" {
+			var err error
+			if hotelID, err = strconv.Atoi(hotelIDStr); err != nil || hotelID <= 0 {
+				log.Error("Invalid hotel ID: %s", hotelIDStr)
+				http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		nights, err := overbookingService.GetOversoldReport(r.Context(), hotelID)
+		if err != nil {
+			log.Error("Failed to build oversold report: %v", err)
+			http.Error(w, "Failed to build oversold report", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(nights); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
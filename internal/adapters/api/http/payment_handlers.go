@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/logger"
+)
+
+const (
+	signatureHeader          = "X-Signature"
+	signatureTimestampHeader = "X-Signature-Timestamp" // unix seconds, signed together with body
+
+	// callbackTolerance - callbacks signed earlier or later are rejected to prevent replay of captured requests.
+	callbackTolerance = 5 * time.Minute
+	callbackMaxBody   = 1 << 20
+)
+
+// payment provider handlers, requests are authenticated by HMAC signature of shared secret.
+func registerPaymentHandlers(mux *http.ServeMux, log logger.Logger, paymentService service.PaymentService, secret []byte) {
+	mux.HandleFunc("POST /api/v1/payment/callback", postPaymentCallbackHandler(log, paymentService, secret))
+}
+
+// postPaymentCallbackHandler - provider retries callback until 2xx is answered, so redelivery is answered 200 as well.
+func postPaymentCallbackHandler(log logger.Logger, paymentService service.PaymentService, secret []byte) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postPaymentCallbackHandler")
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, callbackMaxBody))
+		if err != nil {
+			log.Error("Failed to read callback body: %v", err)
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(signatureTimestampHeader), 10, 64)
+		if err != nil {
+			log.Error("Invalid signature timestamp: %s", r.Header.Get(signatureTimestampHeader))
+			http.Error(w, "Invalid signature timestamp", http.StatusUnauthorized)
+			return
+		}
+
+		if age := time.Since(time.Unix(timestamp, 0)); age > callbackTolerance || age < -callbackTolerance {
+			log.Error("Stale callback signature: signed %s ago", age)
+			http.Error(w, "Signature timestamp is out of tolerance", http.StatusUnauthorized)
+			return
+		}
+
+		if !util.VerifySignature(secret, timestamp, body, r.Header.Get(signatureHeader)) {
+			log.Error("Invalid callback signature")
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var callback model.PaymentCallback
+		if err = json.Unmarshal(body, &callback); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		err = paymentService.CallbackHandler(r.Context(), callback)
+		switch {
+		case errors.Is(err, model.ErrPaymentCallbackInvalid):
+			log.Error("Invalid payment callback: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, storage.ErrNotFound):
+			log.Error("Payment %v of callback %s is not found", callback.PaymentID, callback.EventID)
+			http.Error(w, "Payment not found", http.StatusNotFound)
+			return
+		case errors.Is(err, model.ErrPaymentAmountMismatch):
+			log.Error("Payment callback is rejected: %v", err)
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			log.Error("Failed to handle payment callback: %v", err)
+			http.Error(w, "Failed to handle payment callback", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
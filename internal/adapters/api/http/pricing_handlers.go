@@ -0,0 +1,39 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+func registerPricingHandlers(mux *http.ServeMux, log logger.Logger, pricingService service.PricingService) {
+	mux.HandleFunc("GET /api/v1/hotel/{id}/rate-plan", getListRatePlansHandler(log, pricingService))
+}
+
+// getListRatePlansHandler - rate plans which can be selected for orders of the hotel.
+func getListRatePlansHandler(log logger.Logger, pricingService service.PricingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getListRatePlansHandler")
+
+		hotelID, ok := catalogPathID(r, "id")
+		if !ok {
+			http.Error(w, "Invalid hotel ID: ID must be positive integer", http.StatusBadRequest)
+			return
+		}
+
+		plans, err := pricingService.GetListRatePlans(r.Context(), hotelID)
+		if err != nil {
+			log.Error("Failed to get rate plans: %v", err)
+			http.Error(w, "Failed to get rate plans", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(plans); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+// admin handlers for promo codes, in real life must be protected by auth.
+func registerPromoHandlers(mux *http.ServeMux, log logger.Logger, promoService service.PromoService) {
+	mux.HandleFunc("POST /api/v1/promo", postPromoCodeHandler(log, promoService))
+	mux.HandleFunc("GET /api/v1/promo/{code}", getPromoCodeHandler(log, promoService))
+
+	mux.HandleFunc("GET /api/v1/promo", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		promos, _ := promoService.GetListPromoCodes(r.Context())
+		_ = json.NewEncoder(w).Encode(promos)
+	})
+}
+
+func postPromoCodeHandler(log logger.Logger, promoService service.PromoService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postPromoCodeHandler")
+
+		var promo model.PromoCode
+		if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		err := promoService.CreatePromoCode(r.Context(), promo)
+		switch {
+		case errors.Is(err, model.ErrPromoInvalid):
+			log.Error("Invalid promo code: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, storage.ErrDuplicateConstraint):
+			log.Error("Promo code %s already exists", promo.Code)
+			http.Error(w, "Promo code already exists", http.StatusConflict)
+			return
+		case err != nil:
+			log.Error("Failed to create promo code: %v", err)
+			http.Error(w, "Failed to create promo code", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"code":   model.NormalizePromoCode(promo.Code),
+			"status": "created",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+func getPromoCodeHandler(log logger.Logger, promoService service.PromoService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getPromoCodeHandler")
+
+		promo, err := promoService.GetPromoCode(r.Context(), r.PathValue("code"))
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Promo code not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to retrieve promo code: %v", err)
+			http.Error(w, "Failed to retrieve promo code", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(promo); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+// admin handlers of reports of payment reconciliation, in real life must be protected by auth. Settlement reports are
+// reconciled by settlement.Job from directory, they are not uploaded.
+func registerReconciliationHandlers(mux *http.ServeMux, log logger.Logger, paymentService service.PaymentService) {
+	mux.HandleFunc("GET /api/v1/payment/reconciliation", getListReconciliationsHandler(log, paymentService))
+	mux.HandleFunc("GET /api/v1/payment/reconciliation/{id}", getReconciliationHandler(log, paymentService))
+}
+
+func getListReconciliationsHandler(log logger.Logger, paymentService service.PaymentService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getListReconciliationsHandler")
+
+		reports, err := paymentService.GetListReconciliationReports(r.Context())
+		if err != nil {
+			log.Error("Failed to get reconciliation reports: %v", err)
+			http.Error(w, "Failed to get reconciliation reports", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, reports)
+	}
+}
+
+func getReconciliationHandler(log logger.Logger, paymentService service.PaymentService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getReconciliationHandler")
+
+		reportID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid report ID format")
+			http.Error(w, "Invalid report ID format", http.StatusBadRequest)
+			return
+		}
+
+		report, err := paymentService.GetReconciliationReport(r.Context(), reportID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Reconciliation report not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to get reconciliation report: %v", err)
+			http.Error(w, "Failed to get reconciliation report", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, report)
+	}
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+// admin handlers of booking sagas, in real life must be protected by auth.
+func registerSagaHandlers(mux *http.ServeMux, log logger.Logger, q queue.Queue, bookingService service.BookingService) {
+	mux.HandleFunc("GET /api/v1/saga", getListSagasHandler(log, bookingService))
+	mux.HandleFunc("GET /api/v1/saga/{id}", getSagaHandler(log, bookingService))
+	mux.HandleFunc("POST /api/v1/saga/{id}/resume", resumeSagaHandler(log, q, bookingService))
+}
+
+// getListSagasHandler - all sagas, or only stuck ones with stuck=true.
+func getListSagasHandler(log logger.Logger, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getListSagasHandler")
+
+		stuck := false
+		if value := r.URL.Query().Get("stuck"); value != "" {
+			var err error
+			if stuck, err = strconv.ParseBool(value); err != nil {
+				log.Error("Invalid stuck parameter: %s", value)
+				http.Error(w, "Invalid stuck parameter: must be true or false", http.StatusBadRequest)
+				return
+			}
+		}
+
+		sagas, err := bookingService.GetListSagas(r.Context(), stuck)
+		if err != nil {
+			log.Error("Failed to get sagas: %v", err)
+			http.Error(w, "Failed to get sagas", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, sagas)
+	}
+}
+
+// getSagaHandler - saga of the order.
+func getSagaHandler(log logger.Logger, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getSagaHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid Order ID format")
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		saga, err := bookingService.GetSaga(r.Context(), orderID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Saga not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to get saga: %v", err)
+			http.Error(w, "Failed to get saga", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, saga)
+	}
+}
+
+// resumeSagaHandler - the current step of stuck saga is attempted again asynchronously, its result can be found in saga.
+func resumeSagaHandler(log logger.Logger, q queue.Queue, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("resumeSagaHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid Order ID format")
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		saga, err := bookingService.GetSaga(r.Context(), orderID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Saga not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to get saga: %v", err)
+			http.Error(w, "Failed to get saga", http.StatusInternalServerError)
+			return
+		}
+
+		if !saga.IsStuck() {
+			log.Error("Saga of order `%s` is not stuck: %s step is %s", orderID, saga.Step, saga.Status)
+			http.Error(w, "Saga is not stuck", http.StatusConflict)
+			return
+		}
+
+		err = q.Publish(r.Context(), queue.ResumeSagaRequest, events.ResumeSagaEvent{
+			OrderID:   orderID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Error("Failed to publish the resume request: %v", err)
+			http.Error(w, "Failed to publish the resume request: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusAccepted, saga)
+	}
+}
@@ -11,6 +11,23 @@ import (
 	"aplication-design-test-task/internal/logger"
 )
 
+// syncOrderTimeout - how long synchronous order submission waits for booking worker.
+const syncOrderTimeout = 3 * time.Second
+
+// Services - core services used by http handlers.
+type Services struct {
+	Booking service.BookingService
+	Catalog service.CatalogService
+	Promo   service.PromoService
+	Pricing service.PricingService
+	Loyalty service.LoyaltyService
+	Payment service.PaymentService
+	Webhook service.WebhookService
+	Invoice service.InvoiceService
+
+	Overbooking service.OverbookingService
+}
+
 type server struct {
 	addr   string
 	log    logger.Logger
@@ -18,16 +35,35 @@ type server struct {
 	server *http.Server
 }
 
-func NewServer(addr string, log logger.Logger, q queue.Queue, bookingService service.BookingService) *server {
+// NewServer - callbackSecret is shared with payment provider to sign payment callbacks, adminToken authenticates
+// admin requests of partner webhook subscriptions.
+func NewServer(addr string, log logger.Logger, q queue.Queue, services Services, callbackSecret, adminToken []byte) *server {
 	mux := http.NewServeMux()
+	bookingService := services.Booking
 
 	mux.HandleFunc("GET /api/v1/ping", func(writer http.ResponseWriter, request *http.Request) {
 		_, _ = fmt.Fprintf(writer, "pong")
 	})
 
 	mux.HandleFunc("GET /api/v1/order/{id}", getReservationOrderHandler(log, bookingService))
-	mux.HandleFunc("POST /api/v1/order/", postReservationOrderHandler(log, q))
-	// TODO: payments "ping-back" handlers
+	mux.HandleFunc("POST /api/v1/order/", postReservationOrderHandler(log, q, bookingService, services.Catalog, services.Promo, services.Loyalty, syncOrderTimeout))
+	mux.HandleFunc("PATCH /api/v1/order/{id}", patchReservationOrderHandler(log, q, bookingService))
+	mux.HandleFunc("POST /api/v1/order/{id}/cancel", cancelReservationOrderHandler(log, q, bookingService))
+
+	registerCatalogHandlers(mux, log, services.Catalog)
+	registerPromoHandlers(mux, log, services.Promo)
+	registerPricingHandlers(mux, log, services.Pricing)
+	registerLoyaltyHandlers(mux, log, services.Loyalty)
+	registerWaitlistHandlers(mux, log, q, bookingService)
+	registerInventoryHandlers(mux, log, q)
+	registerAvailabilityHandlers(mux, log, bookingService)
+	registerAllotmentHandlers(mux, log, q, bookingService, services.Catalog)
+	registerOverbookingHandlers(mux, log, services.Overbooking)
+	registerPaymentHandlers(mux, log, services.Payment, callbackSecret)
+	registerReconciliationHandlers(mux, log, services.Payment)
+	registerWebhookHandlers(mux, log, services.Webhook, adminToken)
+	registerInvoiceHandlers(mux, log, services.Invoice)
+	registerSagaHandlers(mux, log, q, bookingService)
 
 	registerDebugHandlers(mux, bookingService)
 
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+func registerWaitlistHandlers(mux *http.ServeMux, log logger.Logger, q queue.Queue, bookingService service.BookingService) {
+	mux.HandleFunc("POST /api/v1/order/{id}/waitlist", joinWaitlistHandler(log, q, bookingService))
+	mux.HandleFunc("GET /api/v1/waitlist", getListWaitlistHandler(log, bookingService))
+}
+
+// joinWaitlistHandler - order is put into waitlist by booking worker, its entry can be found in waitlist.
+func joinWaitlistHandler(log logger.Logger, q queue.Queue, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("joinWaitlistHandler")
+
+		orderID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid Order ID format")
+			http.Error(w, "Invalid Order ID format", http.StatusBadRequest)
+			return
+		}
+
+		order, err := bookingService.GetOrder(r.Context(), orderID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				log.Error("Order with id: `%s` not found", orderID)
+				http.Error(w, "Order not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to retrieve order")
+			http.Error(w, "Failed to retrieve order", http.StatusInternalServerError)
+			return
+		}
+
+		if order.Status != model.NoRooms {
+			log.Error("Order with id: `%s` can not join waitlist in status: %s", orderID, order.Status)
+			http.Error(w, "Order can not join waitlist in status: "+string(order.Status), http.StatusConflict)
+			return
+		}
+
+		err = q.Publish(r.Context(), queue.JoinWaitlistRequest, events.JoinWaitlistEvent{
+			OrderID:   orderID,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Error("Failed to publish the waitlist request: %v", err)
+			http.Error(w, "Failed to publish the waitlist request: internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		err = json.NewEncoder(w).Encode(map[string]string{
+			"order_id": orderID.String(),
+			"status":   "waitlist request received",
+		})
+		if err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
+
+func getListWaitlistHandler(log logger.Logger, bookingService service.BookingService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getListWaitlistHandler")
+
+		entries, err := bookingService.GetListWaitlist(r.Context())
+		if err != nil {
+			log.Error("Failed to retrieve waitlist: %v", err)
+			http.Error(w, "Failed to retrieve waitlist", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err = json.NewEncoder(w).Encode(entries); err != nil {
+			log.Error("Failed to encode the response: %v", err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service"
+	"aplication-design-test-task/internal/logger"
+)
+
+// admin handlers for partner webhook subscriptions and their delivery log, requests are authenticated by admin token.
+// Partner receives status changes of orders booked from its allotments, so only admin may subscribe partner.
+func registerWebhookHandlers(mux *http.ServeMux, log logger.Logger, webhookService service.WebhookService, adminToken []byte) {
+	mux.HandleFunc("POST /api/v1/webhook", requireAdmin(log, adminToken, postWebhookSubscriptionHandler(log, webhookService)))
+	mux.HandleFunc("GET /api/v1/webhook", requireAdmin(log, adminToken, getWebhookSubscriptionsHandler(log, webhookService)))
+	mux.HandleFunc("DELETE /api/v1/webhook/{id}", requireAdmin(log, adminToken, deleteWebhookSubscriptionHandler(log, webhookService)))
+	mux.HandleFunc("GET /api/v1/webhook/{id}/deliveries", requireAdmin(log, adminToken, getWebhookDeliveriesHandler(log, webhookService)))
+	mux.HandleFunc("POST /api/v1/webhook/delivery/{id}/redeliver", requireAdmin(log, adminToken, redeliverWebhookHandler(log, webhookService)))
+}
+
+// postWebhookSubscriptionHandler - secret is answered only here, partner must keep it to verify signatures.
+func postWebhookSubscriptionHandler(log logger.Logger, webhookService service.WebhookService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("postWebhookSubscriptionHandler")
+
+		var subscription model.WebhookSubscription
+		if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+			log.Error("Invalid request body: %v", err)
+			http.Error(w, "Invalid request body: please ensure JSON is properly formatted", http.StatusBadRequest)
+			return
+		}
+
+		subscription, err := webhookService.CreateSubscription(r.Context(), subscription)
+		switch {
+		case errors.Is(err, model.ErrWebhookSubscriptionInvalid):
+			log.Error("Invalid webhook subscription: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			log.Error("Failed to create webhook subscription: %v", err)
+			http.Error(w, "Failed to create webhook subscription", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusCreated, subscription)
+	}
+}
+
+func getWebhookSubscriptionsHandler(log logger.Logger, webhookService service.WebhookService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getWebhookSubscriptionsHandler")
+
+		subscriptions, err := webhookService.GetListSubscriptions(r.Context())
+		if err != nil {
+			log.Error("Failed to get webhook subscriptions: %v", err)
+			http.Error(w, "Failed to get webhook subscriptions", http.StatusInternalServerError)
+			return
+		}
+
+		for i := range subscriptions {
+			subscriptions[i].Secret = ""
+		}
+
+		writeJSON(w, log, http.StatusOK, subscriptions)
+	}
+}
+
+func deleteWebhookSubscriptionHandler(log logger.Logger, webhookService service.WebhookService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("deleteWebhookSubscriptionHandler")
+
+		subscriptionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid subscription ID format")
+			http.Error(w, "Invalid subscription ID format", http.StatusBadRequest)
+			return
+		}
+
+		if err = webhookService.DeleteSubscription(r.Context(), subscriptionID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Webhook subscription not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to delete webhook subscription: %v", err)
+			http.Error(w, "Failed to delete webhook subscription", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func getWebhookDeliveriesHandler(log logger.Logger, webhookService service.WebhookService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("getWebhookDeliveriesHandler")
+
+		subscriptionID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid subscription ID format")
+			http.Error(w, "Invalid subscription ID format", http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := webhookService.GetListDeliveries(r.Context(), subscriptionID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Webhook subscription not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to get webhook deliveries: %v", err)
+			http.Error(w, "Failed to get webhook deliveries", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusOK, deliveries)
+	}
+}
+
+// redeliverWebhookHandler - new delivery is attempted asynchronously, its result can be found in delivery log.
+func redeliverWebhookHandler(log logger.Logger, webhookService service.WebhookService) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Info("redeliverWebhookHandler")
+
+		deliveryID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			log.Error("Invalid delivery ID format")
+			http.Error(w, "Invalid delivery ID format", http.StatusBadRequest)
+			return
+		}
+
+		delivery, err := webhookService.Redeliver(r.Context(), deliveryID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				http.Error(w, "Webhook delivery or its subscription not found", http.StatusNotFound)
+				return
+			}
+
+			log.Error("Failed to redeliver webhook: %v", err)
+			http.Error(w, "Failed to redeliver webhook", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, log, http.StatusAccepted, delivery)
+	}
+}
+
+// requireAdmin - handler answers only requests which carry admin token as bearer token.
+func requireAdmin(log logger.Logger, token []byte, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || len(token) == 0 || subtle.ConstantTimeCompare([]byte(bearer), token) != 1 {
+			log.Error("Unauthorized request: %s %s", r.Method, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,167 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// Scenario - how fake gateway answers payments of a payer.
+type Scenario struct {
+	Latency       time.Duration // delay before answer
+	DeclineReason string        // payment is declined if not empty
+	Pending       bool          // result is sent later by provider callback
+
+	RefundDeclineReason string // refunds are declined if not empty
+}
+
+// PaymentGateway - deterministic payment gateway for tests and local runs. Payments are charged immediately unless
+// scenario of payer email says otherwise, transaction ID is derived from payment ID.
+type PaymentGateway struct {
+	m         sync.RWMutex
+	scenarios map[string]Scenario // by lower-case payer email
+	fallback  Scenario
+	now       func() time.Time
+}
+
+type Option func(*PaymentGateway)
+
+// WithDecline - payments of email are declined with reason.
+func WithDecline(email, reason string) Option {
+	return func(g *PaymentGateway) {
+		scenario := g.scenarios[strings.ToLower(email)]
+		scenario.DeclineReason = reason
+		g.scenarios[strings.ToLower(email)] = scenario
+	}
+}
+
+// WithRefundDecline - refunds of payments of email are declined with reason.
+func WithRefundDecline(email, reason string) Option {
+	return func(g *PaymentGateway) {
+		scenario := g.scenarios[strings.ToLower(email)]
+		scenario.RefundDeclineReason = reason
+		g.scenarios[strings.ToLower(email)] = scenario
+	}
+}
+
+// WithLatency - payments of email are answered after delay.
+func WithLatency(email string, latency time.Duration) Option {
+	return func(g *PaymentGateway) {
+		scenario := g.scenarios[strings.ToLower(email)]
+		scenario.Latency = latency
+		g.scenarios[strings.ToLower(email)] = scenario
+	}
+}
+
+// WithPending - payments of email are accepted, result is sent by provider callback.
+func WithPending(email string) Option {
+	return func(g *PaymentGateway) {
+		scenario := g.scenarios[strings.ToLower(email)]
+		scenario.Pending = true
+		g.scenarios[strings.ToLower(email)] = scenario
+	}
+}
+
+// WithDefault - scenario for payers without own scenario.
+func WithDefault(scenario Scenario) Option {
+	return func(g *PaymentGateway) {
+		g.fallback = scenario
+	}
+}
+
+func NewPaymentGateway(opts ...Option) *PaymentGateway {
+	g := &PaymentGateway{
+		scenarios: make(map[string]Scenario),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// SetScenario changes scenario of payer email at runtime.
+func (g *PaymentGateway) SetScenario(email string, scenario Scenario) {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	g.scenarios[strings.ToLower(email)] = scenario
+}
+
+func (g *PaymentGateway) Charge(ctx context.Context, payment model.Payment) (model.Charge, error) {
+	scenario := g.scenario(payment.PayerEmail)
+
+	if err := wait(ctx, scenario.Latency); err != nil {
+		return model.Charge{}, err
+	}
+
+	if scenario.Pending {
+		return model.Charge{}, model.ErrPaymentPending
+	}
+
+	if scenario.DeclineReason != "" {
+		return model.Charge{}, fmt.Errorf("%w: %s", model.ErrPaymentDeclined, scenario.DeclineReason)
+	}
+
+	return model.Charge{
+		TransactionID: "fake_" + payment.ID.String(),
+		PaymentID:     payment.ID,
+		Amount:        payment.Amount,
+		ChargedAt:     g.now(),
+	}, nil
+}
+
+// Refund - refunds are answered at once unless scenario of payer email says otherwise, reference of refund is derived
+// from payment ID and amount refunded before.
+func (g *PaymentGateway) Refund(ctx context.Context, payment model.Payment, amount model.Money) (string, error) {
+	scenario := g.scenario(payment.PayerEmail)
+
+	if err := wait(ctx, scenario.Latency); err != nil {
+		return "", err
+	}
+
+	if scenario.RefundDeclineReason != "" {
+		return "", fmt.Errorf("%w: %s", model.ErrRefundDeclined, scenario.RefundDeclineReason)
+	}
+
+	if !payment.IsPaid {
+		return "", fmt.Errorf("%w: payment %v is not charged", model.ErrRefundDeclined, payment.ID)
+	}
+
+	return fmt.Sprintf("fake_refund_%s_%d", payment.ID, payment.RefundedAmount.Amount), nil
+}
+
+func (g *PaymentGateway) scenario(email string) Scenario {
+	g.m.RLock()
+	defer g.m.RUnlock()
+
+	scenario, ok := g.scenarios[strings.ToLower(email)]
+	if !ok {
+		scenario = g.fallback
+	}
+
+	return scenario
+}
+
+// wait - sleep for latency or until context is done.
+func wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
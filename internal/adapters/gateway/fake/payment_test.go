@@ -0,0 +1,81 @@
+package fake
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+func TestPaymentGateway_Charge(t *testing.T) {
+	ctx := context.Background()
+	g := NewPaymentGateway(
+		WithDecline("declined@example.com", "card expired"),
+		WithLatency("slow@example.com", 20*time.Millisecond),
+	)
+
+	payment := model.Payment{ID: uuid.New(), Amount: model.Money{Amount: 100_00, Currency: "EUR"}, PayerEmail: "guest@example.com"}
+	charge, err := g.Charge(ctx, payment)
+	require.NoError(t, err)
+	assert.Equal(t, "fake_"+payment.ID.String(), charge.TransactionID)
+	assert.Equal(t, payment.Amount, charge.Amount)
+
+	_, err = g.Charge(ctx, model.Payment{ID: uuid.New(), PayerEmail: "DECLINED@example.com"})
+	assert.ErrorIs(t, err, model.ErrPaymentDeclined)
+	assert.ErrorContains(t, err, "card expired")
+
+	start := time.Now()
+	_, err = g.Charge(ctx, model.Payment{ID: uuid.New(), PayerEmail: "slow@example.com"})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	_, err = g.Charge(timeoutCtx, model.Payment{ID: uuid.New(), PayerEmail: "slow@example.com"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = NewPaymentGateway(WithPending("callback@example.com")).Charge(ctx, model.Payment{ID: uuid.New(), PayerEmail: "callback@example.com"})
+	assert.ErrorIs(t, err, model.ErrPaymentPending)
+
+	g.SetScenario("guest@example.com", Scenario{DeclineReason: "fraud suspected"})
+	_, err = g.Charge(ctx, payment)
+	assert.ErrorIs(t, err, model.ErrPaymentDeclined)
+}
+
+func TestPaymentGateway_Default(t *testing.T) {
+	g := NewPaymentGateway(WithDefault(Scenario{DeclineReason: "gateway maintenance"}), WithLatency("vip@example.com", 0))
+
+	_, err := g.Charge(context.Background(), model.Payment{ID: uuid.New(), PayerEmail: "guest@example.com"})
+	assert.ErrorIs(t, err, model.ErrPaymentDeclined)
+
+	_, err = g.Charge(context.Background(), model.Payment{ID: uuid.New(), PayerEmail: "vip@example.com"})
+	assert.NoError(t, err, "payer scenario overrides default one")
+}
+
+func TestPaymentGateway_Refund(t *testing.T) {
+	ctx := context.Background()
+	g := NewPaymentGateway(WithRefundDecline("declined@example.com", "account closed"))
+
+	payment := model.Payment{ID: uuid.New(), IsPaid: true, Amount: model.Money{Amount: 100_00, Currency: "EUR"}, PayerEmail: "guest@example.com"}
+	ref, err := g.Refund(ctx, payment, model.Money{Amount: 40_00, Currency: "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, "fake_refund_"+payment.ID.String()+"_0", ref)
+
+	payment.RefundedAmount = model.Money{Amount: 40_00, Currency: "EUR"}
+	next, err := g.Refund(ctx, payment, model.Money{Amount: 60_00, Currency: "EUR"})
+	require.NoError(t, err)
+	assert.NotEqual(t, ref, next, "every partial refund has own reference")
+
+	_, err = g.Refund(ctx, model.Payment{ID: uuid.New(), PayerEmail: "guest@example.com"}, payment.Amount)
+	assert.ErrorIs(t, err, model.ErrRefundDeclined, "not charged payment can not be refunded")
+
+	payment.PayerEmail = "Declined@example.com"
+	_, err = g.Refund(ctx, payment, payment.Amount)
+	assert.ErrorIs(t, err, model.ErrRefundDeclined)
+	assert.ErrorContains(t, err, "account closed")
+}
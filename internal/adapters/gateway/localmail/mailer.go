@@ -0,0 +1,84 @@
+package localmail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+const sentLimit = 100 // emails kept in memory, older ones are dropped
+
+// Mailer - mailer for tests and local runs. Last emails are kept in memory and, if directory is set,
+// written there as .eml files which can be opened by any mail client. Attachments are written next to email.
+type Mailer struct {
+	m     sync.RWMutex
+	dir   string
+	sent  []model.Email
+	count int // emails sent since start, numbers files
+	limit int
+	now   func() time.Time
+}
+
+// New - dir may be empty, then emails are kept in memory only.
+func New(dir string) (*Mailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("could not create mail directory %s: %w", dir, err)
+		}
+	}
+
+	return &Mailer{
+		dir:   dir,
+		limit: sentLimit,
+		now:   func() time.Time { return time.Now().UTC() },
+	}, nil
+}
+
+func (m *Mailer) Send(ctx context.Context, email model.Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if m.dir != "" {
+		name := fmt.Sprintf("%s_%03d", m.now().Format("20060102T150405"), m.count+1)
+		content := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", email.To, email.Subject, email.Body)
+
+		if err := os.WriteFile(filepath.Join(m.dir, name+".eml"), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("could not write email: %w", err)
+		}
+
+		for _, attachment := range email.Attachments {
+			path := filepath.Join(m.dir, name+"_"+filepath.Base(attachment.Filename))
+			if err := os.WriteFile(path, attachment.Content, 0o644); err != nil {
+				return fmt.Errorf("could not write attachment: %w", err)
+			}
+		}
+	}
+
+	m.count++
+	m.sent = append(m.sent, email)
+	if len(m.sent) > m.limit {
+		m.sent = append(m.sent[:0:0], m.sent[len(m.sent)-m.limit:]...)
+	}
+
+	return nil
+}
+
+// Sent returns last emails in order of sending.
+func (m *Mailer) Sent() []model.Email {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	sent := make([]model.Email, len(m.sent))
+	copy(sent, m.sent)
+
+	return sent
+}
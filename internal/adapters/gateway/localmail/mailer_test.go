@@ -0,0 +1,54 @@
+package localmail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+func TestMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer, err := New(dir)
+	require.NoError(t, err)
+
+	email := model.Email{To: "guest@example.com", Subject: "Booking confirmed", Body: "Dear guest"}
+	require.NoError(t, mailer.Send(context.Background(), email))
+	assert.Equal(t, []model.Email{email}, mailer.Sent())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, "To: guest@example.com\r\nSubject: Booking confirmed\r\n\r\nDear guest", string(content))
+
+	attached := model.Email{To: "guest@example.com", Subject: "Payment received", Attachments: []model.Attachment{
+		{Filename: "../invoice-1-000001.html", ContentType: "text/html", Content: []byte("<h1>Invoice</h1>")},
+	}}
+	require.NoError(t, mailer.Send(context.Background(), attached))
+
+	attachments, err := filepath.Glob(filepath.Join(dir, "*_invoice-1-000001.html"))
+	require.NoError(t, err)
+	require.Len(t, attachments, 1, "attachment is written next to email, never outside of directory")
+	content, err = os.ReadFile(attachments[0])
+	require.NoError(t, err)
+	assert.Equal(t, "<h1>Invoice</h1>", string(content))
+
+	inMemory, err := New("")
+	require.NoError(t, err)
+	require.NoError(t, inMemory.Send(context.Background(), email))
+	assert.Len(t, inMemory.Sent(), 1)
+
+	// only last emails are kept
+	inMemory.limit = 2
+	require.NoError(t, inMemory.Send(context.Background(), attached))
+	require.NoError(t, inMemory.Send(context.Background(), email))
+	assert.Equal(t, []model.Email{attached, email}, inMemory.Sent())
+}
@@ -0,0 +1,129 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/logger"
+)
+
+const (
+	scanInterval = time.Minute
+	doneDir      = "done"   // reconciled reports
+	failedDir    = "failed" // invalid reports, fixed report must be put to directory again
+)
+
+// Reconciler reconciles payments against records of settlement report.
+type Reconciler interface {
+	Reconcile(ctx context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error)
+}
+
+// Job reconciles settlement reports which provider puts to directory as CSV files. Reconciled report is moved to
+// done subdirectory and invalid one to failed subdirectory, report which could not be reconciled is retried on next scan.
+type Job struct {
+	log        logger.Logger
+	reconciler Reconciler
+	dir        string
+	fix        bool
+	interval   time.Duration
+}
+
+// NewJob - job reconciling reports of dir, stuck payments and orders are fixed only if fix is set.
+func NewJob(log logger.Logger, reconciler Reconciler, dir string, fix bool) *Job {
+	return &Job{
+		log:        log,
+		reconciler: reconciler,
+		dir:        dir,
+		fix:        fix,
+		interval:   scanInterval,
+	}
+}
+
+// Run scans directory at once and then every interval until ctx is done.
+func (j *Job) Run(ctx context.Context) error {
+	for _, dir := range []string{doneDir, failedDir} {
+		if err := os.MkdirAll(filepath.Join(j.dir, dir), 0o755); err != nil {
+			return fmt.Errorf("could not create settlement reports directory: %w", err)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.ReconcileReports(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ReconcileReports reconciles reports of directory in order of their names.
+func (j *Job) ReconcileReports(ctx context.Context) {
+	paths, err := filepath.Glob(filepath.Join(j.dir, "*.csv"))
+	if err != nil {
+		j.log.Error("[settlement.Job.ReconcileReports] Failed to list settlement reports: %v", err)
+		return
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return
+		}
+
+		j.reconcileReport(ctx, path)
+	}
+}
+
+func (j *Job) reconcileReport(ctx context.Context, path string) {
+	name := filepath.Base(path)
+
+	report, err := j.readReport(ctx, path)
+	switch {
+	case errors.Is(err, model.ErrSettlementRecordInvalid):
+		j.log.Error("[settlement.Job.reconcileReport] Invalid settlement report %s: %v", name, err)
+		j.move(path, failedDir)
+	case err != nil:
+		j.log.Error("[settlement.Job.reconcileReport] Failed to reconcile settlement report %s, it is retried: %v", name, err)
+	default:
+		j.log.Info("[settlement.Job.reconcileReport] Settlement report %s is reconciled as %v: %d discrepancies, %d fixed",
+			name, report.ID, len(report.Discrepancies), report.Fixed)
+		j.move(path, doneDir)
+	}
+}
+
+func (j *Job) readReport(ctx context.Context, path string) (model.ReconciliationReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return model.ReconciliationReport{}, err
+	}
+	defer file.Close()
+
+	records, err := ParseReport(file)
+	if err != nil {
+		return model.ReconciliationReport{}, err
+	}
+
+	return j.reconciler.Reconcile(ctx, filepath.Base(path), records, j.fix)
+}
+
+// move - report is moved out of scanned directory, so it is reconciled once.
+func (j *Job) move(path, dir string) {
+	if err := os.Rename(path, filepath.Join(j.dir, dir, filepath.Base(path))); err != nil {
+		j.log.Error("[settlement.Job.move] Failed to move settlement report %s to %s: %v", filepath.Base(path), dir, err)
+	}
+}
@@ -0,0 +1,80 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/logger"
+)
+
+type reconcilerFunc func(ctx context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error)
+
+func (f reconcilerFunc) Reconcile(ctx context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error) {
+	return f(ctx, source, records, fix)
+}
+
+func TestJob_ReconcileReports(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	paymentID := uuid.New()
+	header := strings.Join(Header, ",") + "\n"
+	valid := header + "psp_1," + paymentID.String() + ",settled,200.00,EUR,2024-03-20T10:00:00Z,\n"
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("2024-03-20.csv", valid)
+	write("2024-03-21.csv", header+"psp_2,x,settled,1,EUR,2024-03-21T10:00:00Z,\n")
+	write("2024-03-22.csv", valid)
+	write("notes.txt", "not a report")
+
+	var reconciled []string
+	unavailable := true
+	job := NewJob(logger.New(), reconcilerFunc(func(_ context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error) {
+		if source == "2024-03-22.csv" && unavailable {
+			return model.ReconciliationReport{}, errors.New("storage is unavailable")
+		}
+
+		assert.True(t, fix)
+		require.Len(t, records, 1)
+		assert.Equal(t, paymentID, records[0].PaymentID)
+
+		reconciled = append(reconciled, source)
+		return model.ReconciliationReport{ID: uuid.New(), Source: source}, nil
+	}), dir, true)
+	require.NoError(t, job.Run(canceled()))
+
+	exists := func(path ...string) bool {
+		_, err := os.Stat(filepath.Join(append([]string{dir}, path...)...))
+		return err == nil
+	}
+
+	job.ReconcileReports(ctx)
+	assert.Equal(t, []string{"2024-03-20.csv"}, reconciled)
+	assert.True(t, exists(doneDir, "2024-03-20.csv"))
+	assert.True(t, exists(failedDir, "2024-03-21.csv"), "invalid report is not retried")
+	assert.True(t, exists("2024-03-22.csv"), "report is retried if it could not be reconciled")
+	assert.True(t, exists("notes.txt"))
+
+	unavailable = false
+	job.ReconcileReports(ctx)
+	assert.Equal(t, []string{"2024-03-20.csv", "2024-03-22.csv"}, reconciled, "reconciled report is not reconciled again")
+	assert.True(t, exists(doneDir, "2024-03-22.csv"))
+}
+
+// canceled - context of job which creates its directories, but does not scan them in background.
+func canceled() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,83 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// Header - columns of settlement report of payment provider. Amount is decimal in major units of currency,
+// settled_at is RFC 3339 timestamp.
+var Header = []string{"transaction_id", "payment_id", "status", "amount", "currency", "settled_at", "reason"}
+
+// ParseReport reads settlement report in CSV. Whole report is rejected if any line is invalid, so it is never
+// reconciled partially.
+func ParseReport(r io.Reader) ([]model.SettlementRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(Header)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %v", model.ErrSettlementRecordInvalid, err)
+	}
+
+	if strings.Join(header, ",") != strings.Join(Header, ",") {
+		return nil, fmt.Errorf("%w: header must be %q", model.ErrSettlementRecordInvalid, strings.Join(Header, ","))
+	}
+
+	var records []model.SettlementRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", model.ErrSettlementRecordInvalid, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		record, err := parseRecord(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		records = append(records, record)
+	}
+}
+
+func parseRecord(fields []string) (model.SettlementRecord, error) {
+	paymentID, err := uuid.Parse(fields[1])
+	if err != nil {
+		return model.SettlementRecord{}, fmt.Errorf("%w: payment ID: %v", model.ErrSettlementRecordInvalid, err)
+	}
+
+	amount, err := model.ParseMoney(fields[3], strings.ToUpper(fields[4]))
+	if err != nil {
+		return model.SettlementRecord{}, fmt.Errorf("%w: %w", model.ErrSettlementRecordInvalid, err)
+	}
+
+	settledAt, err := time.Parse(time.RFC3339, fields[5])
+	if err != nil {
+		return model.SettlementRecord{}, fmt.Errorf("%w: settled_at: %v", model.ErrSettlementRecordInvalid, err)
+	}
+
+	record := model.SettlementRecord{
+		TransactionID: fields[0],
+		PaymentID:     paymentID,
+		Status:        model.SettlementStatus(strings.ToLower(fields[2])),
+		Amount:        amount,
+		SettledAt:     settledAt.UTC(),
+		Reason:        fields[6],
+	}
+
+	return record, record.Validate()
+}
@@ -0,0 +1,68 @@
+package settlement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+func TestParseReport(t *testing.T) {
+	settledID, failedID := uuid.New(), uuid.New()
+
+	records, err := ParseReport(strings.NewReader(`transaction_id,payment_id,status,amount,currency,settled_at,reason
+psp_1,` + settledID.String() + `,settled,200.00,eur,2024-03-20T10:00:00+01:00,
+,` + failedID.String() + `,FAILED,60,EUR,2024-03-20T11:00:00Z,"expired card, retry"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []model.SettlementRecord{
+		{
+			TransactionID: "psp_1",
+			PaymentID:     settledID,
+			Status:        model.SettlementSettled,
+			Amount:        model.Money{Amount: 200_00, Currency: "EUR"},
+			SettledAt:     time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			PaymentID: failedID,
+			Status:    model.SettlementFailed,
+			Amount:    model.Money{Amount: 60_00, Currency: "EUR"},
+			SettledAt: time.Date(2024, 3, 20, 11, 0, 0, 0, time.UTC),
+			Reason:    "expired card, retry",
+		},
+	}, records)
+
+	header := strings.Join(Header, ",") + "\n"
+	records, err = ParseReport(strings.NewReader(header))
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	tests := []struct {
+		name   string
+		report string
+	}{
+		{name: "empty file", report: ""},
+		{name: "wrong header", report: "id,payment,status,amount,currency,at,reason\n"},
+		{name: "missing column", report: header + "psp_1," + settledID.String() + ",settled,200.00,EUR,2024-03-20T10:00:00Z\n"},
+		{name: "bad payment ID", report: header + "psp_1,42,settled,200.00,EUR,2024-03-20T10:00:00Z,\n"},
+		{name: "bad amount", report: header + "psp_1," + settledID.String() + ",settled,200.005,EUR,2024-03-20T10:00:00Z,\n"},
+		{name: "bad time", report: header + "psp_1," + settledID.String() + ",settled,200.00,EUR,20.03.2024,\n"},
+		{name: "unknown status", report: header + "psp_1," + settledID.String() + ",refunded,200.00,EUR,2024-03-20T10:00:00Z,\n"},
+		{name: "settled without transaction", report: header + "," + settledID.String() + ",settled,200.00,EUR,2024-03-20T10:00:00Z,\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReport(strings.NewReader(tt.report))
+			assert.ErrorIs(t, err, model.ErrSettlementRecordInvalid)
+		})
+	}
+
+	_, err = ParseReport(strings.NewReader(header + "psp_1," + settledID.String() + ",settled,200.00,EUR,2024-03-20T10:00:00Z,\npsp_2,42,settled,1,EUR,2024-03-20T10:00:00Z,\n"))
+	assert.ErrorContains(t, err, "line 3")
+}
@@ -0,0 +1,160 @@
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	netsmtp "net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// Config - SMTP server of outgoing mail. Auth is used only if Username is set.
+type Config struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+// Mailer - delivers emails by SMTP. STARTTLS is used if server supports it.
+type Mailer struct {
+	cfg Config
+	now func() time.Time
+}
+
+func New(cfg Config) *Mailer {
+	return &Mailer{
+		cfg: cfg,
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Send - whole SMTP session is limited by ctx deadline.
+func (m *Mailer) Send(ctx context.Context, email model.Email) error {
+	host, _, err := net.SplitHostPort(m.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %s: %w", m.cfg.Addr, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("could not connect to SMTP server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := netsmtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("SMTP greeting: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS: %w", err)
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err = client.Auth(netsmtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)); err != nil {
+			return fmt.Errorf("SMTP auth: %w", err)
+		}
+	}
+
+	if err = client.Mail(m.cfg.From); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM: %w", err)
+	}
+
+	if err = client.Rcpt(email.To); err != nil {
+		return fmt.Errorf("SMTP RCPT TO: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+
+	if _, err = w.Write(m.message(email)); err != nil {
+		return fmt.Errorf("SMTP write message: %w", err)
+	}
+
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("SMTP message is not accepted: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (m *Mailer) message(email model.Email) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(m.cfg.From) + "\r\n")
+	b.WriteString("To: " + headerValue(email.To) + "\r\n")
+	b.WriteString("Subject: " + headerValue(email.Subject) + "\r\n")
+	b.WriteString("Date: " + m.now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(email.Attachments) == 0 {
+		b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		b.WriteString("\r\n")
+		b.WriteString(crlf(email.Body))
+
+		return []byte(b.String())
+	}
+
+	// multipart/mixed: text of email followed by attachments in base64.
+	mw := multipart.NewWriter(&b)
+	b.WriteString("Content-Type: multipart/mixed; boundary=" + mw.Boundary() + "\r\n")
+	b.WriteString("\r\n")
+
+	text, _ := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	_, _ = text.Write([]byte(crlf(email.Body)))
+
+	for _, attachment := range email.Attachments {
+		part, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {headerValue(attachment.ContentType)},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		_, _ = part.Write([]byte(base64Lines(attachment.Content)))
+	}
+
+	_ = mw.Close() // writes into builder, can not fail
+
+	return []byte(b.String())
+}
+
+// crlf - SMTP requires CRLF line breaks.
+func crlf(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// base64Lines - base64 of content split into lines of 76 characters as MIME requires.
+func base64Lines(content []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(content)
+
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+
+	return b.String()
+}
+
+// headerValue drops line breaks, so value can not inject other headers.
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
@@ -0,0 +1,49 @@
+package smtp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/gateway/smtp/smtptest"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+func TestMailer_Send(t *testing.T) {
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	mailer := New(Config{Addr: server.Addr, From: "booking@example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := mailer.Send(ctx, model.Email{
+		To:      "guest@example.com",
+		Subject: "Booking confirmed\r\nBcc: spam@example.com",
+		Body:    "Dear guest,\n.\nsee you soon",
+	})
+	require.NoError(t, err)
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "booking@example.com", messages[0].From)
+	assert.Equal(t, []string{"guest@example.com"}, messages[0].To)
+	assert.Contains(t, messages[0].Data, "Subject: Booking confirmedBcc: spam@example.com\r\n", "header can not be injected")
+	assert.Contains(t, messages[0].Data, "\r\n\r\nDear guest,\r\n.\r\nsee you soon")
+
+	server.FailNext(1)
+	err = mailer.Send(ctx, model.Email{To: "guest@example.com", Subject: "retry"})
+	assert.ErrorContains(t, err, "451")
+	assert.Len(t, server.Messages(), 1)
+}
+
+func TestMailer_SendUnavailableServer(t *testing.T) {
+	server := smtptest.NewServer()
+	server.Close()
+
+	err := New(Config{Addr: server.Addr, From: "booking@example.com"}).Send(context.Background(), model.Email{To: "guest@example.com"})
+	assert.ErrorContains(t, err, "could not connect")
+}
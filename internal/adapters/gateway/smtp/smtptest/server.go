@@ -0,0 +1,165 @@
+// Package smtptest provides in-process SMTP server for tests, like net/http/httptest does for HTTP.
+package smtptest
+
+import (
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Message - mail accepted by server.
+type Message struct {
+	From string
+	To   []string
+	Data string // headers and body, lines are separated by CRLF
+}
+
+// Server - plain SMTP server on local port without TLS and auth.
+type Server struct {
+	Addr string // host:port to connect
+
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	m        sync.Mutex
+	messages []Message
+	failures int // number of next messages which are rejected with temporary error
+}
+
+// NewServer starts server which accepts all mails.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("smtptest: failed to listen: " + err.Error())
+	}
+
+	s := &Server{Addr: listener.Addr().String(), listener: listener}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s
+}
+
+// FailNext makes server reject next n messages with temporary error 451.
+func (s *Server) FailNext(n int) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.failures = n
+}
+
+// Messages returns accepted mails in order of delivery.
+func (s *Server) Messages() []Message {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	messages := make([]Message, len(s.messages))
+	copy(messages, s.messages)
+
+	return messages
+}
+
+// Close stops server and waits for open sessions.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // listener is closed
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.session(conn)
+		}()
+	}
+}
+
+func (s *Server) session(conn net.Conn) {
+	c := textproto.NewConn(conn)
+	defer c.Close()
+
+	var message Message
+	reply := func(format string, args ...any) bool {
+		return c.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 localhost smtptest") {
+		return
+	}
+
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			message = Message{From: address(arg)}
+			reply("250 OK")
+		case "RCPT":
+			message.To = append(message.To, address(arg))
+			reply("250 OK")
+		case "DATA":
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+
+			data, err := io.ReadAll(c.DotReader())
+			if err != nil {
+				return
+			}
+			message.Data = strings.ReplaceAll(string(data), "\n", "\r\n")
+
+			if s.accept(message) {
+				reply("250 OK")
+			} else {
+				reply("451 Temporary failure, try again later")
+			}
+		case "RSET":
+			message = Message{}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *Server) accept(message Message) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return false
+	}
+
+	s.messages = append(s.messages, message)
+
+	return true
+}
+
+// address extracts mailbox from "FROM:<a@b>" or "TO:<a@b>" argument.
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	return strings.Trim(strings.TrimSpace(addr), "<>")
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+const responseLimit = 64 << 10 // response body is drained to reuse connection, but is not needed
+
+var ErrNotPublicAddress = errors.New("webhook endpoint is not in public network")
+
+// Sender - delivers webhooks by HTTP POST. Redirects are not followed, partner must register final URL.
+// Endpoints are connected only by public addresses, so a partner host can not be used to reach internal services.
+type Sender struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+type Option func(*Sender)
+
+// WithPrivateNetworks - endpoints in local and private networks are allowed, e.g. partner stubs of tests.
+func WithPrivateNetworks() Option {
+	return func(s *Sender) {
+		s.allowPrivate = true
+	}
+}
+
+func NewSender(timeout time.Duration, opts ...Option) *Sender {
+	s := &Sender{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, Control: s.checkAddress}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // proxy would connect to endpoint on behalf of sender, bypassing address check
+	transport.DialContext = dialer.DialContext
+
+	s.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return s
+}
+
+// checkAddress - called for resolved address of every connection, ErrNotPublicAddress if it is not public.
+func (s *Sender) checkAddress(_, address string, _ syscall.RawConn) error {
+	if s.allowPrivate {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !util.IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrNotPublicAddress, addr)
+	}
+
+	return nil
+}
+
+func (s *Sender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "booking-webhooks/1.0")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, responseLimit))
+
+	return resp.StatusCode, nil
+}
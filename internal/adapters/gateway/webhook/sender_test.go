@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_Send(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "evt_1", r.Header.Get("X-Webhook-Event-ID"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	headers := map[string]string{"X-Webhook-Event-ID": "evt_1"}
+
+	_, err := NewSender(time.Second).Send(context.Background(), server.URL, headers, []byte(`{}`))
+	assert.ErrorIs(t, err, ErrNotPublicAddress, "loopback endpoint is rejected")
+
+	code, err := NewSender(time.Second, WithPrivateNetworks()).Send(context.Background(), server.URL, headers, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, code)
+}
@@ -8,6 +8,27 @@ const (
 	SuccessPaymentProcess Topic = "SuccessPaymentProcess"
 )
 
+// Order change flow.
+const (
+	ModifyOrderRequest Topic = "ModifyOrderRequest"
+	CancelOrderRequest Topic = "CancelOrderRequest"
+	RefundRequest      Topic = "RefundRequest"
+	RefundProcessed    Topic = "RefundProcessed"
+	OrderStatusChanged Topic = "OrderStatusChanged"
+)
+
+// Inventory flow.
+const (
+	InventoryUpdateRequest Topic = "InventoryUpdateRequest"
+	ExpireOrdersRequest    Topic = "ExpireOrdersRequest"
+	JoinWaitlistRequest    Topic = "JoinWaitlistRequest"
+)
+
+// Saga flow.
+const (
+	ResumeSagaRequest Topic = "ResumeSagaRequest"
+)
+
 // Error flow.
 const (
 	FailedOrder          Topic = "FailedOrder"
@@ -23,4 +44,13 @@ var AllTopics = [...]Topic{
 	NotificationRequest,
 	FailedPaymentProcess,
 	SuccessPaymentProcess,
+	ModifyOrderRequest,
+	CancelOrderRequest,
+	RefundRequest,
+	RefundProcessed,
+	OrderStatusChanged,
+	InventoryUpdateRequest,
+	ExpireOrdersRequest,
+	JoinWaitlistRequest,
+	ResumeSagaRequest,
 }
@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	s "aplication-design-test-task/internal/adapters/storage"
 	"aplication-design-test-task/internal/adapters/storage/inmemory"
 	"aplication-design-test-task/internal/adapters/storage/repository"
@@ -11,17 +13,72 @@ import (
 )
 
 type storage struct {
+	hotelRepo    *repository.HotelRepository
+	roomTypeRepo *repository.RoomTypeRepository
+
 	orderRepo *repository.OrderRepository
 	roomRepo  *repository.RoomRepository
+	promoRepo *repository.PromoRepository
+	rateRepo  *repository.RateRepository
+	fxRepo    *repository.FXRepository
+
+	loyaltyRepo  *repository.LoyaltyRepository
+	waitlistRepo *repository.WaitlistRepository
+
+	overbookingRepo *repository.OverbookingRepository
+	allotmentRepo   *repository.AllotmentRepository
+	paymentRepo     *repository.PaymentRepository
+	invoiceRepo     *repository.InvoiceRepository
+	reconcileRepo   *repository.ReconciliationRepository
+	sagaRepo        *repository.SagaRepository
+	webhookRepo     *repository.WebhookRepository
 }
 
 func NewStorage() *storage {
+	innMemStoreForHotels := inmemory.NewInMemoryStorage[model.HotelID, model.Hotel]()
+	innMemStoreForRoomTypes := inmemory.NewInMemoryStorage[model.RoomTypeKey, model.RoomType]()
 	innMemStoreForReservationOrders := inmemory.NewInMemoryStorage[model.OrderID, model.Order]()
 	innMemStoreForRoomAvailability := inmemory.NewInMemoryStorage[model.RoomAvailabilityID, model.RoomAvailability]()
+	innMemStoreForPromoCodes := inmemory.NewInMemoryStorage[string, model.PromoCode]()
+	innMemStoreForPromoRedemptions := inmemory.NewInMemoryStorage[model.OrderID, model.PromoRedemption]()
+	innMemStoreForRates := inmemory.NewInMemoryStorage[model.RateID, model.Rate]()
+	innMemStoreForRatePlans := inmemory.NewInMemoryStorage[model.RatePlanID, model.RatePlan]()
+	innMemStoreForFXRates := inmemory.NewInMemoryStorage[string, model.FXRate]()
+	innMemStoreForLoyaltyAccounts := inmemory.NewInMemoryStorage[string, model.LoyaltyAccount]()
+	innMemStoreForLoyaltyEntries := inmemory.NewInMemoryStorage[uuid.UUID, model.LoyaltyEntry]()
+	innMemStoreForWaitlist := inmemory.NewInMemoryStorage[uuid.UUID, model.WaitlistEntry]()
+	innMemStoreForOverbookingRules := inmemory.NewInMemoryStorage[model.OverbookingRuleID, model.OverbookingRule]()
+	innMemStoreForAllotments := inmemory.NewInMemoryStorage[string, model.Allotment]()
+	innMemStoreForPayments := inmemory.NewInMemoryStorage[uuid.UUID, model.Payment]()
+	innMemStoreForPaymentCallbacks := inmemory.NewInMemoryStorage[string, model.PaymentCallback]()
+	innMemStoreForRefunds := inmemory.NewInMemoryStorage[uuid.UUID, model.Refund]()
+	innMemStoreForInvoices := inmemory.NewInMemoryStorage[model.OrderID, model.Invoice]()
+	innMemStoreForInvoiceDocuments := inmemory.NewInMemoryStorage[string, model.Invoice]()
+	innMemStoreForReconciliations := inmemory.NewInMemoryStorage[uuid.UUID, model.ReconciliationReport]()
+	innMemStoreForSagas := inmemory.NewInMemoryStorage[model.OrderID, model.Saga]()
+	innMemStoreForWebhookSubscriptions := inmemory.NewInMemoryStorage[model.WebhookSubscriptionID, model.WebhookSubscription]()
+	innMemStoreForWebhookDeliveries := inmemory.NewInMemoryStorage[uuid.UUID, model.WebhookDelivery]()
 
 	return &storage{
+		hotelRepo:    repository.NewHotelRepository(innMemStoreForHotels),
+		roomTypeRepo: repository.NewRoomTypeRepository(innMemStoreForRoomTypes),
+
 		orderRepo: repository.NewOrderRepository(innMemStoreForReservationOrders),
 		roomRepo:  repository.NewRoomRepository(innMemStoreForRoomAvailability),
+		promoRepo: repository.NewPromoRepository(innMemStoreForPromoCodes, innMemStoreForPromoRedemptions),
+		rateRepo:  repository.NewRateRepository(innMemStoreForRates, innMemStoreForRatePlans),
+		fxRepo:    repository.NewFXRepository(innMemStoreForFXRates),
+
+		loyaltyRepo:  repository.NewLoyaltyRepository(innMemStoreForLoyaltyAccounts, innMemStoreForLoyaltyEntries),
+		waitlistRepo: repository.NewWaitlistRepository(innMemStoreForWaitlist),
+
+		overbookingRepo: repository.NewOverbookingRepository(innMemStoreForOverbookingRules),
+		allotmentRepo:   repository.NewAllotmentRepository(innMemStoreForAllotments),
+		paymentRepo:     repository.NewPaymentRepository(innMemStoreForPayments, innMemStoreForPaymentCallbacks, innMemStoreForRefunds),
+		invoiceRepo:     repository.NewInvoiceRepository(innMemStoreForInvoices, innMemStoreForInvoiceDocuments),
+		reconcileRepo:   repository.NewReconciliationRepository(innMemStoreForReconciliations),
+		sagaRepo:        repository.NewSagaRepository(innMemStoreForSagas),
+		webhookRepo:     repository.NewWebhookRepository(innMemStoreForWebhookSubscriptions, innMemStoreForWebhookDeliveries),
 	}
 }
 
@@ -29,6 +86,14 @@ func (s *storage) BeginTx(ctx context.Context) (s.Transaction, error) {
 	return transaction.New(ctx), nil
 }
 
+func (s *storage) GetHotelRepo() *repository.HotelRepository {
+	return s.hotelRepo
+}
+
+func (s *storage) GetRoomTypeRepo() *repository.RoomTypeRepository {
+	return s.roomTypeRepo
+}
+
 func (s *storage) GetOrderRepo() *repository.OrderRepository {
 	return s.orderRepo
 }
@@ -37,6 +102,54 @@ func (s *storage) GetRoomRepo() *repository.RoomRepository {
 	return s.roomRepo
 }
 
+func (s *storage) GetPromoRepo() *repository.PromoRepository {
+	return s.promoRepo
+}
+
+func (s *storage) GetRateRepo() *repository.RateRepository {
+	return s.rateRepo
+}
+
+func (s *storage) GetFXRepo() *repository.FXRepository {
+	return s.fxRepo
+}
+
+func (s *storage) GetLoyaltyRepo() *repository.LoyaltyRepository {
+	return s.loyaltyRepo
+}
+
+func (s *storage) GetWaitlistRepo() *repository.WaitlistRepository {
+	return s.waitlistRepo
+}
+
+func (s *storage) GetOverbookingRepo() *repository.OverbookingRepository {
+	return s.overbookingRepo
+}
+
+func (s *storage) GetAllotmentRepo() *repository.AllotmentRepository {
+	return s.allotmentRepo
+}
+
+func (s *storage) GetPaymentRepo() *repository.PaymentRepository {
+	return s.paymentRepo
+}
+
+func (s *storage) GetInvoiceRepo() *repository.InvoiceRepository {
+	return s.invoiceRepo
+}
+
+func (s *storage) GetReconciliationRepo() *repository.ReconciliationRepository {
+	return s.reconcileRepo
+}
+
+func (s *storage) GetSagaRepo() *repository.SagaRepository {
+	return s.sagaRepo
+}
+
+func (s *storage) GetWebhookRepo() *repository.WebhookRepository {
+	return s.webhookRepo
+}
+
 func (s *storage) Close(_ context.Context) error {
 	return nil
 }
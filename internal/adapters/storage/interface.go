@@ -10,8 +10,22 @@ type (
 	Storage interface {
 		BeginTx(ctx context.Context) (Transaction, error)
 
+		GetHotelRepo() *repository.HotelRepository
+		GetRoomTypeRepo() *repository.RoomTypeRepository
 		GetOrderRepo() *repository.OrderRepository
 		GetRoomRepo() *repository.RoomRepository
+		GetPromoRepo() *repository.PromoRepository
+		GetRateRepo() *repository.RateRepository
+		GetFXRepo() *repository.FXRepository
+		GetLoyaltyRepo() *repository.LoyaltyRepository
+		GetWaitlistRepo() *repository.WaitlistRepository
+		GetOverbookingRepo() *repository.OverbookingRepository
+		GetAllotmentRepo() *repository.AllotmentRepository
+		GetPaymentRepo() *repository.PaymentRepository
+		GetInvoiceRepo() *repository.InvoiceRepository
+		GetReconciliationRepo() *repository.ReconciliationRepository
+		GetSagaRepo() *repository.SagaRepository
+		GetWebhookRepo() *repository.WebhookRepository
 
 		// Repo[T any]()T // todo wait in future in Golang =)
 		//  see more Repository pattern with Go generics -> github.com/imperiuse/golib/db/db.go
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type Allotment = model.Allotment
+
+type AllotmentRepository struct {
+	storage Storer[string, Allotment]
+}
+
+func NewAllotmentRepository(store Storer[string, Allotment]) *AllotmentRepository {
+	return &AllotmentRepository{storage: store}
+}
+
+func (r *AllotmentRepository) StoreAllotment(ctx context.Context, allotment Allotment) error {
+	return r.storage.Create(ctx, allotment.Code, allotment)
+}
+
+func (r *AllotmentRepository) GetAllotment(ctx context.Context, code string) (Allotment, error) {
+	return r.storage.Read(ctx, code)
+}
+
+func (r *AllotmentRepository) UpdateAllotment(ctx context.Context, allotment Allotment) error {
+	return r.storage.Update(ctx, allotment.Code, allotment)
+}
+
+func (r *AllotmentRepository) GetListAllotments(ctx context.Context) ([]Allotment, error) {
+	return r.storage.List(ctx)
+}
+
+// GetAllotmentsToRelease returns active allotments which release date is not after the moment.
+func (r *AllotmentRepository) GetAllotmentsToRelease(ctx context.Context, at time.Time) ([]Allotment, error) {
+	all, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allotments := make([]Allotment, 0)
+	for _, allotment := range all {
+		if allotment.Status == model.AllotmentActive && !allotment.ReleaseAt.After(at) {
+			allotments = append(allotments, allotment)
+		}
+	}
+
+	return allotments, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type FXRate = model.FXRate
+
+type FXRepository struct {
+	m     sync.Mutex // serializes replacement of rates
+	rates Storer[string, FXRate]
+}
+
+func NewFXRepository(rates Storer[string, FXRate]) *FXRepository {
+	return &FXRepository{rates: rates}
+}
+
+func fxRateKey(base, quote string, effectiveFrom time.Time) string {
+	return fmt.Sprintf("%s/%s/%s", base, quote, effectiveFrom.UTC().Format(time.RFC3339))
+}
+
+// StoreRate - store rate of currency pair, rate of the pair with the same effective date is replaced.
+func (r *FXRepository) StoreRate(ctx context.Context, rate FXRate) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	key := fxRateKey(rate.Base, rate.Quote, rate.EffectiveFrom)
+
+	// not readable rate is created, real storage errors are returned by Create below.
+	if _, err := r.rates.Read(ctx, key); err == nil {
+		return r.rates.Update(ctx, key, rate)
+	}
+
+	return r.rates.Create(ctx, key, rate)
+}
+
+func (r *FXRepository) GetListRates(ctx context.Context) ([]FXRate, error) {
+	return r.rates.List(ctx)
+}
+
+// GetRate returns rate of currency pair effective at time at. Rate of reversed pair is inverted if the pair itself
+// has no rate. model.ErrUnknownFXRate if there is neither.
+func (r *FXRepository) GetRate(ctx context.Context, base, quote string, at time.Time) (FXRate, error) {
+	rates, err := r.rates.List(ctx)
+	if err != nil {
+		return FXRate{}, err
+	}
+
+	var direct, reversed *FXRate
+	for i, rate := range rates {
+		if rate.EffectiveFrom.After(at) {
+			continue
+		}
+
+		switch {
+		case rate.Base == base && rate.Quote == quote:
+			if direct == nil || rate.EffectiveFrom.After(direct.EffectiveFrom) {
+				direct = &rates[i]
+			}
+		case rate.Base == quote && rate.Quote == base:
+			if reversed == nil || rate.EffectiveFrom.After(reversed.EffectiveFrom) {
+				reversed = &rates[i]
+			}
+		}
+	}
+
+	switch {
+	case direct != nil:
+		return *direct, nil
+	case reversed != nil:
+		return reversed.Inverse()
+	default:
+		return FXRate{}, fmt.Errorf("%w: %s/%s at %s", model.ErrUnknownFXRate, base, quote, at.Format(time.RFC3339))
+	}
+}
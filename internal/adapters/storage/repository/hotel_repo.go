@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type Hotel = model.Hotel
+
+type HotelRepository struct {
+	storage Storer[model.HotelID, Hotel]
+}
+
+func NewHotelRepository(store Storer[model.HotelID, Hotel]) *HotelRepository {
+	return &HotelRepository{storage: store}
+}
+
+func (r *HotelRepository) StoreHotel(ctx context.Context, hotel Hotel) error {
+	return r.storage.Create(ctx, hotel.ID, hotel)
+}
+
+func (r *HotelRepository) GetHotel(ctx context.Context, id model.HotelID) (Hotel, error) {
+	return r.storage.Read(ctx, id)
+}
+
+func (r *HotelRepository) UpdateHotel(ctx context.Context, hotel Hotel) error {
+	return r.storage.Update(ctx, hotel.ID, hotel)
+}
+
+func (r *HotelRepository) DeleteHotel(ctx context.Context, id model.HotelID) error {
+	return r.storage.Delete(ctx, id)
+}
+
+func (r *HotelRepository) GetListHotels(ctx context.Context) ([]Hotel, error) {
+	return r.storage.List(ctx)
+}
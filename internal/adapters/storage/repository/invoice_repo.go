@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type Invoice = model.Invoice
+
+type InvoiceRepository struct {
+	m         sync.Mutex                          // serializes numbering, so numbers of hotel have no gaps and duplicates
+	invoices  Storer[ReservationOrderID, Invoice] // current invoice of the order
+	documents Storer[string, Invoice]             // every issued invoice and credit note by number
+}
+
+func NewInvoiceRepository(invoices Storer[ReservationOrderID, Invoice], documents Storer[string, Invoice]) *InvoiceRepository {
+	return &InvoiceRepository{invoices: invoices, documents: documents}
+}
+
+// IssueInvoice stores invoice with the next number of its hotel as current invoice of the order. Order has one
+// current invoice, already issued invoice of the order is returned as is.
+func (r *InvoiceRepository) IssueInvoice(ctx context.Context, invoice Invoice) (Invoice, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if issued, err := r.invoices.Read(ctx, invoice.OrderID); err == nil {
+		return issued, nil
+	}
+
+	invoice.Kind = model.InvoiceDocument
+	if err := r.number(ctx, &invoice); err != nil {
+		return Invoice{}, err
+	}
+
+	if err := r.documents.Create(ctx, invoice.Number, invoice); err != nil {
+		return Invoice{}, err
+	}
+
+	return invoice, r.invoices.Create(ctx, invoice.OrderID, invoice)
+}
+
+// IssueCreditNote stores credit note with the next number of its hotel. Credit note which cancels corrected invoice
+// removes it from current invoice of the order, so the order may get replacement invoice.
+func (r *InvoiceRepository) IssueCreditNote(ctx context.Context, note Invoice, cancels bool) (Invoice, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	current, err := r.invoices.Read(ctx, note.OrderID)
+	if err != nil {
+		return Invoice{}, err
+	}
+	if current.Number != note.Corrects {
+		return Invoice{}, fmt.Errorf("invoice %s is not current invoice of order %v", note.Corrects, note.OrderID)
+	}
+
+	note.Kind = model.CreditNote
+	if err = r.number(ctx, &note); err != nil {
+		return Invoice{}, err
+	}
+
+	if err = r.documents.Create(ctx, note.Number, note); err != nil {
+		return Invoice{}, err
+	}
+
+	if cancels {
+		return note, r.invoices.Delete(ctx, note.OrderID)
+	}
+
+	return note, nil
+}
+
+// number - assign the next number of hotel to the document.
+func (r *InvoiceRepository) number(ctx context.Context, invoice *Invoice) error {
+	documents, err := r.documents.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	seq := 1
+	for _, issued := range documents {
+		if issued.HotelID == invoice.HotelID {
+			seq++
+		}
+	}
+
+	invoice.Number = fmt.Sprintf("%d-%06d", invoice.HotelID, seq)
+
+	return nil
+}
+
+// GetInvoice returns current invoice of the order.
+func (r *InvoiceRepository) GetInvoice(ctx context.Context, orderID ReservationOrderID) (Invoice, error) {
+	return r.invoices.Read(ctx, orderID)
+}
+
+// GetListOrderInvoices returns invoices and credit notes of the order in order of their numbers.
+func (r *InvoiceRepository) GetListOrderInvoices(ctx context.Context, orderID ReservationOrderID) ([]Invoice, error) {
+	documents, err := r.documents.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoices []Invoice
+	for _, invoice := range documents {
+		if invoice.OrderID == orderID {
+			invoices = append(invoices, invoice)
+		}
+	}
+
+	slices.SortFunc(invoices, func(a, b Invoice) int { return cmp.Compare(a.Number, b.Number) })
+
+	return invoices, nil
+}
+
+// GetListInvoices returns every issued invoice and credit note.
+func (r *InvoiceRepository) GetListInvoices(ctx context.Context) ([]Invoice, error) {
+	return r.documents.List(ctx)
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type (
+	LoyaltyAccount = model.LoyaltyAccount
+	LoyaltyEntry   = model.LoyaltyEntry
+)
+
+type LoyaltyRepository struct {
+	m sync.Mutex // serialize balance changes, so balance can not be spent twice
+
+	accounts Storer[string, LoyaltyAccount]
+	entries  Storer[uuid.UUID, LoyaltyEntry]
+}
+
+func NewLoyaltyRepository(accounts Storer[string, LoyaltyAccount], entries Storer[uuid.UUID, LoyaltyEntry]) *LoyaltyRepository {
+	return &LoyaltyRepository{accounts: accounts, entries: entries}
+}
+
+func (r *LoyaltyRepository) GetAccount(ctx context.Context, email string) (LoyaltyAccount, error) {
+	return r.accounts.Read(ctx, model.NormalizeEmail(email))
+}
+
+// GetLedger returns entries of account ordered by creation time.
+func (r *LoyaltyRepository) GetLedger(ctx context.Context, email string) ([]LoyaltyEntry, error) {
+	return r.filterEntries(ctx, func(e LoyaltyEntry) bool { return e.UserEmail == model.NormalizeEmail(email) })
+}
+
+// GetOrderEntries returns entries made for the order ordered by creation time.
+func (r *LoyaltyRepository) GetOrderEntries(ctx context.Context, orderID ReservationOrderID) ([]LoyaltyEntry, error) {
+	return r.filterEntries(ctx, func(e LoyaltyEntry) bool { return e.OrderID == orderID })
+}
+
+// AddEntry atomically stores ledger entry and applies it to account balance, account is created on first entry.
+func (r *LoyaltyRepository) AddEntry(ctx context.Context, entry LoyaltyEntry) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	entry.UserEmail = model.NormalizeEmail(entry.UserEmail)
+
+	// not readable account is created, real storage errors are returned by Create below.
+	account, err := r.accounts.Read(ctx, entry.UserEmail)
+	isNew := err != nil
+	if isNew {
+		account = LoyaltyAccount{UserEmail: entry.UserEmail, Tier: model.BasicTier, CreatedAt: entry.CreatedAt}
+	}
+
+	if account, err = account.Apply(entry); err != nil {
+		return err
+	}
+
+	if err = r.entries.Create(ctx, entry.ID, entry); err != nil {
+		return err
+	}
+
+	if isNew {
+		err = r.accounts.Create(ctx, account.UserEmail, account)
+	} else {
+		err = r.accounts.Update(ctx, account.UserEmail, account)
+	}
+
+	if err != nil {
+		_ = r.entries.Delete(ctx, entry.ID)
+	}
+
+	return err
+}
+
+// RemoveEntry atomically removes ledger entry and reverts it on account balance, e.g. on transaction rollback.
+func (r *LoyaltyRepository) RemoveEntry(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	entry, err := r.entries.Read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	account, err := r.accounts.Read(ctx, entry.UserEmail)
+	if err != nil {
+		return err
+	}
+
+	if err = r.accounts.Update(ctx, account.UserEmail, account.Revert(entry, at)); err != nil {
+		return err
+	}
+
+	if err = r.entries.Delete(ctx, id); err != nil {
+		_ = r.accounts.Update(ctx, account.UserEmail, account)
+		return err
+	}
+
+	return nil
+}
+
+func (r *LoyaltyRepository) filterEntries(ctx context.Context, match func(LoyaltyEntry) bool) ([]LoyaltyEntry, error) {
+	all, err := r.entries.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LoyaltyEntry, 0)
+	for _, entry := range all {
+		if match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b LoyaltyEntry) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	return entries, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type OverbookingRule = model.OverbookingRule
+
+type OverbookingRepository struct {
+	storage Storer[model.OverbookingRuleID, OverbookingRule]
+}
+
+func NewOverbookingRepository(store Storer[model.OverbookingRuleID, OverbookingRule]) *OverbookingRepository {
+	return &OverbookingRepository{storage: store}
+}
+
+func (r *OverbookingRepository) StoreRule(ctx context.Context, rule OverbookingRule) error {
+	return r.storage.Create(ctx, rule.ID, rule)
+}
+
+func (r *OverbookingRepository) GetRule(ctx context.Context, id model.OverbookingRuleID) (OverbookingRule, error) {
+	return r.storage.Read(ctx, id)
+}
+
+func (r *OverbookingRepository) DeleteRule(ctx context.Context, id model.OverbookingRuleID) error {
+	return r.storage.Delete(ctx, id)
+}
+
+func (r *OverbookingRepository) GetListRules(ctx context.Context) ([]OverbookingRule, error) {
+	return r.storage.List(ctx)
+}
+
+// GetRulesForHotel returns rules of hotel, which can match any of its room-days.
+func (r *OverbookingRepository) GetRulesForHotel(ctx context.Context, hotelID int) ([]OverbookingRule, error) {
+	all, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]OverbookingRule, 0)
+	for _, rule := range all {
+		if rule.HotelID == hotelID {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
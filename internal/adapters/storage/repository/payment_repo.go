@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type (
+	Payment         = model.Payment
+	PaymentCallback = model.PaymentCallback
+	Refund          = model.Refund
+)
+
+type PaymentRepository struct {
+	payments  Storer[uuid.UUID, Payment]
+	callbacks Storer[string, PaymentCallback]
+	refunds   Storer[uuid.UUID, Refund]
+}
+
+func NewPaymentRepository(payments Storer[uuid.UUID, Payment], callbacks Storer[string, PaymentCallback], refunds Storer[uuid.UUID, Refund]) *PaymentRepository {
+	return &PaymentRepository{payments: payments, callbacks: callbacks, refunds: refunds}
+}
+
+func (r *PaymentRepository) StorePayment(ctx context.Context, payment Payment) error {
+	return r.payments.Create(ctx, payment.ID, payment)
+}
+
+func (r *PaymentRepository) GetPayment(ctx context.Context, id uuid.UUID) (Payment, error) {
+	return r.payments.Read(ctx, id)
+}
+
+func (r *PaymentRepository) UpdatePayment(ctx context.Context, payment Payment) error {
+	return r.payments.Update(ctx, payment.ID, payment)
+}
+
+func (r *PaymentRepository) GetListPayments(ctx context.Context) ([]Payment, error) {
+	return r.payments.List(ctx)
+}
+
+// GetListOrderPayments - charged payments of the order, the latest first.
+func (r *PaymentRepository) GetListOrderPayments(ctx context.Context, orderID model.OrderID) ([]Payment, error) {
+	payments, err := r.payments.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderPayments []Payment
+	for _, payment := range payments {
+		if payment.OrderID == orderID && payment.IsPaid {
+			orderPayments = append(orderPayments, payment)
+		}
+	}
+
+	slices.SortStableFunc(orderPayments, func(a, b Payment) int { return b.PaidAt.Compare(a.PaidAt) })
+
+	return orderPayments, nil
+}
+
+// StoreCallback - storage.ErrDuplicateConstraint if callback event is already received.
+func (r *PaymentRepository) StoreCallback(ctx context.Context, callback PaymentCallback) error {
+	return r.callbacks.Create(ctx, callback.EventID, callback)
+}
+
+// StoreRefund - storage.ErrDuplicateConstraint if refund is already requested.
+func (r *PaymentRepository) StoreRefund(ctx context.Context, refund Refund) error {
+	return r.refunds.Create(ctx, refund.ID, refund)
+}
+
+func (r *PaymentRepository) GetRefund(ctx context.Context, id uuid.UUID) (Refund, error) {
+	return r.refunds.Read(ctx, id)
+}
+
+func (r *PaymentRepository) UpdateRefund(ctx context.Context, refund Refund) error {
+	return r.refunds.Update(ctx, refund.ID, refund)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type (
+	PromoCode       = model.PromoCode
+	PromoRedemption = model.PromoRedemption
+)
+
+type PromoRepository struct {
+	m sync.Mutex // serialize redemptions, so usage limits can not be exceeded by concurrent bookings
+
+	codes       Storer[string, PromoCode]
+	redemptions Storer[ReservationOrderID, PromoRedemption]
+}
+
+func NewPromoRepository(
+	codes Storer[string, PromoCode],
+	redemptions Storer[ReservationOrderID, PromoRedemption],
+) *PromoRepository {
+	return &PromoRepository{codes: codes, redemptions: redemptions}
+}
+
+func (r *PromoRepository) StorePromoCode(ctx context.Context, promo PromoCode) error {
+	return r.codes.Create(ctx, promo.Code, promo)
+}
+
+func (r *PromoRepository) GetPromoCode(ctx context.Context, code string) (PromoCode, error) {
+	return r.codes.Read(ctx, code)
+}
+
+func (r *PromoRepository) GetListPromoCodes(ctx context.Context) ([]PromoCode, error) {
+	return r.codes.List(ctx)
+}
+
+// CountRedemptions returns number of redemptions of promo code made by email.
+func (r *PromoRepository) CountRedemptions(ctx context.Context, code string, email string) (int, error) {
+	redemptions, err := r.redemptions.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := 0
+	for _, redemption := range redemptions {
+		if redemption.Code == code && strings.EqualFold(redemption.UserEmail, email) {
+			cnt++
+		}
+	}
+
+	return cnt, nil
+}
+
+// Redeem atomically checks promo code limits and stores redemption for the order.
+func (r *PromoRepository) Redeem(ctx context.Context, redemption PromoRedemption) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	promo, err := r.codes.Read(ctx, redemption.Code)
+	if err != nil {
+		return err
+	}
+
+	usedByEmail, err := r.CountRedemptions(ctx, redemption.Code, redemption.UserEmail)
+	if err != nil {
+		return err
+	}
+
+	if err = promo.CheckRedemption(redemption, usedByEmail); err != nil {
+		return err
+	}
+
+	if err = r.redemptions.Create(ctx, redemption.OrderID, redemption); err != nil {
+		return err
+	}
+
+	promo.Used++
+	if err = r.codes.Update(ctx, promo.Code, promo); err != nil {
+		_ = r.redemptions.Delete(ctx, redemption.OrderID)
+		return err
+	}
+
+	return nil
+}
+
+// Release returns usage of promo code redeemed by the order.
+func (r *PromoRepository) Release(ctx context.Context, orderID ReservationOrderID) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	redemption, err := r.redemptions.Read(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	promo, err := r.codes.Read(ctx, redemption.Code)
+	if err != nil {
+		return err
+	}
+
+	if err = r.redemptions.Delete(ctx, orderID); err != nil {
+		return err
+	}
+
+	promo.Used--
+	return r.codes.Update(ctx, promo.Code, promo)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+)
+
+type (
+	Rate     = model.Rate
+	RatePlan = model.RatePlan
+)
+
+type RateRepository struct {
+	rates Storer[model.RateID, Rate]
+	plans Storer[model.RatePlanID, RatePlan]
+}
+
+func NewRateRepository(rates Storer[model.RateID, Rate], plans Storer[model.RatePlanID, RatePlan]) *RateRepository {
+	return &RateRepository{rates: rates, plans: plans}
+}
+
+func (r *RateRepository) StoreRate(ctx context.Context, rate Rate) error {
+	return r.rates.Create(ctx, rate.ID, rate)
+}
+
+func (r *RateRepository) UpdateRate(ctx context.Context, id model.RateID, rate Rate) error {
+	return r.rates.Update(ctx, id, rate)
+}
+
+// GetRatesForHotelByRoomTypeAndDate returns rates of stay nights [checkIn, checkOut).
+func (r *RateRepository) GetRatesForHotelByRoomTypeAndDate(
+	ctx context.Context,
+	hotelID,
+	roomTypeID int,
+	checkIn time.Time,
+	checkOut time.Time,
+) ([]Rate, error) {
+	allRates, err := r.rates.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredRates []Rate
+	for _, rate := range allRates {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if rate.HotelID == hotelID && rate.RoomTypeID == roomTypeID && util.IsNightBetween(rate.Date, checkIn, checkOut) {
+			filteredRates = append(filteredRates, rate)
+		}
+	}
+	return filteredRates, nil
+}
+
+func (r *RateRepository) StoreRatePlan(ctx context.Context, plan RatePlan) error {
+	return r.plans.Create(ctx, plan.ID, plan)
+}
+
+func (r *RateRepository) GetRatePlan(ctx context.Context, id model.RatePlanID) (RatePlan, error) {
+	return r.plans.Read(ctx, id)
+}
+
+func (r *RateRepository) GetListRatePlans(ctx context.Context) ([]RatePlan, error) {
+	return r.plans.List(ctx)
+}
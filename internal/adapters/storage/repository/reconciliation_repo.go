@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type ReconciliationReport = model.ReconciliationReport
+
+type ReconciliationRepository struct {
+	reports Storer[uuid.UUID, ReconciliationReport]
+}
+
+func NewReconciliationRepository(reports Storer[uuid.UUID, ReconciliationReport]) *ReconciliationRepository {
+	return &ReconciliationRepository{reports: reports}
+}
+
+func (r *ReconciliationRepository) StoreReport(ctx context.Context, report ReconciliationReport) error {
+	return r.reports.Create(ctx, report.ID, report)
+}
+
+func (r *ReconciliationRepository) GetReport(ctx context.Context, id uuid.UUID) (ReconciliationReport, error) {
+	return r.reports.Read(ctx, id)
+}
+
+// GetListReports returns reports, the latest first.
+func (r *ReconciliationRepository) GetListReports(ctx context.Context) ([]ReconciliationReport, error) {
+	reports, err := r.reports.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(reports, func(a, b ReconciliationReport) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	return reports, nil
+}
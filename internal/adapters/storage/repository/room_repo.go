@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"aplication-design-test-task/internal/core/domain/model"
@@ -12,14 +13,28 @@ type Room = model.RoomAvailability
 
 type RoomRepository struct {
 	storage Storer[int, Room]
+
+	m      sync.RWMutex
+	byDate map[string][]int // room-day IDs by date, so period queries read only room-days of the period
 }
 
 func NewRoomRepository(store Storer[int, Room]) *RoomRepository {
-	return &RoomRepository{storage: store}
+	return &RoomRepository{storage: store, byDate: make(map[string][]int)}
 }
 
 func (r *RoomRepository) StoreRoom(ctx context.Context, room Room) error {
-	return r.storage.Create(ctx, room.ID, room)
+	if err := r.storage.Create(ctx, room.ID, room); err != nil {
+		return err
+	}
+
+	// date of room-day is never changed by update, so index is maintained on create only
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	date := util.ToDay(room.Date).Format(time.DateOnly)
+	r.byDate[date] = append(r.byDate[date], room.ID)
+
+	return nil
 }
 
 func (r *RoomRepository) GetRoom(ctx context.Context, id int) (Room, error) {
@@ -34,12 +49,13 @@ func (r *RoomRepository) GetAllRooms(ctx context.Context) ([]Room, error) {
 	return r.storage.List(ctx)
 }
 
+// GetRoomsForHotelByRoomTypeAndDate returns room-days of stay nights [checkIn, checkOut).
 func (r *RoomRepository) GetRoomsForHotelByRoomTypeAndDate(
 	ctx context.Context,
 	hotelID,
 	roomTypeID int,
-	fromDate time.Time,
-	toDate time.Time,
+	checkIn time.Time,
+	checkOut time.Time,
 ) ([]Room, error) {
 	allRooms, err := r.storage.List(ctx)
 	if err != nil {
@@ -52,13 +68,39 @@ func (r *RoomRepository) GetRoomsForHotelByRoomTypeAndDate(
 			return nil, err
 		}
 
-		if room.HotelID == hotelID && room.RoomTypeID == roomTypeID && util.IsDayBetween(room.Date, fromDate, toDate) {
+		if room.HotelID == hotelID && room.RoomTypeID == roomTypeID && util.IsNightBetween(room.Date, checkIn, checkOut) {
 			filteredRooms = append(filteredRooms, room)
 		}
 	}
 	return filteredRooms, nil
 }
 
+// GetRoomsForPeriod returns room-days of all room types for days [from, to) of hotel, of all hotels if hotelID is 0.
+// Room-days are looked up by date index instead of listing the whole storage.
+func (r *RoomRepository) GetRoomsForPeriod(ctx context.Context, hotelID int, from, to time.Time) ([]Room, error) {
+	var ids []int
+
+	r.m.RLock()
+	for _, day := range util.NightsBetween(from, to) {
+		ids = append(ids, r.byDate[day.Format(time.DateOnly)]...)
+	}
+	r.m.RUnlock()
+
+	rooms := make([]Room, 0, len(ids))
+	for _, id := range ids {
+		room, err := r.storage.Read(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if hotelID == 0 || room.HotelID == hotelID {
+			rooms = append(rooms, room)
+		}
+	}
+
+	return rooms, nil
+}
+
 // GetListRooms retrieves all Rooms from the repository
 func (r *RoomRepository) GetListRooms(ctx context.Context) ([]Room, error) {
 	return r.storage.List(ctx)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	m "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"aplication-design-test-task/internal/adapters/storage/repository/mock"
@@ -173,6 +174,7 @@ func TestGetRoomsForHotelByRoomTypeAndDate(t *testing.T) {
 		{ID: 2, HotelID: 1, RoomTypeID: 2, Date: fromDate.AddDate(0, 0, 1)}, // should be included
 		{ID: 3, HotelID: 2, RoomTypeID: 1, Date: fromDate.AddDate(0, 0, 2)}, // different hotel ID
 		{ID: 4, HotelID: 1, RoomTypeID: 2, Date: toDate.AddDate(0, 0, 1)},   // outside date range
+		{ID: 5, HotelID: 1, RoomTypeID: 2, Date: toDate},                    // checkout day is not occupied
 		// Add more sample data if needed
 	}
 
@@ -188,3 +190,36 @@ func TestGetRoomsForHotelByRoomTypeAndDate(t *testing.T) {
 	assert.NoError(t, err, "GetRoomsForHotelByRoomTypeAndDate should not return an error")
 	assert.Equal(t, expectedRooms, filteredRooms, "GetRoomsForHotelByRoomTypeAndDate should return the correct filtered rooms")
 }
+
+func TestRoomRepository_GetRoomsForPeriod(t *testing.T) {
+	ctx := context.Background()
+	mockStorage := new(mock.MockRoomStorer)
+	roomRepo := NewRoomRepository(mockStorage)
+
+	fromDate := util.NewDay(2024, 4, 1)
+	rooms := []model.RoomAvailability{
+		{ID: 1, HotelID: 1, RoomTypeID: 1, Date: fromDate},
+		{ID: 2, HotelID: 1, RoomTypeID: 2, Date: fromDate.AddDate(0, 0, 1)},
+		{ID: 3, HotelID: 2, RoomTypeID: 1, Date: fromDate.AddDate(0, 0, 1)}, // different hotel
+		{ID: 4, HotelID: 1, RoomTypeID: 1, Date: fromDate.AddDate(0, 0, 2)}, // outside of period
+	}
+
+	for _, room := range rooms {
+		mockStorage.On("Create", ctx, room.ID, room).Return(nil)
+		require.NoError(t, roomRepo.StoreRoom(ctx, room))
+	}
+	for _, room := range rooms[:3] {
+		mockStorage.On("Read", ctx, room.ID).Return(room, nil)
+	}
+
+	hotelRooms, err := roomRepo.GetRoomsForPeriod(ctx, 1, fromDate, fromDate.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, []model.RoomAvailability{rooms[0], rooms[1]}, hotelRooms)
+
+	allRooms, err := roomRepo.GetRoomsForPeriod(ctx, 0, fromDate, fromDate.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, rooms[:3], allRooms)
+
+	mockStorage.AssertNotCalled(t, "List", m.Anything)
+	mockStorage.AssertNotCalled(t, "Read", ctx, rooms[3].ID)
+}
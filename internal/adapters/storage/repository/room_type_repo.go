@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type RoomType = model.RoomType
+
+type RoomTypeRepository struct {
+	storage Storer[model.RoomTypeKey, RoomType]
+}
+
+func NewRoomTypeRepository(store Storer[model.RoomTypeKey, RoomType]) *RoomTypeRepository {
+	return &RoomTypeRepository{storage: store}
+}
+
+func (r *RoomTypeRepository) StoreRoomType(ctx context.Context, roomType RoomType) error {
+	return r.storage.Create(ctx, roomType.Key(), roomType)
+}
+
+func (r *RoomTypeRepository) GetRoomType(ctx context.Context, hotelID model.HotelID, id model.RoomTypeID) (RoomType, error) {
+	return r.storage.Read(ctx, model.RoomTypeKey{HotelID: hotelID, RoomTypeID: id})
+}
+
+func (r *RoomTypeRepository) UpdateRoomType(ctx context.Context, roomType RoomType) error {
+	return r.storage.Update(ctx, roomType.Key(), roomType)
+}
+
+func (r *RoomTypeRepository) DeleteRoomType(ctx context.Context, hotelID model.HotelID, id model.RoomTypeID) error {
+	return r.storage.Delete(ctx, model.RoomTypeKey{HotelID: hotelID, RoomTypeID: id})
+}
+
+func (r *RoomTypeRepository) GetListRoomTypes(ctx context.Context) ([]RoomType, error) {
+	return r.storage.List(ctx)
+}
+
+// GetHotelRoomTypes returns room types of hotel sorted by ID.
+func (r *RoomTypeRepository) GetHotelRoomTypes(ctx context.Context, hotelID model.HotelID) ([]RoomType, error) {
+	all, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roomTypes := make([]RoomType, 0)
+	for _, roomType := range all {
+		if roomType.HotelID == hotelID {
+			roomTypes = append(roomTypes, roomType)
+		}
+	}
+
+	slices.SortFunc(roomTypes, func(a, b RoomType) int { return cmp.Compare(a.ID, b.ID) })
+
+	return roomTypes, nil
+}
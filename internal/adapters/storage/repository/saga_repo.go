@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type Saga = model.Saga
+
+// SagaRepository - booking sagas, one per order.
+type SagaRepository struct {
+	sagas Storer[ReservationOrderID, Saga]
+}
+
+func NewSagaRepository(sagas Storer[ReservationOrderID, Saga]) *SagaRepository {
+	return &SagaRepository{sagas: sagas}
+}
+
+func (r *SagaRepository) StoreSaga(ctx context.Context, saga Saga) error {
+	return r.sagas.Create(ctx, saga.OrderID, saga)
+}
+
+func (r *SagaRepository) GetSaga(ctx context.Context, orderID ReservationOrderID) (Saga, error) {
+	return r.sagas.Read(ctx, orderID)
+}
+
+func (r *SagaRepository) UpdateSaga(ctx context.Context, saga Saga) error {
+	return r.sagas.Update(ctx, saga.OrderID, saga)
+}
+
+// GetListSagas returns sagas ordered by creation time.
+func (r *SagaRepository) GetListSagas(ctx context.Context) ([]Saga, error) {
+	sagas, err := r.sagas.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(sagas, func(a, b Saga) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	return sagas, nil
+}
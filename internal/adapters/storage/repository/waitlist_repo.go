@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+)
+
+type WaitlistEntry = model.WaitlistEntry
+
+type WaitlistRepository struct {
+	m       sync.Mutex // serializes positions of entries
+	storage Storer[uuid.UUID, WaitlistEntry]
+}
+
+func NewWaitlistRepository(store Storer[uuid.UUID, WaitlistEntry]) *WaitlistRepository {
+	return &WaitlistRepository{storage: store}
+}
+
+// StoreEntry stores entry at the end of the waitlist, so entries created at the same time keep order of joining.
+func (r *WaitlistRepository) StoreEntry(ctx context.Context, entry WaitlistEntry) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	all, err := r.storage.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	entry.Position = len(all) + 1
+
+	return r.storage.Create(ctx, entry.ID, entry)
+}
+
+func (r *WaitlistRepository) UpdateEntry(ctx context.Context, entry WaitlistEntry) error {
+	return r.storage.Update(ctx, entry.ID, entry)
+}
+
+// GetListEntries returns all entries ordered by creation time.
+func (r *WaitlistRepository) GetListEntries(ctx context.Context) ([]WaitlistEntry, error) {
+	return r.filter(ctx, func(WaitlistEntry) bool { return true })
+}
+
+// GetOrderEntries returns entries of the order ordered by creation time.
+func (r *WaitlistRepository) GetOrderEntries(ctx context.Context, orderID ReservationOrderID) ([]WaitlistEntry, error) {
+	return r.filter(ctx, func(e WaitlistEntry) bool { return e.OrderID == orderID })
+}
+
+// GetWaitingEntries returns waiting entries of hotel room type which stay intersects stay [checkIn, checkOut),
+// ordered by creation time, so the earliest request is served first.
+func (r *WaitlistRepository) GetWaitingEntries(
+	ctx context.Context,
+	hotelID,
+	roomTypeID int,
+	checkIn time.Time,
+	checkOut time.Time,
+) ([]WaitlistEntry, error) {
+	return r.filter(ctx, func(e WaitlistEntry) bool {
+		return e.Status == model.WaitlistWaiting && e.HotelID == hotelID && e.RoomTypeID == roomTypeID &&
+			util.IsStaysIntersect(e.From, e.To, checkIn, checkOut)
+	})
+}
+
+func (r *WaitlistRepository) filter(ctx context.Context, match func(WaitlistEntry) bool) ([]WaitlistEntry, error) {
+	all, err := r.storage.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]WaitlistEntry, 0)
+	for _, entry := range all {
+		if match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b WaitlistEntry) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.Position, b.Position))
+	})
+
+	return entries, nil
+}
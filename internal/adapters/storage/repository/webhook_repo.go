@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type (
+	WebhookSubscription = model.WebhookSubscription
+	WebhookDelivery     = model.WebhookDelivery
+)
+
+type WebhookRepository struct {
+	subscriptions Storer[model.WebhookSubscriptionID, WebhookSubscription]
+	deliveries    Storer[uuid.UUID, WebhookDelivery]
+}
+
+func NewWebhookRepository(
+	subscriptions Storer[model.WebhookSubscriptionID, WebhookSubscription],
+	deliveries Storer[uuid.UUID, WebhookDelivery],
+) *WebhookRepository {
+	return &WebhookRepository{subscriptions: subscriptions, deliveries: deliveries}
+}
+
+func (r *WebhookRepository) StoreSubscription(ctx context.Context, subscription WebhookSubscription) error {
+	return r.subscriptions.Create(ctx, subscription.ID, subscription)
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id model.WebhookSubscriptionID) (WebhookSubscription, error) {
+	return r.subscriptions.Read(ctx, id)
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id model.WebhookSubscriptionID) error {
+	return r.subscriptions.Delete(ctx, id)
+}
+
+func (r *WebhookRepository) GetListSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+	return r.subscriptions.List(ctx)
+}
+
+func (r *WebhookRepository) StoreDelivery(ctx context.Context, delivery WebhookDelivery) error {
+	return r.deliveries.Create(ctx, delivery.ID, delivery)
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (WebhookDelivery, error) {
+	return r.deliveries.Read(ctx, id)
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery WebhookDelivery) error {
+	return r.deliveries.Update(ctx, delivery.ID, delivery)
+}
+
+// GetListDeliveries returns deliveries in order of creation, uuid.Nil subscription - of all subscriptions.
+func (r *WebhookRepository) GetListDeliveries(ctx context.Context, subscriptionID model.WebhookSubscriptionID) ([]WebhookDelivery, error) {
+	all, err := r.deliveries.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]WebhookDelivery, 0, len(all))
+	for _, delivery := range all {
+		if subscriptionID == uuid.Nil || delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	slices.SortFunc(deliveries, func(a, b WebhookDelivery) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), slices.Compare(a.ID[:], b.ID[:]))
+	})
+
+	return deliveries, nil
+}
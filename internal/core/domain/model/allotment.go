@@ -0,0 +1,139 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+var (
+	ErrAllotmentInvalid   = errors.New("invalid allotment")
+	ErrAllotmentExhausted = errors.New("no rooms left in allotment")
+	ErrBlockCodeMismatch  = errors.New("block code can not be used for order")
+)
+
+type AllotmentStatus string
+
+const (
+	AllotmentActive   AllotmentStatus = "active"
+	AllotmentRejected AllotmentStatus = "rejected" // not enough quota to hold the block
+	AllotmentReleased AllotmentStatus = "released" // unused rooms are returned to general sale
+)
+
+// AllotmentNight - rooms of the block for one night.
+type AllotmentNight struct {
+	Date time.Time `json:"date"`
+	Held int       `json:"held"` // rooms left in block, removed from general quota
+	Sold int       `json:"sold"` // rooms booked with block code
+}
+
+// Allotment - block of rooms held for partner, e.g. event organiser or travel agent.
+// Rooms of the block are removed from general sale, bookings with block code draw from the block and
+// at release date unused rooms are returned to general quota.
+type Allotment struct {
+	Code       string    `json:"code"` // block code used by bookings
+	Partner    string    `json:"partner"`
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`    // exclusive, like check-out date
+	Rooms      int       `json:"rooms"` // rooms held for every night
+	ReleaseAt  time.Time `json:"release_at"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Status AllotmentStatus  `json:"status"`
+	Reason string           `json:"reason,omitempty"` // why allotment is rejected
+	Nights []AllotmentNight `json:"nights,omitempty"`
+}
+
+func NormalizeBlockCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate - ErrAllotmentInvalid if allotment can not be held at now, its release date must be in the future
+// and before the first night of the block.
+func (a Allotment) Validate(now time.Time) error {
+	if a.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrAllotmentInvalid)
+	}
+
+	if a.HotelID <= 0 || a.RoomTypeID <= 0 {
+		return fmt.Errorf("%w: hotel and room type IDs must be positive integers", ErrAllotmentInvalid)
+	}
+
+	if !a.From.Before(a.To) {
+		return fmt.Errorf("%w: from date must be before to date", ErrAllotmentInvalid)
+	}
+
+	if a.Rooms <= 0 {
+		return fmt.Errorf("%w: rooms must be positive integer", ErrAllotmentInvalid)
+	}
+
+	if a.ReleaseAt.IsZero() {
+		return fmt.Errorf("%w: release date is required", ErrAllotmentInvalid)
+	}
+
+	if !a.ReleaseAt.After(now) {
+		return fmt.Errorf("%w: release date must be in the future", ErrAllotmentInvalid)
+	}
+
+	if !a.ReleaseAt.Before(a.From) {
+		return fmt.Errorf("%w: release date must be before from date", ErrAllotmentInvalid)
+	}
+
+	return nil
+}
+
+// Accepts - ErrBlockCodeMismatch if order can not be booked from the block.
+func (a Allotment) Accepts(order Order) error {
+	switch {
+	case a.Status != AllotmentActive:
+		return fmt.Errorf("%w: allotment %s is %s", ErrBlockCodeMismatch, a.Code, a.Status)
+	case a.HotelID != order.HotelID || a.RoomTypeID != order.RoomTypeID:
+		return fmt.Errorf("%w: allotment %s is for another hotel or room type", ErrBlockCodeMismatch, a.Code)
+	case order.From.Before(util.ToDay(a.From)) || order.To.After(util.ToDay(a.To)):
+		return fmt.Errorf("%w: stay is out of allotment %s period", ErrBlockCodeMismatch, a.Code)
+	}
+
+	return nil
+}
+
+// Draw returns allotment with one room of every night [from, to) sold, ErrAllotmentExhausted if some night has no rooms.
+func (a Allotment) Draw(from, to time.Time) (Allotment, error) {
+	drawn := a.copyNights()
+	for i, night := range drawn.Nights {
+		if !util.IsNightBetween(night.Date, from, to) {
+			continue
+		}
+
+		if night.Held <= 0 {
+			return a, fmt.Errorf("%w: %s on %s", ErrAllotmentExhausted, a.Code, night.Date.Format(time.DateOnly))
+		}
+
+		drawn.Nights[i].Held--
+		drawn.Nights[i].Sold++
+	}
+
+	return drawn, nil
+}
+
+// Return returns allotment with one room of every night [from, to) given back to the block.
+func (a Allotment) Return(from, to time.Time) Allotment {
+	returned := a.copyNights()
+	for i, night := range returned.Nights {
+		if util.IsNightBetween(night.Date, from, to) && night.Sold > 0 {
+			returned.Nights[i].Held++
+			returned.Nights[i].Sold--
+		}
+	}
+
+	return returned
+}
+
+func (a Allotment) copyNights() Allotment {
+	a.Nights = append([]AllotmentNight(nil), a.Nights...)
+	return a
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+func TestAllotment_Validate(t *testing.T) {
+	valid := Allotment{
+		Code: "EXPO", HotelID: 1, RoomTypeID: 1, Rooms: 20,
+		From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 4), ReleaseAt: util.NewDay(2024, 3, 1),
+	}
+	now := util.NewDay(2024, 2, 1)
+	assert.NoError(t, valid.Validate(now))
+
+	for name, modify := range map[string]func(*Allotment){
+		"no code":              func(a *Allotment) { a.Code = "" },
+		"no hotel":             func(a *Allotment) { a.HotelID = 0 },
+		"empty period":         func(a *Allotment) { a.To = a.From },
+		"no rooms":             func(a *Allotment) { a.Rooms = 0 },
+		"no release at":        func(a *Allotment) { a.ReleaseAt = util.Day{} },
+		"release in the past":  func(a *Allotment) { a.ReleaseAt = now.Add(-time.Hour) },
+		"release at from date": func(a *Allotment) { a.ReleaseAt = a.From },
+	} {
+		invalid := valid
+		modify(&invalid)
+		assert.ErrorIs(t, invalid.Validate(now), ErrAllotmentInvalid, name)
+	}
+}
+
+func TestAllotment_DrawAndReturn(t *testing.T) {
+	allotment := Allotment{
+		Code: "EXPO", HotelID: 1, RoomTypeID: 1, Status: AllotmentActive,
+		From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 3),
+		Nights: []AllotmentNight{
+			{Date: util.NewDay(2024, 4, 1), Held: 1},
+			{Date: util.NewDay(2024, 4, 2), Held: 2},
+		},
+	}
+
+	order := Order{HotelID: 1, RoomTypeID: 1, From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 3)}
+	assert.NoError(t, allotment.Accepts(order))
+	assert.ErrorIs(t, allotment.Accepts(Order{HotelID: 1, RoomTypeID: 2, From: order.From, To: order.To}), ErrBlockCodeMismatch)
+	assert.ErrorIs(t, allotment.Accepts(Order{HotelID: 1, RoomTypeID: 1, From: order.From, To: util.NewDay(2024, 4, 4)}), ErrBlockCodeMismatch)
+
+	drawn, err := allotment.Draw(order.From, order.To)
+	require.NoError(t, err)
+	assert.Equal(t, []AllotmentNight{
+		{Date: util.NewDay(2024, 4, 1), Held: 0, Sold: 1},
+		{Date: util.NewDay(2024, 4, 2), Held: 1, Sold: 1},
+	}, drawn.Nights)
+	assert.Equal(t, 1, allotment.Nights[0].Held, "original allotment is not changed")
+
+	_, err = drawn.Draw(order.From, order.To)
+	assert.ErrorIs(t, err, ErrAllotmentExhausted)
+
+	returned := drawn.Return(util.NewDay(2024, 4, 2), order.To)
+	assert.Equal(t, []AllotmentNight{
+		{Date: util.NewDay(2024, 4, 1), Held: 0, Sold: 1},
+		{Date: util.NewDay(2024, 4, 2), Held: 2, Sold: 0},
+	}, returned.Nights)
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type AlternativeKind string
+
+const (
+	AlternativeRoomType AlternativeKind = "room_type" // other room type of the same hotel
+	AlternativeDates    AlternativeKind = "dates"     // same room type, stay shifted by few days
+	AlternativeHotel    AlternativeKind = "hotel"     // nearby hotel for the same dates
+)
+
+// Alternative - available stay offered instead of order which ended without rooms.
+// It has all fields needed to submit a new order.
+type Alternative struct {
+	Kind       AlternativeKind `json:"kind"`
+	HotelID    int             `json:"hotel_id"`
+	RoomTypeID int             `json:"room_type_id"`
+	From       time.Time       `json:"from"`  // hotel local check-in date
+	To         time.Time       `json:"to"`    // hotel local check-out date
+	Price      Price           `json:"price"` // best available rate, promo code and points are not applied
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrAvailabilityQueryInvalid = errors.New("invalid availability query")
+
+// AvailabilityQuery - search of rooms for nights [From, To) in hotel local dates.
+type AvailabilityQuery struct {
+	HotelID int       `json:"hotel_id"` // 0 - all hotels
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Rooms   int       `json:"rooms"` // rooms needed for every night
+}
+
+func (q AvailabilityQuery) Validate() error {
+	if q.HotelID < 0 {
+		return fmt.Errorf("%w: hotel ID must be positive integer", ErrAvailabilityQueryInvalid)
+	}
+
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from date must be before to date", ErrAvailabilityQueryInvalid)
+	}
+
+	if q.Rooms <= 0 {
+		return fmt.Errorf("%w: rooms must be positive integer", ErrAvailabilityQueryInvalid)
+	}
+
+	return nil
+}
+
+// AvailabilityOffer - hotel room type which has enough quota on every night of searched stay.
+type AvailabilityOffer struct {
+	HotelID    int `json:"hotel_id"`
+	RoomTypeID int `json:"room_type_id"`
+	Available  int `json:"available"` // rooms left on the most sold night
+
+	Price Price `json:"price"` // best available rate of one room for the stay
+
+	Bookable    bool   `json:"bookable"`              // false if stay violates restrictions
+	Restriction string `json:"restriction,omitempty"` // violated restriction of the stay
+}
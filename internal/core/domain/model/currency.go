@@ -0,0 +1,183 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCurrencyInvalid = errors.New("invalid currency")
+	ErrAmountInvalid   = errors.New("invalid amount")
+	ErrFXRateInvalid   = errors.New("invalid FX rate")
+	ErrUnknownFXRate   = errors.New("no FX rate for currency pair")
+)
+
+// currencyExponents - number of minor unit digits of ISO 4217 currencies which differ from 2.
+var currencyExponents = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// CurrencyExponent returns number of minor unit digits of currency, e.g. 2 for EUR cents.
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+
+	return 2
+}
+
+// ValidateCurrency checks that code looks like ISO 4217 code: three upper-case latin letters.
+func ValidateCurrency(code string) error {
+	if len(code) != 3 || strings.ToUpper(code) != code || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: %q must be ISO 4217 code, e.g. EUR", ErrCurrencyInvalid, code)
+	}
+
+	return nil
+}
+
+// String formats money in major units of its currency, e.g. "12.50 EUR".
+func (m Money) String() string {
+	exp := CurrencyExponent(m.Currency)
+
+	return strings.TrimSpace(new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(exp)).FloatString(exp) + " " + m.Currency)
+}
+
+// ParseMoney parses decimal amount in major units of currency, e.g. "12.50" EUR. Amount with more fractional digits
+// than currency has is invalid, it is never rounded.
+func ParseMoney(amount, currency string) (Money, error) {
+	if err := ValidateCurrency(currency); err != nil {
+		return Money{}, err
+	}
+
+	v, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok || strings.ContainsAny(amount, "/eE") {
+		return Money{}, fmt.Errorf("%w: %q must be decimal", ErrAmountInvalid, amount)
+	}
+
+	v.Mul(v, new(big.Rat).SetInt(pow10(CurrencyExponent(currency))))
+	if !v.IsInt() || !v.Num().IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q is not whole number of minor units of %s", ErrAmountInvalid, amount, currency)
+	}
+
+	return Money{Amount: v.Num().Int64(), Currency: currency}, nil
+}
+
+// FXRate - exchange rate of currency pair which is used from EffectiveFrom until the next rate of the pair.
+type FXRate struct {
+	Base          string    `json:"base"`  // e.g. EUR
+	Quote         string    `json:"quote"` // e.g. USD
+	Rate          string    `json:"rate"`  // exact decimal or fraction: units of quote currency for one unit of base one
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+func (r FXRate) Validate() error {
+	if err := ValidateCurrency(r.Base); err != nil {
+		return fmt.Errorf("%w: %w", ErrFXRateInvalid, err)
+	}
+
+	if err := ValidateCurrency(r.Quote); err != nil {
+		return fmt.Errorf("%w: %w", ErrFXRateInvalid, err)
+	}
+
+	if r.Base == r.Quote {
+		return fmt.Errorf("%w: base and quote currencies are the same", ErrFXRateInvalid)
+	}
+
+	if _, err := r.Ratio(); err != nil {
+		return err
+	}
+
+	if r.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: effective date is required", ErrFXRateInvalid)
+	}
+
+	return nil
+}
+
+// Ratio returns exact positive rate. Floats are never used for money.
+func (r FXRate) Ratio() (*big.Rat, error) {
+	ratio, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || ratio.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: rate %q must be positive decimal", ErrFXRateInvalid, r.Rate)
+	}
+
+	return ratio, nil
+}
+
+// Inverse returns rate of reversed pair.
+func (r FXRate) Inverse() (FXRate, error) {
+	ratio, err := r.Ratio()
+	if err != nil {
+		return FXRate{}, err
+	}
+
+	return FXRate{Base: r.Quote, Quote: r.Base, Rate: ratio.Inv(ratio).RatString(), EffectiveFrom: r.EffectiveFrom}, nil
+}
+
+// Convert converts money in base currency into quote currency at time at.
+func (r FXRate) Convert(m Money, at time.Time) (CurrencyConversion, error) {
+	if m.Currency != r.Base {
+		return CurrencyConversion{}, fmt.Errorf("%w: rate is for %s, money is in %s", ErrCurrencyMismatch, r.Base, m.Currency)
+	}
+
+	ratio, err := r.Ratio()
+	if err != nil {
+		return CurrencyConversion{}, err
+	}
+
+	return CurrencyConversion{
+		From:          m,
+		To:            convert(m, r.Quote, ratio),
+		Rate:          r.Rate,
+		EffectiveFrom: r.EffectiveFrom,
+		ConvertedAt:   at,
+	}, nil
+}
+
+// CurrencyConversion - money converted into another currency, rate is kept to convert related amounts the same way.
+type CurrencyConversion struct {
+	From          Money     `json:"from"`
+	To            Money     `json:"to"`
+	Rate          string    `json:"rate"`
+	EffectiveFrom time.Time `json:"effective_from"` // of used FX rate
+	ConvertedAt   time.Time `json:"converted_at"`
+}
+
+// Apply converts other amount in the same currency by rate of the conversion.
+func (c CurrencyConversion) Apply(m Money) (CurrencyConversion, error) {
+	rate := FXRate{Base: c.From.Currency, Quote: c.To.Currency, Rate: c.Rate, EffectiveFrom: c.EffectiveFrom}
+
+	return rate.Convert(m, c.ConvertedAt)
+}
+
+// convert multiplies amount by ratio and scales minor units between currencies, rounded half away from zero.
+func convert(m Money, currency string, ratio *big.Rat) Money {
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, ratio)
+	v.Mul(v, new(big.Rat).SetFrac(pow10(CurrencyExponent(currency)), pow10(CurrencyExponent(m.Currency))))
+
+	return Money{Amount: roundHalfAwayFromZero(v), Currency: currency}
+}
+
+func roundHalfAwayFromZero(v *big.Rat) int64 {
+	num, den := new(big.Int).Abs(v.Num()), v.Denom()
+
+	// (2*num + den) / (2*den) is round half up of positive value
+	q := new(big.Int).Mul(num, big.NewInt(2))
+	q.Add(q, den)
+	q.Quo(q, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if v.Sign() < 0 {
+		q.Neg(q)
+	}
+
+	return q.Int64()
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
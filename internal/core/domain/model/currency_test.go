@@ -0,0 +1,168 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCurrency(t *testing.T) {
+	for _, code := range []string{"EUR", "USD", "JPY"} {
+		assert.NoError(t, ValidateCurrency(code), code)
+	}
+
+	for _, code := range []string{"", "EU", "EURO", "eur", "E1R"} {
+		assert.ErrorIs(t, ValidateCurrency(code), ErrCurrencyInvalid, code)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "12.50 EUR", Money{Amount: 12_50, Currency: "EUR"}.String())
+	assert.Equal(t, "-0.05 USD", Money{Amount: -5, Currency: "USD"}.String())
+	assert.Equal(t, "1250 JPY", Money{Amount: 1250, Currency: "JPY"}.String())
+	assert.Equal(t, "1.250 KWD", Money{Amount: 1250, Currency: "KWD"}.String())
+}
+
+func TestParseMoney(t *testing.T) {
+	for _, tt := range []struct {
+		amount, currency string
+		expected         Money
+	}{
+		{"12.50", "EUR", Money{Amount: 12_50, Currency: "EUR"}},
+		{"12.5", "EUR", Money{Amount: 12_50, Currency: "EUR"}},
+		{"-0.05", "USD", Money{Amount: -5, Currency: "USD"}},
+		{"1250", "JPY", Money{Amount: 1250, Currency: "JPY"}},
+		{"1.250", "KWD", Money{Amount: 1250, Currency: "KWD"}},
+	} {
+		money, err := ParseMoney(tt.amount, tt.currency)
+		require.NoError(t, err, tt.amount)
+		assert.Equal(t, tt.expected, money)
+	}
+
+	for _, amount := range []string{"", "ten", "12.505", "1/2", "1e2", "12,50"} {
+		_, err := ParseMoney(amount, "EUR")
+		assert.ErrorIs(t, err, ErrAmountInvalid, amount)
+	}
+
+	_, err := ParseMoney("12.5", "JPY")
+	assert.ErrorIs(t, err, ErrAmountInvalid)
+
+	_, err = ParseMoney("12.50", "euro")
+	assert.ErrorIs(t, err, ErrCurrencyInvalid)
+}
+
+func TestFXRate_Validate(t *testing.T) {
+	valid := FXRate{Base: "EUR", Quote: "USD", Rate: "1.0825", EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name string
+		rate func(r FXRate) FXRate
+	}{
+		{name: "bad base", rate: func(r FXRate) FXRate { r.Base = "euro"; return r }},
+		{name: "bad quote", rate: func(r FXRate) FXRate { r.Quote = ""; return r }},
+		{name: "same currencies", rate: func(r FXRate) FXRate { r.Quote = r.Base; return r }},
+		{name: "not a number", rate: func(r FXRate) FXRate { r.Rate = "one"; return r }},
+		{name: "zero rate", rate: func(r FXRate) FXRate { r.Rate = "0"; return r }},
+		{name: "negative rate", rate: func(r FXRate) FXRate { r.Rate = "-1.08"; return r }},
+		{name: "no effective date", rate: func(r FXRate) FXRate { r.EffectiveFrom = time.Time{}; return r }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.rate(valid).Validate(), ErrFXRateInvalid)
+		})
+	}
+}
+
+func TestFXRate_Convert(t *testing.T) {
+	effectiveFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rate     FXRate
+		money    Money
+		expected Money
+	}{
+		{
+			name:     "Rounded to cents",
+			rate:     FXRate{Base: "EUR", Quote: "USD", Rate: "1.0825"},
+			money:    Money{Amount: 123_45, Currency: "EUR"},
+			expected: Money{Amount: 133_63, Currency: "USD"}, // 133.634625
+		},
+		{
+			name:     "Half is rounded away from zero",
+			rate:     FXRate{Base: "EUR", Quote: "USD", Rate: "1.5"},
+			money:    Money{Amount: 1, Currency: "EUR"},
+			expected: Money{Amount: 2, Currency: "USD"},
+		},
+		{
+			name:     "Negative half is rounded away from zero",
+			rate:     FXRate{Base: "EUR", Quote: "USD", Rate: "1.5"},
+			money:    Money{Amount: -1, Currency: "EUR"},
+			expected: Money{Amount: -2, Currency: "USD"},
+		},
+		{
+			name:     "Into currency without minor units",
+			rate:     FXRate{Base: "EUR", Quote: "JPY", Rate: "162.35"},
+			money:    Money{Amount: 100_50, Currency: "EUR"},
+			expected: Money{Amount: 16316, Currency: "JPY"}, // 16316.175
+		},
+		{
+			name:     "From currency without minor units",
+			rate:     FXRate{Base: "JPY", Quote: "EUR", Rate: "20/3247"}, // 1/162.35
+			money:    Money{Amount: 16316, Currency: "JPY"},
+			expected: Money{Amount: 100_50, Currency: "EUR"},
+		},
+		{
+			name:     "Exact decimal, no float error",
+			rate:     FXRate{Base: "EUR", Quote: "USD", Rate: "0.1"},
+			money:    Money{Amount: 3_00, Currency: "EUR"},
+			expected: Money{Amount: 30, Currency: "USD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rate.EffectiveFrom = effectiveFrom
+
+			conversion, err := tt.rate.Convert(tt.money, at)
+			require.NoError(t, err)
+			assert.Equal(t, CurrencyConversion{
+				From: tt.money, To: tt.expected, Rate: tt.rate.Rate, EffectiveFrom: effectiveFrom, ConvertedAt: at,
+			}, conversion)
+		})
+	}
+
+	_, err := FXRate{Base: "EUR", Quote: "USD", Rate: "1.08"}.Convert(Money{Amount: 1, Currency: "GBP"}, at)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestFXRate_Inverse(t *testing.T) {
+	rate := FXRate{Base: "EUR", Quote: "USD", Rate: "1.25", EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	inverse, err := rate.Inverse()
+	require.NoError(t, err)
+	assert.Equal(t, FXRate{Base: "USD", Quote: "EUR", Rate: "4/5", EffectiveFrom: rate.EffectiveFrom}, inverse)
+
+	conversion, err := inverse.Convert(Money{Amount: 10_00, Currency: "USD"}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: 8_00, Currency: "EUR"}, conversion.To)
+}
+
+func TestCurrencyConversion_Apply(t *testing.T) {
+	rate := FXRate{Base: "EUR", Quote: "USD", Rate: "1.0825", EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	at := time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)
+
+	total, err := rate.Convert(Money{Amount: 200_00, Currency: "EUR"}, at)
+	require.NoError(t, err)
+
+	deposit, err := total.Apply(Money{Amount: 60_00, Currency: "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: 64_95, Currency: "USD"}, deposit.To)
+	assert.Equal(t, total.Rate, deposit.Rate)
+	assert.Equal(t, at, deposit.ConvertedAt, "conversion time of booking is kept")
+}
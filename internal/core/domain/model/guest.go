@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrGuestInvalid      = errors.New("invalid guest")
+	ErrOccupancyInvalid  = errors.New("invalid occupancy")
+	ErrOccupancyExceeded = errors.New("occupancy exceeds room type capacity")
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9 ()-]{5,20}$`)
+
+type Guest struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone,omitempty"`
+}
+
+func (g Guest) Validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrGuestInvalid)
+	}
+
+	if g.Phone != "" && !phonePattern.MatchString(g.Phone) {
+		return fmt.Errorf("%w: phone %q is not valid", ErrGuestInvalid, g.Phone)
+	}
+
+	return nil
+}
+
+// Occupancy - guests staying in the room, lead guest is one of adults.
+type Occupancy struct {
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+}
+
+// Guests returns total number of guests.
+func (o Occupancy) Guests() int {
+	return o.Adults + o.Children
+}
+
+func (o Occupancy) Validate() error {
+	if o.Adults < 1 {
+		return fmt.Errorf("%w: at least one adult is required", ErrOccupancyInvalid)
+	}
+
+	if o.Children < 0 {
+		return fmt.Errorf("%w: children must not be negative", ErrOccupancyInvalid)
+	}
+
+	return nil
+}
+
+// ValidateGuests checks lead guest, occupancy and additional guests of order.
+// Additional guests are optional, but there can not be more of them than occupancy allows.
+func (o Order) ValidateGuests() error {
+	if err := o.Guest.Validate(); err != nil {
+		return err
+	}
+
+	if err := o.Occupancy.Validate(); err != nil {
+		return err
+	}
+
+	if len(o.AdditionalGuests) > o.Occupancy.Guests()-1 {
+		return fmt.Errorf("%w: %d additional guests for occupancy of %d", ErrOccupancyInvalid, len(o.AdditionalGuests), o.Occupancy.Guests())
+	}
+
+	for _, guest := range o.AdditionalGuests {
+		if strings.TrimSpace(guest.Name) == "" {
+			return fmt.Errorf("%w: name of additional guest is required", ErrGuestInvalid)
+		}
+	}
+
+	return nil
+}
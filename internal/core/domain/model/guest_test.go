@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrder_ValidateGuests(t *testing.T) {
+	order := Order{
+		Guest:            Guest{Name: "Arseny", Phone: "+7 (900) 123-45-67"},
+		Occupancy:        Occupancy{Adults: 2, Children: 1},
+		AdditionalGuests: []Guest{{Name: "Anna"}, {Name: "Mark"}},
+	}
+	assert.NoError(t, order.ValidateGuests())
+
+	tests := map[string]struct {
+		modify func(*Order)
+		err    error
+	}{
+		"no lead guest name":     {func(o *Order) { o.Guest.Name = " " }, ErrGuestInvalid},
+		"invalid phone":          {func(o *Order) { o.Guest.Phone = "call me" }, ErrGuestInvalid},
+		"no adults":              {func(o *Order) { o.Occupancy.Adults = 0 }, ErrOccupancyInvalid},
+		"negative children":      {func(o *Order) { o.Occupancy.Children = -1 }, ErrOccupancyInvalid},
+		"too many guests":        {func(o *Order) { o.Occupancy.Children = 0 }, ErrOccupancyInvalid},
+		"unnamed additional one": {func(o *Order) { o.AdditionalGuests[1].Name = "" }, ErrGuestInvalid},
+	}
+
+	for name, tt := range tests {
+		invalid := order
+		invalid.AdditionalGuests = append([]Guest(nil), order.AdditionalGuests...)
+		tt.modify(&invalid)
+
+		assert.ErrorIs(t, invalid.ValidateGuests(), tt.err, name)
+	}
+}
+
+func TestRoomType_CheckOccupancy(t *testing.T) {
+	double := RoomType{ID: 1, HotelID: 1, Name: "Standard", Capacity: 2}
+
+	assert.NoError(t, double.CheckOccupancy(Occupancy{Adults: 1, Children: 1}))
+	assert.ErrorIs(t, double.CheckOccupancy(Occupancy{Adults: 2, Children: 1}), ErrOccupancyExceeded)
+}
@@ -0,0 +1,141 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+var (
+	ErrHotelInvalid = errors.New("invalid hotel")
+	ErrUnknownHotel = errors.New("unknown hotel")
+	ErrHotelInUse   = errors.New("hotel has room types")
+)
+
+const hotelTimeLayout = "15:04"
+
+type HotelID = int
+
+// Hotel - hotel settings which define stay dates. Stay occupies nights [check-in date, check-out date)
+// in hotel local time.
+type Hotel struct {
+	ID           HotelID   `json:"id"`
+	Name         string    `json:"name"`
+	Address      string    `json:"address"`
+	Amenities    []string  `json:"amenities,omitempty"`
+	Geo          *GeoPoint `json:"geo,omitempty"`  // used to find nearby hotels
+	Timezone     string    `json:"timezone"`       // IANA timezone, e.g. Europe/Berlin
+	CheckInTime  string    `json:"check_in_time"`  // local time, e.g. 15:00
+	CheckOutTime string    `json:"check_out_time"` // local time, e.g. 11:00
+	Currency     string    `json:"currency"`       // ISO 4217 code of hotel rates, e.g. EUR
+
+	TaxPercent int    `json:"tax_percent,omitempty"` // VAT included in rates, shown on invoices
+	TaxID      string `json:"tax_id,omitempty"`      // VAT number of hotel as seller
+}
+
+// GeoPoint - coordinates in degrees.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+const earthRadiusKm = 6371
+
+// DistanceKm returns great-circle distance between points by haversine formula.
+func (p GeoPoint) DistanceKm(to GeoPoint) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat, dLon := rad(to.Lat-p.Lat), rad(to.Lon-p.Lon)
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(rad(p.Lat))*math.Cos(rad(to.Lat))*math.Pow(math.Sin(dLon/2), 2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func (h Hotel) Validate() error {
+	if h.ID <= 0 {
+		return fmt.Errorf("%w: ID must be positive integer", ErrHotelInvalid)
+	}
+
+	if strings.TrimSpace(h.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrHotelInvalid)
+	}
+
+	if h.Geo != nil && (math.Abs(h.Geo.Lat) > 90 || math.Abs(h.Geo.Lon) > 180) {
+		return fmt.Errorf("%w: coordinates are out of range", ErrHotelInvalid)
+	}
+
+	if err := ValidateCurrency(h.Currency); err != nil {
+		return fmt.Errorf("%w: %w", ErrHotelInvalid, err)
+	}
+
+	if h.TaxPercent < 0 || h.TaxPercent > 100 {
+		return fmt.Errorf("%w: tax percent must be between 0 and 100", ErrHotelInvalid)
+	}
+
+	if _, err := h.Location(); err != nil {
+		return fmt.Errorf("%w: %v", ErrHotelInvalid, err)
+	}
+
+	for _, clock := range []string{h.CheckInTime, h.CheckOutTime} {
+		if _, err := time.Parse(hotelTimeLayout, clock); err != nil {
+			return fmt.Errorf("%w: time %q must be in HH:MM format", ErrHotelInvalid, clock)
+		}
+	}
+
+	return nil
+}
+
+func (h Hotel) Location() (*time.Location, error) {
+	return time.LoadLocation(h.Timezone)
+}
+
+// LocalDay returns hotel local date of timestamp.
+func (h Hotel) LocalDay(timestamp time.Time) (util.Day, error) {
+	loc, err := h.Location()
+	if err != nil {
+		return util.Day{}, err
+	}
+
+	return util.ToLocalDay(timestamp, loc), nil
+}
+
+// StayDates converts requested arrival and departure timestamps to hotel local check-in and check-out dates.
+func (h Hotel) StayDates(from, to time.Time) (checkIn, checkOut util.Day, err error) {
+	if checkIn, err = h.LocalDay(from); err != nil {
+		return util.Day{}, util.Day{}, err
+	}
+
+	if checkOut, err = h.LocalDay(to); err != nil {
+		return util.Day{}, util.Day{}, err
+	}
+
+	return checkIn, checkOut, nil
+}
+
+// CheckInAt returns moment of check-in on hotel local date.
+func (h Hotel) CheckInAt(day util.Day) (time.Time, error) {
+	return h.at(day, h.CheckInTime)
+}
+
+// CheckOutAt returns moment of check-out on hotel local date.
+func (h Hotel) CheckOutAt(day util.Day) (time.Time, error) {
+	return h.at(day, h.CheckOutTime)
+}
+
+func (h Hotel) at(day util.Day, clock string) (time.Time, error) {
+	loc, err := h.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := time.Parse(hotelTimeLayout, clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+func TestHotel_Validate(t *testing.T) {
+	valid := Hotel{ID: 1, Name: "Hotel Berlin", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00", Currency: "EUR"}
+	assert.NoError(t, valid.Validate())
+
+	for name, hotel := range map[string]Hotel{
+		"no id":            {Name: "Hotel Berlin", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00"},
+		"no name":          {ID: 1, Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00"},
+		"unknown timezone": {ID: 1, Name: "Hotel Berlin", Timezone: "Mars/Olympus", CheckInTime: "15:00", CheckOutTime: "11:00"},
+		"bad check-in":     {ID: 1, Name: "Hotel Berlin", Timezone: "Europe/Berlin", CheckInTime: "3pm", CheckOutTime: "11:00"},
+		"bad coordinates":  {ID: 1, Name: "Hotel Berlin", Geo: &GeoPoint{Lat: 152.5}, Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00"},
+		"no currency":      {ID: 1, Name: "Hotel Berlin", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00"},
+		"bad currency":     {ID: 1, Name: "Hotel Berlin", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00", Currency: "euro"},
+		"bad tax percent":  {ID: 1, Name: "Hotel Berlin", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00", Currency: "EUR", TaxPercent: 101},
+	} {
+		assert.ErrorIs(t, hotel.Validate(), ErrHotelInvalid, name)
+	}
+}
+
+func TestHotel_StayDates(t *testing.T) {
+	hotel := Hotel{ID: 1, Timezone: "America/New_York", CheckInTime: "15:00", CheckOutTime: "11:00"}
+
+	// late evening arrival in New York is already next day in UTC
+	from := time.Date(2024, 4, 2, 1, 30, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 4, 12, 0, 0, 0, time.UTC)
+
+	checkIn, checkOut, err := hotel.StayDates(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, util.NewDay(2024, 4, 1), checkIn)
+	assert.Equal(t, util.NewDay(2024, 4, 4), checkOut)
+
+	checkInAt, err := hotel.CheckInAt(checkIn)
+	require.NoError(t, err)
+	assert.True(t, checkInAt.Equal(time.Date(2024, 4, 1, 19, 0, 0, 0, time.UTC)))
+
+	checkOutAt, err := hotel.CheckOutAt(checkOut)
+	require.NoError(t, err)
+	assert.True(t, checkOutAt.Equal(time.Date(2024, 4, 4, 15, 0, 0, 0, time.UTC)))
+}
+
+func TestGeoPoint_DistanceKm(t *testing.T) {
+	berlin := GeoPoint{Lat: 52.5200, Lon: 13.4050}
+	london := GeoPoint{Lat: 51.5074, Lon: -0.1278}
+
+	assert.InDelta(t, 932, berlin.DistanceKm(london), 5)
+	assert.InDelta(t, berlin.DistanceKm(london), london.DistanceKm(berlin), 1e-9)
+	assert.Zero(t, berlin.DistanceKm(berlin))
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+type InvoiceKind string
+
+const (
+	InvoiceDocument InvoiceKind = "invoice"
+	CreditNote      InvoiceKind = "credit_note" // cancels or credits issued invoice, amounts are negative
+)
+
+// Invoice - tax document of paid order. Prices include VAT of the hotel, so net amount and tax are derived from them.
+// Number is sequential per hotel and is assigned when invoice is stored. Invoice of changed order is cancelled by
+// credit note and replaced by new invoice, money returned by refund is credited by credit note.
+type Invoice struct {
+	Number   string      `json:"number"` // e.g. 1-000042
+	Kind     InvoiceKind `json:"kind"`
+	Corrects string      `json:"corrects,omitempty"` // number of invoice corrected by credit note
+	HotelID  HotelID     `json:"hotel_id"`
+	OrderID  OrderID     `json:"order_id"`
+	IssuedAt time.Time   `json:"issued_at"`
+
+	Seller InvoiceParty `json:"seller"`
+	Buyer  InvoiceParty `json:"buyer"`
+
+	From time.Time `json:"from"` // check-in date
+	To   time.Time `json:"to"`   // check-out date
+
+	Lines      []InvoiceLine `json:"lines"`
+	TaxPercent int           `json:"tax_percent"`
+	Net        Money         `json:"net"`
+	Tax        Money         `json:"tax"`
+	Total      Money         `json:"total"` // gross, equals price of the order
+
+	Conversion *CurrencyConversion `json:"conversion,omitempty"` // total in display currency of the order
+}
+
+type InvoiceParty struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Email   string `json:"email,omitempty"`
+	TaxID   string `json:"tax_id,omitempty"`
+}
+
+// InvoiceLine - night of stay or discount. Discounts have negative amounts.
+type InvoiceLine struct {
+	Description string    `json:"description"`
+	Date        time.Time `json:"date,omitempty"` // night of stay
+	Net         Money     `json:"net"`
+	Tax         Money     `json:"tax"`
+	Gross       Money     `json:"gross"`
+}
+
+// NewInvoice builds not numbered invoice of paid order: line per night and per discount of its price.
+func NewInvoice(order Order, hotel Hotel, roomType RoomType, issuedAt time.Time) (Invoice, error) {
+	invoice := Invoice{
+		Kind:     InvoiceDocument,
+		HotelID:  order.HotelID,
+		OrderID:  order.ID,
+		IssuedAt: issuedAt,
+		Seller:   InvoiceParty{Name: hotel.Name, Address: hotel.Address, TaxID: hotel.TaxID},
+		Buyer:    InvoiceParty{Name: order.Guest.Name, Email: order.UserEmail},
+		From:     order.From,
+		To:       order.To,
+
+		TaxPercent: hotel.TaxPercent,
+		Net:        Money{Currency: order.Price.Total.Currency},
+		Tax:        Money{Currency: order.Price.Total.Currency},
+		Total:      Money{Currency: order.Price.Total.Currency},
+		Conversion: order.Conversion,
+	}
+
+	for _, night := range order.Price.Nights {
+		invoice.addLine(fmt.Sprintf("%s, night of %s", roomType.Name, night.Date.Format(time.DateOnly)), night.Date, night.Price)
+	}
+
+	if discount := order.Price.Discount; discount.Amount > 0 {
+		invoice.addLine("Promo code "+order.PromoCode, time.Time{}, Money{Amount: -discount.Amount, Currency: discount.Currency})
+	}
+
+	if discount := order.Price.PointsDiscount; discount.Amount > 0 {
+		invoice.addLine(fmt.Sprintf("Loyalty points (%d)", order.LoyaltyPoints), time.Time{}, Money{Amount: -discount.Amount, Currency: discount.Currency})
+	}
+
+	if err := invoice.sum(); err != nil {
+		return Invoice{}, err
+	}
+
+	if invoice.Total != order.Price.Total {
+		return Invoice{}, fmt.Errorf("invoice total %v differs from price %v of order %v", invoice.Total, order.Price.Total, order.ID)
+	}
+
+	return invoice, nil
+}
+
+// NewCreditNote builds not numbered credit note which cancels the whole invoice.
+func NewCreditNote(invoice Invoice, issuedAt time.Time) Invoice {
+	note := invoice
+	note.Number = ""
+	note.Kind = CreditNote
+	note.Corrects = invoice.Number
+	note.IssuedAt = issuedAt
+	note.Conversion = nil
+
+	note.Lines = make([]InvoiceLine, 0, len(invoice.Lines))
+	for _, line := range invoice.Lines {
+		line.Description = "Cancelled: " + line.Description
+		line.Net.Amount, line.Tax.Amount, line.Gross.Amount = -line.Net.Amount, -line.Tax.Amount, -line.Gross.Amount
+		note.Lines = append(note.Lines, line)
+	}
+
+	note.Net.Amount, note.Tax.Amount, note.Total.Amount = -invoice.Net.Amount, -invoice.Tax.Amount, -invoice.Total.Amount
+
+	return note
+}
+
+// NewRefundCreditNote builds not numbered credit note of money returned to the buyer of invoice.
+func NewRefundCreditNote(invoice Invoice, refunded Money, reason RefundReason, issuedAt time.Time) (Invoice, error) {
+	note := Invoice{
+		Kind:     CreditNote,
+		Corrects: invoice.Number,
+		HotelID:  invoice.HotelID,
+		OrderID:  invoice.OrderID,
+		IssuedAt: issuedAt,
+		Seller:   invoice.Seller,
+		Buyer:    invoice.Buyer,
+		From:     invoice.From,
+		To:       invoice.To,
+
+		TaxPercent: invoice.TaxPercent,
+		Net:        Money{Currency: invoice.Total.Currency},
+		Tax:        Money{Currency: invoice.Total.Currency},
+		Total:      Money{Currency: invoice.Total.Currency},
+	}
+
+	note.addLine(fmt.Sprintf("Refund (%s) of invoice %s", reason, invoice.Number), time.Time{}, Money{Amount: -refunded.Amount, Currency: refunded.Currency})
+
+	return note, note.sum()
+}
+
+// sum - totals of invoice lines, error if a line is in other currency.
+func (i *Invoice) sum() error {
+	for _, line := range i.Lines {
+		if line.Gross.Currency != i.Total.Currency {
+			return fmt.Errorf("%w: line %q is in %s", ErrCurrencyMismatch, line.Description, line.Gross.Currency)
+		}
+
+		i.Net.Amount += line.Net.Amount
+		i.Tax.Amount += line.Tax.Amount
+		i.Total.Amount += line.Gross.Amount
+	}
+
+	return nil
+}
+
+func (i *Invoice) addLine(description string, date time.Time, gross Money) {
+	net := grossToNet(gross, i.TaxPercent)
+
+	i.Lines = append(i.Lines, InvoiceLine{
+		Description: description,
+		Date:        date,
+		Net:         net,
+		Tax:         Money{Amount: gross.Amount - net.Amount, Currency: gross.Currency},
+		Gross:       gross,
+	})
+}
+
+// grossToNet - amount without tax included in gross one, rounded to minor units.
+func grossToNet(gross Money, taxPercent int) Money {
+	net := new(big.Rat).SetFrac64(gross.Amount*100, int64(100+taxPercent))
+
+	return Money{Amount: roundHalfAwayFromZero(net), Currency: gross.Currency}
+}
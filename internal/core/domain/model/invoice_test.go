@@ -0,0 +1,95 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+func TestNewInvoice(t *testing.T) {
+	eur := func(amount int64) Money { return Money{Amount: amount, Currency: "EUR"} }
+	issuedAt := time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	hotel := Hotel{ID: 1, Name: "Spree Riverside", Address: "Friedrichstraße 1, 10117 Berlin", TaxPercent: 7, TaxID: "DE123456789"}
+	order := Order{
+		ID: uuid.New(), HotelID: 1, RoomTypeID: 2, UserEmail: "guest@example.com", Guest: Guest{Name: "Jane Roe"},
+		From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 3), PromoCode: "WELCOME10", LoyaltyPoints: 5,
+		Price: Price{
+			Nights: []NightPrice{
+				{Date: util.NewDay(2024, 4, 1), Price: eur(100_00)},
+				{Date: util.NewDay(2024, 4, 2), Price: eur(120_00)},
+			},
+			Subtotal:       eur(220_00),
+			Discount:       eur(22_00),
+			PointsDiscount: eur(5_00),
+			Total:          eur(193_00),
+		},
+	}
+
+	invoice, err := NewInvoice(order, hotel, RoomType{Name: "Double"}, issuedAt)
+	require.NoError(t, err)
+
+	assert.Empty(t, invoice.Number, "number is assigned by storage")
+	assert.Equal(t, InvoiceParty{Name: hotel.Name, Address: hotel.Address, TaxID: "DE123456789"}, invoice.Seller)
+	assert.Equal(t, InvoiceParty{Name: "Jane Roe", Email: "guest@example.com"}, invoice.Buyer)
+	assert.Equal(t, issuedAt, invoice.IssuedAt)
+
+	assert.Equal(t, []InvoiceLine{
+		{Description: "Double, night of 2024-04-01", Date: util.NewDay(2024, 4, 1), Net: eur(93_46), Tax: eur(6_54), Gross: eur(100_00)},
+		{Description: "Double, night of 2024-04-02", Date: util.NewDay(2024, 4, 2), Net: eur(112_15), Tax: eur(7_85), Gross: eur(120_00)},
+		{Description: "Promo code WELCOME10", Net: eur(-20_56), Tax: eur(-1_44), Gross: eur(-22_00)},
+		{Description: "Loyalty points (5)", Net: eur(-4_67), Tax: eur(-33), Gross: eur(-5_00)},
+	}, invoice.Lines)
+
+	assert.Equal(t, eur(180_38), invoice.Net)
+	assert.Equal(t, eur(12_62), invoice.Tax)
+	assert.Equal(t, order.Price.Total, invoice.Total)
+
+	hotel.TaxPercent = 0
+	invoice, err = NewInvoice(order, hotel, RoomType{Name: "Double"}, issuedAt)
+	require.NoError(t, err)
+	assert.Equal(t, invoice.Total, invoice.Net, "no tax")
+	assert.Equal(t, eur(0), invoice.Tax)
+
+	order.Price.Total = eur(1)
+	_, err = NewInvoice(order, hotel, RoomType{Name: "Double"}, issuedAt)
+	assert.ErrorContains(t, err, "differs from price")
+}
+
+func TestNewCreditNote(t *testing.T) {
+	eur := func(amount int64) Money { return Money{Amount: amount, Currency: "EUR"} }
+	issuedAt := time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	invoice := Invoice{
+		Number: "1-000001", Kind: InvoiceDocument, HotelID: 1, OrderID: uuid.New(), TaxPercent: 7,
+		Lines: []InvoiceLine{{Description: "Double, night of 2024-04-01", Net: eur(93_46), Tax: eur(6_54), Gross: eur(100_00)}},
+		Net:   eur(93_46), Tax: eur(6_54), Total: eur(100_00),
+	}
+
+	note := NewCreditNote(invoice, issuedAt.Add(time.Hour))
+	assert.Empty(t, note.Number, "number is assigned by storage")
+	assert.Equal(t, CreditNote, note.Kind)
+	assert.Equal(t, "1-000001", note.Corrects)
+	assert.Equal(t, []InvoiceLine{
+		{Description: "Cancelled: Double, night of 2024-04-01", Net: eur(-93_46), Tax: eur(-6_54), Gross: eur(-100_00)},
+	}, note.Lines)
+	assert.Equal(t, eur(-100_00), note.Total)
+	assert.Equal(t, eur(100_00), invoice.Lines[0].Gross, "lines of invoice are kept")
+
+	refund, err := NewRefundCreditNote(invoice, eur(50_00), CancellationRefund, issuedAt)
+	require.NoError(t, err)
+	assert.Equal(t, CreditNote, refund.Kind)
+	assert.Equal(t, "1-000001", refund.Corrects)
+	assert.Equal(t, []InvoiceLine{
+		{Description: "Refund (cancellation) of invoice 1-000001", Net: eur(-46_73), Tax: eur(-3_27), Gross: eur(-50_00)},
+	}, refund.Lines)
+	assert.Equal(t, eur(-50_00), refund.Total)
+
+	_, err = NewRefundCreditNote(invoice, Money{Amount: 50_00, Currency: "USD"}, CancellationRefund, issuedAt)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInsufficientPoints = errors.New("insufficient loyalty points")
+
+type LoyaltyTier string
+
+const (
+	BasicTier  LoyaltyTier = "basic"
+	SilverTier LoyaltyTier = "silver"
+	GoldTier   LoyaltyTier = "gold"
+)
+
+// tiers - lifetime earned points needed for tier and percent of points earned on top of base, from the highest tier.
+var tiers = [...]struct {
+	tier         LoyaltyTier
+	lifetime     int64
+	bonusPercent int
+}{
+	{GoldTier, 5_000, 50},
+	{SilverTier, 1_000, 25},
+	{BasicTier, 0, 0},
+}
+
+// LoyaltyCurrency - currency of loyalty program: points are earned for amounts in it and valued in it, so a point
+// is worth the same in every hotel. Amounts in other currencies are converted by FX rates.
+const LoyaltyCurrency = "EUR"
+
+// PointValue - value of one loyalty point in minor units of LoyaltyCurrency.
+const PointValue = 1
+
+type LoyaltyEntryKind string
+
+const (
+	EarnEntry    LoyaltyEntryKind = "earn"    // points earned by paid order
+	RedeemEntry  LoyaltyEntryKind = "redeem"  // points spent as discount of order
+	ReverseEntry LoyaltyEntryKind = "reverse" // earned or redeemed points returned back by cancellation or refund
+)
+
+// LoyaltyAccount - loyalty program account of a guest, identified by email.
+type LoyaltyAccount struct {
+	UserEmail string      `json:"email"`
+	Tier      LoyaltyTier `json:"tier"`
+	Balance   int64       `json:"balance"`
+	Lifetime  int64       `json:"lifetime"` // all earned points, defines tier
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// LoyaltyEntry - ledger record of points change.
+type LoyaltyEntry struct {
+	ID        uuid.UUID        `json:"id"`
+	UserEmail string           `json:"email"`
+	OrderID   OrderID          `json:"order_id"`
+	Kind      LoyaltyEntryKind `json:"kind"`
+	Points    int64            `json:"points"` // signed change of balance
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// NormalizeEmail - emails are case-insensitive identifiers of guests.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// TierFor returns tier reached with lifetime points.
+func TierFor(lifetime int64) LoyaltyTier {
+	for _, t := range tiers {
+		if lifetime >= t.lifetime {
+			return t.tier
+		}
+	}
+
+	return BasicTier
+}
+
+// EarnedPoints returns points earned for paid amount in LoyaltyCurrency: one point per whole currency unit plus
+// tier bonus.
+func EarnedPoints(paid Money, tier LoyaltyTier) int64 {
+	if paid.Currency != LoyaltyCurrency {
+		return 0
+	}
+
+	points := paid.Amount / pow10(CurrencyExponent(paid.Currency)).Int64()
+	for _, t := range tiers {
+		if t.tier == tier {
+			points += points * int64(t.bonusPercent) / 100
+		}
+	}
+
+	return points
+}
+
+// Apply changes account by ledger entry.
+func (a LoyaltyAccount) Apply(entry LoyaltyEntry) (LoyaltyAccount, error) {
+	if entry.Kind == RedeemEntry && a.Balance+entry.Points < 0 {
+		return a, ErrInsufficientPoints
+	}
+
+	a.Balance += entry.Points
+	switch entry.Kind {
+	case EarnEntry:
+		a.Lifetime += entry.Points
+	case ReverseEntry:
+		if entry.Points < 0 { // reversed earned points
+			a.Lifetime += entry.Points
+		}
+	}
+
+	a.Tier = TierFor(a.Lifetime)
+	a.UpdatedAt = entry.CreatedAt
+
+	return a, nil
+}
+
+// Revert undoes entry applied to account, e.g. when entry is removed by rolled back transaction.
+func (a LoyaltyAccount) Revert(entry LoyaltyEntry, at time.Time) LoyaltyAccount {
+	a.Balance -= entry.Points
+	switch entry.Kind {
+	case EarnEntry:
+		a.Lifetime -= entry.Points
+	case ReverseEntry:
+		if entry.Points < 0 {
+			a.Lifetime -= entry.Points
+		}
+	}
+
+	a.Tier = TierFor(a.Lifetime)
+	a.UpdatedAt = at
+
+	return a
+}
+
+// RedeemPoints returns discount which points give on amount, never more than the amount, and points which are
+// really spent for it. Rate converts LoyaltyCurrency into currency of the amount, it is not used for LoyaltyCurrency.
+// Fractions of minor unit which points are worth are not discounted.
+func RedeemPoints(points int64, amount Money, rate FXRate) (Money, int64, error) {
+	discount := Money{Currency: amount.Currency}
+	if points <= 0 || amount.Amount <= 0 {
+		return discount, 0, nil
+	}
+
+	ratio := big.NewRat(1, 1)
+	if amount.Currency != LoyaltyCurrency {
+		if rate.Base != LoyaltyCurrency || rate.Quote != amount.Currency {
+			return Money{}, 0, fmt.Errorf("%w: rate is %s/%s, points are redeemed on %s",
+				ErrCurrencyMismatch, rate.Base, rate.Quote, amount.Currency)
+		}
+
+		var err error
+		if ratio, err = rate.Ratio(); err != nil {
+			return Money{}, 0, err
+		}
+	}
+
+	// value of one point in minor units of amount currency
+	pointValue := new(big.Rat).SetFrac(pow10(CurrencyExponent(amount.Currency)), pow10(CurrencyExponent(LoyaltyCurrency)))
+	pointValue.Mul(pointValue, ratio)
+	pointValue.Mul(pointValue, big.NewRat(PointValue, 1))
+
+	value := new(big.Rat).Mul(pointValue, new(big.Rat).SetInt64(points))
+	if value.Cmp(new(big.Rat).SetInt64(amount.Amount)) < 0 {
+		discount.Amount = new(big.Int).Quo(value.Num(), value.Denom()).Int64()
+		return discount, points, nil
+	}
+
+	// points cover the whole amount, only needed ones are spent
+	needed := new(big.Rat).Quo(new(big.Rat).SetInt64(amount.Amount), pointValue)
+	spent := new(big.Int).Quo(new(big.Int).Sub(new(big.Int).Add(needed.Num(), needed.Denom()), big.NewInt(1)), needed.Denom())
+
+	discount.Amount = amount.Amount
+	return discount, min(spent.Int64(), points), nil
+}
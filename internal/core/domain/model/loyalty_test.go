@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTierFor(t *testing.T) {
+	assert.Equal(t, BasicTier, TierFor(0))
+	assert.Equal(t, BasicTier, TierFor(999))
+	assert.Equal(t, SilverTier, TierFor(1_000))
+	assert.Equal(t, GoldTier, TierFor(5_000))
+}
+
+func TestEarnedPoints(t *testing.T) {
+	paid := Money{Amount: 200_99, Currency: "EUR"}
+
+	assert.EqualValues(t, 200, EarnedPoints(paid, BasicTier), "one point per whole currency unit")
+	assert.EqualValues(t, 250, EarnedPoints(paid, SilverTier))
+	assert.EqualValues(t, 300, EarnedPoints(paid, GoldTier))
+
+	assert.Zero(t, EarnedPoints(Money{Amount: 200_99, Currency: "USD"}, BasicTier), "not converted into loyalty currency")
+}
+
+func TestLoyaltyAccount_Apply(t *testing.T) {
+	account := LoyaltyAccount{Tier: BasicTier}
+
+	account, err := account.Apply(LoyaltyEntry{Kind: EarnEntry, Points: 1_200})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1_200, account.Balance)
+	assert.Equal(t, SilverTier, account.Tier)
+
+	_, err = account.Apply(LoyaltyEntry{Kind: RedeemEntry, Points: -1_201})
+	assert.ErrorIs(t, err, ErrInsufficientPoints)
+
+	account, err = account.Apply(LoyaltyEntry{Kind: RedeemEntry, Points: -1_000})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 200, account.Balance)
+	assert.Equal(t, SilverTier, account.Tier, "redeem does not change tier")
+
+	account, err = account.Apply(LoyaltyEntry{Kind: ReverseEntry, Points: -1_200})
+	assert.NoError(t, err, "earned points are reversed even if balance becomes negative")
+	assert.EqualValues(t, -1_000, account.Balance)
+	assert.Equal(t, BasicTier, account.Tier)
+}
+
+func TestLoyaltyAccount_Revert(t *testing.T) {
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	earn := LoyaltyEntry{Kind: EarnEntry, Points: 1_200}
+
+	account, err := LoyaltyAccount{Tier: BasicTier}.Apply(earn)
+	require.NoError(t, err)
+	reversed, err := account.Apply(LoyaltyEntry{Kind: ReverseEntry, Points: -1_200})
+	require.NoError(t, err)
+
+	assert.Equal(t, account, reversed.Revert(LoyaltyEntry{Kind: ReverseEntry, Points: -1_200}, account.UpdatedAt))
+
+	account = account.Revert(earn, at)
+	assert.Zero(t, account.Balance)
+	assert.Zero(t, account.Lifetime)
+	assert.Equal(t, BasicTier, account.Tier)
+	assert.Equal(t, at, account.UpdatedAt)
+}
+
+func TestRedeemPoints(t *testing.T) {
+	rate := func(quote, ratio string) FXRate {
+		return FXRate{Base: LoyaltyCurrency, Quote: quote, Rate: ratio, EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	}
+
+	tests := []struct {
+		name             string
+		points           int64
+		amount           Money
+		rate             FXRate
+		expectedDiscount int64
+		expectedSpent    int64
+	}{
+		{"Loyalty currency", 150, Money{Amount: 200_00, Currency: "EUR"}, FXRate{}, 150, 150},
+		{"Points cover amount", 300, Money{Amount: 2_00, Currency: "EUR"}, FXRate{}, 2_00, 200},
+		{"Converted", 1_000, Money{Amount: 200_00, Currency: "USD"}, rate("USD", "1.0825"), 10_82, 1_000},
+		{"Converted points cover amount", 1_000, Money{Amount: 5_00, Currency: "USD"}, rate("USD", "1.0825"), 5_00, 462},
+		{"Currency without minor units", 100, Money{Amount: 20_000, Currency: "JPY"}, rate("JPY", "162.35"), 162, 100},
+		{"No points", 0, Money{Amount: 2_00, Currency: "USD"}, FXRate{}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount, spent, err := RedeemPoints(tt.points, tt.amount, tt.rate)
+			require.NoError(t, err)
+			assert.Equal(t, Money{Amount: tt.expectedDiscount, Currency: tt.amount.Currency}, discount)
+			assert.Equal(t, tt.expectedSpent, spent)
+		})
+	}
+
+	_, _, err := RedeemPoints(100, Money{Amount: 2_00, Currency: "USD"}, rate("JPY", "162.35"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
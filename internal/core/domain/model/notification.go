@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationKind string
+
+const (
+	OrderBookedNotification      NotificationKind = "order_booked"
+	OrderDepositPaidNotification NotificationKind = "order_deposit_paid"
+	OrderPaidNotification        NotificationKind = "order_paid"
+	OrderCancelledNotification   NotificationKind = "order_cancelled"
+	OrderFailedNotification      NotificationKind = "order_failed" // order is not booked or its payment failed
+
+	WaitlistBookedNotification NotificationKind = "waitlist_booked"
+)
+
+// Notification - request to notify guest about the order.
+type Notification struct {
+	ID        uuid.UUID        `json:"id"`
+	OrderID   OrderID          `json:"order_id"`
+	UserEmail string           `json:"email"`
+	Guest     Guest            `json:"guest"`
+	Kind      NotificationKind `json:"kind"`
+	CreatedAt time.Time        `json:"created_at"`
+
+	Order Order `json:"order"` // state of the order at the moment of notification
+}
+
+// Email - message sent to guest by mailer.
+type Email struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"` // plain text
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment - file attached to email, e.g. invoice.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,6 +9,8 @@ import (
 
 type Status string
 
+var ErrOrderStatus = errors.New("operation is not allowed in order status")
+
 type OrderID = uuid.UUID
 
 type Order struct {
@@ -18,22 +21,98 @@ type Order struct {
 	HotelID    int       `json:"hotel_id"`
 	RoomTypeID int       `json:"room_type_id"`
 	UserEmail  string    `json:"email"`
+	From       time.Time `json:"from"` // hotel local check-in date
+	To         time.Time `json:"to"`   // hotel local check-out date, its night is not occupied
+	PromoCode  string    `json:"promo_code,omitempty"`
+	RatePlanID int       `json:"rate_plan_id,omitempty"`
+	BlockCode  string    `json:"block_code,omitempty"` // book from allotment instead of general quota
+
+	CheckInAt  time.Time `json:"check_in_at"`  // moment of check-in by hotel check-in time
+	CheckOutAt time.Time `json:"check_out_at"` // moment of check-out by hotel check-out time
+
+	Guest            Guest     `json:"guest"` // lead guest
+	Occupancy        Occupancy `json:"occupancy"`
+	AdditionalGuests []Guest   `json:"additional_guests,omitempty"`
+
+	LoyaltyPoints int64 `json:"loyalty_points,omitempty"` // points to redeem as discount
+	Waitlist      bool  `json:"waitlist,omitempty"`       // join waitlist if there are no rooms
+
+	Price Price `json:"price"`
+
+	DisplayCurrency string              `json:"display_currency,omitempty"` // selected by guest, empty - currency of hotel
+	Conversion      *CurrencyConversion `json:"conversion,omitempty"`       // total in display currency, fixed at booking
+
+	PaymentSchedule []Installment  `json:"payment_schedule,omitempty"` // empty - total is paid at once
+	Payments        []OrderPayment `json:"payments,omitempty"`         // requested apart from schedule
+	PaidAmount      Money          `json:"paid_amount"`
+
+	Refunds        []Refund `json:"refunds,omitempty"`
+	RefundedAmount Money    `json:"refunded_amount"` // sum of succeeded refunds
+
+	Status        Status `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"` // why order is rejected, e.g. violated stay restriction
+
+	Alternatives []Alternative `json:"alternatives,omitempty"` // available stays offered when there are no rooms
+
+	Changes []OrderChange `json:"changes,omitempty"`
+}
+
+// OrderModification - request to change dates or room type of an existing order.
+type OrderModification struct {
+	OrderID    OrderID   `json:"order_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	RoomTypeID int       `json:"room_type_id"`
 	From       time.Time `json:"from"`
 	To         time.Time `json:"to"`
+}
+
+// OrderChange - history record of an order modification.
+type OrderChange struct {
+	ChangedAt time.Time `json:"changed_at"`
+	Applied   bool      `json:"applied"`          // false if new period was not available and original booking is kept
+	Reason    string    `json:"reason,omitempty"` // why change is not applied
+
+	OldRoomTypeID int       `json:"old_room_type_id"`
+	OldFrom       time.Time `json:"old_from"`
+	OldTo         time.Time `json:"old_to"`
+
+	NewRoomTypeID int       `json:"new_room_type_id"`
+	NewFrom       time.Time `json:"new_from"`
+	NewTo         time.Time `json:"new_to"`
+}
 
-	Status Status `json:"status"`
+// OrderCancellation - request to cancel an existing order.
+type OrderCancellation struct {
+	OrderID   OrderID   `json:"order_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 const (
 	New Status = "new"
 
-	NoRooms = "no_rooms"
+	NoRooms Status = "no_rooms"
+
+	Booked      Status = "booked"
+	DepositPaid Status = "depositPaid" // deposit is paid, balance is due later
+	Paid        Status = "paid"
 
-	Booked Status = "booked"
-	Paid   Status = "paid"
+	FailedBook   Status = "failedBook"
+	FailedPay    Status = "failedPay"
+	InvalidPromo Status = "invalidPromo"
+	Restricted   Status = "restricted" // stay violates restrictions of room-days
 
-	FailedBook Status = "failedBook"
-	FailedPay  Status = "failedPay"
+	Cancelled Status = "cancelled"
+	Expired   Status = "expired" // booked, but not paid in time
 )
 
-var allStatuses = [...]Status{New, NoRooms, Booked, Paid, FailedBook, FailedPay}
+var allStatuses = [...]Status{New, NoRooms, Booked, DepositPaid, Paid, FailedBook, FailedPay, InvalidPromo, Restricted, Cancelled, Expired}
+
+// IsCancellable reports whether the order still holds quota and can be cancelled.
+func (o Order) IsCancellable() bool {
+	return o.Status == Booked || o.Status == DepositPaid || o.Status == Paid
+}
+
+// IsModifiable reports whether dates or room type of the order can be changed.
+func (o Order) IsModifiable() bool {
+	return o.Status == Booked || o.Status == DepositPaid || o.Status == Paid
+}
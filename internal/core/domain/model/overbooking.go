@@ -0,0 +1,110 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+var ErrOverbookingRuleInvalid = errors.New("invalid overbooking rule")
+
+type OverbookingLimitType string
+
+const (
+	AbsoluteOverbooking OverbookingLimitType = "absolute" // rooms over capacity
+	PercentOverbooking  OverbookingLimitType = "percent"  // percent of capacity, rounded down
+)
+
+type OverbookingRuleID = uuid.UUID
+
+// OverbookingRule - how many rooms can be sold over physical capacity.
+// The most specific matching rule is applied: hotel < room type < date < room type and date.
+type OverbookingRule struct {
+	ID         OverbookingRuleID    `json:"id"`
+	HotelID    int                  `json:"hotel_id"`
+	RoomTypeID int                  `json:"room_type_id,omitempty"` // 0 - all room types of hotel
+	Date       time.Time            `json:"date,omitempty"`         // zero - all dates
+	LimitType  OverbookingLimitType `json:"limit_type"`
+	Limit      int                  `json:"limit"`
+}
+
+// OversoldNight - room-day which has more sold rooms than physical capacity.
+type OversoldNight struct {
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	Date       time.Time `json:"date"`
+	Capacity   int       `json:"capacity"`
+	Sold       int       `json:"sold"`
+	Oversold   int       `json:"oversold"`
+	Allowance  int       `json:"allowance"`
+}
+
+func (r OverbookingRule) Validate() error {
+	switch {
+	case r.HotelID <= 0:
+		return fmt.Errorf("%w: hotel ID must be positive integer", ErrOverbookingRuleInvalid)
+	case r.RoomTypeID < 0:
+		return fmt.Errorf("%w: room type ID must not be negative", ErrOverbookingRuleInvalid)
+	case r.Limit < 0:
+		return fmt.Errorf("%w: limit must not be negative", ErrOverbookingRuleInvalid)
+	case r.LimitType != AbsoluteOverbooking && r.LimitType != PercentOverbooking:
+		return fmt.Errorf("%w: unknown limit type %q", ErrOverbookingRuleInvalid, r.LimitType)
+	case r.LimitType == PercentOverbooking && r.Limit > 100:
+		return fmt.Errorf("%w: percent limit must not be greater than 100", ErrOverbookingRuleInvalid)
+	}
+
+	return nil
+}
+
+// Matches reports whether rule is applicable to room-day.
+func (r OverbookingRule) Matches(room RoomAvailability) bool {
+	return r.HotelID == room.HotelID &&
+		(r.RoomTypeID == 0 || r.RoomTypeID == room.RoomTypeID) &&
+		(r.Date.IsZero() || util.ToDay(r.Date).Equal(util.ToDay(room.Date)))
+}
+
+// Allowance returns rooms which can be sold over capacity.
+func (r OverbookingRule) Allowance(capacity int) int {
+	if r.LimitType == PercentOverbooking {
+		return max(capacity, 0) * r.Limit / 100
+	}
+
+	return r.Limit
+}
+
+func (r OverbookingRule) specificity() int {
+	specificity := 0
+	if r.RoomTypeID != 0 {
+		specificity++
+	}
+	if !r.Date.IsZero() {
+		specificity += 2
+	}
+
+	return specificity
+}
+
+// OverbookingAllowance returns rooms which can be sold over capacity of room-day by the most specific matching rule,
+// without matching rule overbooking is not allowed.
+func OverbookingAllowance(rules []OverbookingRule, room RoomAvailability) int {
+	var (
+		found bool
+		rule  OverbookingRule
+	)
+
+	for _, r := range rules {
+		if r.Matches(room) && (!found || r.specificity() > rule.specificity()) {
+			found, rule = true, r
+		}
+	}
+
+	if !found {
+		return 0
+	}
+
+	return rule.Allowance(room.Capacity)
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+func TestOverbookingRule_Validate(t *testing.T) {
+	assert.NoError(t, OverbookingRule{HotelID: 1, LimitType: AbsoluteOverbooking, Limit: 2}.Validate())
+	assert.NoError(t, OverbookingRule{HotelID: 1, RoomTypeID: 2, LimitType: PercentOverbooking, Limit: 5}.Validate())
+
+	assert.ErrorIs(t, OverbookingRule{LimitType: AbsoluteOverbooking}.Validate(), ErrOverbookingRuleInvalid)
+	assert.ErrorIs(t, OverbookingRule{HotelID: 1, LimitType: AbsoluteOverbooking, Limit: -1}.Validate(), ErrOverbookingRuleInvalid)
+	assert.ErrorIs(t, OverbookingRule{HotelID: 1, LimitType: "rooms"}.Validate(), ErrOverbookingRuleInvalid)
+	assert.ErrorIs(t, OverbookingRule{HotelID: 1, LimitType: PercentOverbooking, Limit: 101}.Validate(), ErrOverbookingRuleInvalid)
+}
+
+func TestOverbookingAllowance(t *testing.T) {
+	room := RoomAvailability{HotelID: 1, RoomTypeID: 2, Date: util.NewDay(2024, 4, 5), Capacity: 25}
+
+	hotel := OverbookingRule{HotelID: 1, LimitType: PercentOverbooking, Limit: 10}
+	roomType := OverbookingRule{HotelID: 1, RoomTypeID: 2, LimitType: AbsoluteOverbooking, Limit: 1}
+	date := OverbookingRule{HotelID: 1, Date: util.NewDay(2024, 4, 5), LimitType: AbsoluteOverbooking, Limit: 3}
+	otherHotel := OverbookingRule{HotelID: 2, LimitType: AbsoluteOverbooking, Limit: 5}
+	otherDate := OverbookingRule{HotelID: 1, RoomTypeID: 2, Date: util.NewDay(2024, 4, 6), LimitType: AbsoluteOverbooking, Limit: 4}
+
+	assert.Equal(t, 0, OverbookingAllowance(nil, room), "overbooking is not allowed without rule")
+	assert.Equal(t, 0, OverbookingAllowance([]OverbookingRule{otherHotel, otherDate}, room))
+	assert.Equal(t, 2, OverbookingAllowance([]OverbookingRule{hotel}, room), "percent is rounded down")
+	assert.Equal(t, 1, OverbookingAllowance([]OverbookingRule{hotel, roomType}, room))
+	assert.Equal(t, 3, OverbookingAllowance([]OverbookingRule{date, roomType, hotel}, room), "date rule wins")
+}
@@ -1,21 +1,110 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrPaymentDeclined        = errors.New("payment declined")
+	ErrPaymentPending         = errors.New("payment result will be sent by callback")
+	ErrPaymentCallbackInvalid = errors.New("invalid payment callback")
+	ErrPaymentAmountMismatch  = errors.New("callback amount does not match payment")
+)
+
+type PaymentCallbackStatus string
+
+const (
+	PaymentSucceeded PaymentCallbackStatus = "succeeded"
+	PaymentFailed    PaymentCallbackStatus = "failed"
+)
+
 type (
 	Payment = struct {
 		ID        uuid.UUID `json:"id"`
 		OrderID   OrderID   `json:"order_id"`
+		Amount    Money     `json:"amount"`
 		CreatedAt time.Time `json:"createdAt"`
 		PaidAt    time.Time `json:"paidAt"`
 		IsPaid    bool      `json:"isPaid"`
+		SettledAt time.Time `json:"settled_at,omitempty"` // result is published for booking service
+
+		Payer      Guest  `json:"payer"` // lead guest of the order
+		PayerEmail string `json:"payer_email"`
+
+		TransactionID  string `json:"transaction_id,omitempty"` // reference of payment in gateway
+		FailureReason  string `json:"failure_reason,omitempty"` // payment is failed if not empty
+		RefundedAmount Money  `json:"refunded_amount"`          // part of charged amount returned to payer
+
+		Conversion *CurrencyConversion `json:"conversion,omitempty"` // amount in display currency of the order
 		// other
 	}
 
-	SuccessPaymentEvent = struct{ _ [0]int }
-	FailedPaymentEvent  = struct{}
+	// PaymentCallback - asynchronous result of payment sent by payment provider.
+	// Provider may deliver the same event several times, EventID is kept between deliveries.
+	PaymentCallback struct {
+		EventID       string                `json:"event_id"`
+		PaymentID     uuid.UUID             `json:"payment_id"`
+		OrderID       OrderID               `json:"order_id"` // set from stored payment
+		Status        PaymentCallbackStatus `json:"status"`
+		TransactionID string                `json:"transaction_id"`
+		Amount        Money                 `json:"amount"`
+		Reason        string                `json:"reason,omitempty"` // why payment is failed
+		OccurredAt    time.Time             `json:"occurred_at"`
+		ReceivedAt    time.Time             `json:"received_at"`
+	}
+
+	// Charge - money captured by payment gateway.
+	Charge struct {
+		TransactionID string    `json:"transaction_id"` // reference of payment in gateway
+		PaymentID     uuid.UUID `json:"payment_id"`
+		Amount        Money     `json:"amount"`
+		ChargedAt     time.Time `json:"charged_at"`
+	}
+
+	// SuccessPaymentEvent - payment of the order is successfully processed.
+	SuccessPaymentEvent struct {
+		PaymentID uuid.UUID `json:"payment_id"`
+		OrderID   OrderID   `json:"order_id"`
+		Amount    Money     `json:"amount"`
+		PaidAt    time.Time `json:"paid_at"`
+	}
+
+	// FailedPaymentEvent - payment of the order is declined or failed.
+	FailedPaymentEvent struct {
+		PaymentID uuid.UUID `json:"payment_id"`
+		OrderID   OrderID   `json:"order_id"`
+		Reason    string    `json:"reason"`
+		FailedAt  time.Time `json:"failed_at"`
+	}
 )
+
+// IsSettled reports whether result of payment is already known.
+func IsSettled(p Payment) bool {
+	return p.IsPaid || p.FailureReason != ""
+}
+
+func (c PaymentCallback) Validate() error {
+	if c.EventID == "" {
+		return fmt.Errorf("%w: event ID is required", ErrPaymentCallbackInvalid)
+	}
+
+	if c.PaymentID == uuid.Nil {
+		return fmt.Errorf("%w: payment ID is required", ErrPaymentCallbackInvalid)
+	}
+
+	switch c.Status {
+	case PaymentSucceeded:
+		return nil
+	case PaymentFailed:
+		if c.Reason == "" {
+			return fmt.Errorf("%w: reason of failed payment is required", ErrPaymentCallbackInvalid)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrPaymentCallbackInvalid, c.Status)
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money - amount in minor units (cents) of currency. Floats are never used for money.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency && m.Amount != 0 && other.Amount != 0 {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	if m.Currency == "" {
+		m.Currency = other.Currency
+	}
+	m.Amount += other.Amount
+
+	return m, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	other.Amount = -other.Amount
+	return m.Add(other)
+}
+
+// Percent returns percent of money, rounded half away from zero to minor unit.
+func (m Money) Percent(percent int) Money {
+	v := m.Amount * int64(percent)
+	if v >= 0 {
+		m.Amount = (v + 50) / 100
+	} else {
+		m.Amount = (v - 50) / 100
+	}
+
+	return m
+}
+
+type (
+	RateID     = int
+	RatePlanID = int
+)
+
+// Rate - price of one night for hotel, room type and date.
+type Rate struct {
+	ID         RateID
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	Date       time.Time `json:"date"`
+	Price      Money     `json:"price"`
+}
+
+// RatePlan - conditions of sale, which change nightly rate of the hotel.
+type RatePlan struct {
+	ID      RatePlanID `json:"id"`
+	HotelID int        `json:"hotel_id"`
+	Code    string     `json:"code"`
+	Name    string     `json:"name"`
+
+	Refundable        bool `json:"refundable"`
+	AdjustmentPercent int  `json:"adjustment_percent"` // e.g. -10 for 10% cheaper non-refundable plan
+
+	DepositPercent int `json:"deposit_percent,omitempty"`  // paid on booking, the rest is balance; 0 - total is paid on booking
+	BalanceDueDays int `json:"balance_due_days,omitempty"` // balance is charged so many days before check-in
+
+	Cancellation CancellationPolicy `json:"cancellation_policy"`
+}
+
+// DefaultRatePlan - best available rate, used when order has no rate plan.
+var DefaultRatePlan = RatePlan{Code: "BAR", Name: "Best available rate", Refundable: true}
+
+// NightPrice - price of one night of stay.
+type NightPrice struct {
+	Date  time.Time `json:"date"`
+	Price Money     `json:"price"`
+}
+
+// Price - price breakdown of order.
+type Price struct {
+	Nights         []NightPrice `json:"nights"`
+	Subtotal       Money        `json:"subtotal"`
+	Discount       Money        `json:"discount"`        // promo code discount
+	PointsDiscount Money        `json:"points_discount"` // redeemed loyalty points
+	RedeemedPoints int64        `json:"redeemed_points,omitempty"`
+	Total          Money        `json:"total"`
+	Refundable     bool         `json:"refundable"`
+
+	DepositPercent int `json:"deposit_percent,omitempty"`  // payment terms of rate plan
+	BalanceDueDays int `json:"balance_due_days,omitempty"` // payment terms of rate plan
+
+	Cancellation CancellationPolicy `json:"cancellation_policy"` // of rate plan, applied to refunds
+}
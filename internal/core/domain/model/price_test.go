@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_Percent(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		percent  int
+		expected int64
+	}{
+		{"Whole", 100_00, 90, 90_00},
+		{"Round half up", 1_05, 50, 53},
+		{"Round down", 1_04, 50, 52},
+		{"Negative half away from zero", -1_05, 50, -53},
+		{"Zero", 100_00, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money{Amount: tt.amount, Currency: "EUR"}.Percent(tt.percent)
+			assert.Equal(t, Money{Amount: tt.expected, Currency: "EUR"}, m)
+		})
+	}
+}
+
+func TestMoney_AddSub(t *testing.T) {
+	sum, err := Money{}.Add(Money{Amount: 10_00, Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: 10_00, Currency: "EUR"}, sum, "zero money takes currency of other")
+
+	diff, err := sum.Sub(Money{Amount: 15_00, Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: -5_00, Currency: "EUR"}, diff)
+
+	_, err = sum.Add(Money{Amount: 1, Currency: "USD"})
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+type DiscountType string
+
+const (
+	PercentDiscount DiscountType = "percent"
+	FixedDiscount   DiscountType = "fixed"
+)
+
+var (
+	ErrPromoInvalid       = errors.New("promo code is invalid")
+	ErrPromoNotActive     = errors.New("promo code is not active")
+	ErrPromoNotApplicable = errors.New("promo code is not applicable for hotel or room type")
+	ErrPromoUsageLimit    = errors.New("promo code usage limit is reached")
+	ErrPromoEmailLimit    = errors.New("promo code usage limit for email is reached")
+)
+
+// PromoCode - discount which can be applied to order.
+type PromoCode struct {
+	Code string `json:"code"`
+
+	DiscountType  DiscountType `json:"discount_type"`
+	DiscountValue int          `json:"discount_value"` // percent for PercentDiscount, amount in minor units for FixedDiscount
+
+	ValidFrom time.Time `json:"valid_from"`
+	ValidTo   time.Time `json:"valid_to"`
+
+	UsageLimit    int `json:"usage_limit"`     // 0 - unlimited
+	PerEmailLimit int `json:"per_email_limit"` // 0 - unlimited
+	Used          int `json:"used"`
+
+	HotelIDs    []int `json:"hotel_ids,omitempty"`     // empty - any hotel
+	RoomTypeIDs []int `json:"room_type_ids,omitempty"` // empty - any room type
+}
+
+// PromoRedemption - usage of promo code by order.
+type PromoRedemption struct {
+	OrderID    OrderID   `json:"order_id"`
+	Code       string    `json:"code"`
+	UserEmail  string    `json:"email"`
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+// NormalizePromoCode - promo codes are case-insensitive.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks that promo code is well-formed.
+func (p PromoCode) Validate() error {
+	switch {
+	case p.Code == "" || p.Code != NormalizePromoCode(p.Code):
+		return errors.Join(ErrPromoInvalid, errors.New("code must be non-empty upper-case string"))
+	case p.DiscountType == PercentDiscount && (p.DiscountValue <= 0 || p.DiscountValue > 100):
+		return errors.Join(ErrPromoInvalid, errors.New("percent discount must be in range (0, 100]"))
+	case p.DiscountType == FixedDiscount && p.DiscountValue <= 0:
+		return errors.Join(ErrPromoInvalid, errors.New("fixed discount must be positive"))
+	case p.DiscountType != PercentDiscount && p.DiscountType != FixedDiscount:
+		return errors.Join(ErrPromoInvalid, errors.New("unknown discount type"))
+	case !p.ValidTo.IsZero() && p.ValidTo.Before(p.ValidFrom):
+		return errors.Join(ErrPromoInvalid, errors.New("valid_to must be after valid_from"))
+	case p.UsageLimit < 0 || p.PerEmailLimit < 0:
+		return errors.Join(ErrPromoInvalid, errors.New("limits must not be negative"))
+	}
+
+	return nil
+}
+
+// CheckRedemption checks that promo code can be redeemed, usedByEmail - number of redemptions already made by the email.
+func (p PromoCode) CheckRedemption(r PromoRedemption, usedByEmail int) error {
+	switch {
+	case !p.isActive(r.RedeemedAt):
+		return ErrPromoNotActive
+	case len(p.HotelIDs) > 0 && !slices.Contains(p.HotelIDs, r.HotelID):
+		return ErrPromoNotApplicable
+	case len(p.RoomTypeIDs) > 0 && !slices.Contains(p.RoomTypeIDs, r.RoomTypeID):
+		return ErrPromoNotApplicable
+	case p.UsageLimit > 0 && p.Used >= p.UsageLimit:
+		return ErrPromoUsageLimit
+	case p.PerEmailLimit > 0 && usedByEmail >= p.PerEmailLimit:
+		return ErrPromoEmailLimit
+	}
+
+	return nil
+}
+
+// isActive reports whether promo code is valid at the moment. Promo code is valid through the whole day of ValidTo.
+func (p PromoCode) isActive(at time.Time) bool {
+	if at.Before(p.ValidFrom) {
+		return false
+	}
+	if p.ValidTo.IsZero() {
+		return true
+	}
+
+	year, month, day := p.ValidTo.Date()
+
+	return at.Before(time.Date(year, month, day+1, 0, 0, 0, 0, p.ValidTo.Location()))
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromoCode_Validate(t *testing.T) {
+	valid := PromoCode{Code: "WELCOME10", DiscountType: PercentDiscount, DiscountValue: 10}
+
+	tests := []struct {
+		name  string
+		promo func(p PromoCode) PromoCode
+		valid bool
+	}{
+		{"Valid percent", func(p PromoCode) PromoCode { return p }, true},
+		{"Valid fixed", func(p PromoCode) PromoCode { p.DiscountType, p.DiscountValue = FixedDiscount, 500; return p }, true},
+		{"Lower case code", func(p PromoCode) PromoCode { p.Code = "welcome10"; return p }, false},
+		{"Percent above 100", func(p PromoCode) PromoCode { p.DiscountValue = 101; return p }, false},
+		{"Unknown type", func(p PromoCode) PromoCode { p.DiscountType = "gift"; return p }, false},
+		{"Window end before start", func(p PromoCode) PromoCode {
+			p.ValidFrom, p.ValidTo = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			return p
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.promo(valid).Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrPromoInvalid)
+			}
+		})
+	}
+}
+
+func TestPromoCode_CheckRedemption(t *testing.T) {
+	promo := PromoCode{
+		Code:          "SPRING",
+		DiscountType:  PercentDiscount,
+		DiscountValue: 10,
+		ValidFrom:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		ValidTo:       time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+		UsageLimit:    2,
+		PerEmailLimit: 1,
+		HotelIDs:      []int{1},
+		RoomTypeIDs:   []int{1, 2},
+	}
+
+	redemption := PromoRedemption{HotelID: 1, RoomTypeID: 2, RedeemedAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name        string
+		promo       func(p PromoCode) PromoCode
+		redemption  func(r PromoRedemption) PromoRedemption
+		usedByEmail int
+		expectedErr error
+	}{
+		{"Applicable", nil, nil, 0, nil},
+		{"Before window", nil, func(r PromoRedemption) PromoRedemption {
+			r.RedeemedAt = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+			return r
+		}, 0, ErrPromoNotActive},
+		{"Last valid day", nil, func(r PromoRedemption) PromoRedemption {
+			r.RedeemedAt = time.Date(2024, 5, 31, 23, 59, 0, 0, time.UTC)
+			return r
+		}, 0, nil},
+		{"After window", nil, func(r PromoRedemption) PromoRedemption {
+			r.RedeemedAt = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			return r
+		}, 0, ErrPromoNotActive},
+		{"Other hotel", nil, func(r PromoRedemption) PromoRedemption { r.HotelID = 2; return r }, 0, ErrPromoNotApplicable},
+		{"Other room type", nil, func(r PromoRedemption) PromoRedemption { r.RoomTypeID = 3; return r }, 0, ErrPromoNotApplicable},
+		{"Usage limit", func(p PromoCode) PromoCode { p.Used = 2; return p }, nil, 0, ErrPromoUsageLimit},
+		{"Email limit", nil, nil, 1, ErrPromoEmailLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, r := promo, redemption
+			if tt.promo != nil {
+				p = tt.promo(p)
+			}
+			if tt.redemption != nil {
+				r = tt.redemption(r)
+			}
+
+			err := p.CheckRedemption(r, tt.usedByEmail)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrSettlementRecordInvalid = errors.New("invalid settlement record")
+
+type SettlementStatus string
+
+const (
+	SettlementSettled SettlementStatus = "settled"
+	SettlementFailed  SettlementStatus = "failed"
+)
+
+// SettlementRecord - result of payment as it is settled by payment provider, line of settlement report.
+type SettlementRecord struct {
+	TransactionID string           `json:"transaction_id"` // empty for failed payment
+	PaymentID     uuid.UUID        `json:"payment_id"`
+	Status        SettlementStatus `json:"status"`
+	Amount        Money            `json:"amount"`
+	SettledAt     time.Time        `json:"settled_at"`
+	Reason        string           `json:"reason,omitempty"` // why payment is failed
+}
+
+func (r SettlementRecord) Validate() error {
+	if r.PaymentID == uuid.Nil {
+		return fmt.Errorf("%w: payment ID is required", ErrSettlementRecordInvalid)
+	}
+
+	switch r.Status {
+	case SettlementSettled:
+		if r.TransactionID == "" {
+			return fmt.Errorf("%w: transaction ID of settled payment is required", ErrSettlementRecordInvalid)
+		}
+	case SettlementFailed:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrSettlementRecordInvalid, r.Status)
+	}
+
+	if err := ValidateCurrency(r.Amount.Currency); err != nil {
+		return fmt.Errorf("%w: %w", ErrSettlementRecordInvalid, err)
+	}
+
+	if r.SettledAt.IsZero() {
+		return fmt.Errorf("%w: settlement time is required", ErrSettlementRecordInvalid)
+	}
+
+	return nil
+}
+
+type DiscrepancyKind string
+
+const (
+	UnknownPaymentDiscrepancy DiscrepancyKind = "unknown_payment" // provider settled payment which is not requested by us
+	NotSettledDiscrepancy     DiscrepancyKind = "not_settled"     // payment is paid by us, but provider did not settle it
+	AmountDiscrepancy         DiscrepancyKind = "amount_mismatch" // settled amount differs from charged one
+	StatusDiscrepancy         DiscrepancyKind = "status_mismatch" // payment is paid by us and failed by provider or vice versa
+	StuckPaymentDiscrepancy   DiscrepancyKind = "stuck_payment"   // provider settled payment, we still wait for its result
+	StuckOrderDiscrepancy     DiscrepancyKind = "stuck_order"     // payment is settled, but order did not get its result
+	MissingOrderDiscrepancy   DiscrepancyKind = "order_not_found" // payment of unknown order
+)
+
+// Discrepancy - disagreement of payment or order state with settlement report. Stuck payments and orders
+// can be fixed by emitting missing payment result, other discrepancies are resolved manually.
+type Discrepancy struct {
+	Kind          DiscrepancyKind `json:"kind"`
+	PaymentID     uuid.UUID       `json:"payment_id"`
+	OrderID       OrderID         `json:"order_id"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	Detail        string          `json:"detail"`
+
+	Fixable bool   `json:"fixable"`
+	Fix     string `json:"fix,omitempty"` // emitted event if discrepancy is fixed
+}
+
+// ReconciliationReport - result of comparison of settlement report with payments and orders.
+type ReconciliationReport struct {
+	ID        uuid.UUID `json:"id"`
+	Source    string    `json:"source"` // e.g. name of settlement report file
+	CreatedAt time.Time `json:"created_at"`
+
+	From    time.Time `json:"from"` // settlement period covered by report
+	To      time.Time `json:"to"`
+	Records int       `json:"records"`
+	Matched int       `json:"matched"`
+	Fixed   int       `json:"fixed"`
+
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
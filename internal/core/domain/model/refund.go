@@ -0,0 +1,101 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrRefundDeclined = errors.New("refund declined")
+
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundFailed    RefundStatus = "failed"
+	RefundPartial   RefundStatus = "partial" // some payments are refunded, refund of the rest failed
+)
+
+type RefundReason string
+
+const (
+	CancellationRefund RefundReason = "cancellation"
+	ModificationRefund RefundReason = "modification" // price of modified order became lower
+	CompensationRefund RefundReason = "compensation" // payment succeeded after booking of the order was released
+)
+
+// Refund - request to return money of a paid order. Amount is returned from payments of the order,
+// the latest payments are refunded first.
+type Refund struct {
+	ID         uuid.UUID    `json:"id"`
+	OrderID    OrderID      `json:"order_id"`
+	Amount     Money        `json:"amount"`
+	Reason     RefundReason `json:"reason"`
+	Status     RefundStatus `json:"status"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	RefundedAt time.Time    `json:"refundedAt"`
+
+	TransactionIDs []string `json:"transaction_ids,omitempty"` // references of refunds in gateway, one per refunded payment
+	RefundedAmount Money    `json:"refunded_amount"`           // returned part of amount, whole amount of succeeded refund
+	FailureReason  string   `json:"failure_reason,omitempty"`
+}
+
+func (r Refund) IsSettled() bool {
+	return r.Status == RefundSucceeded || r.Status == RefundFailed || r.Status == RefundPartial
+}
+
+// Returned returns money which is given back by settled refund.
+func (r Refund) Returned() Money {
+	switch r.Status {
+	case RefundSucceeded:
+		return r.Amount
+	case RefundPartial:
+		return r.RefundedAmount
+	default:
+		return Money{Currency: r.Amount.Currency}
+	}
+}
+
+// CancellationPolicy - part of paid money which is kept by hotel when order is cancelled or its price is lowered.
+// Zero policy returns whole amount.
+type CancellationPolicy struct {
+	FreeCancellationDays int `json:"free_cancellation_days,omitempty"` // whole amount is returned so many days before check-in, 0 - until check-in
+	LateFeePercent       int `json:"late_fee_percent,omitempty"`       // part of amount kept by hotel when it is later
+}
+
+// RefundableAmount returns part of paid amount which is returned when order with check-in at checkInAt is cancelled
+// at cancelledAt. Nothing is returned by non-refundable price.
+func (p Price) RefundableAmount(paid Money, checkInAt, cancelledAt time.Time) Money {
+	if !p.Refundable || paid.Amount <= 0 {
+		return Money{Currency: paid.Currency}
+	}
+
+	freeUntil := checkInAt.AddDate(0, 0, -p.Cancellation.FreeCancellationDays)
+	if p.Cancellation.LateFeePercent <= 0 || !cancelledAt.After(freeUntil) {
+		return paid
+	}
+
+	return paid.Percent(100 - min(p.Cancellation.LateFeePercent, 100))
+}
+
+// NetPaid returns money of the order kept by hotel: paid amount without money returned by settled refunds and money
+// of pending ones, which is being returned. Failed refund and not returned part of partial one stay paid.
+func (o Order) NetPaid() Money {
+	net := o.PaidAmount
+	if net.Currency == "" {
+		net.Currency = o.Price.Total.Currency
+	}
+
+	for _, refund := range o.Refunds {
+		if refund.Status == RefundPending {
+			net.Amount -= refund.Amount.Amount
+			continue
+		}
+
+		net.Amount -= refund.Returned().Amount
+	}
+
+	return net
+}
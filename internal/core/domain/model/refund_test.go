@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrice_RefundableAmount(t *testing.T) {
+	checkInAt := time.Date(2024, 4, 10, 15, 0, 0, 0, time.UTC)
+	paid := Money{Amount: 200_00, Currency: "EUR"}
+	flexible := Price{Refundable: true, Cancellation: CancellationPolicy{FreeCancellationDays: 3, LateFeePercent: 25}}
+
+	tests := []struct {
+		name        string
+		price       Price
+		cancelledAt time.Time
+		expected    int64
+	}{
+		{"Free cancellation", flexible, checkInAt.AddDate(0, 0, -5), 200_00},
+		{"Last free moment", flexible, checkInAt.AddDate(0, 0, -3), 200_00},
+		{"Late fee", flexible, checkInAt.AddDate(0, 0, -1), 150_00},
+		{"After check-in", flexible, checkInAt.Add(time.Hour), 150_00},
+		{"No policy", Price{Refundable: true}, checkInAt.Add(time.Hour), 200_00},
+		{"Free until check-in", Price{Refundable: true, Cancellation: CancellationPolicy{LateFeePercent: 10}}, checkInAt, 200_00},
+		{"No show", Price{Refundable: true, Cancellation: CancellationPolicy{LateFeePercent: 10}}, checkInAt.Add(time.Minute), 180_00},
+		{"Whole fee", Price{Refundable: true, Cancellation: CancellationPolicy{LateFeePercent: 150}}, checkInAt.Add(time.Minute), 0},
+		{"Non-refundable", Price{}, checkInAt.AddDate(0, 0, -30), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := tt.price.RefundableAmount(paid, checkInAt, tt.cancelledAt)
+			assert.Equal(t, Money{Amount: tt.expected, Currency: "EUR"}, amount)
+		})
+	}
+}
+
+func TestOrder_NetPaid(t *testing.T) {
+	eur := func(amount int64) Money { return Money{Amount: amount, Currency: "EUR"} }
+
+	order := Order{Status: Booked, Price: Price{Total: eur(300_00)}}
+	assert.Equal(t, eur(0), order.NetPaid())
+
+	order.Status = Paid
+	order.PaidAmount = eur(250_00)
+	assert.Equal(t, eur(250_00), order.NetPaid(), "not paid part of price of modified paid order is not counted")
+
+	order.Status = DepositPaid
+	order.PaidAmount = eur(90_00)
+	order.Refunds = []Refund{
+		{Amount: eur(20_00), Status: RefundSucceeded},
+		{Amount: eur(10_00), Status: RefundPending},
+		{Amount: eur(50_00), Status: RefundFailed},
+		{Amount: eur(30_00), Status: RefundPartial, RefundedAmount: eur(5_00)},
+	}
+	assert.Equal(t, eur(55_00), order.NetPaid(), "failed refunds and not returned part of partial ones stay paid")
+
+	order.Status = Cancelled
+	assert.Equal(t, eur(55_00), order.NetPaid())
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+var ErrStayRestricted = errors.New("stay is restricted")
+
+type RestrictionReason string
+
+const (
+	MinStayReason           RestrictionReason = "min_stay"
+	MaxStayReason           RestrictionReason = "max_stay"
+	ClosedToArrivalReason   RestrictionReason = "closed_to_arrival"
+	ClosedToDepartureReason RestrictionReason = "closed_to_departure"
+)
+
+// StayRestrictions - restrictions of room-day. Min and max stay are checked for every night of stay,
+// so "minimum 3 nights on weekends" is set on weekend room-days.
+type StayRestrictions struct {
+	MinStay           int  `json:"min_stay,omitempty"` // nights, 0 - no restriction
+	MaxStay           int  `json:"max_stay,omitempty"` // nights, 0 - no restriction
+	ClosedToArrival   bool `json:"closed_to_arrival,omitempty"`
+	ClosedToDeparture bool `json:"closed_to_departure,omitempty"`
+}
+
+// StayRestrictionsUpdate - set restrictions of hotel room type for every day of period.
+type StayRestrictionsUpdate struct {
+	HotelID      int              `json:"hotel_id"`
+	RoomTypeID   int              `json:"room_type_id"`
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	Restrictions StayRestrictions `json:"restrictions"`
+}
+
+// StayRestrictionError - stay violates restriction of room-day.
+type StayRestrictionError struct {
+	Reason RestrictionReason
+	Date   time.Time
+	Limit  int
+}
+
+func (e StayRestrictionError) Error() string {
+	date := e.Date.Format("2006-01-02")
+
+	switch e.Reason {
+	case MinStayReason:
+		return fmt.Sprintf("%s: minimum stay is %d nights on %s", e.Reason, e.Limit, date)
+	case MaxStayReason:
+		return fmt.Sprintf("%s: maximum stay is %d nights on %s", e.Reason, e.Limit, date)
+	case ClosedToArrivalReason:
+		return fmt.Sprintf("%s: arrival is not allowed on %s", e.Reason, date)
+	case ClosedToDepartureReason:
+		return fmt.Sprintf("%s: departure is not allowed on %s", e.Reason, date)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Reason, date)
+}
+
+func (e StayRestrictionError) Is(target error) bool {
+	return target == ErrStayRestricted
+}
+
+func (r StayRestrictions) Validate() error {
+	switch {
+	case r.MinStay < 0 || r.MaxStay < 0:
+		return errors.New("min and max stay must not be negative")
+	case r.MaxStay != 0 && r.MinStay > r.MaxStay:
+		return errors.New("min stay must not be greater than max stay")
+	}
+
+	return nil
+}
+
+// CheckStayRestrictions checks restrictions of stay nights and departure room-day, departure is nil when
+// there is no room-day for departure date. Returns StayRestrictionError of the first violated restriction.
+func CheckStayRestrictions(nights []RoomAvailability, arrival time.Time, departure *RoomAvailability) error {
+	stay := len(nights)
+
+	// violations are reported for the earliest night of stay
+	nights = append([]RoomAvailability(nil), nights...)
+	sort.Slice(nights, func(i, j int) bool { return nights[i].Date.Before(nights[j].Date) })
+
+	for _, night := range nights {
+		if util.ToDay(night.Date).Equal(util.ToDay(arrival)) && night.ClosedToArrival {
+			return StayRestrictionError{Reason: ClosedToArrivalReason, Date: night.Date}
+		}
+
+		if night.MinStay != 0 && stay < night.MinStay {
+			return StayRestrictionError{Reason: MinStayReason, Date: night.Date, Limit: night.MinStay}
+		}
+
+		if night.MaxStay != 0 && stay > night.MaxStay {
+			return StayRestrictionError{Reason: MaxStayReason, Date: night.Date, Limit: night.MaxStay}
+		}
+	}
+
+	if departure != nil && departure.ClosedToDeparture {
+		return StayRestrictionError{Reason: ClosedToDepartureReason, Date: departure.Date}
+	}
+
+	return nil
+}
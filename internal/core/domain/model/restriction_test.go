@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+func TestCheckStayRestrictions(t *testing.T) {
+	night := func(day int, restrictions StayRestrictions) RoomAvailability {
+		return RoomAvailability{Date: util.NewDay(2024, 4, day), StayRestrictions: restrictions}
+	}
+
+	weekend := []RoomAvailability{
+		night(4, StayRestrictions{}),
+		night(5, StayRestrictions{MinStay: 3}),
+		night(6, StayRestrictions{MinStay: 3, ClosedToArrival: true}),
+	}
+
+	assert.NoError(t, CheckStayRestrictions(weekend, util.NewDay(2024, 4, 4), nil))
+	assert.NoError(t, CheckStayRestrictions(weekend[:1], util.NewDay(2024, 4, 4), nil), "restriction of other night")
+
+	err := CheckStayRestrictions(weekend[1:], util.NewDay(2024, 4, 5), nil)
+	assert.ErrorIs(t, err, ErrStayRestricted)
+	assert.Equal(t, StayRestrictionError{Reason: MinStayReason, Date: util.NewDay(2024, 4, 5), Limit: 3}, err)
+	assert.EqualError(t, err, "min_stay: minimum stay is 3 nights on 2024-04-05")
+
+	err = CheckStayRestrictions(weekend[2:], util.NewDay(2024, 4, 6), nil)
+	assert.Equal(t, StayRestrictionError{Reason: ClosedToArrivalReason, Date: util.NewDay(2024, 4, 6)}, err)
+
+	err = CheckStayRestrictions([]RoomAvailability{night(1, StayRestrictions{MaxStay: 1}), night(2, StayRestrictions{})}, util.NewDay(2024, 4, 1), nil)
+	assert.Equal(t, StayRestrictionError{Reason: MaxStayReason, Date: util.NewDay(2024, 4, 1), Limit: 1}, err)
+
+	departure := night(7, StayRestrictions{ClosedToDeparture: true})
+	err = CheckStayRestrictions(weekend, util.NewDay(2024, 4, 4), &departure)
+	assert.Equal(t, StayRestrictionError{Reason: ClosedToDepartureReason, Date: util.NewDay(2024, 4, 7)}, err)
+}
+
+func TestStayRestrictions_Validate(t *testing.T) {
+	assert.NoError(t, StayRestrictions{MinStay: 2, MaxStay: 7}.Validate())
+	assert.NoError(t, StayRestrictions{MinStay: 2}.Validate())
+	assert.Error(t, StayRestrictions{MinStay: -1}.Validate())
+	assert.Error(t, StayRestrictions{MinStay: 3, MaxStay: 2}.Validate())
+}
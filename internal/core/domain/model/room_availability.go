@@ -9,5 +9,13 @@ type RoomAvailability struct {
 	HotelID    int       `json:"hotel_id"`
 	RoomTypeID int       `json:"room_type_id"`
 	Date       time.Time `json:"date"`
-	Quota      int       `json:"quota"`
+	Quota      int       `json:"quota"`    // rooms left to sell, negative when room-day is overbooked
+	Capacity   int       `json:"capacity"` // physical rooms
+
+	StayRestrictions
+}
+
+// Sold returns rooms which are sold for room-day.
+func (r RoomAvailability) Sold() int {
+	return r.Capacity - r.Quota
 }
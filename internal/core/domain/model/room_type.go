@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrRoomTypeInvalid = errors.New("invalid room type")
+	ErrUnknownRoomType = errors.New("unknown room type")
+)
+
+type RoomTypeID = int
+
+// RoomTypeKey - room type IDs are unique within hotel only.
+type RoomTypeKey struct {
+	HotelID    HotelID
+	RoomTypeID RoomTypeID
+}
+
+type RoomType struct {
+	ID        RoomTypeID `json:"id"`
+	HotelID   HotelID    `json:"hotel_id"`
+	Name      string     `json:"name"`
+	Capacity  int        `json:"capacity"` // max guests in room
+	Amenities []string   `json:"amenities,omitempty"`
+}
+
+func (t RoomType) Key() RoomTypeKey {
+	return RoomTypeKey{HotelID: t.HotelID, RoomTypeID: t.ID}
+}
+
+func (t RoomType) Validate() error {
+	if t.ID <= 0 || t.HotelID <= 0 {
+		return fmt.Errorf("%w: IDs must be positive integers", ErrRoomTypeInvalid)
+	}
+
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrRoomTypeInvalid)
+	}
+
+	if t.Capacity <= 0 {
+		return fmt.Errorf("%w: capacity must be positive integer", ErrRoomTypeInvalid)
+	}
+
+	return nil
+}
+
+// CheckOccupancy - ErrOccupancyExceeded if guests do not fit into room.
+func (t RoomType) CheckOccupancy(occupancy Occupancy) error {
+	if occupancy.Guests() > t.Capacity {
+		return fmt.Errorf("%w: %d guests, room type %q fits %d", ErrOccupancyExceeded, occupancy.Guests(), t.Name, t.Capacity)
+	}
+
+	return nil
+}
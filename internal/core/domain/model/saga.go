@@ -0,0 +1,162 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrSagaNotStuck = errors.New("saga is not stuck")
+
+type SagaStep string
+
+const (
+	BookStep    SagaStep = "book"    // price the order and reserve quota
+	PaymentStep SagaStep = "payment" // wait for result of payment request
+	NotifyStep  SagaStep = "notify"  // request notification of the guest about payment
+	BalanceStep SagaStep = "balance" // wait for balance of deposit paid order until its schedule is paid
+	ReleaseStep SagaStep = "release" // compensation: return quota, promo code and loyalty points of not paid order
+	RefundStep  SagaStep = "refund"  // compensation: return money paid after booking is released
+)
+
+type SagaStatus string
+
+const (
+	SagaRunning      SagaStatus = "running"
+	SagaCompensating SagaStatus = "compensating"
+	SagaCompleted    SagaStatus = "completed"   // order is paid in full and guest is notified
+	SagaFailed       SagaStatus = "failed"      // order is not booked, there is nothing to compensate
+	SagaCompensated  SagaStatus = "compensated" // booking is released and paid money is returned
+)
+
+// Saga - state of booking flow of the order: book, payment, notification and balance steps, and compensations of the
+// booking if it is not paid. Every step must be done before its deadline, timed out step is compensated
+// or makes saga stuck until it is resumed.
+type Saga struct {
+	OrderID OrderID    `json:"order_id"`
+	Status  SagaStatus `json:"status"`
+	Step    SagaStep   `json:"step"`
+
+	StepStartedAt time.Time `json:"step_started_at"`
+	Deadline      time.Time `json:"deadline,omitempty"` // zero - step has no timeout, e.g. order is waitlisted
+	Attempts      int       `json:"attempts"`           // of the current step, resumed step is attempted again
+	StuckAt       time.Time `json:"stuck_at,omitempty"` // zero - saga is not stuck
+	Error         string    `json:"error,omitempty"`    // why the last attempt of the step failed
+
+	PaymentID uuid.UUID `json:"payment_id,omitempty"` // requested payment, or payment which is refunded by compensation
+
+	CompensationReason string    `json:"compensation_reason,omitempty"`
+	ReleaseStatus      Status    `json:"release_status,omitempty"` // final status of order released by compensation
+	RefundID           uuid.UUID `json:"refund_id,omitempty"`
+	RefundAmount       Money     `json:"refund_amount,omitempty"`
+
+	History []SagaTransition `json:"history"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SagaTransition - entry of saga history: step which saga moved to or result of its attempt.
+type SagaTransition struct {
+	Step   SagaStep   `json:"step"`
+	Status SagaStatus `json:"status"`
+	At     time.Time  `json:"at"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+// SagaResumption - request to attempt the current step of stuck saga of the order again.
+type SagaResumption struct {
+	OrderID   OrderID   `json:"order_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewSaga starts saga of new order with book step.
+func NewSaga(orderID OrderID, at time.Time, timeout time.Duration) Saga {
+	saga := Saga{OrderID: orderID, CreatedAt: at}
+	saga.MoveTo(BookStep, SagaRunning, at, timeout, "order is received")
+
+	return saga
+}
+
+func (s Saga) IsFinished() bool {
+	return s.Status == SagaCompleted || s.Status == SagaFailed || s.Status == SagaCompensated
+}
+
+func (s Saga) IsStuck() bool {
+	return !s.StuckAt.IsZero()
+}
+
+// IsTimedOut reports whether step of not finished saga is not done before its deadline.
+func (s Saga) IsTimedOut(at time.Time) bool {
+	return !s.IsFinished() && !s.Deadline.IsZero() && !at.Before(s.Deadline)
+}
+
+// MoveTo starts step of saga, zero timeout - step waits without deadline.
+func (s *Saga) MoveTo(step SagaStep, status SagaStatus, at time.Time, timeout time.Duration, detail string) {
+	s.Step = step
+	s.Status = status
+	s.StepStartedAt = at
+	s.Deadline = deadline(at, timeout)
+	s.Attempts = 1
+	s.StuckAt = time.Time{}
+	s.Error = ""
+
+	s.record(at, detail)
+}
+
+// Finish ends saga in the current step.
+func (s *Saga) Finish(status SagaStatus, at time.Time, detail string) {
+	s.Status = status
+	s.Deadline = time.Time{}
+	s.StuckAt = time.Time{}
+	s.Error = ""
+
+	s.record(at, detail)
+}
+
+// Fail records failed attempt of the current step, saga waits for the step until its deadline.
+func (s *Saga) Fail(at time.Time, reason string) {
+	s.Error = reason
+
+	s.record(at, "attempt failed: "+reason)
+}
+
+// MarkStuck stops waiting for the current step, it is attempted again only if saga is resumed.
+func (s *Saga) MarkStuck(at time.Time, reason string) {
+	s.StuckAt = at
+	s.Deadline = time.Time{}
+	s.Error = reason
+
+	s.record(at, "stuck: "+reason)
+}
+
+// Resume attempts the current step of stuck saga again.
+func (s *Saga) Resume(at time.Time, timeout time.Duration) error {
+	if !s.IsStuck() {
+		return ErrSagaNotStuck
+	}
+
+	s.StuckAt = time.Time{}
+	s.Deadline = deadline(at, timeout)
+	s.Attempts++
+
+	s.record(at, "resumed")
+
+	return nil
+}
+
+func (s *Saga) record(at time.Time, detail string) {
+	s.UpdatedAt = at
+	// history is copied on append, so the original saga is kept untouched
+	s.History = append(slices.Clip(s.History), SagaTransition{Step: s.Step, Status: s.Status, At: at, Detail: detail})
+}
+
+func deadline(at time.Time, timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+
+	return at.Add(timeout)
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaga(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	saga := NewSaga(uuid.New(), now, time.Minute)
+	assert.Equal(t, BookStep, saga.Step)
+	assert.Equal(t, SagaRunning, saga.Status)
+	assert.Equal(t, now.Add(time.Minute), saga.Deadline)
+	assert.Equal(t, 1, saga.Attempts)
+	require.Len(t, saga.History, 1)
+
+	assert.False(t, saga.IsTimedOut(now.Add(time.Second)))
+	assert.True(t, saga.IsTimedOut(now.Add(time.Minute)))
+
+	// stuck step waits without deadline until it is resumed
+	booked := saga
+	booked.Fail(now, "no connection")
+	assert.Equal(t, "no connection", booked.Error)
+	assert.False(t, booked.IsStuck())
+
+	booked.MarkStuck(now.Add(time.Minute), "book step timed out")
+	assert.True(t, booked.IsStuck())
+	assert.False(t, booked.IsTimedOut(now.Add(time.Hour)))
+	assert.Len(t, saga.History, 1, "history of original saga is kept")
+
+	require.NoError(t, booked.Resume(now.Add(time.Hour), time.Minute))
+	assert.False(t, booked.IsStuck())
+	assert.Equal(t, 2, booked.Attempts)
+	assert.Equal(t, now.Add(time.Hour+time.Minute), booked.Deadline)
+	assert.ErrorIs(t, booked.Resume(now.Add(time.Hour), time.Minute), ErrSagaNotStuck)
+
+	// next step is attempted from scratch
+	booked.MoveTo(PaymentStep, SagaRunning, now.Add(time.Hour), 0, "order is booked")
+	assert.Equal(t, 1, booked.Attempts)
+	assert.Empty(t, booked.Error)
+	assert.True(t, booked.Deadline.IsZero())
+	assert.False(t, booked.IsTimedOut(now.AddDate(1, 0, 0)), "step without timeout")
+
+	booked.Finish(SagaCompleted, now.Add(2*time.Hour), "guest is notified")
+	assert.True(t, booked.IsFinished())
+	assert.Equal(t, now.Add(2*time.Hour), booked.UpdatedAt)
+
+	steps := make([]string, 0, len(booked.History))
+	for _, transition := range booked.History {
+		steps = append(steps, string(transition.Step)+" "+transition.Detail)
+	}
+	assert.Equal(t, []string{
+		"book order is received",
+		"book attempt failed: no connection",
+		"book stuck: book step timed out",
+		"book resumed",
+		"payment order is booked",
+		"payment guest is notified",
+	}, steps)
+}
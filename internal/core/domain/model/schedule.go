@@ -0,0 +1,162 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownPayment   = errors.New("payment is not requested for the order")
+	ErrDuplicatePayment = errors.New("payment is already applied to the order")
+	ErrReplacedPayment  = errors.New("payment is replaced by payment of new price of the order")
+)
+
+type InstallmentKind string
+
+const (
+	DepositInstallment InstallmentKind = "deposit"
+	BalanceInstallment InstallmentKind = "balance"
+)
+
+// Installment - part of order price which is charged by own payment.
+type Installment struct {
+	Kind          InstallmentKind `json:"kind"`
+	PaymentID     uuid.UUID       `json:"payment_id"` // ID of payment request of the installment
+	Amount        Money           `json:"amount"`
+	DueAt         time.Time       `json:"due_at"`
+	RequestedAt   time.Time       `json:"requested_at,omitempty"` // zero - payment is not requested yet
+	PaidAt        time.Time       `json:"paid_at,omitempty"`      // zero - not paid
+	FailureReason string          `json:"failure_reason,omitempty"`
+	Failures      int             `json:"failures,omitempty"` // failed payments of the installment
+}
+
+func (i Installment) IsPaid() bool {
+	return !i.PaidAt.IsZero()
+}
+
+// OrderPayment - payment requested for the order apart from its payment schedule: total price of the order
+// or price difference of modified order.
+type OrderPayment struct {
+	ID          uuid.UUID `json:"id"`
+	Amount      Money     `json:"amount"`
+	RequestedAt time.Time `json:"requested_at"`
+	PaidAt      time.Time `json:"paid_at,omitempty"`     // zero - not paid
+	ReplacedAt  time.Time `json:"replaced_at,omitempty"` // not zero - price changed before payment, it is not accepted
+}
+
+// ApplyPayment returns order with installment or requested payment of paymentID marked as paid at paidAt and
+// amount added to paid amount. Schedule and payments are copied, the order itself is kept untouched.
+// ErrUnknownPayment if the payment is not requested for the order, ErrDuplicatePayment if it is already paid.
+// Replaced payment is recorded as paid too, but ErrReplacedPayment is returned: its money must be returned.
+func (o Order) ApplyPayment(paymentID uuid.UUID, amount Money, paidAt time.Time) (Order, error) {
+	o.PaymentSchedule = append([]Installment(nil), o.PaymentSchedule...)
+	o.Payments = append([]OrderPayment(nil), o.Payments...)
+
+	found, replaced := false, false
+	for i := range o.PaymentSchedule {
+		if o.PaymentSchedule[i].PaymentID != paymentID {
+			continue
+		}
+		if o.PaymentSchedule[i].IsPaid() {
+			return o, ErrDuplicatePayment
+		}
+
+		o.PaymentSchedule[i].PaidAt = paidAt
+		o.PaymentSchedule[i].FailureReason = ""
+		found = true
+	}
+
+	for i := range o.Payments {
+		if o.Payments[i].ID != paymentID {
+			continue
+		}
+		if !o.Payments[i].PaidAt.IsZero() {
+			return o, ErrDuplicatePayment
+		}
+
+		o.Payments[i].PaidAt = paidAt
+		found = true
+		replaced = !o.Payments[i].ReplacedAt.IsZero()
+	}
+
+	if !found {
+		return o, ErrUnknownPayment
+	}
+
+	paidAmount, err := o.PaidAmount.Add(amount)
+	if err != nil {
+		return o, err
+	}
+	o.PaidAmount = paidAmount
+
+	if replaced {
+		return o, ErrReplacedPayment
+	}
+
+	return o, nil
+}
+
+// IsReplacedPayment reports whether payment of the order is replaced by payment of its new price.
+func (o Order) IsReplacedPayment(paymentID uuid.UUID) bool {
+	for _, payment := range o.Payments {
+		if payment.ID == paymentID {
+			return !payment.ReplacedAt.IsZero()
+		}
+	}
+
+	return false
+}
+
+// NewPaymentSchedule splits total into deposit due now and balance due at balanceDueAt. Returns nil if total is paid
+// at once: there is no deposit or balance would be due already.
+func NewPaymentSchedule(total Money, depositPercent int, now, balanceDueAt time.Time) []Installment {
+	deposit := total.Percent(depositPercent)
+	if deposit.Amount <= 0 || deposit.Amount >= total.Amount || !balanceDueAt.After(now) {
+		return nil
+	}
+
+	balance, err := total.Sub(deposit)
+	if err != nil {
+		return nil
+	}
+
+	return []Installment{
+		{Kind: DepositInstallment, PaymentID: uuid.New(), Amount: deposit, DueAt: now},
+		{Kind: BalanceInstallment, PaymentID: uuid.New(), Amount: balance, DueAt: balanceDueAt},
+	}
+}
+
+// AmountDue - amount which must be paid to keep the booking: the first not paid installment or total price.
+func (o Order) AmountDue() Money {
+	for _, installment := range o.PaymentSchedule {
+		if !installment.IsPaid() {
+			return installment.Amount
+		}
+	}
+
+	return o.Price.Total
+}
+
+// Balance returns not paid balance installment of the order, nil if there is none.
+func (o Order) Balance() *Installment {
+	for i := range o.PaymentSchedule {
+		if o.PaymentSchedule[i].Kind == BalanceInstallment && !o.PaymentSchedule[i].IsPaid() {
+			return &o.PaymentSchedule[i]
+		}
+	}
+
+	return nil
+}
+
+// IsSchedulePaid reports whether all installments of the schedule are paid.
+func IsSchedulePaid(schedule []Installment) bool {
+	for _, installment := range schedule {
+		if !installment.IsPaid() {
+			return false
+		}
+	}
+
+	return true
+}
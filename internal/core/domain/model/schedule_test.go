@@ -0,0 +1,95 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPaymentSchedule(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	dueAt := now.AddDate(0, 0, 20)
+	total := Money{Amount: 100_01, Currency: "EUR"}
+
+	schedule := NewPaymentSchedule(total, 30, now, dueAt)
+	require.Len(t, schedule, 2)
+	assert.Equal(t, DepositInstallment, schedule[0].Kind)
+	assert.Equal(t, Money{Amount: 30_00, Currency: "EUR"}, schedule[0].Amount)
+	assert.Equal(t, now, schedule[0].DueAt)
+	assert.Equal(t, BalanceInstallment, schedule[1].Kind)
+	assert.Equal(t, Money{Amount: 70_01, Currency: "EUR"}, schedule[1].Amount)
+	assert.Equal(t, dueAt, schedule[1].DueAt)
+	assert.NotEqual(t, schedule[0].PaymentID, schedule[1].PaymentID)
+
+	assert.Nil(t, NewPaymentSchedule(total, 0, now, dueAt), "no deposit")
+	assert.Nil(t, NewPaymentSchedule(total, 100, now, dueAt), "deposit is whole price")
+	assert.Nil(t, NewPaymentSchedule(total, 30, now, now), "balance is already due")
+}
+
+func TestOrder_AmountDue(t *testing.T) {
+	order := Order{Price: Price{Total: Money{Amount: 100_00, Currency: "EUR"}}}
+	assert.Equal(t, order.Price.Total, order.AmountDue())
+	assert.Nil(t, order.Balance())
+
+	now := time.Now()
+	order.PaymentSchedule = NewPaymentSchedule(order.Price.Total, 20, now, now.Add(time.Hour))
+	assert.Equal(t, Money{Amount: 20_00, Currency: "EUR"}, order.AmountDue())
+	assert.False(t, IsSchedulePaid(order.PaymentSchedule))
+
+	order.PaymentSchedule[0].PaidAt = now
+	assert.Equal(t, Money{Amount: 80_00, Currency: "EUR"}, order.AmountDue())
+	require.NotNil(t, order.Balance())
+	assert.Equal(t, BalanceInstallment, order.Balance().Kind)
+
+	order.PaymentSchedule[1].PaidAt = now
+	assert.True(t, IsSchedulePaid(order.PaymentSchedule))
+	assert.Nil(t, order.Balance())
+}
+
+func TestOrder_ApplyPayment(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	order := Order{
+		Price:      Price{Total: Money{Amount: 100_00, Currency: "EUR"}},
+		PaidAmount: Money{Currency: "EUR"},
+	}
+	order.PaymentSchedule = NewPaymentSchedule(order.Price.Total, 20, now, now.AddDate(0, 0, 20))
+	order.Payments = []OrderPayment{{ID: uuid.New(), Amount: Money{Amount: 10_00, Currency: "EUR"}, RequestedAt: now}}
+
+	deposit := order.PaymentSchedule[0]
+	paid, err := order.ApplyPayment(deposit.PaymentID, deposit.Amount, now)
+	require.NoError(t, err)
+	assert.True(t, paid.PaymentSchedule[0].IsPaid())
+	assert.Equal(t, deposit.Amount, paid.PaidAmount)
+	assert.False(t, order.PaymentSchedule[0].IsPaid(), "schedule of original order is kept")
+
+	_, err = paid.ApplyPayment(deposit.PaymentID, deposit.Amount, now)
+	assert.ErrorIs(t, err, ErrDuplicatePayment)
+
+	paid, err = paid.ApplyPayment(order.Payments[0].ID, order.Payments[0].Amount, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, paid.Payments[0].PaidAt)
+	assert.Equal(t, Money{Amount: 30_00, Currency: "EUR"}, paid.PaidAmount)
+	assert.True(t, order.Payments[0].PaidAt.IsZero(), "payments of original order are kept")
+
+	_, err = paid.ApplyPayment(order.Payments[0].ID, order.Payments[0].Amount, now)
+	assert.ErrorIs(t, err, ErrDuplicatePayment)
+
+	_, err = paid.ApplyPayment(uuid.New(), deposit.Amount, now)
+	assert.ErrorIs(t, err, ErrUnknownPayment)
+
+	replaced := OrderPayment{ID: uuid.New(), Amount: Money{Amount: 5_00, Currency: "EUR"}, RequestedAt: now, ReplacedAt: now}
+	paid.Payments = append(paid.Payments, replaced)
+	assert.True(t, paid.IsReplacedPayment(replaced.ID))
+	assert.False(t, paid.IsReplacedPayment(order.Payments[0].ID))
+
+	paid, err = paid.ApplyPayment(replaced.ID, replaced.Amount, now)
+	assert.ErrorIs(t, err, ErrReplacedPayment)
+	assert.Equal(t, now, paid.Payments[1].PaidAt, "replaced payment is recorded, so it is refunded once")
+	assert.Equal(t, Money{Amount: 35_00, Currency: "EUR"}, paid.PaidAmount)
+
+	_, err = paid.ApplyPayment(replaced.ID, replaced.Amount, now)
+	assert.ErrorIs(t, err, ErrDuplicatePayment)
+}
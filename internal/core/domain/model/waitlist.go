@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistFulfilled WaitlistStatus = "fulfilled" // order is booked from waitlist
+	WaitlistExpired   WaitlistStatus = "expired"   // stay is already started
+	WaitlistFailed    WaitlistStatus = "failed"    // order can not be booked anymore, e.g. promo code is exhausted
+)
+
+// WaitlistEntry - request to book the order when quota of hotel room type is released for its period.
+type WaitlistEntry struct {
+	ID         uuid.UUID      `json:"id"`
+	OrderID    OrderID        `json:"order_id"`
+	UserEmail  string         `json:"email"`
+	HotelID    int            `json:"hotel_id"`
+	RoomTypeID int            `json:"room_type_id"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Status     WaitlistStatus `json:"status"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Position   int            `json:"position"` // order of joining the waitlist, assigned by storage
+}
+
+// WaitlistRequest - request to put order which ended without rooms into waitlist.
+type WaitlistRequest struct {
+	OrderID   OrderID   `json:"order_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InventoryUpdate - change of room quota for hotel room type and period.
+type InventoryUpdate struct {
+	HotelID    int       `json:"hotel_id"`
+	RoomTypeID int       `json:"room_type_id"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Delta      int       `json:"delta"`
+}
+
+// OrdersExpiration - request to expire booked orders which are not paid in time.
+type OrdersExpiration struct {
+	At time.Time `json:"at"`
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+var ErrWebhookSubscriptionInvalid = errors.New("invalid webhook subscription")
+
+type WebhookSubscriptionID = uuid.UUID
+
+// WebhookSubscription - partner endpoint which receives order status changes signed by Secret.
+type WebhookSubscription struct {
+	ID        WebhookSubscriptionID `json:"id"`
+	Partner   string                `json:"partner"`
+	URL       string                `json:"url"`
+	Secret    string                `json:"secret,omitempty"`   // generated if empty, shown only on creation
+	Statuses  []Status              `json:"statuses,omitempty"` // order statuses to deliver, empty - all
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+func (s WebhookSubscription) Validate() error {
+	if s.Partner == "" {
+		return fmt.Errorf("%w: partner is required", ErrWebhookSubscriptionInvalid)
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be absolute http(s) URL", ErrWebhookSubscriptionInvalid)
+	}
+
+	if !isPublicHost(u.Hostname()) {
+		return fmt.Errorf("%w: url must not point to local or private network", ErrWebhookSubscriptionInvalid)
+	}
+
+	for _, status := range s.Statuses {
+		if !slices.Contains(allStatuses[:], status) {
+			return fmt.Errorf("%w: unknown order status %q", ErrWebhookSubscriptionInvalid, status)
+		}
+	}
+
+	return nil
+}
+
+// isPublicHost - host is not a local name or not public IP address. Names are resolved only on delivery,
+// so sender checks the resolved address once more.
+func isPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return util.IsPublicAddr(addr)
+	}
+
+	return true
+}
+
+// Matches reports whether order status change to status is delivered to subscription.
+func (s WebhookSubscription) Matches(status Status) bool {
+	return len(s.Statuses) == 0 || slices.Contains(s.Statuses, status)
+}
+
+// OrderStatusChange - order has got new status. Partners get it as OrderWebhook.
+type OrderStatusChange struct {
+	EventID        uuid.UUID `json:"event_id"` // the same for all deliveries of the change, partner can deduplicate by it
+	OrderID        OrderID   `json:"order_id"`
+	PreviousStatus Status    `json:"previous_status"`
+	Status         Status    `json:"status"`
+	ChangedAt      time.Time `json:"changed_at"` // retried deliveries may come out of order, partner can order by it
+	Order          Order     `json:"order"`
+}
+
+// OrderWebhook - payload of order webhooks. It identifies the order of partner and its status,
+// guest data and price of the order are not sent.
+type OrderWebhook struct {
+	EventID        uuid.UUID `json:"event_id"` // the same for all deliveries of the change, partner can deduplicate by it
+	OrderID        OrderID   `json:"order_id"`
+	BlockCode      string    `json:"block_code"` // allotment of partner which the order is booked from
+	PreviousStatus Status    `json:"previous_status"`
+	Status         Status    `json:"status"`
+	CreatedAt      time.Time `json:"created_at"` // of the order
+	ChangedAt      time.Time `json:"changed_at"` // retried deliveries may come out of order, partner can order by it
+}
+
+func NewOrderWebhook(change OrderStatusChange) OrderWebhook {
+	return OrderWebhook{
+		EventID:        change.EventID,
+		OrderID:        change.OrderID,
+		BlockCode:      change.Order.BlockCode,
+		PreviousStatus: change.PreviousStatus,
+		Status:         change.Status,
+		CreatedAt:      change.Order.CreatedAt,
+		ChangedAt:      change.ChangedAt,
+	}
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending" // waits for next attempt
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	DeliveryFailed    WebhookDeliveryStatus = "failed" // all attempts are failed
+)
+
+// WebhookDelivery - delivery log record of one event to one subscription.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	SubscriptionID WebhookSubscriptionID `json:"subscription_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	OrderID        OrderID               `json:"order_id"`
+	Payload        json.RawMessage       `json:"payload"` // signed body, the same for all attempts
+	RedeliveryOf   uuid.UUID             `json:"redelivery_of,omitempty"`
+
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	ResponseCode  int                   `json:"response_code,omitempty"` // of the last attempt
+	LastError     string                `json:"last_error,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	NextAttemptAt time.Time             `json:"next_attempt_at,omitempty"`
+	DeliveredAt   time.Time             `json:"delivered_at,omitempty"`
+}
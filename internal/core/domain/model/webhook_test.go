@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSubscription_Validate(t *testing.T) {
+	assert.NoError(t, WebhookSubscription{Partner: "ota", URL: "https://ota.example.com/hooks"}.Validate())
+	assert.NoError(t, WebhookSubscription{Partner: "ota", URL: "http://93.184.216.34:9000", Statuses: []Status{Paid, Cancelled}}.Validate())
+
+	assert.ErrorIs(t, WebhookSubscription{URL: "https://ota.example.com/hooks"}.Validate(), ErrWebhookSubscriptionInvalid)
+	assert.ErrorIs(t, WebhookSubscription{Partner: "ota", URL: "ota.example.com/hooks"}.Validate(), ErrWebhookSubscriptionInvalid)
+	assert.ErrorIs(t, WebhookSubscription{Partner: "ota", URL: "ftp://ota.example.com"}.Validate(), ErrWebhookSubscriptionInvalid)
+	assert.ErrorIs(t, WebhookSubscription{Partner: "ota", URL: "https://ota.example.com", Statuses: []Status{"refunded"}}.Validate(), ErrWebhookSubscriptionInvalid)
+
+	for _, url := range []string{
+		"http://localhost:9000", "http://api.localhost", "http://127.0.0.1/hooks", "http://10.0.0.5",
+		"http://169.254.169.254/latest/meta-data", "http://[::1]:8080", "http://[fe80::1]",
+	} {
+		assert.ErrorIs(t, WebhookSubscription{Partner: "ota", URL: url}.Validate(), ErrWebhookSubscriptionInvalid, url)
+	}
+}
+
+func TestNewOrderWebhook(t *testing.T) {
+	change := OrderStatusChange{
+		OrderID: uuid.New(), PreviousStatus: Booked, Status: Paid,
+		Order: Order{BlockCode: "EXPO", UserEmail: "guest@example.com", Guest: Guest{Name: "Ada Lovelace"}},
+	}
+
+	payload, err := json.Marshal(NewOrderWebhook(change))
+	require.NoError(t, err)
+	assert.Contains(t, string(payload), `"block_code":"EXPO"`)
+	assert.Contains(t, string(payload), `"status":"paid"`)
+	assert.NotContains(t, string(payload), "guest")
+	assert.NotContains(t, string(payload), "Ada Lovelace")
+}
+
+func TestWebhookSubscription_Matches(t *testing.T) {
+	assert.True(t, WebhookSubscription{}.Matches(Booked), "all statuses by default")
+
+	paid := WebhookSubscription{Statuses: []Status{Paid}}
+	assert.True(t, paid.Matches(Paid))
+	assert.False(t, paid.Matches(Booked))
+}
@@ -5,7 +5,22 @@ import "aplication-design-test-task/internal/core/domain/model"
 type (
 	ReservationOrderEvent = model.Order // todo can be dived to separate struct Event <-> DTO
 
+	CancelOrderEvent  = model.OrderCancellation
+	ModifyOrderEvent  = model.OrderModification
+	JoinWaitlistEvent = model.WaitlistRequest
+
+	InventoryUpdateEvent        = model.InventoryUpdate
+	StayRestrictionsUpdateEvent = model.StayRestrictionsUpdate
+	ExpireOrdersEvent           = model.OrdersExpiration
+	AllotmentEvent              = model.Allotment
+	NotificationRequest         = model.Notification
+	OrderStatusChangedEvent     = model.OrderStatusChange
+	ResumeSagaEvent             = model.SagaResumption
+
 	PaymentRequest = model.Payment
+	RefundRequest  = model.Refund
+
+	RefundProcessedEvent = model.Refund // refund with final status
 
 	// todo
 	SuccessPaymentEvent = model.SuccessPaymentEvent
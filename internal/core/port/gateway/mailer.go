@@ -0,0 +1,13 @@
+package gateway
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// Mailer - external service which delivers emails to guests.
+type Mailer interface {
+	// Send delivers email. Error means email is not accepted and sending may be retried.
+	Send(context.Context, model.Email) error
+}
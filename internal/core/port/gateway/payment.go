@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// PaymentGateway - external payment provider which charges payer of the order.
+type PaymentGateway interface {
+	// Charge captures amount of payment. Declined payment returns error wrapping model.ErrPaymentDeclined,
+	// other errors mean gateway is not available or does not answer in time.
+	Charge(context.Context, model.Payment) (model.Charge, error)
+
+	// Refund returns amount of charged payment to payer and returns reference of the refund in gateway.
+	// Declined refund returns error wrapping model.ErrRefundDeclined.
+	Refund(ctx context.Context, payment model.Payment, amount model.Money) (string, error)
+}
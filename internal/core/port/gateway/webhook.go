@@ -0,0 +1,10 @@
+package gateway
+
+import "context"
+
+// WebhookSender - delivers webhook requests to partner endpoints.
+type WebhookSender interface {
+	// Send posts JSON body with headers to url and returns status code of the response.
+	// Error means endpoint is not reachable or does not answer in time.
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
@@ -0,0 +1,261 @@
+package booking
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// AllotmentEventHandler - hold block of rooms for partner, rooms of every night [From, To) are removed from general quota.
+// Allotment is stored as rejected if its room type is not in catalog or some night has not enough quota.
+func (s *bookingService) AllotmentEventHandler(ctx context.Context, event events.AllotmentEvent) {
+	allotment := event
+	allotment.Code = model.NormalizeBlockCode(allotment.Code)
+	allotment.CreatedAt = s.now()
+
+	if err := allotment.Validate(allotment.CreatedAt); err != nil {
+		s.log.Error("[bookingService.AllotmentEventHandler] Invalid allotment: %v", err)
+		return
+	}
+
+	allotment.Status, allotment.Reason = model.AllotmentActive, ""
+
+	_, err := s.storage.GetRoomTypeRepo().GetRoomType(ctx, allotment.HotelID, allotment.RoomTypeID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.log.Error("[bookingService.AllotmentEventHandler] Failed to get room type: %v", err)
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		allotment.Status = model.AllotmentRejected
+		allotment.Reason = fmt.Sprintf("%v: %d in hotel %d", model.ErrUnknownRoomType, allotment.RoomTypeID, allotment.HotelID)
+	}
+
+	var rooms []RoomAvailability
+	if allotment.Status == model.AllotmentActive {
+		rooms, err = s.roomsForPeriod(ctx, allotment.HotelID, allotment.RoomTypeID, allotment.From, allotment.To)
+		if err != nil && !errors.Is(err, errNoRooms) {
+			s.log.Error("[bookingService.AllotmentEventHandler] Failed to retrieve rooms information: %v", err)
+			return
+		}
+
+		if errors.Is(err, errNoRooms) {
+			allotment.Status, allotment.Reason = model.AllotmentRejected, errNoRooms.Error()
+		}
+	}
+
+	slices.SortFunc(rooms, func(a, b RoomAvailability) int { return a.Date.Compare(b.Date) })
+
+	for _, room := range rooms {
+		if room.Quota < allotment.Rooms {
+			allotment.Status = model.AllotmentRejected
+			allotment.Reason = fmt.Sprintf("%d rooms left on %s", room.Quota, room.Date.Format(time.DateOnly))
+			break
+		}
+
+		allotment.Nights = append(allotment.Nights, model.AllotmentNight{Date: room.Date, Held: allotment.Rooms})
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.AllotmentEventHandler] Failed to start transaction: %v", err)
+		return
+	}
+
+	if allotment.Status == model.AllotmentActive {
+		change := newQuotaChange()
+		change.add(rooms, -allotment.Rooms)
+		s.applyQuotaChange(ctx, tx, change)
+	} else {
+		allotment.Nights = nil
+	}
+
+	tx.Execute(
+		func() error {
+			return s.storage.GetAllotmentRepo().StoreAllotment(ctx, allotment)
+		},
+		func() error {
+			return nil // store is the last operation, nothing to roll back
+		},
+	)
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.AllotmentEventHandler] Failed to commit transaction: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.AllotmentEventHandler] Allotment %s is %s: %+v", allotment.Code, allotment.Status, allotment)
+}
+
+// checkBlockCode - model.ErrBlockCodeMismatch if order can not be booked from allotment of its block code.
+func (s *bookingService) checkBlockCode(ctx context.Context, order ReservationOrder) error {
+	if order.BlockCode == "" {
+		return nil
+	}
+
+	allotment, err := s.storage.GetAllotmentRepo().GetAllotment(ctx, order.BlockCode)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%w: unknown block code %s", model.ErrBlockCodeMismatch, order.BlockCode)
+	}
+	if err != nil {
+		return err
+	}
+
+	return allotment.Accepts(order)
+}
+
+// bookFromAllotment - sell one room of every order night from allotment, general quota is not touched.
+func (s *bookingService) bookFromAllotment(ctx context.Context, order ReservationOrder) model.Status {
+	allotment, err := s.storage.GetAllotmentRepo().GetAllotment(ctx, order.BlockCode)
+	if err != nil {
+		s.log.Error("[bookingService.bookFromAllotment] Failed to get allotment %s: %v", order.BlockCode, err)
+		return model.FailedBook
+	}
+
+	drawn, err := allotment.Draw(order.From, order.To)
+	if errors.Is(err, model.ErrAllotmentExhausted) {
+		s.log.Info("[bookingService.bookFromAllotment] Booking process stopped: %v", err)
+		return model.NoRooms
+	}
+	if err != nil {
+		s.log.Error("[bookingService.bookFromAllotment] Failed to draw rooms from allotment: %v", err)
+		return model.FailedBook
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.bookFromAllotment] Failed to start transaction: %v", err)
+		return model.FailedBook
+	}
+
+	var promoErr error
+	s.redeemOrderBenefits(ctx, tx, order, &promoErr)
+
+	tx.Execute(
+		func() error {
+			return s.storage.GetAllotmentRepo().UpdateAllotment(ctx, drawn)
+		},
+		func() error {
+			return s.storage.GetAllotmentRepo().UpdateAllotment(ctx, allotment)
+		},
+	)
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.bookFromAllotment] Failed to commit transaction: %v", err)
+
+		if promoErr != nil {
+			return model.InvalidPromo
+		}
+		return model.FailedBook
+	}
+
+	s.log.Info("[bookingService.bookFromAllotment] Order is booked from allotment %s", allotment.Code)
+
+	return model.Booked
+}
+
+// releaseOrderQuota - add into transaction operations which return one room for every night of [from, to).
+// Rooms of block orders go back to their allotment until it is released, afterwards to general quota.
+func (s *bookingService) releaseOrderQuota(ctx context.Context, tx storage.Transaction, order ReservationOrder, from, to time.Time) error {
+	if order.BlockCode == "" {
+		return s.releaseQuota(ctx, tx, order.HotelID, order.RoomTypeID, from, to)
+	}
+
+	allotment, err := s.storage.GetAllotmentRepo().GetAllotment(ctx, order.BlockCode)
+	if err != nil {
+		return err
+	}
+
+	if allotment.Status != model.AllotmentActive {
+		return s.releaseQuota(ctx, tx, order.HotelID, order.RoomTypeID, from, to)
+	}
+
+	returned := allotment.Return(from, to)
+	tx.Execute(
+		func() error {
+			return s.storage.GetAllotmentRepo().UpdateAllotment(ctx, returned)
+		},
+		func() error {
+			return s.storage.GetAllotmentRepo().UpdateAllotment(ctx, allotment)
+		},
+	)
+
+	return nil
+}
+
+// releaseAllotments - return unused rooms of allotments, which release date has come, to general quota.
+// Returned rooms are offered to waitlisted orders.
+func (s *bookingService) releaseAllotments(ctx context.Context, at time.Time) {
+	allotments, err := s.storage.GetAllotmentRepo().GetAllotmentsToRelease(ctx, at)
+	if err != nil {
+		s.log.Error("[bookingService.releaseAllotments] Failed to get allotments: %v", err)
+		return
+	}
+
+	slices.SortFunc(allotments, func(a, b model.Allotment) int { return cmp.Compare(a.Code, b.Code) })
+
+	for _, allotment := range allotments {
+		if err = s.releaseAllotment(ctx, allotment); err != nil {
+			s.log.Error("[bookingService.releaseAllotments] Failed to release allotment %s: %v", allotment.Code, err)
+			continue
+		}
+
+		s.log.Info("[bookingService.releaseAllotments] Allotment %s is released", allotment.Code)
+
+		s.processWaitlist(ctx, allotment.HotelID, allotment.RoomTypeID, allotment.From, allotment.To)
+	}
+}
+
+func (s *bookingService) releaseAllotment(ctx context.Context, allotment model.Allotment) error {
+	rooms, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, allotment.HotelID, allotment.RoomTypeID, allotment.From, allotment.To)
+	if err != nil {
+		return err
+	}
+
+	released := allotment
+	released.Status = model.AllotmentReleased
+	released.Nights = make([]model.AllotmentNight, 0, len(allotment.Nights))
+
+	held := make(map[string]int, len(allotment.Nights))
+	for _, night := range allotment.Nights {
+		held[night.Date.Format(time.DateOnly)] = night.Held
+		released.Nights = append(released.Nights, model.AllotmentNight{Date: night.Date, Sold: night.Sold})
+	}
+
+	change := newQuotaChange()
+	for _, room := range rooms {
+		change.add([]RoomAvailability{room}, held[room.Date.Format(time.DateOnly)])
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.applyQuotaChange(ctx, tx, change)
+
+	tx.Execute(
+		func() error {
+			return s.storage.GetAllotmentRepo().UpdateAllotment(ctx, released)
+		},
+		func() error {
+			return s.storage.GetAllotmentRepo().UpdateAllotment(ctx, allotment)
+		},
+	)
+
+	return tx.Commit()
+}
+
+func (s *bookingService) GetAllotment(ctx context.Context, code string) (model.Allotment, error) {
+	return s.storage.GetAllotmentRepo().GetAllotment(ctx, model.NormalizeBlockCode(code))
+}
+
+func (s *bookingService) GetListAllotments(ctx context.Context) ([]model.Allotment, error) {
+	return s.storage.GetAllotmentRepo().GetListAllotments(ctx)
+}
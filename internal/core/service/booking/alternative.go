@@ -0,0 +1,124 @@
+package booking
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+const (
+	maxAlternativesPerKind = 3
+	maxDateShift           = 3  // days before and after requested stay
+	nearbyHotelRadiusKm    = 10 // hotels closer than this are offered as alternatives
+)
+
+// alternatives - available stays for order which ended without rooms: other room types of the hotel,
+// the same room type with stay shifted by up to maxDateShift days and nearby hotels for the same dates.
+// Only bookable stays which fit guests of the order are offered. Block code orders are bound to their allotment,
+// so nothing is offered for them.
+func (s *bookingService) alternatives(ctx context.Context, order ReservationOrder) []model.Alternative {
+	if order.BlockCode != "" {
+		return nil
+	}
+
+	var alternatives []model.Alternative
+	add := func(kind model.AlternativeKind, offer model.AvailabilityOffer, from, to time.Time) {
+		alternatives = append(alternatives, model.Alternative{
+			Kind:       kind,
+			HotelID:    offer.HotelID,
+			RoomTypeID: offer.RoomTypeID,
+			From:       from,
+			To:         to,
+			Price:      offer.Price,
+		})
+	}
+
+	found := 0
+	for _, offer := range s.offers(ctx, order, order.HotelID, order.From, order.To) {
+		if offer.RoomTypeID != order.RoomTypeID && found < maxAlternativesPerKind {
+			add(model.AlternativeRoomType, offer, order.From, order.To)
+			found++
+		}
+	}
+
+	found = 0
+	today := s.localToday(ctx, order.HotelID)
+	for shift := 1; shift <= maxDateShift && found < maxAlternativesPerKind; shift++ {
+		for _, days := range []int{-shift, shift} {
+			from, to := order.From.AddDate(0, 0, days), order.To.AddDate(0, 0, days)
+			if from.Before(today) || found == maxAlternativesPerKind {
+				continue
+			}
+
+			for _, offer := range s.offers(ctx, order, order.HotelID, from, to) {
+				if offer.RoomTypeID == order.RoomTypeID {
+					add(model.AlternativeDates, offer, from, to)
+					found++
+				}
+			}
+		}
+	}
+
+	found = 0
+	for _, hotel := range s.nearbyHotels(ctx, order.HotelID) {
+		if found == maxAlternativesPerKind {
+			break
+		}
+
+		if offers := s.offers(ctx, order, hotel.ID, order.From, order.To); len(offers) > 0 {
+			add(model.AlternativeHotel, offers[0], order.From, order.To)
+			found++
+		}
+	}
+
+	return alternatives
+}
+
+// offers - bookable offers of hotel for one room, which fit guests of the order.
+func (s *bookingService) offers(ctx context.Context, order ReservationOrder, hotelID int, from, to time.Time) []model.AvailabilityOffer {
+	offers, err := s.SearchAvailability(ctx, model.AvailabilityQuery{HotelID: hotelID, From: from, To: to, Rooms: 1})
+	if err != nil {
+		s.log.Error("[bookingService.offers] Failed to search availability: %v", err)
+		return nil
+	}
+
+	return slices.DeleteFunc(offers, func(offer model.AvailabilityOffer) bool {
+		if !offer.Bookable {
+			return true
+		}
+
+		roomType, err := s.storage.GetRoomTypeRepo().GetRoomType(ctx, offer.HotelID, offer.RoomTypeID)
+		if err != nil {
+			return true // room type is not in catalog, order for it would be rejected
+		}
+
+		return roomType.CheckOccupancy(order.Occupancy) != nil
+	})
+}
+
+// nearbyHotels - other hotels within nearbyHotelRadiusKm of hotel, the closest first.
+func (s *bookingService) nearbyHotels(ctx context.Context, hotelID int) []model.Hotel {
+	hotel, err := s.storage.GetHotelRepo().GetHotel(ctx, hotelID)
+	if err != nil || hotel.Geo == nil {
+		return nil
+	}
+
+	hotels, err := s.storage.GetHotelRepo().GetListHotels(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.nearbyHotels] Failed to get hotels: %v", err)
+		return nil
+	}
+
+	hotels = slices.DeleteFunc(hotels, func(h model.Hotel) bool {
+		return h.ID == hotel.ID || h.Geo == nil || hotel.Geo.DistanceKm(*h.Geo) > nearbyHotelRadiusKm
+	})
+
+	slices.SortFunc(hotels, func(a, b model.Hotel) int {
+		return cmp.Compare(hotel.Geo.DistanceKm(*a.Geo), hotel.Geo.DistanceKm(*b.Geo))
+	})
+
+	return hotels
+}
@@ -0,0 +1,139 @@
+package booking
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/service/pricing"
+	"aplication-design-test-task/internal/core/util"
+)
+
+// CheckAvailability returns hotel local nights of order stay which have no room left for one more booking.
+// Overbooking allowance is taken into account like by booking itself. Block code orders draw from allotment
+// instead of general quota, so they are not checked here.
+func (s *bookingService) CheckAvailability(ctx context.Context, order ReservationOrder) ([]time.Time, error) {
+	if order.BlockCode != "" {
+		return nil, nil
+	}
+
+	stayOrder, err := s.withLocalStay(ctx, order, order.From, order.To)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, order.HotelID, order.RoomTypeID, stayOrder.From, stayOrder.To)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := s.overbookingAllowance(ctx, order.HotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]bool, len(rooms))
+	for _, room := range rooms {
+		if room.Quota-1 >= -allowance(room) {
+			available[room.Date.Format(time.DateOnly)] = true
+		}
+	}
+
+	var unavailable []time.Time
+	for _, night := range util.NightsBetween(stayOrder.From, stayOrder.To) {
+		if !available[night.Format(time.DateOnly)] {
+			unavailable = append(unavailable, night)
+		}
+	}
+
+	return unavailable, nil
+}
+
+// SearchAvailability returns offers of room types which have query.Rooms left on every night of the stay,
+// with best available price and violated stay restriction. Room-days of the stay and of its departure day are read
+// by one period query. Room types without rate for some night are not offered.
+func (s *bookingService) SearchAvailability(ctx context.Context, query model.AvailabilityQuery) ([]model.AvailabilityOffer, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	from, to := util.ToDay(query.From), util.ToDay(query.To)
+
+	rooms, err := s.storage.GetRoomRepo().GetRoomsForPeriod(ctx, query.HotelID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	nights := make(map[model.RoomTypeKey][]RoomAvailability)
+	departures := make(map[model.RoomTypeKey]*RoomAvailability)
+	for _, room := range rooms {
+		key := model.RoomTypeKey{HotelID: room.HotelID, RoomTypeID: room.RoomTypeID}
+		if util.ToDay(room.Date).Equal(to) {
+			departures[key] = &room
+			continue
+		}
+
+		nights[key] = append(nights[key], room)
+	}
+
+	stay := len(util.NightsBetween(from, to))
+	allowances := make(map[int]func(RoomAvailability) int)
+
+	offers := make([]model.AvailabilityOffer, 0, len(nights))
+	for key, stayNights := range nights {
+		if len(stayNights) < stay {
+			continue // some night has no inventory
+		}
+
+		allowance, ok := allowances[key.HotelID]
+		if !ok {
+			if allowance, err = s.overbookingAllowance(ctx, key.HotelID); err != nil {
+				return nil, err
+			}
+			allowances[key.HotelID] = allowance
+		}
+
+		available := math.MaxInt
+		for _, night := range stayNights {
+			available = min(available, night.Quota+allowance(night))
+		}
+
+		if available < query.Rooms {
+			continue
+		}
+
+		price, err := s.pricing.Quote(ctx, ReservationOrder{HotelID: key.HotelID, RoomTypeID: key.RoomTypeID, From: from, To: to})
+		if errors.Is(err, pricing.ErrNoRate) {
+			s.log.Info("[bookingService.SearchAvailability] Room type %v is not offered: %v", key, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		offer := model.AvailabilityOffer{
+			HotelID:    key.HotelID,
+			RoomTypeID: key.RoomTypeID,
+			Available:  available,
+			Price:      price,
+			Bookable:   true,
+		}
+
+		if err = model.CheckStayRestrictions(stayNights, from, departures[key]); err != nil {
+			offer.Bookable = false
+			offer.Restriction = err.Error()
+		}
+
+		offers = append(offers, offer)
+	}
+
+	slices.SortFunc(offers, func(a, b model.AvailabilityOffer) int {
+		return cmp.Or(cmp.Compare(a.HotelID, b.HotelID), cmp.Compare(a.RoomTypeID, b.RoomTypeID))
+	})
+
+	return offers, nil
+}
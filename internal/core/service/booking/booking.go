@@ -2,6 +2,7 @@ package booking
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,12 +13,27 @@ import (
 	"aplication-design-test-task/internal/core/domain/model"
 	"aplication-design-test-task/internal/core/port/events"
 	"aplication-design-test-task/internal/core/service/booking/worker"
-	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/core/service/invoice"
+	"aplication-design-test-task/internal/core/service/pricing"
 	"aplication-design-test-task/internal/logger"
 )
 
 const workerCnt = 1 // for now magic number
 
+// subscribedTopics - topics consumed by every booking worker.
+var subscribedTopics = [...]queue.Topic{
+	queue.ReservedOrderRequest,
+	queue.ModifyOrderRequest,
+	queue.CancelOrderRequest,
+	queue.SuccessPaymentProcess,
+	queue.FailedPaymentProcess,
+	queue.RefundProcessed,
+	queue.InventoryUpdateRequest,
+	queue.ExpireOrdersRequest,
+	queue.JoinWaitlistRequest,
+	queue.ResumeSagaRequest,
+}
+
 type (
 	ID         int
 	HotelID    = ID
@@ -34,10 +50,25 @@ type (
 		q       queue.Queue
 		storage storage.Storage
 		workers []bookingWorker
+		pricing pricer
+
+		invoicing invoicer
+
+		now func() time.Time
 	}
 
 	bookingWorker interface {
-		Run(context.Context, <-chan queue.Msg)
+		Run(context.Context, ...<-chan queue.Msg)
+	}
+
+	pricer interface {
+		Quote(context.Context, model.Order) (model.Price, error)
+	}
+
+	invoicer interface {
+		Issue(ctx context.Context, order model.Order, issuedAt time.Time) (model.Invoice, error)
+		Reissue(ctx context.Context, order model.Order, issuedAt time.Time) (model.Invoice, error)
+		Credit(ctx context.Context, orderID model.OrderID, refunded model.Money, reason model.RefundReason, issuedAt time.Time) (model.Invoice, error)
 	}
 )
 
@@ -47,6 +78,11 @@ func New(log logger.Logger, q queue.Queue, s storage.Storage) (*bookingService,
 		q:       q,
 		storage: s,
 		workers: make([]bookingWorker, 0, workerCnt),
+		pricing: pricing.New(s),
+
+		invoicing: invoice.New(s),
+
+		now: func() time.Time { return time.Now().UTC() },
 	}
 
 	for range workerCnt {
@@ -58,17 +94,23 @@ func New(log logger.Logger, q queue.Queue, s storage.Storage) (*bookingService,
 
 func (s *bookingService) Run(ctx context.Context) error {
 	for _, w := range s.workers {
-		const topicName = queue.ReservedOrderRequest
+		chs := make([]<-chan queue.Msg, 0, len(subscribedTopics))
 
 		// TODO: If workerCnt > 1, additional logic is needed to support partitioning, queue groups, or similar features.
-		ch, err := s.q.Subscribe(ctx, topicName)
-		if err != nil {
-			return fmt.Errorf("could not subscribe to topic %s. err: %v", topicName, err)
+		for _, topicName := range subscribedTopics {
+			ch, err := s.q.Subscribe(ctx, topicName)
+			if err != nil {
+				return fmt.Errorf("could not subscribe to topic %s. err: %v", topicName, err)
+			}
+
+			chs = append(chs, ch)
 		}
 
-		w.Run(ctx, ch)
+		w.Run(ctx, chs...)
 	}
 
+	s.runExpiration(ctx)
+
 	return nil
 }
 
@@ -121,7 +163,19 @@ func (s *bookingService) ReservationOrderEventHandler(ctx context.Context, event
 		UserEmail:  event.UserEmail,
 		From:       event.From,
 		To:         event.To,
+		PromoCode:  model.NormalizePromoCode(event.PromoCode),
+		RatePlanID: event.RatePlanID,
+		BlockCode:  model.NormalizeBlockCode(event.BlockCode),
 		Status:     model.New,
+
+		DisplayCurrency: event.DisplayCurrency,
+
+		LoyaltyPoints: event.LoyaltyPoints,
+		Waitlist:      event.Waitlist,
+
+		Guest:            event.Guest,
+		Occupancy:        event.Occupancy,
+		AdditionalGuests: event.AdditionalGuests,
 	}
 
 	// todo properly handle db error (re-try or other policy...)
@@ -131,130 +185,263 @@ func (s *bookingService) ReservationOrderEventHandler(ctx context.Context, event
 		s.log.Info("[bookingService.ReservationOrderEventHandler] Stored new order: %v", newOrder)
 	}
 
-	// Wrap transaction stuff in separate func
-	isBookedSuccessfully := func() bool {
-		tx, err := s.storage.BeginTx(ctx)
-		if err != nil {
-			s.log.Error("[bookingService.ReservationOrderEventHandler] Failed to start transaction: %v", err)
-			return false
-		}
+	s.startSaga(ctx, newOrder)
+	s.bookNewOrder(ctx, newOrder)
+}
 
-		defer func() {
-			if err := tx.Commit(); err != nil {
-				s.log.Error("[bookingService.ReservationOrderEventHandler] Failed to commit transaction: %v", err)
-				return
-			}
+// bookNewOrder - book step of saga: process new order, then request its payment or report why it is not booked.
+func (s *bookingService) bookNewOrder(ctx context.Context, newOrder ReservationOrder) {
+	processedOrder := newOrder
+	if stayOrder, err := s.withLocalStay(ctx, newOrder, newOrder.From, newOrder.To); err != nil {
+		s.log.Error("[bookingService.bookNewOrder] Failed to convert stay to hotel local dates: %v", err)
+		processedOrder.Status = model.FailedBook
+		processedOrder.FailureReason = err.Error()
+		processedOrder.UpdatedAt = s.now()
+	} else {
+		processedOrder = s.processOrder(ctx, stayOrder)
+	}
 
-			s.log.Info("[bookingService.ReservationOrderEventHandler] Success commit transaction")
-		}()
+	if processedOrder.Status == model.NoRooms {
+		processedOrder.Alternatives = s.alternatives(ctx, processedOrder)
+	}
 
-		processedOrder := newOrder
-		processedOrder.Status = model.NoRooms
+	if err := s.storage.GetOrderRepo().UpdateOrder(ctx, processedOrder.ID, processedOrder); err != nil {
+		s.log.Error("[bookingService.bookNewOrder] Failed to update processed order: %v", err)
+		// todo compensate booked quota
+	} else {
+		s.log.Info("[bookingService.bookNewOrder] Updated processed order: %v", processedOrder)
+	}
+
+	s.statusChanged(ctx, newOrder.Status, processedOrder)
+	s.bookStepDone(ctx, processedOrder)
 
-		defer func() {
-			tx.Execute(
-				func() error {
-					err := s.storage.GetOrderRepo().UpdateOrder(ctx, processedOrder.ID, processedOrder)
-					if err != nil {
-						s.log.Error("[bookingService.ReservationOrderEventHandler] Failed to update processed order: %v", err)
-					} else {
-						s.log.Info("[bookingService.ReservationOrderEventHandler] Updated processed order: %v", processedOrder)
-					}
+	switch processedOrder.Status {
+	case model.Booked:
+		s.notify(ctx, processedOrder, model.OrderBookedNotification)
+	case model.NoRooms:
+		if !processedOrder.Waitlist {
+			s.notify(ctx, processedOrder, model.OrderFailedNotification)
+			return
+		}
 
-					return err
-				},
+		if _, err := s.joinWaitlist(ctx, processedOrder); err != nil {
+			s.log.Error("[bookingService.bookNewOrder] Failed to join waitlist: %v", err)
+		}
+	default:
+		s.notify(ctx, processedOrder, model.OrderFailedNotification)
+	}
+}
 
-				func() error {
-					s.log.Info("[bookingService.ReservationOrderEventHandler] Rollback processed order to new: %v", newOrder)
-					return s.storage.GetOrderRepo().UpdateOrder(ctx, newOrder.ID, newOrder)
-				},
-			)
-		}()
+// processOrder - price the order and book it. Returns order with price and status which it gets as result.
+func (s *bookingService) processOrder(ctx context.Context, order ReservationOrder) ReservationOrder {
+	processedOrder := order
+	processedOrder.UpdatedAt = s.now()
+	processedOrder.FailureReason = ""
+	processedOrder.Alternatives = nil
+	processedOrder.PaymentSchedule = nil
+	processedOrder.Payments = nil
+	processedOrder.Conversion = nil
+
+	err := s.checkStayRestrictions(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+	switch {
+	case errors.Is(err, model.ErrStayRestricted):
+		s.log.Info("[bookingService.processOrder] Stay is restricted: %v", err)
+		processedOrder.Status = model.Restricted
+		processedOrder.FailureReason = err.Error()
+		return processedOrder
+	case err != nil:
+		s.log.Error("[bookingService.processOrder] Failed to check stay restrictions: %v", err)
+		processedOrder.Status = model.FailedBook
+		return processedOrder
+	}
 
-		rooms, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, event.HotelID, event.RoomTypeID, event.From, event.To)
-		if err != nil {
-			s.log.Error("[bookingService.ReservationOrderEventHandler] Failed to retrieve rooms information: %v", err)
+	if err = s.checkBlockCode(ctx, order); err != nil {
+		s.log.Info("[bookingService.processOrder] Block code can not be used: %v", err)
+		processedOrder.Status = model.FailedBook
+		processedOrder.FailureReason = err.Error()
+		return processedOrder
+	}
 
+	price, err := s.pricing.Quote(ctx, order)
+	switch {
+	case errors.Is(err, model.ErrPromoInvalid):
+		s.log.Error("[bookingService.processOrder] Failed to apply promo code: %v", err)
+		processedOrder.Status = model.InvalidPromo
+	case err != nil:
+		s.log.Error("[bookingService.processOrder] Failed to calculate price: %v", err)
+		processedOrder.Status = model.FailedBook
+	default:
+		processedOrder.Price = price
+		processedOrder.LoyaltyPoints = price.RedeemedPoints // only points which are really needed
+
+		if processedOrder.Conversion, err = s.convertPrice(ctx, processedOrder); err != nil {
+			s.log.Error("[bookingService.processOrder] Failed to convert price into %s: %v", order.DisplayCurrency, err)
 			processedOrder.Status = model.FailedBook
-			return false // early exit (defer for Order update status to model.NoRooms)
+			processedOrder.FailureReason = err.Error()
+			return processedOrder
 		}
 
-		if len(rooms) == 0 || len(rooms) < len(util.DaysBetween(event.From, event.To)) {
-			s.log.Error("[bookingService.ReservationOrderEventHandler] No rooms for period")
+		processedOrder.Status = s.book(ctx, processedOrder)
 
-			return false // early exit (defer for Order update status to model.NoRooms)
+		if processedOrder.Status == model.Booked {
+			processedOrder.PaymentSchedule = s.paymentSchedule(processedOrder)
+			if len(processedOrder.PaymentSchedule) == 0 {
+				processedOrder.Payments = []model.OrderPayment{{ID: uuid.New(), Amount: price.Total, RequestedAt: s.now()}}
+			}
 		}
+	}
 
-		s.log.Info("[bookingService.ReservationOrderEventHandler] Retrieve rooms information for order."+
-			" event.HotelID: %d, event.RoomTypeID: %d, event.From: %v, event.To: %v.  rooms: %s",
-			event.HotelID, event.RoomTypeID, event.From, event.To, rooms)
-
-		for _, room := range rooms {
-			oldRoomInfo := room
-
-			if room.Quota > 0 { // todo if user will can book more the one room, must be change this place
-				room.Quota-- // change here too: e.g. room.Quota -= some
-
-				tx.Execute(
-					func() error {
-						err := s.storage.GetRoomRepo().UpdateRoom(ctx, room.ID, room)
-						if err != nil {
-							s.log.Error("[bookingService.ReservationOrderEventHandler] Failed to update room quota: %v", err)
-						} else {
-							s.log.Info("[bookingService.ReservationOrderEventHandler] Updated room quota: %v", room)
-						}
-
-						return err
-					},
-
-					func() error {
-						processedOrder.Status = model.FailedBook
-						return s.storage.GetRoomRepo().UpdateRoom(ctx, room.ID, oldRoomInfo)
-					})
-			} else {
-				s.log.Info("[bookingService.ReservationOrderEventHandler] "+
-					"No room quota event.HotelID: %d, event.RoomTypeID: %d for date: %v. Booking process stopped!",
-					room.HotelID, room.RoomTypeID, room.Date)
-
-				return false // early exit (defer for Order update status to model.NoRooms)
-			}
+	return processedOrder
+}
+
+// paymentRequestID - ID of the first payment of booked order: deposit payment or payment of total price.
+// Payment replaced by modification of the order is skipped.
+func paymentRequestID(order ReservationOrder) uuid.UUID {
+	if len(order.PaymentSchedule) > 0 {
+		return order.PaymentSchedule[0].PaymentID
+	}
+	for _, payment := range order.Payments {
+		if payment.ReplacedAt.IsZero() {
+			return payment.ID
 		}
+	}
 
-		s.log.Info("[bookingService.ReservationOrderEventHandler] Room available for all days. " +
-			"Try to set up processOrder.Status = model.Booked")
+	return uuid.Nil
+}
 
-		processedOrder.Status = model.Booked
+// requestPayment - publish payment request with given ID for total price of booked order or for its deposit.
+// Request with the same ID is charged only once, so it can be repeated.
+func (s *bookingService) requestPayment(ctx context.Context, order ReservationOrder, id uuid.UUID) {
+	if len(order.PaymentSchedule) > 0 {
+		_ = s.publishPaymentRequest(ctx, order, id, order.PaymentSchedule[0].Amount)
+		return
+	}
 
-		return true
-	}()
+	_ = s.publishPaymentRequest(ctx, order, id, order.Price.Total)
+}
 
-	if !isBookedSuccessfully {
-		return // not send paymentRequestMsg if not successfully booke
+// publishPaymentRequest - publish request of payment with given ID and amount for the order.
+func (s *bookingService) publishPaymentRequest(ctx context.Context, order ReservationOrder, id uuid.UUID, amount model.Money) error {
+	conversion, err := applyConversion(order, amount)
+	if err != nil {
+		s.log.Error("[bookingService.publishPaymentRequest] Failed to convert payment of order %v: %v", order.ID, err)
 	}
 
 	paymentRequestMsg := events.PaymentRequest{
-		ID:        uuid.New(),
-		OrderID:   newOrder.ID,
+		ID:        id,
+		OrderID:   order.ID,
+		Amount:    amount,
 		CreatedAt: time.Now().UTC(),
 		PaidAt:    time.Time{},
 		IsPaid:    false,
+
+		Payer:      order.Guest,
+		PayerEmail: order.UserEmail,
+
+		Conversion: conversion,
 	}
-	err := s.q.AsyncPublish(ctx, queue.PaymentRequest, paymentRequestMsg)
-	if err != nil {
-		s.log.Error("[bookingService.ReservationOrderEventHandler] Failed to publish PaymentRequest msg: %v", err)
+	if err = s.q.AsyncPublish(ctx, queue.PaymentRequest, paymentRequestMsg); err != nil {
+		s.log.Error("[bookingService.publishPaymentRequest] Failed to publish PaymentRequest msg: %v", err)
+		return err
 	}
 
-	s.log.Info("[bookingService.ReservationOrderEventHandler] Published PaymentRequest msg: %v", paymentRequestMsg)
+	s.log.Info("[bookingService.publishPaymentRequest] Published PaymentRequest msg: %v", paymentRequestMsg)
+
+	return nil
 }
 
-func (s *bookingService) SuccessPaymentEventHandler(ctx context.Context, event events.SuccessPaymentEvent) {
-	//TODO implement me
-	panic("implement me")
+// book - reserve quota for every day of the order and redeem its promo code in one transaction.
+// Returns the status which order gets as result of booking.
+func (s *bookingService) book(ctx context.Context, order ReservationOrder) model.Status {
+	if order.BlockCode != "" {
+		return s.bookFromAllotment(ctx, order)
+	}
+
+	rooms, err := s.roomsForPeriod(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+	if errors.Is(err, errNoRooms) {
+		s.log.Error("[bookingService.book] No rooms for period")
+		return model.NoRooms
+	}
+	if err != nil {
+		s.log.Error("[bookingService.book] Failed to retrieve rooms information: %v", err)
+		return model.FailedBook
+	}
+
+	s.log.Info("[bookingService.book] Retrieve rooms information for order."+
+		" HotelID: %d, RoomTypeID: %d, From: %v, To: %v.  rooms: %v",
+		order.HotelID, order.RoomTypeID, order.From, order.To, rooms)
+
+	change := newQuotaChange()
+	change.add(rooms, -1) // todo if user will can book more the one room, must be change this place
+
+	if err = s.checkQuota(ctx, order.HotelID, change); errors.Is(err, errNoRooms) {
+		s.log.Info("[bookingService.book] Booking process stopped!")
+		return model.NoRooms
+	}
+	if err != nil {
+		s.log.Error("[bookingService.book] Failed to check room quota: %v", err)
+		return model.FailedBook
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.book] Failed to start transaction: %v", err)
+		return model.FailedBook
+	}
+
+	var promoErr error
+	s.redeemOrderBenefits(ctx, tx, order, &promoErr)
+
+	s.applyQuotaChange(ctx, tx, change)
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.book] Failed to commit transaction: %v", err)
+
+		if promoErr != nil {
+			return model.InvalidPromo
+		}
+		return model.FailedBook
+	}
+
+	s.log.Info("[bookingService.book] Room available for all days. Order is booked")
+
+	return model.Booked
 }
 
-func (s *bookingService) FailedPaymentEventHandler(ctx context.Context, event events.FailedPaymentEvent) {
-	//TODO implement me
-	panic("implement me")
+// redeemOrderBenefits - add into transaction operations which redeem promo code and loyalty points of the order.
+// Error of promo code redemption is stored into promoErr, when transaction is committed.
+func (s *bookingService) redeemOrderBenefits(ctx context.Context, tx storage.Transaction, order ReservationOrder, promoErr *error) {
+	if order.PromoCode != "" {
+		// redeem first, so limits exceeded by concurrent bookings fail before quota is touched.
+		tx.Execute(
+			func() error {
+				*promoErr = s.storage.GetPromoRepo().Redeem(ctx, model.PromoRedemption{
+					OrderID:    order.ID,
+					Code:       order.PromoCode,
+					UserEmail:  order.UserEmail,
+					HotelID:    order.HotelID,
+					RoomTypeID: order.RoomTypeID,
+					RedeemedAt: s.now(),
+				})
+				return *promoErr
+			},
+			func() error {
+				return s.storage.GetPromoRepo().Release(ctx, order.ID)
+			},
+		)
+	}
+
+	if order.LoyaltyPoints > 0 {
+		redemption := s.loyaltyEntry(order, model.RedeemEntry, -order.LoyaltyPoints)
+		tx.Execute(
+			func() error {
+				return s.storage.GetLoyaltyRepo().AddEntry(ctx, redemption)
+			},
+			func() error {
+				return s.storage.GetLoyaltyRepo().RemoveEntry(ctx, redemption.ID, s.now())
+			},
+		)
+	}
 }
 
 func (s *bookingService) GetOrder(ctx context.Context, id ReservationOrderID) (ReservationOrder, error) {
@@ -2,6 +2,8 @@ package booking
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"aplication-design-test-task/internal/adapters/queue/gochanqueue"
 	"aplication-design-test-task/internal/adapters/storage"
 	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
 	"aplication-design-test-task/internal/core/port/events"
 	"aplication-design-test-task/internal/core/service"
 	"aplication-design-test-task/internal/core/util"
@@ -58,6 +61,20 @@ func (suite *BookingServiceSuite) BeforeTest(suiteName, testName string) {
 	suite.Storage.Close(suite.Context)
 	suite.Storage = instorage.NewStorage()
 	suite.NoError(migration.InitializeStorage(suite.Context, suite.Storage))
+
+	var err error
+	suite.ServiceImpl, err = New(suite.Logger, suite.Queue, suite.Storage) // not running, handlers are called directly
+	suite.NoError(err)
+	suite.Service = suite.ServiceImpl
+
+	// queue is shared by tests and these topics have no consumer, drop messages of previous tests
+	for _, topic := range []queue.Topic{queue.NotificationRequest, queue.OrderStatusChanged, queue.PaymentRequest, queue.RefundRequest} {
+		ch, err := suite.Queue.Subscribe(suite.Context, topic)
+		suite.NoError(err)
+		for len(ch) > 0 {
+			<-ch
+		}
+	}
 }
 
 func (suite *BookingServiceSuite) AfterTest(suiteName, testName string) {
@@ -103,3 +120,1678 @@ func (suite *BookingServiceSuite) TestBookingService_ReservationOrderEventHandle
 		)
 	}
 }
+
+func (suite *BookingServiceSuite) TestBookingService_CancelOrderEventHandler() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 04, 03) }
+
+	refunds, err := suite.Queue.Subscribe(suite.Context, queue.RefundRequest)
+	suite.Require().NoError(err)
+
+	newOrderEvent := func() ReservationOrder {
+		return ReservationOrder{
+			ID:         uuid.New(),
+			CreatedAt:  util.NewDay(2024, 04, 01),
+			HotelID:    1,
+			RoomTypeID: 2,
+			UserEmail:  "ars-saz@ya.ru",
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 05),
+		}
+	}
+
+	quotaFor := func(day util.Day) int {
+		rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 2, day, day.AddDate(0, 0, 1))
+		suite.Require().NoError(err)
+		suite.Require().Len(rooms, 1)
+		return rooms[0].Quota
+	}
+
+	suite.Run("Booked order releases remaining days", func() {
+		event := newOrderEvent()
+		suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+
+		order, err := suite.Service.GetOrder(suite.Context, event.ID)
+		suite.Require().NoError(err)
+		suite.Require().Equal(model.Booked, order.Status)
+
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: event.ID})
+
+		order, err = suite.Service.GetOrder(suite.Context, event.ID)
+		suite.Require().NoError(err)
+		suite.Equal(model.Cancelled, order.Status)
+
+		suite.Equal(9, quotaFor(util.NewDay(2024, 04, 01)), "passed day must stay consumed")
+		suite.Equal(9, quotaFor(util.NewDay(2024, 04, 02)), "passed day must stay consumed")
+		suite.Equal(10, quotaFor(util.NewDay(2024, 04, 03)))
+		suite.Equal(10, quotaFor(util.NewDay(2024, 04, 05)))
+
+		select {
+		case msg := <-refunds:
+			suite.Failf("unexpected refund", "%+v", msg)
+		default:
+		}
+	})
+
+	suite.Run("Paid order requests refund", func() {
+		event := newOrderEvent()
+		suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+
+		order, err := suite.Service.GetOrder(suite.Context, event.ID)
+		suite.Require().NoError(err)
+		order.Status = model.Paid
+		order.PaidAmount = order.Price.Total
+		suite.Require().NoError(suite.Storage.GetOrderRepo().UpdateOrder(suite.Context, order.ID, order))
+
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: event.ID})
+
+		select {
+		case msg := <-refunds:
+			refund, ok := msg.(events.RefundRequest)
+			suite.Require().True(ok)
+			suite.Equal(event.ID, refund.OrderID)
+			suite.Equal(order.Price.Total, refund.Amount)
+			suite.NotZero(refund.Amount.Amount)
+		case <-time.After(time.Second):
+			suite.Fail("refund request was not published")
+		}
+	})
+
+	suite.Run("Cancelled order can not be cancelled twice", func() {
+		event := newOrderEvent()
+		suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: event.ID})
+		before := quotaFor(util.NewDay(2024, 04, 04))
+
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: event.ID})
+
+		suite.Equal(before, quotaFor(util.NewDay(2024, 04, 04)))
+	})
+}
+
+func (suite *BookingServiceSuite) TestBookingService_ModifyOrderEventHandler() {
+	payments, err := suite.Queue.Subscribe(suite.Context, queue.PaymentRequest)
+	suite.Require().NoError(err)
+
+	drain := func(ch <-chan queue.Msg) {
+		for {
+			select {
+			case <-ch:
+			default:
+				return
+			}
+		}
+	}
+
+	quotaFor := func(roomTypeID int, day util.Day) int {
+		rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, roomTypeID, day, day.AddDate(0, 0, 1))
+		suite.Require().NoError(err)
+		suite.Require().Len(rooms, 1)
+		return rooms[0].Quota
+	}
+
+	arseny := func(order *ReservationOrder) {
+		order.Guest = model.Guest{Name: "Arseny", Phone: "+7 900 1234567"}
+		order.Occupancy = model.Occupancy{Adults: 2}
+	}
+
+	suite.Run("Shift dates with overlap", func() {
+		order := suite.book(arseny, stay(1, 1, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 03)))
+		suite.Require().Equal(model.Booked, order.Status)
+
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 1,
+			From:       util.NewDay(2024, 04, 02),
+			To:         util.NewDay(2024, 04, 04),
+		})
+
+		modified, err := suite.Service.GetOrder(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Equal(util.NewDay(2024, 04, 02), modified.From)
+		suite.Equal(util.NewDay(2024, 04, 04), modified.To)
+		suite.Require().Len(modified.Changes, 1)
+		suite.True(modified.Changes[0].Applied)
+
+		suite.Equal(10, quotaFor(1, util.NewDay(2024, 04, 01)))
+		suite.Equal(9, quotaFor(1, util.NewDay(2024, 04, 02)))
+		suite.Equal(9, quotaFor(1, util.NewDay(2024, 04, 03)))
+		suite.Equal(10, quotaFor(1, util.NewDay(2024, 04, 04)))
+	})
+
+	suite.Run("Failed order update moves quota back", func() {
+		order := suite.book(arseny, stay(1, 1, util.NewDay(2024, 04, 05), util.NewDay(2024, 04, 06)))
+		suite.Require().Equal(model.Booked, order.Status)
+
+		movedOrder, err := suite.ServiceImpl.withLocalStay(suite.Context, order, util.NewDay(2024, 04, 06), util.NewDay(2024, 04, 07))
+		suite.Require().NoError(err)
+
+		order.ID = uuid.New() // not stored order can not be updated
+		movedOrder.ID = order.ID
+		suite.Error(suite.ServiceImpl.moveReservation(suite.Context, order, movedOrder))
+
+		suite.Equal(9, quotaFor(1, util.NewDay(2024, 04, 05)))
+		suite.Equal(10, quotaFor(1, util.NewDay(2024, 04, 06)))
+	})
+
+	suite.Run("Not available room type keeps original booking", func() {
+		rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 3, util.NewDay(2024, 04, 06), util.NewDay(2024, 04, 07))
+		suite.Require().NoError(err)
+		suite.Require().Len(rooms, 1)
+		rooms[0].Quota = 0
+		suite.Require().NoError(suite.Storage.GetRoomRepo().UpdateRoom(suite.Context, rooms[0].ID, rooms[0]))
+
+		order := suite.book(arseny, stay(1, 2, util.NewDay(2024, 04, 05), util.NewDay(2024, 04, 07)))
+		suite.Require().Equal(model.Booked, order.Status)
+
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 3,
+			From:       util.NewDay(2024, 04, 05),
+			To:         util.NewDay(2024, 04, 07),
+		})
+
+		modified, err := suite.Service.GetOrder(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Equal(2, modified.RoomTypeID)
+		suite.Require().Len(modified.Changes, 1)
+		suite.False(modified.Changes[0].Applied)
+
+		suite.Equal(9, quotaFor(2, util.NewDay(2024, 04, 05)))
+		suite.Equal(10, quotaFor(3, util.NewDay(2024, 04, 05)))
+	})
+
+	suite.Run("Guests do not fit smaller room type", func() {
+		order := suite.book(arseny, stay(1, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)))
+		suite.Require().Equal(model.Booked, order.Status)
+		order.Occupancy = model.Occupancy{Adults: 2, Children: 1}
+		suite.Require().NoError(suite.Storage.GetOrderRepo().UpdateOrder(suite.Context, order.ID, order))
+
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 1,
+			From:       order.CheckInAt,
+			To:         order.CheckOutAt,
+		})
+
+		modified, err := suite.Service.GetOrder(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Equal(3, modified.RoomTypeID)
+		suite.Require().Len(modified.Changes, 1)
+		suite.False(modified.Changes[0].Applied)
+		suite.Contains(modified.Changes[0].Reason, model.ErrOccupancyExceeded.Error())
+	})
+
+	suite.Run("Longer stay of paid order requests payment", func() {
+		order := suite.book(arseny, stay(1, 2, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)))
+		suite.Require().Equal(model.Booked, order.Status)
+		order.Status = model.Paid
+		suite.Require().NoError(suite.Storage.GetOrderRepo().UpdateOrder(suite.Context, order.ID, order))
+		drain(payments)
+
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 2,
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 04),
+		})
+
+		select {
+		case msg := <-payments:
+			payment, ok := msg.(events.PaymentRequest)
+			suite.Require().True(ok)
+			suite.Equal(order.ID, payment.OrderID)
+			suite.Equal(model.Money{Amount: 300_00, Currency: "EUR"}, payment.Amount, "two more nights of room type 2")
+			suite.Equal(model.Guest{Name: "Arseny", Phone: "+7 900 1234567"}, payment.Payer)
+			suite.Equal(order.UserEmail, payment.PayerEmail)
+		case <-time.After(time.Second):
+			suite.Fail("payment request was not published")
+		}
+	})
+
+	suite.Run("Booked order is charged its new price", func() {
+		order := suite.book(arseny, stay(1, 2, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)))
+		suite.Require().Equal(model.Booked, order.Status)
+		drain(payments)
+		old := paymentRequestID(order)
+
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 2,
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 04),
+		})
+
+		modified := suite.order(order.ID)
+		suite.Equal(model.Booked, modified.Status)
+		suite.Equal(model.Money{Amount: 450_00, Currency: "EUR"}, modified.Price.Total)
+		suite.Require().Len(modified.Payments, 2)
+		suite.Equal(old, modified.Payments[0].ID)
+		suite.True(modified.IsReplacedPayment(old))
+		suite.NotEqual(old, paymentRequestID(modified))
+		suite.Equal(modified.Price.Total, modified.Payments[1].Amount)
+
+		saga, err := suite.ServiceImpl.GetSaga(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Equal(paymentRequestID(modified), saga.PaymentID)
+
+		select {
+		case msg := <-payments:
+			payment, ok := msg.(events.PaymentRequest)
+			suite.Require().True(ok)
+			suite.Equal(paymentRequestID(modified), payment.ID)
+			suite.Equal(modified.Price.Total, payment.Amount)
+		case <-time.After(time.Second):
+			suite.Fail("payment request was not published")
+		}
+
+		// failure and late success of payment of old price do not settle the order, its money is returned
+		suite.ServiceImpl.FailedPaymentEventHandler(suite.Context, events.FailedPaymentEvent{PaymentID: old, OrderID: order.ID, Reason: "declined"})
+		suite.Equal(model.Booked, suite.order(order.ID).Status)
+
+		suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: old, OrderID: order.ID, Amount: order.Price.Total})
+		modified = suite.order(order.ID)
+		suite.Equal(model.Booked, modified.Status)
+		suite.Require().Len(modified.Refunds, 1)
+		suite.Equal(order.Price.Total, modified.Refunds[0].Amount)
+		suite.Equal(model.ModificationRefund, modified.Refunds[0].Reason)
+		suite.Zero(modified.NetPaid().Amount)
+
+		suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(modified), OrderID: order.ID, Amount: modified.Price.Total})
+		modified = suite.order(order.ID)
+		suite.Equal(model.Paid, modified.Status)
+		suite.Equal(modified.Price.Total, modified.NetPaid())
+	})
+}
+
+func (suite *BookingServiceSuite) TestBookingService_ReservationWithPromoCode() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 04, 01) }
+
+	newOrderEvent := func(email string) ReservationOrder {
+		return ReservationOrder{
+			ID:         uuid.New(),
+			HotelID:    1,
+			RoomTypeID: 1,
+			UserEmail:  email,
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 03),
+			PromoCode:  "welcome10",
+		}
+	}
+
+	first := newOrderEvent("ars-saz@ya.ru")
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, first)
+
+	order, err := suite.Service.GetOrder(suite.Context, first.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.Booked, order.Status)
+	suite.Equal("WELCOME10", order.PromoCode)
+	suite.Equal(model.Money{Amount: 20_00, Currency: "EUR"}, order.Price.Discount)
+	suite.Equal(model.Money{Amount: 180_00, Currency: "EUR"}, order.Price.Total)
+
+	second := newOrderEvent("ars-saz@ya.ru") // WELCOME10 is limited by one usage per email
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, second)
+
+	order, err = suite.Service.GetOrder(suite.Context, second.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.InvalidPromo, order.Status)
+
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, first.From, first.To)
+	suite.Require().NoError(err)
+	for _, room := range rooms {
+		suite.Equal(9, room.Quota, "quota must be reserved only by the first order")
+	}
+
+	promo, err := suite.Storage.GetPromoRepo().GetPromoCode(suite.Context, "WELCOME10")
+	suite.Require().NoError(err)
+	suite.Equal(1, promo.Used)
+
+	// cancelled order releases its redemption, so the email may use the promo code again
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: first.ID})
+	suite.Equal(model.Cancelled, suite.order(first.ID).Status)
+
+	promo, err = suite.Storage.GetPromoRepo().GetPromoCode(suite.Context, "WELCOME10")
+	suite.Require().NoError(err)
+	suite.Zero(promo.Used)
+
+	third := newOrderEvent("ars-saz@ya.ru")
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, third)
+	suite.Equal(model.Booked, suite.order(third.ID).Status)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_PaymentEventHandlersWithLoyalty() {
+	const email = "ars-saz@ya.ru"
+
+	balance := func() int64 {
+		account, err := suite.Storage.GetLoyaltyRepo().GetAccount(suite.Context, email)
+		suite.Require().NoError(err)
+		return account.Balance
+	}
+
+	// paid order earns points
+	paid := suite.book(func(order *ReservationOrder) { order.UserEmail = email })
+	suite.Require().Equal(model.Booked, paid.Status)
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(paid), OrderID: paid.ID, Amount: paid.Price.Total})
+
+	order, err := suite.Service.GetOrder(suite.Context, paid.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.Paid, order.Status)
+	suite.EqualValues(200, balance())
+
+	// points are redeemed as discount and returned when payment fails
+	discounted := suite.book(func(order *ReservationOrder) {
+		order.UserEmail = email
+		order.LoyaltyPoints = 150
+	})
+	suite.Require().Equal(model.Booked, discounted.Status)
+	suite.EqualValues(150, discounted.LoyaltyPoints)
+	suite.Equal(model.Money{Amount: 200_00 - 150, Currency: "EUR"}, discounted.Price.Total)
+	suite.EqualValues(50, balance())
+
+	suite.ServiceImpl.FailedPaymentEventHandler(suite.Context, events.FailedPaymentEvent{OrderID: discounted.ID, Reason: "declined"})
+
+	order, err = suite.Service.GetOrder(suite.Context, discounted.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.FailedPay, order.Status)
+	suite.Equal("payment failed: declined", order.FailureReason, "reason is stored with the status")
+	suite.EqualValues(200, balance())
+
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, paid.From, paid.To)
+	suite.Require().NoError(err)
+	for _, room := range rooms {
+		suite.Equal(9, room.Quota, "quota of failed order must be released")
+	}
+
+	// rolled back reversal leaves no entries in ledger
+	tx, err := suite.Storage.BeginTx(suite.Context)
+	suite.Require().NoError(err)
+	suite.ServiceImpl.reverseLoyaltyPointsTx(suite.Context, tx, paid)
+	tx.Execute(func() error { return errors.New("order is not stored") }, func() error { return nil })
+	suite.Error(tx.Commit())
+	suite.EqualValues(200, balance())
+
+	ledger, err := suite.Storage.GetLoyaltyRepo().GetLedger(suite.Context, email)
+	suite.Require().NoError(err)
+	suite.Len(ledger, 3) // earn, redeem, reverse redeem
+
+	// cancellation takes back earned points
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: paid.ID})
+	suite.EqualValues(0, balance())
+
+	ledger, err = suite.Storage.GetLoyaltyRepo().GetLedger(suite.Context, email)
+	suite.Require().NoError(err)
+	suite.Len(ledger, 4) // earn, redeem, reverse redeem, reverse earn
+
+	// points are earned for amount converted into loyalty currency
+	usd := ReservationOrder{
+		ID:         uuid.New(),
+		HotelID:    2,
+		RoomTypeID: 3,
+		UserEmail:  email,
+		From:       util.NewDay(2024, 04, 01),
+		To:         util.NewDay(2024, 04, 03),
+	}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, usd)
+	usd = suite.order(usd.ID)
+	suite.Require().Equal("USD", usd.Price.Total.Currency)
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(usd), OrderID: usd.ID, Amount: usd.Price.Total})
+
+	rate, err := suite.Storage.GetFXRepo().GetRate(suite.Context, "USD", model.LoyaltyCurrency, suite.ServiceImpl.now())
+	suite.Require().NoError(err)
+	eur, err := rate.Convert(usd.Price.Total, suite.ServiceImpl.now())
+	suite.Require().NoError(err)
+	suite.Equal(model.EarnedPoints(eur.To, model.BasicTier), balance())
+	suite.Less(balance(), usd.Price.Total.Amount/100, "dollar is cheaper than euro")
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Waitlist() {
+	now := util.NewDay(2024, 03, 31)
+	suite.ServiceImpl.now = func() time.Time { return now }
+
+	notifications, err := suite.Queue.Subscribe(suite.Context, queue.NotificationRequest)
+	suite.Require().NoError(err)
+
+	from, to := util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)
+
+	// only one room is left for the period
+	suite.ServiceImpl.InventoryUpdateEventHandler(suite.Context, events.InventoryUpdateEvent{
+		HotelID: 2, RoomTypeID: 3, From: from, To: to, Delta: -9,
+	})
+
+	later := func(*ReservationOrder) { now = now.Add(time.Minute) } // waitlist is served in order of requests
+	room := stay(2, 3, from, to)
+	waitlist := func(order *ReservationOrder) { order.Waitlist = true }
+
+	booked := suite.book(later, room)
+	suite.Require().Equal(model.Booked, booked.Status)
+
+	notWaiting := suite.book(later, room)
+	suite.Equal(model.NoRooms, notWaiting.Status)
+	first := suite.book(later, room, waitlist)
+	suite.Equal(model.NoRooms, first.Status)
+	second := suite.book(later, room, waitlist)
+	suite.Equal(model.NoRooms, second.Status)
+
+	entries, err := suite.Service.GetListWaitlist(suite.Context)
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 2)
+
+	suite.ServiceImpl.JoinWaitlistEventHandler(suite.Context, events.JoinWaitlistEvent{OrderID: booked.ID})
+	suite.ServiceImpl.JoinWaitlistEventHandler(suite.Context, events.JoinWaitlistEvent{OrderID: first.ID})
+
+	joined, err := suite.Service.GetListWaitlist(suite.Context)
+	suite.Require().NoError(err)
+	suite.Equal(entries, joined, "booked order is not waitlisted, waiting order is waitlisted only once")
+
+	// cancellation releases room for the earliest waitlisted order
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: booked.ID})
+
+	suite.Equal(model.Booked, suite.order(first.ID).Status)
+	suite.Equal(model.NoRooms, suite.order(second.ID).Status)
+	suite.Equal(model.NoRooms, suite.order(notWaiting.ID).Status)
+
+	notification := suite.notification(notifications, first.ID, model.WaitlistBookedNotification)
+	suite.Equal(model.Booked, notification.Order.Status)
+
+	// inventory increase serves the next one
+	suite.ServiceImpl.InventoryUpdateEventHandler(suite.Context, events.InventoryUpdateEvent{
+		HotelID: 2, RoomTypeID: 3, From: from, To: to, Delta: 1,
+	})
+	suite.Equal(model.Booked, suite.order(second.ID).Status)
+
+	entries, err = suite.Service.GetListWaitlist(suite.Context)
+	suite.Require().NoError(err)
+	for _, entry := range entries {
+		suite.Equal(model.WaitlistFulfilled, entry.Status)
+	}
+
+	// not paid orders expire and release quota
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{
+		At: suite.ServiceImpl.now().Add(paymentHoldTTL + time.Second),
+	})
+	suite.Equal(model.Expired, suite.order(first.ID).Status)
+	suite.Equal(model.Expired, suite.order(second.ID).Status)
+
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 2, 3, from, to)
+	suite.Require().NoError(err)
+	for _, room := range rooms {
+		suite.Equal(2, room.Quota)
+	}
+
+	// order which did not opt in at submission can join later
+	suite.ServiceImpl.JoinWaitlistEventHandler(suite.Context, events.JoinWaitlistEvent{OrderID: notWaiting.ID})
+	joined, err = suite.Service.GetListWaitlist(suite.Context)
+	suite.Require().NoError(err)
+	suite.Require().Len(joined, 3)
+	suite.Equal(notWaiting.ID, joined[2].OrderID)
+	suite.Equal(model.WaitlistWaiting, joined[2].Status)
+}
+
+func (suite *BookingServiceSuite) order(id ReservationOrderID) ReservationOrder {
+	order, err := suite.Service.GetOrder(suite.Context, id)
+	suite.Require().NoError(err)
+	return order
+}
+
+// book places reservation order and returns it as processed. By default it is two nights in room type 1 of hotel 1,
+// overrides change the order before it is placed.
+func (suite *BookingServiceSuite) book(overrides ...func(*ReservationOrder)) ReservationOrder {
+	event := ReservationOrder{
+		ID:         uuid.New(),
+		HotelID:    1,
+		RoomTypeID: 1,
+		UserEmail:  "ars-saz@ya.ru",
+		From:       util.NewDay(2024, 04, 01),
+		To:         util.NewDay(2024, 04, 03),
+	}
+	for _, override := range overrides {
+		override(&event)
+	}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+
+	return suite.order(event.ID)
+}
+
+// stay - override of booked room type and nights.
+func stay(hotelID, roomTypeID int, from, to util.Day) func(*ReservationOrder) {
+	return func(order *ReservationOrder) {
+		order.HotelID, order.RoomTypeID = hotelID, roomTypeID
+		order.From, order.To = from, to
+	}
+}
+
+// notification returns first published notification of the order kind, other notifications are skipped.
+func (suite *BookingServiceSuite) notification(ch <-chan queue.Msg, orderID ReservationOrderID, kind model.NotificationKind) events.NotificationRequest {
+	for {
+		select {
+		case msg := <-ch:
+			notification, ok := msg.(events.NotificationRequest)
+			suite.Require().True(ok)
+			if notification.OrderID == orderID && notification.Kind == kind {
+				return notification
+			}
+		case <-time.After(time.Second):
+			suite.FailNow("notification was not published", "%s of order %v", kind, orderID)
+		}
+	}
+}
+
+func (suite *BookingServiceSuite) TestBookingService_StatusChanges() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 31) }
+
+	changes, err := suite.Queue.Subscribe(suite.Context, queue.OrderStatusChanged)
+	suite.Require().NoError(err)
+
+	next := func() events.OrderStatusChangedEvent {
+		select {
+		case msg := <-changes:
+			change, ok := msg.(events.OrderStatusChangedEvent)
+			suite.Require().True(ok)
+			return change
+		case <-time.After(time.Second):
+			suite.FailNow("status change was not published")
+			return events.OrderStatusChangedEvent{}
+		}
+	}
+
+	order := ReservationOrder{
+		ID:         uuid.New(),
+		HotelID:    2,
+		RoomTypeID: 3,
+		UserEmail:  "ars-saz@ya.ru",
+		From:       util.NewDay(2024, 04, 01),
+		To:         util.NewDay(2024, 04, 02),
+	}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, order)
+
+	change := next()
+	suite.Equal(order.ID, change.OrderID)
+	suite.Equal(model.New, change.PreviousStatus)
+	suite.Equal(model.Booked, change.Status)
+	suite.Equal(model.Booked, change.Order.Status)
+
+	paid := suite.order(order.ID)
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(paid), OrderID: order.ID, Amount: paid.Price.Total})
+	change = next()
+	suite.Equal(model.Booked, change.PreviousStatus)
+	suite.Equal(model.Paid, change.Status)
+
+	// redelivered payment does not change status
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(paid), OrderID: order.ID, Amount: paid.Price.Total})
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: order.ID})
+	change = next()
+	suite.Equal(model.Paid, change.PreviousStatus)
+	suite.Equal(model.Cancelled, change.Status)
+	suite.NotEqual(uuid.Nil, change.EventID)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Notifications() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 31) }
+
+	notifications, err := suite.Queue.Subscribe(suite.Context, queue.NotificationRequest)
+	suite.Require().NoError(err)
+
+	room := stay(2, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02))
+	guest := func(email string) func(*ReservationOrder) {
+		return func(order *ReservationOrder) {
+			order.UserEmail = email
+			order.Guest = model.Guest{Name: "Ars"}
+		}
+	}
+
+	paid := suite.book(room, guest("paid@example.com"))
+	suite.Require().Equal(model.Booked, paid.Status)
+	notification := suite.notification(notifications, paid.ID, model.OrderBookedNotification)
+	suite.Equal("paid@example.com", notification.UserEmail)
+	suite.Equal(paid.Price.Total, notification.Order.Price.Total)
+
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(paid), OrderID: paid.ID, Amount: paid.Price.Total})
+	suite.notification(notifications, paid.ID, model.OrderPaidNotification)
+
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: paid.ID})
+	notification = suite.notification(notifications, paid.ID, model.OrderCancelledNotification)
+	suite.Equal(model.Cancelled, notification.Order.Status)
+
+	declined := suite.book(room, guest("declined@example.com"))
+	suite.ServiceImpl.FailedPaymentEventHandler(suite.Context, events.FailedPaymentEvent{OrderID: declined.ID, Reason: "insufficient funds"})
+	notification = suite.notification(notifications, declined.ID, model.OrderFailedNotification)
+	suite.Equal(model.FailedPay, notification.Order.Status)
+	suite.Contains(notification.Order.FailureReason, "insufficient funds")
+
+	suite.ServiceImpl.InventoryUpdateEventHandler(suite.Context, events.InventoryUpdateEvent{
+		HotelID: 2, RoomTypeID: 3, From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 02), Delta: -10,
+	})
+	noRooms := suite.book(room, guest("late@example.com"))
+	suite.Require().Equal(model.NoRooms, noRooms.Status)
+	suite.notification(notifications, noRooms.ID, model.OrderFailedNotification)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Overbooking() {
+	from, to := util.NewDay(2024, 04, 03), util.NewDay(2024, 04, 04)
+
+	// sell all physical rooms
+	suite.ServiceImpl.InventoryUpdateEventHandler(suite.Context, events.InventoryUpdateEvent{
+		HotelID: 1, RoomTypeID: 3, From: from, To: to, Delta: -8,
+	})
+
+	room := stay(1, 3, from, to)
+
+	suite.Equal(model.Booked, suite.book(room).Status)
+	suite.Equal(model.Booked, suite.book(room).Status)
+	suite.Equal(model.NoRooms, suite.book(room).Status, "overbooking is not allowed without rule")
+
+	rule := model.OverbookingRule{
+		ID: uuid.New(), HotelID: 1, RoomTypeID: 3, LimitType: model.PercentOverbooking, Limit: 50, // 1 room of 2
+	}
+	suite.Require().NoError(suite.Storage.GetOverbookingRepo().StoreRule(suite.Context, rule))
+
+	suite.Equal(model.Booked, suite.book(room).Status)
+	suite.Equal(model.NoRooms, suite.book(room).Status)
+
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 3, from, to)
+	suite.Require().NoError(err)
+	for _, room := range rooms {
+		suite.Equal(-1, room.Quota)
+		suite.Equal(2, room.Capacity)
+		suite.Equal(3, room.Sold())
+	}
+
+	// capacity can not be reduced under sold rooms
+	suite.ServiceImpl.InventoryUpdateEventHandler(suite.Context, events.InventoryUpdateEvent{
+		HotelID: 1, RoomTypeID: 3, From: from, To: to, Delta: -1,
+	})
+
+	rooms, err = suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 3, from, to)
+	suite.Require().NoError(err)
+	for _, room := range rooms {
+		suite.Equal(2, room.Capacity)
+	}
+
+	// rooms are added to a day which stays oversold beyond allowance after the rule is removed
+	suite.Require().NoError(suite.Storage.GetOverbookingRepo().DeleteRule(suite.Context, rule.ID))
+	rule = model.OverbookingRule{
+		ID: uuid.New(), HotelID: 1, RoomTypeID: 3, LimitType: model.AbsoluteOverbooking, Limit: 2,
+	}
+	suite.Require().NoError(suite.Storage.GetOverbookingRepo().StoreRule(suite.Context, rule))
+	suite.Equal(model.Booked, suite.book(room).Status)
+	suite.Require().NoError(suite.Storage.GetOverbookingRepo().DeleteRule(suite.Context, rule.ID))
+
+	suite.ServiceImpl.InventoryUpdateEventHandler(suite.Context, events.InventoryUpdateEvent{
+		HotelID: 1, RoomTypeID: 3, From: from, To: to, Delta: 1,
+	})
+
+	rooms, err = suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 3, from, to)
+	suite.Require().NoError(err)
+	for _, room := range rooms {
+		suite.Equal(3, room.Capacity)
+		suite.Equal(-1, room.Quota)
+	}
+}
+
+func (suite *BookingServiceSuite) TestBookingService_StayRestrictions() {
+	// minimum 3 nights through the weekend, no arrivals on Saturday
+	suite.ServiceImpl.StayRestrictionsUpdateEventHandler(suite.Context, events.StayRestrictionsUpdateEvent{
+		HotelID: 1, RoomTypeID: 1, From: util.NewDay(2024, 04, 05), To: util.NewDay(2024, 04, 07),
+		Restrictions: model.StayRestrictions{MinStay: 3},
+	})
+	suite.ServiceImpl.StayRestrictionsUpdateEventHandler(suite.Context, events.StayRestrictionsUpdateEvent{
+		HotelID: 1, RoomTypeID: 1, From: util.NewDay(2024, 04, 06), To: util.NewDay(2024, 04, 07),
+		Restrictions: model.StayRestrictions{MinStay: 3, ClosedToArrival: true},
+	})
+
+	short := suite.book(stay(1, 1, util.NewDay(2024, 04, 05), util.NewDay(2024, 04, 07)))
+	suite.Equal(model.Restricted, short.Status)
+	suite.Equal("min_stay: minimum stay is 3 nights on 2024-04-05", short.FailureReason)
+
+	saturday := suite.book(stay(1, 1, util.NewDay(2024, 04, 06), util.NewDay(2024, 04, 07)))
+	suite.Equal(model.Restricted, saturday.Status)
+	suite.Contains(saturday.FailureReason, string(model.ClosedToArrivalReason))
+
+	long := suite.book(stay(1, 1, util.NewDay(2024, 04, 04), util.NewDay(2024, 04, 07)))
+	suite.Equal(model.Booked, long.Status)
+	suite.Empty(long.FailureReason)
+
+	weekday := suite.book(stay(1, 1, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)))
+	suite.Equal(model.Booked, weekday.Status)
+
+	// modification into restricted stay keeps original booking
+	suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+		OrderID:    weekday.ID,
+		RoomTypeID: 1,
+		From:       util.NewDay(2024, 04, 06),
+		To:         util.NewDay(2024, 04, 07),
+	})
+
+	modified := suite.order(weekday.ID)
+	suite.Equal(util.NewDay(2024, 04, 01), modified.From)
+	suite.Require().Len(modified.Changes, 1)
+	suite.False(modified.Changes[0].Applied)
+	suite.Contains(modified.Changes[0].Reason, string(model.ClosedToArrivalReason))
+}
+
+func (suite *BookingServiceSuite) TestBookingService_NightStay() {
+	quotaFor := func(day util.Day) int {
+		rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, day, day.AddDate(0, 0, 1))
+		suite.Require().NoError(err)
+		suite.Require().Len(rooms, 1)
+		return rooms[0].Quota
+	}
+
+	// late evening arrival in Berlin is requested as UTC timestamp
+	event := ReservationOrder{
+		ID:         uuid.New(),
+		HotelID:    1,
+		RoomTypeID: 1,
+		UserEmail:  "ars-saz@ya.ru",
+		From:       time.Date(2024, 04, 01, 22, 30, 0, 0, time.UTC),
+		To:         time.Date(2024, 04, 03, 9, 0, 0, 0, time.UTC),
+	}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+
+	order := suite.order(event.ID)
+	suite.Require().Equal(model.Booked, order.Status)
+	suite.Equal(util.NewDay(2024, 04, 02), order.From)
+	suite.Equal(util.NewDay(2024, 04, 03), order.To)
+	suite.True(order.CheckInAt.Equal(time.Date(2024, 04, 02, 13, 0, 0, 0, time.UTC)))
+	suite.True(order.CheckOutAt.Equal(time.Date(2024, 04, 03, 9, 0, 0, 0, time.UTC)))
+	suite.Len(order.Price.Nights, 1)
+
+	suite.Equal(10, quotaFor(util.NewDay(2024, 04, 01)))
+	suite.Equal(9, quotaFor(util.NewDay(2024, 04, 02)))
+	suite.Equal(10, quotaFor(util.NewDay(2024, 04, 03)), "checkout day is free for next guest")
+
+	empty := ReservationOrder{
+		ID:         uuid.New(),
+		HotelID:    1,
+		RoomTypeID: 1,
+		UserEmail:  "ars-saz@ya.ru",
+		From:       util.NewDay(2024, 04, 04),
+		To:         util.NewDay(2024, 04, 04),
+	}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, empty)
+	suite.Equal(model.FailedBook, suite.order(empty.ID).Status)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Allotment() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 25) } // within payment hold at release
+
+	quotaFor := func(day util.Day) int {
+		rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, day, day.AddDate(0, 0, 1))
+		suite.Require().NoError(err)
+		suite.Require().Len(rooms, 1)
+		return rooms[0].Quota
+	}
+
+	allotment := func(code string) model.Allotment {
+		allotment, err := suite.Service.GetAllotment(suite.Context, code)
+		suite.Require().NoError(err)
+		return allotment
+	}
+
+	block := func(code string) func(*ReservationOrder) {
+		return func(order *ReservationOrder) {
+			order.UserEmail = "agent@travel.com"
+			order.BlockCode = code
+		}
+	}
+
+	releaseAt := util.NewDay(2024, 03, 25).Add(10 * time.Minute)
+
+	suite.ServiceImpl.AllotmentEventHandler(suite.Context, events.AllotmentEvent{
+		Code: "expo", Partner: "Expo GmbH", HotelID: 1, RoomTypeID: 1, Rooms: 2,
+		From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 04), ReleaseAt: releaseAt,
+	})
+	suite.ServiceImpl.AllotmentEventHandler(suite.Context, events.AllotmentEvent{
+		Code: "HUGE", HotelID: 1, RoomTypeID: 1, Rooms: 9,
+		From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 02), ReleaseAt: releaseAt,
+	})
+	suite.ServiceImpl.AllotmentEventHandler(suite.Context, events.AllotmentEvent{
+		Code: "GHOST", HotelID: 1, RoomTypeID: 4, Rooms: 1,
+		From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 02), ReleaseAt: releaseAt,
+	})
+	suite.ServiceImpl.AllotmentEventHandler(suite.Context, events.AllotmentEvent{
+		Code: "LATE", HotelID: 1, RoomTypeID: 1, Rooms: 1,
+		From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 02), ReleaseAt: util.NewDay(2024, 03, 24),
+	})
+
+	suite.Equal(model.AllotmentActive, allotment("EXPO").Status)
+	suite.Equal(model.AllotmentRejected, allotment("HUGE").Status, "only 8 rooms are left in general sale")
+	suite.Equal(model.AllotmentRejected, allotment("GHOST").Status)
+	suite.Contains(allotment("GHOST").Reason, model.ErrUnknownRoomType.Error())
+	_, err := suite.Service.GetAllotment(suite.Context, "LATE")
+	suite.ErrorIs(err, storage.ErrNotFound, "allotment released in the past is not held")
+	suite.Equal(8, quotaFor(util.NewDay(2024, 04, 01)))
+	suite.Equal(8, quotaFor(util.NewDay(2024, 04, 03)))
+
+	// bookings draw from the block, general quota is not touched
+	first, second, third := suite.book(block("expo")), suite.book(block("EXPO")), suite.book(block("EXPO"))
+	suite.Equal(model.Booked, first.Status)
+	suite.Equal(model.Booked, second.Status)
+	suite.Equal(model.NoRooms, third.Status, "block is exhausted")
+	suite.Equal(8, quotaFor(util.NewDay(2024, 04, 01)))
+	suite.Equal(0, allotment("EXPO").Nights[0].Held)
+
+	unknown := suite.book(block("NOPE"))
+	suite.Equal(model.FailedBook, unknown.Status)
+	suite.Contains(unknown.FailureReason, model.ErrBlockCodeMismatch.Error())
+
+	// block orders keep their stay, rejected modification is recorded
+	suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+		OrderID: first.ID, RoomTypeID: 1, From: util.NewDay(2024, 04, 02), To: util.NewDay(2024, 04, 04),
+	})
+	modified := suite.order(first.ID)
+	suite.Equal(first.From, modified.From)
+	suite.Require().Len(modified.Changes, 1)
+	suite.False(modified.Changes[0].Applied)
+	suite.Contains(modified.Changes[0].Reason, "allotment EXPO")
+
+	// cancelled room returns to the block
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: second.ID})
+	suite.Equal(1, allotment("EXPO").Nights[0].Held)
+	suite.Equal(8, quotaFor(util.NewDay(2024, 04, 01)))
+
+	// at release date unused rooms return to general sale
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: releaseAt})
+	released := allotment("EXPO")
+	suite.Equal(model.AllotmentReleased, released.Status)
+	suite.Equal(1, released.Nights[0].Sold)
+	suite.Equal(9, quotaFor(util.NewDay(2024, 04, 01)))
+	suite.Equal(10, quotaFor(util.NewDay(2024, 04, 03)))
+
+	// after release cancelled room returns to general quota
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: first.ID})
+	suite.Equal(10, quotaFor(util.NewDay(2024, 04, 01)))
+}
+
+func (suite *BookingServiceSuite) TestBookingService_CheckAvailability() {
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 03))
+	suite.Require().NoError(err)
+	suite.Require().Len(rooms, 1)
+
+	soldOut := rooms[0]
+	soldOut.Quota = 0
+	suite.Require().NoError(suite.Storage.GetRoomRepo().UpdateRoom(suite.Context, soldOut.ID, soldOut))
+
+	order := ReservationOrder{
+		HotelID:    1,
+		RoomTypeID: 1,
+		From:       time.Date(2024, 04, 01, 12, 0, 0, 0, time.UTC),
+		To:         time.Date(2024, 04, 04, 9, 0, 0, 0, time.UTC),
+	}
+
+	nights, err := suite.Service.CheckAvailability(suite.Context, order)
+	suite.Require().NoError(err)
+	suite.Equal([]time.Time{util.NewDay(2024, 04, 02)}, nights)
+
+	order.To = util.NewDay(2024, 04, 02)
+	nights, err = suite.Service.CheckAvailability(suite.Context, order)
+	suite.Require().NoError(err)
+	suite.Empty(nights)
+
+	order.From, order.To = util.NewDay(2025, 04, 01), util.NewDay(2025, 04, 02)
+	nights, err = suite.Service.CheckAvailability(suite.Context, order)
+	suite.Require().NoError(err)
+	suite.Equal([]time.Time{util.NewDay(2025, 04, 01)}, nights, "nights without inventory are unavailable")
+}
+
+func (suite *BookingServiceSuite) TestBookingService_SearchAvailability() {
+	search := func(query model.AvailabilityQuery) []model.AvailabilityOffer {
+		offers, err := suite.Service.SearchAvailability(suite.Context, query)
+		suite.Require().NoError(err)
+		return offers
+	}
+
+	offers := search(model.AvailabilityQuery{From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 03), Rooms: 1})
+	suite.Require().Len(offers, 6, "every room type of both hotels")
+	suite.Equal(1, offers[0].HotelID)
+	suite.Equal(1, offers[0].RoomTypeID)
+	suite.Equal(10, offers[0].Available)
+	suite.Equal(model.Money{Amount: 200_00, Currency: "EUR"}, offers[0].Price.Total)
+	suite.True(offers[0].Bookable)
+
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 03))
+	suite.Require().NoError(err)
+	suite.Require().Len(rooms, 1)
+
+	lowQuota := rooms[0]
+	lowQuota.Quota = 2
+	suite.Require().NoError(suite.Storage.GetRoomRepo().UpdateRoom(suite.Context, lowQuota.ID, lowQuota))
+
+	offers = search(model.AvailabilityQuery{HotelID: 1, From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 03), Rooms: 1})
+	suite.Require().Len(offers, 3)
+	suite.Equal(2, offers[0].Available, "rooms left on the most sold night")
+
+	offers = search(model.AvailabilityQuery{HotelID: 1, From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 03), Rooms: 3})
+	suite.Require().Len(offers, 2, "room type without 3 rooms on 04-02 is not offered")
+	suite.Equal(2, offers[0].RoomTypeID)
+
+	suite.Empty(search(model.AvailabilityQuery{From: util.NewDay(2024, 04, 01), To: util.NewDay(2024, 04, 03), Rooms: 11}))
+	suite.Empty(search(model.AvailabilityQuery{From: util.NewDay(2025, 04, 01), To: util.NewDay(2025, 04, 03), Rooms: 1}))
+
+	// second hotel is closed to arrival on Saturday
+	offers = search(model.AvailabilityQuery{HotelID: 2, From: util.NewDay(2024, 04, 06), To: util.NewDay(2024, 04, 07), Rooms: 1})
+	suite.Require().Len(offers, 3)
+	suite.False(offers[0].Bookable)
+	suite.Contains(offers[0].Restriction, string(model.ClosedToArrivalReason))
+
+	_, err = suite.Service.SearchAvailability(suite.Context, model.AvailabilityQuery{From: util.NewDay(2024, 04, 03), To: util.NewDay(2024, 04, 01), Rooms: 1})
+	suite.ErrorIs(err, model.ErrAvailabilityQueryInvalid)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Alternatives() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 25) }
+
+	// small hotel few hundred meters from the first one, rooms are sold from 04-02
+	annex := model.Hotel{
+		ID: 3, Name: "Spree Annex", Geo: &model.GeoPoint{Lat: 52.5190, Lon: 13.3920},
+		Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00", Currency: "EUR",
+	}
+	suite.Require().NoError(suite.Storage.GetHotelRepo().StoreHotel(suite.Context, annex))
+	suite.Require().NoError(suite.Storage.GetRoomTypeRepo().StoreRoomType(suite.Context, model.RoomType{ID: 1, HotelID: annex.ID, Name: "Double", Capacity: 2}))
+	for i, day := range util.NightsBetween(util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 04)) {
+		suite.Require().NoError(suite.Storage.GetRoomRepo().StoreRoom(suite.Context, model.RoomAvailability{ID: 1000 + i, HotelID: annex.ID, RoomTypeID: 1, Date: day, Quota: 1, Capacity: 1}))
+		suite.Require().NoError(suite.Storage.GetRateRepo().StoreRate(suite.Context, model.Rate{ID: 1000 + i, HotelID: annex.ID, RoomTypeID: 1, Date: day, Price: model.Money{Amount: 90_00, Currency: "EUR"}}))
+	}
+
+	rooms, err := suite.Storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(suite.Context, 1, 1, util.NewDay(2024, 04, 03), util.NewDay(2024, 04, 04))
+	suite.Require().NoError(err)
+	suite.Require().Len(rooms, 1)
+
+	soldOut := rooms[0]
+	soldOut.Quota = 0
+	suite.Require().NoError(suite.Storage.GetRoomRepo().UpdateRoom(suite.Context, soldOut.ID, soldOut))
+
+	event := ReservationOrder{
+		ID:         uuid.New(),
+		HotelID:    1,
+		RoomTypeID: 1,
+		UserEmail:  "guest@mail.com",
+		From:       util.NewDay(2024, 04, 02),
+		To:         util.NewDay(2024, 04, 04),
+		Occupancy:  model.Occupancy{Adults: 2},
+	}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+
+	order := suite.order(event.ID)
+	suite.Require().Equal(model.NoRooms, order.Status)
+
+	type stay struct {
+		kind                model.AlternativeKind
+		hotelID, roomTypeID int
+		from, to            time.Time
+	}
+	var stays []stay
+	for _, alternative := range order.Alternatives {
+		suite.NotZero(alternative.Price.Total.Amount)
+		stays = append(stays, stay{alternative.Kind, alternative.HotelID, alternative.RoomTypeID, alternative.From, alternative.To})
+	}
+
+	suite.Equal([]stay{
+		{model.AlternativeRoomType, 1, 2, util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 04)},
+		{model.AlternativeRoomType, 1, 3, util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 04)},
+		{model.AlternativeDates, 1, 1, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 03)},
+		{model.AlternativeDates, 1, 1, util.NewDay(2024, 04, 04), util.NewDay(2024, 04, 06)},
+		{model.AlternativeDates, 1, 1, util.NewDay(2024, 04, 05), util.NewDay(2024, 04, 07)},
+		{model.AlternativeHotel, 3, 1, util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 04)},
+	}, stays)
+
+	// alternatives must fit guests of the order
+	event.ID, event.Occupancy = uuid.New(), model.Occupancy{Adults: 4}
+	suite.ServiceImpl.ReservationOrderEventHandler(suite.Context, event)
+	suite.Equal([]model.Alternative{{Kind: model.AlternativeRoomType, HotelID: 1, RoomTypeID: 3, From: event.From, To: event.To, Price: order.Alternatives[1].Price}},
+		suite.order(event.ID).Alternatives, "only suite fits four guests")
+}
+
+func (suite *BookingServiceSuite) TestBookingService_DepositAndBalance() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 01) }
+
+	payments, err := suite.Queue.Subscribe(suite.Context, queue.PaymentRequest)
+	suite.Require().NoError(err)
+	refunds, err := suite.Queue.Subscribe(suite.Context, queue.RefundRequest)
+	suite.Require().NoError(err)
+	notifications, err := suite.Queue.Subscribe(suite.Context, queue.NotificationRequest)
+	suite.Require().NoError(err)
+
+	nextPayment := func() events.PaymentRequest {
+		select {
+		case msg := <-payments:
+			payment, ok := msg.(events.PaymentRequest)
+			suite.Require().True(ok)
+			return payment
+		case <-time.After(time.Second):
+			suite.FailNow("payment was not requested")
+			return events.PaymentRequest{}
+		}
+	}
+
+	room := stay(2, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02))
+	depositPlan := func(order *ReservationOrder) { order.RatePlanID = 5 } // 30% deposit, balance 7 days before arrival
+
+	order := suite.book(room, depositPlan)
+	suite.Require().Equal(model.Booked, order.Status)
+	suite.Require().Len(order.PaymentSchedule, 2)
+
+	deposit, balance := order.PaymentSchedule[0], order.PaymentSchedule[1]
+	suite.Equal(model.DepositInstallment, deposit.Kind)
+	suite.Equal(order.Price.Total.Percent(30), deposit.Amount)
+	suite.False(deposit.RequestedAt.IsZero())
+	suite.Equal(model.BalanceInstallment, balance.Kind)
+	suite.Equal(order.Price.Total.Amount-deposit.Amount.Amount, balance.Amount.Amount)
+	suite.Equal(order.CheckInAt.AddDate(0, 0, -7), balance.DueAt)
+	suite.True(balance.RequestedAt.IsZero(), "balance is requested when it is due")
+
+	payment := nextPayment()
+	suite.Equal(deposit.PaymentID, payment.ID)
+	suite.Equal(deposit.Amount, payment.Amount)
+
+	// deposit is paid, the rest of price is still due
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+		PaymentID: deposit.PaymentID, OrderID: order.ID, Amount: deposit.Amount, PaidAt: suite.ServiceImpl.now(),
+	})
+	order = suite.order(order.ID)
+	suite.Equal(model.DepositPaid, order.Status)
+	suite.Equal(deposit.Amount, order.PaidAmount)
+	suite.True(order.PaymentSchedule[0].IsPaid())
+	suite.notification(notifications, order.ID, model.OrderDepositPaidNotification)
+
+	earned := func() []model.LoyaltyEntry {
+		entries, err := suite.Storage.GetLoyaltyRepo().GetOrderEntries(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		return entries
+	}
+	suite.Empty(earned(), "deposit earns no points")
+
+	saga, err := suite.ServiceImpl.GetSaga(suite.Context, order.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.BalanceStep, saga.Step, "saga waits for balance")
+	suite.Equal(model.SagaRunning, saga.Status)
+	suite.Equal(balance.DueAt.Add(stepTimeouts[model.BalanceStep]).UTC(), saga.Deadline)
+
+	// balance is not due yet
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: util.NewDay(2024, 03, 20)})
+	suite.Equal(model.DepositPaid, suite.order(order.ID).Status, "deposit paid order does not expire")
+	suite.Empty(payments)
+
+	dueAt := balance.DueAt.Add(time.Minute)
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: dueAt})
+	payment = nextPayment()
+	suite.Equal(balance.PaymentID, payment.ID)
+	suite.Equal(balance.Amount, payment.Amount)
+	suite.Equal(dueAt, suite.order(order.ID).PaymentSchedule[1].RequestedAt)
+
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: dueAt.Add(time.Minute)})
+	suite.Empty(payments, "balance is requested once")
+
+	// failed balance payment keeps the booking, balance is requested again with new payment
+	suite.ServiceImpl.FailedPaymentEventHandler(suite.Context, events.FailedPaymentEvent{
+		PaymentID: balance.PaymentID, OrderID: order.ID, Reason: "declined",
+	})
+	order = suite.order(order.ID)
+	suite.Equal(model.DepositPaid, order.Status)
+	retried := order.PaymentSchedule[1]
+	suite.Equal("declined", retried.FailureReason)
+	suite.Equal(1, retried.Failures)
+	suite.NotEqual(balance.PaymentID, retried.PaymentID)
+	suite.True(retried.RequestedAt.IsZero())
+	suite.Equal(suite.ServiceImpl.now().Add(balanceRetryDelay), retried.DueAt)
+
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: retried.DueAt})
+	payment = nextPayment()
+	suite.Equal(retried.PaymentID, payment.ID)
+	suite.Equal(balance.Amount, payment.Amount)
+
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+		PaymentID: retried.PaymentID, OrderID: order.ID, Amount: balance.Amount, PaidAt: dueAt,
+	})
+	order = suite.order(order.ID)
+	suite.Equal(model.Paid, order.Status)
+	suite.Equal(order.Price.Total, order.PaidAmount)
+	suite.Empty(order.PaymentSchedule[1].FailureReason)
+	suite.notification(notifications, order.ID, model.OrderPaidNotification)
+
+	saga, err = suite.ServiceImpl.GetSaga(suite.Context, order.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.SagaCompleted, saga.Status, "saga is completed when balance is paid")
+
+	entries := earned()
+	suite.Require().Len(entries, 1, "points are earned once for the whole price")
+	rate, err := suite.Storage.GetFXRepo().GetRate(suite.Context, order.Price.Total.Currency, model.LoyaltyCurrency, suite.ServiceImpl.now())
+	suite.Require().NoError(err)
+	total, err := rate.Convert(order.Price.Total, suite.ServiceImpl.now())
+	suite.Require().NoError(err)
+	suite.Equal(model.EarnedPoints(total.To, model.BasicTier), entries[0].Points)
+
+	invoice, err := suite.Storage.GetInvoiceRepo().GetInvoice(suite.Context, order.ID)
+	suite.Require().NoError(err, "invoice is issued when balance is paid")
+	suite.Equal(order.Price.Total, invoice.Total)
+
+	// cancelled deposit paid order refunds paid deposit only
+	cancelled := suite.book(room, depositPlan)
+	nextPayment()
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+		PaymentID: cancelled.PaymentSchedule[0].PaymentID, OrderID: cancelled.ID, Amount: cancelled.PaymentSchedule[0].Amount,
+	})
+	suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: cancelled.ID})
+	suite.Equal(model.Cancelled, suite.order(cancelled.ID).Status)
+
+	select {
+	case msg := <-refunds:
+		refund, ok := msg.(events.RefundRequest)
+		suite.Require().True(ok)
+		suite.Equal(cancelled.ID, refund.OrderID)
+		suite.Equal(cancelled.PaymentSchedule[0].Amount, refund.Amount)
+	case <-time.After(time.Second):
+		suite.FailNow("refund was not requested")
+	}
+
+	// order is cancelled when every attempt of balance payment fails
+	unpaid := suite.book(room, depositPlan)
+	nextPayment()
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+		PaymentID: unpaid.PaymentSchedule[0].PaymentID, OrderID: unpaid.ID, Amount: unpaid.PaymentSchedule[0].Amount,
+	})
+	for range balanceAttempts {
+		suite.Equal(model.DepositPaid, suite.order(unpaid.ID).Status)
+		suite.ServiceImpl.FailedPaymentEventHandler(suite.Context, events.FailedPaymentEvent{
+			PaymentID: suite.order(unpaid.ID).PaymentSchedule[1].PaymentID, OrderID: unpaid.ID, Reason: "insufficient funds",
+		})
+	}
+	unpaid = suite.order(unpaid.ID)
+	suite.Equal(model.Cancelled, unpaid.Status)
+	suite.Equal("balance payment failed: insufficient funds", unpaid.FailureReason)
+	suite.Equal(balanceAttempts, unpaid.PaymentSchedule[1].Failures)
+	suite.notification(notifications, unpaid.ID, model.OrderCancelledNotification)
+
+	saga, err = suite.ServiceImpl.GetSaga(suite.Context, unpaid.ID)
+	suite.Require().NoError(err)
+	suite.Equal(model.SagaCompensated, saga.Status)
+
+	// saga of balance which is neither paid nor failed in time is stuck, resumed saga requests the balance again
+	stuck := suite.book(room, depositPlan)
+	nextPayment()
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+		PaymentID: stuck.PaymentSchedule[0].PaymentID, OrderID: stuck.ID, Amount: stuck.PaymentSchedule[0].Amount,
+	})
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: dueAt})
+	suite.Equal(stuck.PaymentSchedule[1].PaymentID, nextPayment().ID)
+
+	saga, err = suite.ServiceImpl.GetSaga(suite.Context, stuck.ID)
+	suite.Require().NoError(err)
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: saga.Deadline})
+
+	stuckSagas, err := suite.ServiceImpl.GetListSagas(suite.Context, true)
+	suite.Require().NoError(err)
+	suite.True(slices.ContainsFunc(stuckSagas, func(saga model.Saga) bool {
+		return saga.OrderID == stuck.ID && saga.Step == model.BalanceStep
+	}), "deposit paid order waiting for balance is listed as stuck")
+
+	suite.ServiceImpl.ResumeSagaEventHandler(suite.Context, events.ResumeSagaEvent{OrderID: stuck.ID})
+	suite.Equal(stuck.PaymentSchedule[1].PaymentID, nextPayment().ID, "balance is requested again with the same ID")
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Refunds() {
+	refunds, err := suite.Queue.Subscribe(suite.Context, queue.RefundRequest)
+	suite.Require().NoError(err)
+
+	nextRefund := func() events.RefundRequest {
+		select {
+		case msg := <-refunds:
+			refund, ok := msg.(events.RefundRequest)
+			suite.Require().True(ok)
+			return refund
+		case <-time.After(time.Second):
+			suite.FailNow("refund was not requested")
+			return events.RefundRequest{}
+		}
+	}
+
+	ratePlan := func(id int) func(*ReservationOrder) {
+		return func(order *ReservationOrder) { order.RatePlanID = id }
+	}
+
+	pay := func(order ReservationOrder) ReservationOrder {
+		suite.Require().Equal(model.Booked, order.Status)
+		suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(order), OrderID: order.ID, Amount: order.Price.Total})
+
+		return suite.order(order.ID)
+	}
+
+	suite.Run("Free cancellation refunds whole price", func() {
+		suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 31) }
+
+		// flexible plan: free cancellation until a day before arrival
+		order := pay(suite.book(stay(2, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)), ratePlan(3)))
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: order.ID})
+
+		refund := nextRefund()
+		suite.Equal(order.ID, refund.OrderID)
+		suite.Equal(order.Price.Total, refund.Amount)
+		suite.Equal(model.CancellationRefund, refund.Reason)
+
+		cancelled := suite.order(order.ID)
+		suite.Require().Len(cancelled.Refunds, 1)
+		suite.Equal(model.RefundPending, cancelled.Refunds[0].Status)
+
+		// pending refund is requested again when its result is not received in time
+		suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: refund.CreatedAt.Add(refundRepublishDelay - time.Second)})
+		suite.Empty(refunds)
+		suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: refund.CreatedAt.Add(refundRepublishDelay)})
+		suite.Equal(refund, nextRefund())
+
+		refund.Status = model.RefundSucceeded
+		refund.TransactionIDs = []string{"psp_refund_1"}
+		for range 2 { // redelivered result is counted once
+			suite.ServiceImpl.RefundProcessedEventHandler(suite.Context, refund)
+		}
+
+		cancelled = suite.order(order.ID)
+		suite.Equal(model.RefundSucceeded, cancelled.Refunds[0].Status)
+		suite.Equal([]string{"psp_refund_1"}, cancelled.Refunds[0].TransactionIDs)
+		suite.Equal(order.Price.Total, cancelled.RefundedAmount)
+
+		invoices, err := suite.Storage.GetInvoiceRepo().GetListOrderInvoices(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Require().Len(invoices, 2, "refund is credited once")
+		suite.Equal(model.CreditNote, invoices[1].Kind)
+		suite.Equal(invoices[0].Number, invoices[1].Corrects)
+		suite.Equal(-order.Price.Total.Amount, invoices[1].Total.Amount)
+	})
+
+	suite.Run("Late cancellation keeps fee", func() {
+		suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 04, 01) }
+
+		order := pay(suite.book(stay(2, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)), ratePlan(3)))
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: order.ID})
+
+		cancelled := suite.order(order.ID)
+		suite.Equal(model.Cancelled, cancelled.Status)
+		suite.Empty(cancelled.Refunds, "whole price is kept as late cancellation fee")
+		suite.Empty(refunds)
+	})
+
+	suite.Run("Shortened stay refunds price difference", func() {
+		suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 20) }
+
+		order := pay(suite.book(stay(1, 2, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 05)), ratePlan(1)))
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 2,
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 03),
+		})
+
+		modified := suite.order(order.ID)
+		suite.Require().True(modified.Changes[0].Applied)
+
+		// invoice of old stay is cancelled and replaced, refund of modification is not credited again
+		invoices, err := suite.Storage.GetInvoiceRepo().GetListOrderInvoices(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Require().Len(invoices, 3)
+		suite.Equal(model.CreditNote, invoices[1].Kind)
+		suite.Equal(invoices[0].Number, invoices[1].Corrects)
+		invoice, err := suite.Storage.GetInvoiceRepo().GetInvoice(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Equal(invoices[2], invoice)
+		suite.Equal(modified.Price.Total, invoice.Total)
+		suite.Equal(modified.To, invoice.To)
+
+		diff, err := order.Price.Total.Sub(modified.Price.Total)
+		suite.Require().NoError(err)
+
+		refund := nextRefund()
+		suite.Equal(diff, refund.Amount)
+		suite.Equal(model.ModificationRefund, refund.Reason)
+		suite.Require().Len(modified.Refunds, 1)
+		suite.Equal(refund.ID, modified.Refunds[0].ID)
+
+		refund.Status = model.RefundFailed
+		refund.FailureReason = "account closed"
+		suite.ServiceImpl.RefundProcessedEventHandler(suite.Context, refund)
+
+		modified = suite.order(order.ID)
+		suite.Equal(model.RefundFailed, modified.Refunds[0].Status)
+		suite.Equal("account closed", modified.Refunds[0].FailureReason)
+		suite.Zero(modified.RefundedAmount.Amount)
+	})
+
+	suite.Run("Extended stay is invoiced when additional payment is paid", func() {
+		suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 20) }
+
+		order := pay(suite.book(stay(1, 2, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 03)), ratePlan(1)))
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 2,
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 05),
+		})
+
+		modified := suite.order(order.ID)
+		suite.Require().True(modified.Changes[0].Applied)
+		suite.Require().Len(modified.Payments, 2)
+		suite.Equal(order.Price.Total, modified.NetPaid())
+
+		invoices, err := suite.Storage.GetInvoiceRepo().GetListOrderInvoices(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Len(invoices, 1, "not paid price is not invoiced")
+
+		additional := modified.Payments[1]
+		suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: additional.ID, OrderID: order.ID, Amount: additional.Amount})
+
+		invoices, err = suite.Storage.GetInvoiceRepo().GetListOrderInvoices(suite.Context, order.ID)
+		suite.Require().NoError(err)
+		suite.Require().Len(invoices, 3)
+		suite.Equal(model.CreditNote, invoices[1].Kind)
+		suite.Equal(modified.Price.Total, invoices[2].Total)
+		suite.Equal(modified.To, invoices[2].To)
+	})
+
+	suite.Run("Cancellation refunds only paid money", func() {
+		suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 20) }
+
+		order := pay(suite.book(stay(2, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)), ratePlan(3)))
+		suite.ServiceImpl.ModifyOrderEventHandler(suite.Context, events.ModifyOrderEvent{
+			OrderID:    order.ID,
+			RoomTypeID: 3,
+			From:       util.NewDay(2024, 04, 01),
+			To:         util.NewDay(2024, 04, 03),
+		})
+		suite.Require().True(suite.order(order.ID).Changes[0].Applied)
+
+		// additional payment of extended stay is not paid
+		suite.ServiceImpl.CancelOrderEventHandler(suite.Context, events.CancelOrderEvent{OrderID: order.ID})
+
+		refund := nextRefund()
+		suite.Equal(order.Price.Total, refund.Amount)
+		suite.Equal(model.CancellationRefund, refund.Reason)
+	})
+}
+
+func (suite *BookingServiceSuite) TestBookingService_DisplayCurrency() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 01) }
+
+	payments, err := suite.Queue.Subscribe(suite.Context, queue.PaymentRequest)
+	suite.Require().NoError(err)
+
+	room := stay(2, 3, util.NewDay(2024, 04, 01), util.NewDay(2024, 04, 02)) // prices are in USD
+	displayed := func(currency string) func(*ReservationOrder) {
+		return func(order *ReservationOrder) {
+			order.RatePlanID = 5 // 30% deposit
+			order.DisplayCurrency = currency
+		}
+	}
+
+	order := suite.book(room, displayed("EUR"))
+	suite.Require().Equal(model.Booked, order.Status)
+	suite.Require().NotNil(order.Conversion)
+	suite.Equal("USD", order.Price.Total.Currency)
+
+	// EUR/USD 1.0825 is effective from 2024-01-01, reversed pair is used
+	rate := model.FXRate{Base: "USD", Quote: "EUR", Rate: "400/433", EffectiveFrom: util.NewDay(2024, 01, 01)}
+	expected, err := rate.Convert(order.Price.Total, suite.ServiceImpl.now())
+	suite.Require().NoError(err)
+	suite.Equal(expected, *order.Conversion)
+
+	select {
+	case msg := <-payments:
+		payment, ok := msg.(events.PaymentRequest)
+		suite.Require().True(ok)
+		suite.Equal(order.PaymentSchedule[0].Amount, payment.Amount, "deposit is charged in currency of hotel")
+		suite.Require().NotNil(payment.Conversion)
+		suite.Equal(payment.Amount, payment.Conversion.From)
+		suite.Equal("400/433", payment.Conversion.Rate)
+		suite.Equal("EUR", payment.Conversion.To.Currency)
+	case <-time.After(time.Second):
+		suite.FailNow("payment was not requested")
+	}
+
+	// the later rate is used by orders booked after it becomes effective
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 04, 01) }
+	later := suite.book(room, displayed("EUR"))
+	suite.Require().NotNil(later.Conversion)
+	suite.Equal("1000/1079", later.Conversion.Rate)
+	suite.Equal(util.NewDay(2024, 04, 01), later.Conversion.EffectiveFrom)
+	<-payments
+
+	same := suite.book(room, displayed("USD"))
+	suite.Equal(model.Booked, same.Status)
+	suite.Nil(same.Conversion, "no conversion into currency of hotel")
+	<-payments
+
+	unknown := suite.book(room, displayed("SEK"))
+	suite.Equal(model.FailedBook, unknown.Status)
+	suite.Contains(unknown.FailureReason, model.ErrUnknownFXRate.Error())
+	suite.Nil(unknown.Conversion)
+	suite.Empty(payments)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Invoice() {
+	suite.ServiceImpl.now = func() time.Time { return util.NewDay(2024, 03, 25) }
+
+	ars := func(order *ReservationOrder) {
+		order.RoomTypeID = 2
+		order.Guest = model.Guest{Name: "Ars"}
+	}
+
+	first, second := suite.book(ars), suite.book(ars)
+	suite.Require().Equal(model.Booked, first.Status)
+
+	_, err := suite.Storage.GetInvoiceRepo().GetInvoice(suite.Context, first.ID)
+	suite.ErrorIs(err, storage.ErrNotFound, "booked order has no invoice")
+
+	for _, order := range []ReservationOrder{first, second} {
+		suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(order), OrderID: order.ID, Amount: order.Price.Total})
+	}
+
+	invoice, err := suite.Storage.GetInvoiceRepo().GetInvoice(suite.Context, first.ID)
+	suite.Require().NoError(err)
+	suite.Equal("1-000001", invoice.Number)
+	suite.Equal(util.NewDay(2024, 03, 25), invoice.IssuedAt)
+	suite.Equal("Ars", invoice.Buyer.Name)
+	suite.Len(invoice.Lines, 2, "line per night")
+	suite.Equal(first.Price.Total, invoice.Total)
+
+	next, err := suite.Storage.GetInvoiceRepo().GetInvoice(suite.Context, second.ID)
+	suite.Require().NoError(err)
+	suite.Equal("1-000002", next.Number)
+
+	// redelivered payment is not applied again and does not issue another invoice
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: paymentRequestID(first), OrderID: first.ID, Amount: first.Price.Total})
+	invoices, err := suite.Storage.GetInvoiceRepo().GetListInvoices(suite.Context)
+	suite.Require().NoError(err)
+	suite.Len(invoices, 2)
+	suite.Equal(first.Price.Total, suite.order(first.ID).PaidAmount)
+
+	// payment which is not requested for the order is not applied
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{PaymentID: uuid.New(), OrderID: second.ID, Amount: second.Price.Total})
+	suite.Equal(second.Price.Total, suite.order(second.ID).PaidAmount)
+}
+
+func (suite *BookingServiceSuite) TestBookingService_Saga() {
+	now := util.NewDay(2024, 03, 01)
+	suite.ServiceImpl.now = func() time.Time { return now }
+
+	payments, err := suite.Queue.Subscribe(suite.Context, queue.PaymentRequest)
+	suite.Require().NoError(err)
+	refunds, err := suite.Queue.Subscribe(suite.Context, queue.RefundRequest)
+	suite.Require().NoError(err)
+
+	nextPayment := func() events.PaymentRequest {
+		select {
+		case msg := <-payments:
+			payment, ok := msg.(events.PaymentRequest)
+			suite.Require().True(ok)
+			return payment
+		case <-time.After(time.Second):
+			suite.FailNow("payment was not requested")
+			return events.PaymentRequest{}
+		}
+	}
+
+	nextRefund := func() events.RefundRequest {
+		select {
+		case msg := <-refunds:
+			refund, ok := msg.(events.RefundRequest)
+			suite.Require().True(ok)
+			return refund
+		case <-time.After(time.Second):
+			suite.FailNow("refund was not requested")
+			return events.RefundRequest{}
+		}
+	}
+
+	newOrderEvent := func() ReservationOrder {
+		return ReservationOrder{
+			ID:         uuid.New(),
+			HotelID:    1,
+			RoomTypeID: 1,
+			UserEmail:  "ars-saz@ya.ru",
+			From:       util.NewDay(2024, 04, 02),
+			To:         util.NewDay(2024, 04, 04),
+		}
+	}
+
+	booked := func() (ReservationOrder, events.PaymentRequest) {
+		order := suite.book(stay(1, 1, util.NewDay(2024, 04, 02), util.NewDay(2024, 04, 04)))
+		suite.Require().Equal(model.Booked, order.Status)
+
+		return order, nextPayment()
+	}
+
+	saga := func(id ReservationOrderID) model.Saga {
+		saga, err := suite.Service.GetSaga(suite.Context, id)
+		suite.Require().NoError(err)
+		return saga
+	}
+
+	// paid order completes saga
+	paid, payment := booked()
+	started := saga(paid.ID)
+	suite.Equal(model.PaymentStep, started.Step)
+	suite.Equal(model.SagaRunning, started.Status)
+	suite.Equal(payment.ID, started.PaymentID, "saga waits for the requested payment")
+	suite.Equal(now.Add(paymentHoldTTL), started.Deadline)
+
+	suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+		PaymentID: payment.ID, OrderID: paid.ID, Amount: payment.Amount, PaidAt: now,
+	})
+	completed := saga(paid.ID)
+	suite.Equal(model.SagaCompleted, completed.Status)
+	suite.Equal(model.NotifyStep, completed.Step)
+	suite.Len(completed.History, 4, "book, payment and notify steps and completion")
+
+	// failed payment releases booking
+	failed, payment := booked()
+	suite.ServiceImpl.FailedPaymentEventHandler(suite.Context, events.FailedPaymentEvent{
+		PaymentID: payment.ID, OrderID: failed.ID, Reason: "declined",
+	})
+	suite.Equal(model.FailedPay, suite.order(failed.ID).Status)
+	released := saga(failed.ID)
+	suite.Equal(model.ReleaseStep, released.Step)
+	suite.Equal(model.SagaCompensated, released.Status)
+	suite.Equal(model.FailedPay, released.ReleaseStatus)
+	suite.Equal("payment failed: declined", released.CompensationReason)
+
+	// timed out payment step releases booking
+	expired, payment := booked()
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: now.Add(paymentHoldTTL - time.Second)})
+	suite.Equal(model.Booked, suite.order(expired.ID).Status, "payment step is not timed out yet")
+
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: now.Add(paymentHoldTTL)})
+	suite.Equal(model.Expired, suite.order(expired.ID).Status)
+	released = saga(expired.ID)
+	suite.Equal(model.SagaCompensated, released.Status)
+	suite.Equal(model.Expired, released.ReleaseStatus)
+	suite.Equal(completed, saga(paid.ID), "finished saga is not timed out")
+
+	// payment succeeded after booking is released is refunded
+	for range 2 { // redelivered payment is refunded once
+		suite.ServiceImpl.SuccessPaymentEventHandler(suite.Context, events.SuccessPaymentEvent{
+			PaymentID: payment.ID, OrderID: expired.ID, Amount: payment.Amount, PaidAt: now,
+		})
+	}
+	refund := nextRefund()
+	suite.Equal(expired.ID, refund.OrderID)
+	suite.Equal(payment.Amount, refund.Amount)
+	suite.Equal(model.CompensationRefund, refund.Reason)
+
+	refunded := suite.order(expired.ID)
+	suite.Equal(model.Expired, refunded.Status)
+	suite.Equal(payment.Amount, refunded.PaidAmount)
+	suite.Len(refunded.Refunds, 1)
+
+	compensating := saga(expired.ID)
+	suite.Equal(model.RefundStep, compensating.Step)
+	suite.Equal(model.SagaCompensating, compensating.Status)
+	suite.Equal(refund.ID, compensating.RefundID)
+
+	// failed refund makes saga stuck until it is resumed
+	refund.Status = model.RefundFailed
+	refund.FailureReason = "account closed"
+	suite.ServiceImpl.RefundProcessedEventHandler(suite.Context, refund)
+
+	stuck := saga(expired.ID)
+	suite.True(stuck.IsStuck())
+	suite.Equal("refund failed: account closed", stuck.Error)
+
+	stuckSagas, err := suite.Service.GetListSagas(suite.Context, true)
+	suite.Require().NoError(err)
+	suite.Require().Len(stuckSagas, 1)
+	suite.Equal(expired.ID, stuckSagas[0].OrderID)
+
+	allSagas, err := suite.Service.GetListSagas(suite.Context, false)
+	suite.Require().NoError(err)
+	suite.Len(allSagas, 3)
+
+	suite.ServiceImpl.ResumeSagaEventHandler(suite.Context, events.ResumeSagaEvent{OrderID: expired.ID})
+	retry := nextRefund()
+	suite.NotEqual(refund.ID, retry.ID, "failed refund is replaced")
+	suite.Equal(payment.Amount, retry.Amount)
+
+	resumed := saga(expired.ID)
+	suite.False(resumed.IsStuck())
+	suite.Equal(2, resumed.Attempts)
+	suite.Equal(retry.ID, resumed.RefundID)
+
+	// partial refund makes saga stuck, resumed saga refunds the rest
+	returned := model.Money{Amount: payment.Amount.Amount / 2, Currency: payment.Amount.Currency}
+	retry.Status = model.RefundPartial
+	retry.RefundedAmount = returned
+	retry.TransactionIDs = []string{"re_1"}
+	retry.FailureReason = "account closed"
+	suite.ServiceImpl.RefundProcessedEventHandler(suite.Context, retry)
+	suite.True(saga(expired.ID).IsStuck())
+	suite.Equal(returned, suite.order(expired.ID).RefundedAmount)
+
+	suite.ServiceImpl.ResumeSagaEventHandler(suite.Context, events.ResumeSagaEvent{OrderID: expired.ID})
+	rest := nextRefund()
+	suite.Equal(payment.Amount.Amount-returned.Amount, rest.Amount.Amount)
+	suite.Equal(rest.Amount, saga(expired.ID).RefundAmount)
+
+	rest.Status = model.RefundSucceeded
+	rest.RefundedAt = now
+	suite.ServiceImpl.RefundProcessedEventHandler(suite.Context, rest)
+	suite.Equal(model.SagaCompensated, saga(expired.ID).Status)
+	suite.Equal(payment.Amount, suite.order(expired.ID).RefundedAmount)
+
+	// saga which is not stuck is not resumed
+	suite.ServiceImpl.ResumeSagaEventHandler(suite.Context, events.ResumeSagaEvent{OrderID: paid.ID})
+	suite.Equal(completed, saga(paid.ID))
+
+	// timed out book step is stuck, resumed saga books the order
+	event := newOrderEvent()
+	event.Status = model.New
+	event.CreatedAt = now
+	suite.Require().NoError(suite.Storage.GetOrderRepo().StoreOrder(suite.Context, event))
+	suite.ServiceImpl.startSaga(suite.Context, event)
+
+	suite.ServiceImpl.ExpireOrdersEventHandler(suite.Context, events.ExpireOrdersEvent{At: now.Add(stepTimeouts[model.BookStep])})
+	stuck = saga(event.ID)
+	suite.True(stuck.IsStuck())
+	suite.Equal("book step timed out", stuck.Error)
+	suite.Equal(model.New, suite.order(event.ID).Status)
+
+	suite.ServiceImpl.ResumeSagaEventHandler(suite.Context, events.ResumeSagaEvent{OrderID: event.ID})
+	suite.Equal(model.Booked, suite.order(event.ID).Status)
+	resumed = saga(event.ID)
+	suite.Equal(model.PaymentStep, resumed.Step)
+	suite.Equal(nextPayment().ID, resumed.PaymentID)
+}
@@ -0,0 +1,83 @@
+package booking
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// CancelOrderEventHandler - cancel order, release quota for the remaining days and request refund of paid money
+// allowed by cancellation policy of the order.
+func (s *bookingService) CancelOrderEventHandler(ctx context.Context, event events.CancelOrderEvent) {
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.CancelOrderEventHandler] Failed to get order %v: %v", event.OrderID, err)
+		return
+	}
+
+	if !order.IsCancellable() {
+		s.log.Error("[bookingService.CancelOrderEventHandler] Order %v can not be cancelled in status: %s", order.ID, order.Status)
+		return
+	}
+
+	s.cancelOrder(ctx, order, "")
+}
+
+// cancelOrder - cancel order for given reason, empty if it is cancelled by the guest. Quota of the remaining days,
+// promo code and loyalty points are released and refund allowed by cancellation policy of the order is requested.
+func (s *bookingService) cancelOrder(ctx context.Context, order ReservationOrder, reason string) {
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.cancelOrder] Failed to start transaction: %v", err)
+		return
+	}
+
+	// days which are already passed stay consumed, release only remaining ones.
+	from := order.From
+	if today := s.localToday(ctx, order.HotelID); today.After(from) {
+		from = today
+	}
+
+	if err = s.releaseOrderQuota(ctx, tx, order, from, order.To); err != nil {
+		s.log.Error("[bookingService.cancelOrder] Failed to release quota: %v", err)
+		return // nothing executed yet, operations run only on commit
+	}
+
+	cancelledOrder := order
+	cancelledOrder.Status = model.Cancelled
+	cancelledOrder.FailureReason = reason
+	cancelledOrder.UpdatedAt = s.now()
+
+	s.releaseOrderBenefits(ctx, tx, order)
+
+	tx.Execute(
+		func() error {
+			return s.storage.GetOrderRepo().UpdateOrder(ctx, cancelledOrder.ID, cancelledOrder)
+		},
+		func() error {
+			return s.storage.GetOrderRepo().UpdateOrder(ctx, order.ID, order)
+		},
+	)
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.cancelOrder] Failed to commit transaction: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.cancelOrder] Order cancelled: %v", cancelledOrder)
+	detail := "order is cancelled"
+	if reason != "" {
+		detail += ": " + reason
+	}
+	s.finishSaga(ctx, order.ID, model.SagaCompensated, detail)
+
+	if amount := order.Price.RefundableAmount(order.NetPaid(), order.CheckInAt, s.now()); amount.Amount > 0 {
+		cancelledOrder = s.requestRefund(ctx, cancelledOrder, amount, model.CancellationRefund)
+	}
+
+	s.notify(ctx, cancelledOrder, model.OrderCancelledNotification)
+	s.statusChanged(ctx, order.Status, cancelledOrder)
+
+	s.processWaitlist(ctx, order.HotelID, order.RoomTypeID, from, order.To)
+}
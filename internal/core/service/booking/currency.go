@@ -0,0 +1,42 @@
+package booking
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// convertPrice - convert total price of the order into display currency selected by guest by FX rate effective now.
+// Nil if guest pays in currency of the hotel.
+func (s *bookingService) convertPrice(ctx context.Context, order ReservationOrder) (*model.CurrencyConversion, error) {
+	if order.DisplayCurrency == "" || order.DisplayCurrency == order.Price.Total.Currency {
+		return nil, nil
+	}
+
+	rate, err := s.storage.GetFXRepo().GetRate(ctx, order.Price.Total.Currency, order.DisplayCurrency, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := rate.Convert(order.Price.Total, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversion, nil
+}
+
+// applyConversion - amount of the order in its display currency by FX rate fixed at booking, so guest is not
+// affected by later changes of rates. Nil if order is not converted.
+func applyConversion(order ReservationOrder, amount model.Money) (*model.CurrencyConversion, error) {
+	if order.Conversion == nil {
+		return nil, nil
+	}
+
+	conversion, err := order.Conversion.Apply(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conversion, nil
+}
@@ -0,0 +1,104 @@
+package booking
+
+import (
+	"context"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+const (
+	paymentHoldTTL     = 30 * time.Minute // booked order must be paid in this time, otherwise quota is released by saga
+	expirationInterval = time.Minute
+)
+
+// InventoryUpdateEventHandler - change capacity and quota of hotel room type for every day of [From, To).
+// Increased quota is offered to waitlisted orders.
+func (s *bookingService) InventoryUpdateEventHandler(ctx context.Context, event events.InventoryUpdateEvent) {
+	rooms, err := s.roomsForPeriod(ctx, event.HotelID, event.RoomTypeID, event.From, event.To)
+	if err != nil {
+		s.log.Error("[bookingService.InventoryUpdateEventHandler] Failed to retrieve rooms information: %v", err)
+		return
+	}
+
+	change := newQuotaChange()
+	change.addCapacity(rooms, event.Delta)
+
+	if err = s.checkQuota(ctx, event.HotelID, change); err != nil {
+		s.log.Error("[bookingService.InventoryUpdateEventHandler] Rooms are already sold: %v", err)
+		return
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.InventoryUpdateEventHandler] Failed to start transaction: %v", err)
+		return
+	}
+
+	s.applyQuotaChange(ctx, tx, change)
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.InventoryUpdateEventHandler] Failed to commit transaction: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.InventoryUpdateEventHandler] Inventory updated: %+v", event)
+
+	if event.Delta > 0 {
+		s.processWaitlist(ctx, event.HotelID, event.RoomTypeID, event.From, event.To)
+	}
+}
+
+// ExpireOrdersEventHandler - release unused rooms of allotments which release date has come, request payment
+// of due balances, request pending refunds again and apply timeouts of saga steps, so booking of orders not paid during payment hold is released.
+func (s *bookingService) ExpireOrdersEventHandler(ctx context.Context, event events.ExpireOrdersEvent) {
+	s.releaseAllotments(ctx, event.At)
+
+	orders, err := s.storage.GetOrderRepo().GetListOrders(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.ExpireOrdersEventHandler] Failed to get orders: %v", err)
+		return
+	}
+
+	s.requestDueBalances(ctx, orders, event.At)
+	s.republishPendingRefunds(ctx, orders, event.At)
+
+	s.handleSagaTimeouts(ctx, event.At)
+}
+
+// expireOrder - release booking of order which is not paid during payment hold.
+func (s *bookingService) expireOrder(ctx context.Context, order ReservationOrder) {
+	expiredOrder, err := s.releaseNotPaid(ctx, order, model.Expired, "payment hold expired")
+	if err != nil {
+		s.log.Error("[bookingService.expireOrder] Failed to release booking of order %v: %v", order.ID, err)
+		return
+	}
+
+	s.log.Info("[bookingService.expireOrder] Order payment hold expired: %v", expiredOrder)
+	s.statusChanged(ctx, order.Status, expiredOrder)
+
+	s.processWaitlist(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+}
+
+// runExpiration - periodically request expiration of not paid orders, request is handled by worker
+// together with other events, so quota is changed by one goroutine only.
+func (s *bookingService) runExpiration(ctx context.Context) {
+	ticker := time.NewTicker(expirationInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.q.AsyncPublish(ctx, queue.ExpireOrdersRequest, events.ExpireOrdersEvent{At: s.now()}); err != nil {
+					s.log.Error("[bookingService.runExpiration] Failed to publish ExpireOrdersRequest msg: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,61 @@
+package booking
+
+import (
+	"context"
+	"errors"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// issueInvoice - issue invoice of the order which became paid. Failure does not affect the order, invoice is
+// issued by the next status change to paid or may be issued manually.
+func (s *bookingService) issueInvoice(ctx context.Context, order ReservationOrder) {
+	if order.Status != model.Paid {
+		return
+	}
+
+	invoice, err := s.invoicing.Issue(ctx, order, s.now())
+	if err != nil {
+		s.log.Error("[bookingService.issueInvoice] Failed to issue invoice of order %v: %v", order.ID, err)
+		return
+	}
+
+	s.log.Info("[bookingService.issueInvoice] Issued invoice %s of order %v: %v", invoice.Number, order.ID, invoice.Total)
+}
+
+// reissueInvoice - replace invoice of modified paid order by invoice of its new stay and price, the old one is
+// cancelled by credit note.
+func (s *bookingService) reissueInvoice(ctx context.Context, order ReservationOrder) {
+	invoice, err := s.invoicing.Reissue(ctx, order, s.now())
+	if err != nil {
+		s.log.Error("[bookingService.reissueInvoice] Failed to reissue invoice of order %v: %v", order.ID, err)
+		return
+	}
+
+	s.log.Info("[bookingService.reissueInvoice] Invoice %s of order %v: %v", invoice.Number, order.ID, invoice.Total)
+}
+
+// isPaidInFull - money kept from the guest covers price of the order, so it can be invoiced.
+func isPaidInFull(order ReservationOrder) bool {
+	return order.NetPaid().Amount >= order.Price.Total.Amount
+}
+
+// creditRefund - issue credit note of money returned by refund of cancelled order which is invoiced. Refund of
+// modified order is credited by its replacement invoice, order which is not invoiced has nothing to credit.
+func (s *bookingService) creditRefund(ctx context.Context, refund model.Refund) {
+	if refund.Reason != model.CancellationRefund || refund.Returned().Amount <= 0 {
+		return
+	}
+
+	note, err := s.invoicing.Credit(ctx, refund.OrderID, refund.Returned(), refund.Reason, s.now())
+	if errors.Is(err, storage.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		s.log.Error("[bookingService.creditRefund] Failed to credit refund %v of order %v: %v", refund.ID, refund.OrderID, err)
+		return
+	}
+
+	s.log.Info("[bookingService.creditRefund] Issued credit note %s of order %v: %v", note.Number, refund.OrderID, note.Total)
+}
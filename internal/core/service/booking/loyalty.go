@@ -0,0 +1,123 @@
+package booking
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+func (s *bookingService) loyaltyEntry(order ReservationOrder, kind model.LoyaltyEntryKind, points int64) model.LoyaltyEntry {
+	return model.LoyaltyEntry{
+		ID:        uuid.New(),
+		UserEmail: order.UserEmail,
+		OrderID:   order.ID,
+		Kind:      kind,
+		Points:    points,
+		CreatedAt: s.now(),
+	}
+}
+
+// earnLoyaltyPoints - add points for paid amount according to the account tier. Amount is converted into loyalty
+// currency by FX rate effective now.
+func (s *bookingService) earnLoyaltyPoints(ctx context.Context, order ReservationOrder, paid model.Money) error {
+	tier := model.BasicTier
+	if account, err := s.storage.GetLoyaltyRepo().GetAccount(ctx, order.UserEmail); err == nil {
+		tier = account.Tier
+	}
+
+	if paid.Currency != model.LoyaltyCurrency {
+		rate, err := s.storage.GetFXRepo().GetRate(ctx, paid.Currency, model.LoyaltyCurrency, s.now())
+		if err != nil {
+			return err
+		}
+
+		conversion, err := rate.Convert(paid, s.now())
+		if err != nil {
+			return err
+		}
+		paid = conversion.To
+	}
+
+	points := model.EarnedPoints(paid, tier)
+	if points == 0 {
+		return nil
+	}
+
+	return s.storage.GetLoyaltyRepo().AddEntry(ctx, s.loyaltyEntry(order, model.EarnEntry, points))
+}
+
+// earnOrderPoints - add points of the order which became paid for money kept from the guest. Points are earned once,
+// deposit and additional payments of modified order earn nothing on their own.
+func (s *bookingService) earnOrderPoints(ctx context.Context, order ReservationOrder) {
+	if err := s.earnLoyaltyPoints(ctx, order, order.NetPaid()); err != nil {
+		s.log.Error("[bookingService.earnOrderPoints] Failed to earn loyalty points of order %v: %v", order.ID, err)
+	}
+}
+
+// reverseLoyaltyPoints - take back points earned by the order and return points redeemed by it.
+// Returns added reversal entries.
+func (s *bookingService) reverseLoyaltyPoints(ctx context.Context, order ReservationOrder) ([]uuid.UUID, error) {
+	entries, err := s.storage.GetLoyaltyRepo().GetOrderEntries(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var earned, redeemed int64
+	for _, entry := range entries {
+		switch {
+		case entry.Kind == model.EarnEntry:
+			earned += entry.Points
+		case entry.Kind == model.RedeemEntry:
+			redeemed -= entry.Points
+		case entry.Kind == model.ReverseEntry && entry.Points < 0: // already reversed earned points
+			earned += entry.Points
+		case entry.Kind == model.ReverseEntry: // already returned redeemed points
+			redeemed -= entry.Points
+		}
+	}
+
+	var added []uuid.UUID
+	for _, points := range []int64{-earned, redeemed} {
+		if points == 0 {
+			continue
+		}
+
+		entry := s.loyaltyEntry(order, model.ReverseEntry, points)
+		if err = s.storage.GetLoyaltyRepo().AddEntry(ctx, entry); err != nil {
+			return added, err
+		}
+		added = append(added, entry.ID)
+	}
+
+	return added, nil
+}
+
+// reverseLoyaltyPointsTx - add loyalty points reversal into transaction, rollback removes reversal entries.
+func (s *bookingService) reverseLoyaltyPointsTx(ctx context.Context, tx storage.Transaction, order ReservationOrder) {
+	var added []uuid.UUID
+
+	tx.Execute(
+		func() error {
+			var err error
+			added, err = s.reverseLoyaltyPoints(ctx, order)
+			return err
+		},
+		func() error {
+			return s.removeLoyaltyEntries(ctx, added)
+		},
+	)
+}
+
+// removeLoyaltyEntries - remove entries from ledger, their points are reverted on accounts.
+func (s *bookingService) removeLoyaltyEntries(ctx context.Context, ids []uuid.UUID) error {
+	var errs []error
+	for _, id := range ids {
+		errs = append(errs, s.storage.GetLoyaltyRepo().RemoveEntry(ctx, id, s.now()))
+	}
+
+	return errors.Join(errs...)
+}
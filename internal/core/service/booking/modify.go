@@ -0,0 +1,290 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// ModifyOrderEventHandler - move order to new dates and/or room type.
+// Old days are released and new days are reserved in one transaction, if new period is not available
+// the original booking is kept.
+func (s *bookingService) ModifyOrderEventHandler(ctx context.Context, event events.ModifyOrderEvent) {
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.ModifyOrderEventHandler] Failed to get order %v: %v", event.OrderID, err)
+		return
+	}
+
+	if !order.IsModifiable() {
+		s.log.Error("[bookingService.ModifyOrderEventHandler] Order %v can not be modified in status: %s", order.ID, order.Status)
+		return
+	}
+
+	change := model.OrderChange{
+		ChangedAt:     s.now(),
+		OldRoomTypeID: order.RoomTypeID,
+		OldFrom:       order.From,
+		OldTo:         order.To,
+		NewRoomTypeID: event.RoomTypeID,
+		NewFrom:       event.From,
+		NewTo:         event.To,
+	}
+
+	if order.BlockCode != "" {
+		s.log.Info("[bookingService.ModifyOrderEventHandler] Order %v is booked from allotment %s, original booking is kept", order.ID, order.BlockCode)
+		change.Reason = fmt.Sprintf("order is booked from allotment %s and can not be modified", order.BlockCode)
+		s.recordChange(ctx, order, change)
+		return
+	}
+
+	movedOrder := order
+	movedOrder.RoomTypeID = event.RoomTypeID
+
+	var (
+		modifiedOrder ReservationOrder
+		newPrice      model.Price
+		overpaid      model.Money
+		rescheduled   bool
+	)
+
+	movedOrder, err = s.withLocalStay(ctx, movedOrder, event.From, event.To)
+	if err == nil && movedOrder.RoomTypeID != order.RoomTypeID {
+		err = s.checkOccupancy(ctx, movedOrder)
+	}
+	if err == nil {
+		change.NewFrom, change.NewTo = movedOrder.From, movedOrder.To
+		err = s.checkStayRestrictions(ctx, movedOrder.HotelID, movedOrder.RoomTypeID, movedOrder.From, movedOrder.To)
+	}
+	if err == nil {
+		newPrice, err = s.pricing.Quote(ctx, movedOrder)
+	}
+	if err == nil {
+		change.Applied = true
+		modifiedOrder = movedOrder
+		modifiedOrder.Price = newPrice
+		modifiedOrder.UpdatedAt = s.now()
+		modifiedOrder.Changes = append(append([]model.OrderChange(nil), order.Changes...), change)
+
+		if modifiedOrder.Conversion, err = applyConversion(order, newPrice.Total); err != nil {
+			s.log.Error("[bookingService.ModifyOrderEventHandler] Failed to convert new price: %v", err)
+		}
+
+		switch order.Status {
+		case model.Booked:
+			modifiedOrder = s.replacePayment(modifiedOrder)
+		case model.DepositPaid:
+			modifiedOrder, overpaid, rescheduled = s.rescheduleBalance(modifiedOrder)
+		}
+
+		err = s.moveReservation(ctx, order, modifiedOrder)
+	}
+
+	if err != nil {
+		change.Applied = false
+
+		switch {
+		case errors.Is(err, errEmptyStay):
+			s.log.Info("[bookingService.ModifyOrderEventHandler] New stay is empty, original booking is kept: %v", order.ID)
+			change.Reason = errEmptyStay.Error()
+		case errors.Is(err, model.ErrOccupancyExceeded):
+			s.log.Info("[bookingService.ModifyOrderEventHandler] Guests do not fit new room type, original booking is kept: %v", err)
+			change.Reason = err.Error()
+		case errors.Is(err, model.ErrStayRestricted):
+			s.log.Info("[bookingService.ModifyOrderEventHandler] New stay is restricted, original booking is kept: %v", err)
+			change.Reason = err.Error()
+		case errors.Is(err, errNoRooms):
+			s.log.Info("[bookingService.ModifyOrderEventHandler] No rooms for new period, original booking is kept: %v", order.ID)
+			change.Reason = errNoRooms.Error()
+		default:
+			s.log.Error("[bookingService.ModifyOrderEventHandler] Failed to move reservation, original booking is kept: %v", err)
+		}
+
+		s.recordChange(ctx, order, change)
+		return
+	}
+
+	s.log.Info("[bookingService.ModifyOrderEventHandler] Order modified: %+v", change)
+
+	switch {
+	case order.Status == model.Paid, order.Status == model.DepositPaid && !rescheduled:
+		modifiedOrder = s.settleModification(ctx, order, modifiedOrder)
+	case overpaid.Amount > 0:
+		modifiedOrder = s.refundModification(ctx, order, modifiedOrder, overpaid)
+	}
+
+	s.statusChanged(ctx, order.Status, modifiedOrder)
+	switch {
+	case order.Status == model.Booked:
+		s.paymentStepRestarted(ctx, modifiedOrder)
+	case order.Status == model.DepositPaid && modifiedOrder.Status == model.Paid:
+		s.earnOrderPoints(ctx, modifiedOrder)
+		s.finishSaga(ctx, order.ID, model.SagaCompleted, "deposit covers new price")
+	case rescheduled:
+		s.balanceStep(ctx, modifiedOrder, "balance is rescheduled")
+	}
+
+	switch {
+	case order.Status != model.Paid:
+		s.issueInvoice(ctx, modifiedOrder) // deposit of modified order covers its new price
+	case isPaidInFull(modifiedOrder):
+		s.reissueInvoice(ctx, modifiedOrder)
+	default:
+		s.log.Info("[bookingService.ModifyOrderEventHandler] Invoice of order %v is reissued when additional payment is paid", order.ID)
+	}
+
+	s.processWaitlist(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+}
+
+// checkOccupancy - model.ErrOccupancyExceeded if guests of order do not fit its room type.
+func (s *bookingService) checkOccupancy(ctx context.Context, order ReservationOrder) error {
+	roomType, err := s.storage.GetRoomTypeRepo().GetRoomType(ctx, order.HotelID, order.RoomTypeID)
+	if err != nil {
+		return fmt.Errorf("%w: %d in hotel %d: %w", model.ErrUnknownRoomType, order.RoomTypeID, order.HotelID, err)
+	}
+
+	return roomType.CheckOccupancy(order.Occupancy)
+}
+
+// recordChange - store not applied modification on the order, its booking is kept.
+func (s *bookingService) recordChange(ctx context.Context, order ReservationOrder, change model.OrderChange) {
+	changedOrder := order
+	changedOrder.UpdatedAt = s.now()
+	changedOrder.Changes = append(append([]model.OrderChange(nil), order.Changes...), change)
+
+	if err := s.storage.GetOrderRepo().UpdateOrder(ctx, changedOrder.ID, changedOrder); err != nil {
+		s.log.Error("[bookingService.recordChange] Failed to update order: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.recordChange] Order modification recorded: %+v", change)
+}
+
+// moveReservation - release quota of the order nights, reserve quota for nights of the moved order and store it
+// in one transaction, so quota is moved back if the order is not stored.
+func (s *bookingService) moveReservation(ctx context.Context, order, movedOrder ReservationOrder) error {
+	oldRooms, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+	if err != nil {
+		return err
+	}
+
+	newRooms, err := s.roomsForPeriod(ctx, movedOrder.HotelID, movedOrder.RoomTypeID, movedOrder.From, movedOrder.To)
+	if err != nil {
+		return err
+	}
+
+	change := newQuotaChange()
+	change.add(oldRooms, +1)
+	change.add(newRooms, -1)
+
+	if err = s.checkQuota(ctx, order.HotelID, change); err != nil {
+		return err
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.applyQuotaChange(ctx, tx, change)
+
+	tx.Execute(
+		func() error {
+			return s.storage.GetOrderRepo().UpdateOrder(ctx, movedOrder.ID, movedOrder)
+		},
+		func() error {
+			return s.storage.GetOrderRepo().UpdateOrder(ctx, order.ID, order)
+		},
+	)
+
+	return tx.Commit()
+}
+
+// settleModification - request payment or refund of price difference for a paid order.
+// Returns the new order with requested payment or refund.
+func (s *bookingService) settleModification(ctx context.Context, oldOrder, newOrder ReservationOrder) ReservationOrder {
+	diff, err := newOrder.Price.Total.Sub(oldOrder.Price.Total)
+	if err != nil {
+		s.log.Error("[bookingService.settleModification] Failed to calculate price difference: %v", err)
+		return newOrder
+	}
+
+	switch {
+	case diff.Amount > 0:
+		return s.requestAdditionalPayment(ctx, newOrder, diff)
+	case diff.Amount < 0:
+		diff.Amount = -diff.Amount
+		return s.refundModification(ctx, oldOrder, newOrder, diff)
+	}
+
+	return newOrder
+}
+
+// refundModification - request partial refund of lowered price of modified order allowed by its cancellation policy.
+// Policy is applied to the original check-in.
+func (s *bookingService) refundModification(ctx context.Context, oldOrder, newOrder ReservationOrder, lowered model.Money) ReservationOrder {
+	amount := newOrder.Price.RefundableAmount(lowered, oldOrder.CheckInAt, s.now())
+	if amount.Amount <= 0 {
+		s.log.Info("[bookingService.refundModification] Lowered price of order %v is not refundable", newOrder.ID)
+		return newOrder
+	}
+
+	return s.requestRefund(ctx, newOrder, amount, model.ModificationRefund)
+}
+
+// replacePayment - new price of modified booked order is charged by payment with new ID and its schedule is built
+// again. Not paid payment and requested installment of old price are kept as replaced, their late success is refunded.
+func (s *bookingService) replacePayment(order ReservationOrder) ReservationOrder {
+	payments := make([]model.OrderPayment, 0, len(order.Payments)+2)
+	for _, payment := range order.Payments {
+		if payment.PaidAt.IsZero() && payment.ReplacedAt.IsZero() {
+			payment.ReplacedAt = s.now()
+		}
+		payments = append(payments, payment)
+	}
+
+	for _, installment := range order.PaymentSchedule {
+		if installment.RequestedAt.IsZero() || installment.IsPaid() {
+			continue
+		}
+
+		payments = append(payments, model.OrderPayment{
+			ID:          installment.PaymentID,
+			Amount:      installment.Amount,
+			RequestedAt: installment.RequestedAt,
+			ReplacedAt:  s.now(),
+		})
+	}
+
+	order.PaymentSchedule = s.paymentSchedule(order)
+	if len(order.PaymentSchedule) == 0 {
+		payments = append(payments, model.OrderPayment{ID: uuid.New(), Amount: order.Price.Total, RequestedAt: s.now()})
+	}
+	order.Payments = payments
+
+	return order
+}
+
+// requestAdditionalPayment - record payment of given amount on the stored order and publish payment request.
+// Returns the order with recorded payment.
+func (s *bookingService) requestAdditionalPayment(ctx context.Context, order ReservationOrder, amount model.Money) ReservationOrder {
+	payment := model.OrderPayment{ID: uuid.New(), Amount: amount, RequestedAt: s.now()}
+
+	requestedOrder := order
+	requestedOrder.Payments = append(append([]model.OrderPayment(nil), order.Payments...), payment)
+	requestedOrder.UpdatedAt = s.now()
+
+	if err := s.storage.GetOrderRepo().UpdateOrder(ctx, requestedOrder.ID, requestedOrder); err != nil {
+		s.log.Error("[bookingService.requestAdditionalPayment] Failed to record payment of order %v: %v", order.ID, err)
+		return order
+	}
+
+	_ = s.publishPaymentRequest(ctx, requestedOrder, payment.ID, amount)
+
+	return requestedOrder
+}
@@ -0,0 +1,173 @@
+package booking
+
+import (
+	"context"
+	"errors"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// SuccessPaymentEventHandler - track paid amount of the order, mark booked order as deposit paid or paid
+// and add loyalty points when it is paid. Only payments requested for the order are applied, each of them once.
+func (s *bookingService) SuccessPaymentEventHandler(ctx context.Context, event events.SuccessPaymentEvent) {
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.SuccessPaymentEventHandler] Failed to get order %v: %v", event.OrderID, err)
+		return
+	}
+
+	if event.PaidAt.IsZero() {
+		event.PaidAt = s.now()
+	}
+
+	switch order.Status {
+	case model.Booked, model.DepositPaid, model.Paid:
+		paidOrder, err := order.ApplyPayment(event.PaymentID, event.Amount, event.PaidAt)
+		if errors.Is(err, model.ErrDuplicatePayment) {
+			s.log.Info("[bookingService.SuccessPaymentEventHandler] Payment %v of order %v is already applied", event.PaymentID, order.ID)
+			return
+		}
+		if errors.Is(err, model.ErrReplacedPayment) {
+			s.log.Info("[bookingService.SuccessPaymentEventHandler] Payment %v of old price of modified order %v is refunded", event.PaymentID, order.ID)
+			paidOrder.UpdatedAt = s.now()
+			s.requestRefund(ctx, paidOrder, event.Amount, model.ModificationRefund)
+			return
+		}
+		if err != nil {
+			s.log.Error("[bookingService.SuccessPaymentEventHandler] Failed to add payment %v of order %v: %v", event.PaymentID, order.ID, err)
+			return
+		}
+
+		paidOrder.Status = model.Paid
+		if !model.IsSchedulePaid(paidOrder.PaymentSchedule) {
+			paidOrder.Status = model.DepositPaid
+		}
+		paidOrder.UpdatedAt = s.now()
+
+		if err = s.storage.GetOrderRepo().UpdateOrder(ctx, paidOrder.ID, paidOrder); err != nil {
+			s.log.Error("[bookingService.SuccessPaymentEventHandler] Failed to update order: %v", err)
+			return
+		}
+
+		switch {
+		case paidOrder.Status == order.Status:
+			s.log.Info("[bookingService.SuccessPaymentEventHandler] Additional payment of order %v: %v", order.ID, event.Amount)
+			if paidOrder.Status == model.Paid && !isPaidInFull(order) && isPaidInFull(paidOrder) {
+				s.reissueInvoice(ctx, paidOrder) // new price of modified order is paid
+			}
+		case paidOrder.Status == model.DepositPaid:
+			s.log.Info("[bookingService.SuccessPaymentEventHandler] Order deposit is paid: %v", paidOrder)
+			s.statusChanged(ctx, order.Status, paidOrder)
+			s.paymentStepDone(ctx, paidOrder)
+		default:
+			s.log.Info("[bookingService.SuccessPaymentEventHandler] Order is paid: %v", paidOrder)
+			s.statusChanged(ctx, order.Status, paidOrder)
+			s.issueInvoice(ctx, paidOrder) // before notification, invoice is attached to it
+			s.earnOrderPoints(ctx, paidOrder)
+			s.paymentStepDone(ctx, paidOrder)
+		}
+	default:
+		s.log.Error("[bookingService.SuccessPaymentEventHandler] Payment for order %v in status: %s", order.ID, order.Status)
+		s.refundLatePayment(ctx, order, event)
+	}
+
+}
+
+// FailedPaymentEventHandler - booking of not paid order is cancelled: quota, promo code and loyalty points are returned.
+// Failed balance of deposit paid order is requested again, the order is cancelled when all attempts fail.
+func (s *bookingService) FailedPaymentEventHandler(ctx context.Context, event events.FailedPaymentEvent) {
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.FailedPaymentEventHandler] Failed to get order %v: %v", event.OrderID, err)
+		return
+	}
+
+	if order.Status == model.DepositPaid && s.failInstallment(ctx, order, event.PaymentID, event.Reason) {
+		return
+	}
+
+	if order.Status != model.Booked || order.IsReplacedPayment(event.PaymentID) {
+		// e.g. additional payment of modified paid order or payment of old price of modified order is failed,
+		// booking is kept.
+		s.log.Error("[bookingService.FailedPaymentEventHandler] Failed payment of order %v in status: %s", order.ID, order.Status)
+		return
+	}
+
+	s.failPayment(ctx, order, "payment failed: "+event.Reason)
+}
+
+// failPayment - release booking of order which payment failed and notify the guest.
+func (s *bookingService) failPayment(ctx context.Context, order ReservationOrder, reason string) {
+	failedOrder, err := s.releaseNotPaid(ctx, order, model.FailedPay, reason)
+	if err != nil {
+		s.log.Error("[bookingService.failPayment] Failed to release booking: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.failPayment] Order payment failed (%s): %v", reason, failedOrder)
+
+	s.statusChanged(ctx, order.Status, failedOrder)
+	s.notify(ctx, failedOrder, model.OrderFailedNotification)
+
+	s.processWaitlist(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+}
+
+// releaseBooking - return quota, promo code and loyalty points of not paid booked order and set its final status
+// and reason of release.
+func (s *bookingService) releaseBooking(ctx context.Context, order ReservationOrder, status model.Status, reason string) (ReservationOrder, error) {
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		return order, err
+	}
+
+	if err = s.releaseOrderQuota(ctx, tx, order, order.From, order.To); err != nil {
+		return order, err // nothing executed yet, operations run only on commit
+	}
+
+	releasedOrder := order
+	releasedOrder.Status = status
+	releasedOrder.FailureReason = reason
+	releasedOrder.UpdatedAt = s.now()
+
+	s.releaseOrderBenefits(ctx, tx, order)
+
+	tx.Execute(
+		func() error {
+			return s.storage.GetOrderRepo().UpdateOrder(ctx, releasedOrder.ID, releasedOrder)
+		},
+		func() error {
+			return s.storage.GetOrderRepo().UpdateOrder(ctx, order.ID, order)
+		},
+	)
+
+	if err = tx.Commit(); err != nil {
+		return order, err
+	}
+
+	return releasedOrder, nil
+}
+
+// releaseOrderBenefits - add into transaction operations which return promo code usage and loyalty points of the order.
+func (s *bookingService) releaseOrderBenefits(ctx context.Context, tx storage.Transaction, order ReservationOrder) {
+	if order.PromoCode != "" {
+		tx.Execute(
+			func() error {
+				return s.storage.GetPromoRepo().Release(ctx, order.ID)
+			},
+			func() error {
+				return s.storage.GetPromoRepo().Redeem(ctx, model.PromoRedemption{
+					OrderID:    order.ID,
+					Code:       order.PromoCode,
+					UserEmail:  order.UserEmail,
+					HotelID:    order.HotelID,
+					RoomTypeID: order.RoomTypeID,
+					RedeemedAt: s.now(),
+				})
+			},
+		)
+	}
+
+	s.reverseLoyaltyPointsTx(ctx, tx, order)
+}
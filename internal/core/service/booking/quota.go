@@ -0,0 +1,154 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+)
+
+var errNoRooms = errors.New("no rooms for period")
+
+// quotaChange - accumulates quota changes per room-day, so release and reserve of overlapping periods
+// can be checked and applied together in one transaction.
+type quotaChange struct {
+	rooms          map[model.RoomAvailabilityID]RoomAvailability
+	deltas         map[model.RoomAvailabilityID]int
+	capacityDeltas map[model.RoomAvailabilityID]int
+}
+
+func newQuotaChange() *quotaChange {
+	return &quotaChange{
+		rooms:          make(map[model.RoomAvailabilityID]RoomAvailability),
+		deltas:         make(map[model.RoomAvailabilityID]int),
+		capacityDeltas: make(map[model.RoomAvailabilityID]int),
+	}
+}
+
+func (c *quotaChange) add(rooms []RoomAvailability, delta int) {
+	for _, room := range rooms {
+		c.rooms[room.ID] = room
+		c.deltas[room.ID] += delta
+	}
+}
+
+// addCapacity - physical rooms are added or removed, so quota is changed together with capacity.
+func (c *quotaChange) addCapacity(rooms []RoomAvailability, delta int) {
+	c.add(rooms, delta)
+	for _, room := range rooms {
+		c.capacityDeltas[room.ID] += delta
+	}
+}
+
+// overdrawn returns reserved room-days which quota would become less than allowed by overbooking after change.
+// Released room-days are not checked: a release must pass even if the day is already oversold.
+func (c *quotaChange) overdrawn(allowance func(RoomAvailability) int) []RoomAvailability {
+	var rooms []RoomAvailability
+	for id, delta := range c.deltas {
+		if delta >= 0 {
+			continue
+		}
+
+		room := c.rooms[id]
+
+		changed := room
+		changed.Quota += delta
+		changed.Capacity += c.capacityDeltas[id]
+
+		if changed.Quota < -allowance(changed) {
+			rooms = append(rooms, room)
+		}
+	}
+
+	return rooms
+}
+
+// overbookingAllowance - returns allowance of hotel room-days by overbooking rules.
+func (s *bookingService) overbookingAllowance(ctx context.Context, hotelID int) (func(RoomAvailability) int, error) {
+	rules, err := s.storage.GetOverbookingRepo().GetRulesForHotel(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(room RoomAvailability) int {
+		return model.OverbookingAllowance(rules, room)
+	}, nil
+}
+
+// checkQuota - errNoRooms if some room-day of hotel is overdrawn by change.
+func (s *bookingService) checkQuota(ctx context.Context, hotelID int, change *quotaChange) error {
+	allowance, err := s.overbookingAllowance(ctx, hotelID)
+	if err != nil {
+		return err
+	}
+
+	if overdrawn := change.overdrawn(allowance); len(overdrawn) > 0 {
+		s.log.Info("[bookingService.checkQuota] No room quota for days: %v", overdrawn)
+		return errNoRooms
+	}
+
+	return nil
+}
+
+// roomsForPeriod - retrieve room-days of nights [from, to), errNoRooms if some night is absent.
+func (s *bookingService) roomsForPeriod(ctx context.Context, hotelID, roomTypeID int, from, to time.Time) ([]RoomAvailability, error) {
+	rooms, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, hotelID, roomTypeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rooms) == 0 || len(rooms) < len(util.NightsBetween(from, to)) {
+		return nil, errNoRooms
+	}
+
+	return rooms, nil
+}
+
+// applyQuotaChange - add into transaction operations which update quota of changed room-days.
+func (s *bookingService) applyQuotaChange(ctx context.Context, tx storage.Transaction, c *quotaChange) {
+	for id, delta := range c.deltas {
+		if delta == 0 && c.capacityDeltas[id] == 0 {
+			continue
+		}
+
+		oldRoomInfo := c.rooms[id]
+		room := oldRoomInfo
+		room.Quota += delta
+		room.Capacity += c.capacityDeltas[id]
+
+		tx.Execute(
+			func() error {
+				return s.storage.GetRoomRepo().UpdateRoom(ctx, room.ID, room)
+			},
+			func() error {
+				return s.storage.GetRoomRepo().UpdateRoom(ctx, room.ID, oldRoomInfo)
+			},
+		)
+	}
+}
+
+// releaseQuota - add into transaction operations which return one room of quota for every night of [from, to).
+func (s *bookingService) releaseQuota(
+	ctx context.Context,
+	tx storage.Transaction,
+	hotelID, roomTypeID int,
+	from, to time.Time,
+) error {
+	if !from.Before(to) {
+		return nil // nothing to release
+	}
+
+	rooms, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, hotelID, roomTypeID, from, to)
+	if err != nil {
+		return err
+	}
+
+	change := newQuotaChange()
+	change.add(rooms, +1) // todo if user will can book more the one room, must be change this place
+	s.applyQuotaChange(ctx, tx, change)
+
+	return nil
+}
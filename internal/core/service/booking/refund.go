@@ -0,0 +1,126 @@
+package booking
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+const refundRepublishDelay = 5 * time.Minute // pending refund is requested again after this delay
+
+// requestRefund - record pending refund of given amount on the stored order and publish refund request.
+// Returns the order with recorded refund.
+func (s *bookingService) requestRefund(ctx context.Context, order ReservationOrder, amount model.Money, reason model.RefundReason) ReservationOrder {
+	refund := events.RefundRequest{
+		ID:        uuid.New(),
+		OrderID:   order.ID,
+		Amount:    amount,
+		Reason:    reason,
+		Status:    model.RefundPending,
+		CreatedAt: s.now(),
+	}
+
+	refundedOrder := order
+	refundedOrder.Refunds = append(append([]model.Refund(nil), order.Refunds...), refund)
+	refundedOrder.UpdatedAt = s.now()
+
+	if err := s.storage.GetOrderRepo().UpdateOrder(ctx, refundedOrder.ID, refundedOrder); err != nil {
+		s.log.Error("[bookingService.requestRefund] Failed to record refund of order %v: %v", order.ID, err)
+		return order
+	}
+
+	s.publishRefund(ctx, refund) // refund which is not published stays pending and is published again by expiry tick
+
+	return refundedOrder
+}
+
+// republishPendingRefunds - publish again requests of refunds which are pending for refundRepublishDelay:
+// request could be lost or its result is not received yet.
+func (s *bookingService) republishPendingRefunds(ctx context.Context, orders []ReservationOrder, at time.Time) {
+	for _, order := range orders {
+		for _, refund := range order.Refunds {
+			if refund.Status != model.RefundPending || at.Before(refund.CreatedAt.Add(refundRepublishDelay)) {
+				continue
+			}
+
+			s.log.Info("[bookingService.republishPendingRefunds] Refund %v of order %v is pending since %v", refund.ID, order.ID, refund.CreatedAt)
+			s.publishRefund(ctx, refund)
+		}
+	}
+}
+
+// publishRefund - publish request of recorded refund. Request with the same ID is refunded only once.
+func (s *bookingService) publishRefund(ctx context.Context, refund events.RefundRequest) {
+	if err := s.q.AsyncPublish(ctx, queue.RefundRequest, refund); err != nil {
+		s.log.Error("[bookingService.publishRefund] Failed to publish RefundRequest msg: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.publishRefund] Published RefundRequest msg: %v", refund)
+}
+
+// RefundProcessedEventHandler - record result of refund on the order, returned money of succeeded or partial refund
+// is added to refunded amount.
+func (s *bookingService) RefundProcessedEventHandler(ctx context.Context, event events.RefundProcessedEvent) {
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.RefundProcessedEventHandler] Failed to get order %v: %v", event.OrderID, err)
+		return
+	}
+
+	refundedOrder := order
+	refundedOrder.Refunds = append([]model.Refund(nil), order.Refunds...)
+
+	found := false
+	for i, refund := range refundedOrder.Refunds {
+		if refund.ID != event.ID {
+			continue
+		}
+
+		if refund.IsSettled() {
+			s.log.Info("[bookingService.RefundProcessedEventHandler] Refund %v is already settled", refund.ID)
+			return
+		}
+
+		refundedOrder.Refunds[i] = event
+		found = true
+	}
+
+	if !found {
+		s.log.Error("[bookingService.RefundProcessedEventHandler] Refund %v is not requested by order %v", event.ID, order.ID)
+		return
+	}
+
+	if returned := event.Returned(); returned.Amount > 0 {
+		if refundedOrder.RefundedAmount, err = order.RefundedAmount.Add(returned); err != nil {
+			s.log.Error("[bookingService.RefundProcessedEventHandler] Failed to add refunded amount: %v", err)
+			return
+		}
+	}
+	refundedOrder.UpdatedAt = s.now()
+
+	if err = s.storage.GetOrderRepo().UpdateOrder(ctx, refundedOrder.ID, refundedOrder); err != nil {
+		s.log.Error("[bookingService.RefundProcessedEventHandler] Failed to update order: %v", err)
+		return
+	}
+
+	s.refundStepDone(ctx, event)
+	s.creditRefund(ctx, event)
+
+	switch event.Status {
+	case model.RefundFailed:
+		s.log.Error("[bookingService.RefundProcessedEventHandler] Refund %v of order %v failed: %s", event.ID, order.ID, event.FailureReason)
+		return
+	case model.RefundPartial:
+		s.log.Error("[bookingService.RefundProcessedEventHandler] Refund %v of order %v is made partially, %v of %v: %s",
+			event.ID, order.ID, event.RefundedAmount, event.Amount, event.FailureReason)
+		return
+	}
+
+	s.log.Info("[bookingService.RefundProcessedEventHandler] Refund %v of order %v is made: %v", event.ID, order.ID, event.Amount)
+}
@@ -0,0 +1,72 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/util"
+)
+
+// checkStayRestrictions - model.StayRestrictionError if stay violates restrictions of its room-days.
+// Absent room-days are not checked here, such stay ends as NoRooms.
+func (s *bookingService) checkStayRestrictions(ctx context.Context, hotelID, roomTypeID int, from, to time.Time) error {
+	nights, err := s.roomsForPeriod(ctx, hotelID, roomTypeID, from, to)
+	if errors.Is(err, errNoRooms) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	departureDay := util.ToDay(to) // checkout day is not a night of stay
+	departures, err := s.storage.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, hotelID, roomTypeID, departureDay, departureDay.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	var departure *RoomAvailability
+	if len(departures) > 0 {
+		departure = &departures[0]
+	}
+
+	return model.CheckStayRestrictions(nights, from, departure)
+}
+
+// StayRestrictionsUpdateEventHandler - set restrictions of hotel room type for every day of [From, To).
+func (s *bookingService) StayRestrictionsUpdateEventHandler(ctx context.Context, event events.StayRestrictionsUpdateEvent) {
+	rooms, err := s.roomsForPeriod(ctx, event.HotelID, event.RoomTypeID, event.From, event.To)
+	if err != nil {
+		s.log.Error("[bookingService.StayRestrictionsUpdateEventHandler] Failed to retrieve rooms information: %v", err)
+		return
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.StayRestrictionsUpdateEventHandler] Failed to start transaction: %v", err)
+		return
+	}
+
+	for _, oldRoomInfo := range rooms {
+		room := oldRoomInfo
+		room.StayRestrictions = event.Restrictions
+
+		tx.Execute(
+			func() error {
+				return s.storage.GetRoomRepo().UpdateRoom(ctx, room.ID, room)
+			},
+			func() error {
+				return s.storage.GetRoomRepo().UpdateRoom(ctx, oldRoomInfo.ID, oldRoomInfo)
+			},
+		)
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.StayRestrictionsUpdateEventHandler] Failed to commit transaction: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.StayRestrictionsUpdateEventHandler] Stay restrictions updated: %+v", event)
+}
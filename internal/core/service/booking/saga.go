@@ -0,0 +1,440 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// stepTimeouts - time to do step of booking saga. Booking of order which payment step timed out is released,
+// other timed out steps make saga stuck until it is resumed.
+var stepTimeouts = map[model.SagaStep]time.Duration{
+	model.BookStep:    time.Minute,
+	model.PaymentStep: paymentHoldTTL,
+	model.NotifyStep:  time.Minute,
+	model.ReleaseStep: time.Minute,
+	model.RefundStep:  24 * time.Hour,
+	model.BalanceStep: balanceAttempts * balanceRetryDelay, // after the last installment is due
+}
+
+// ResumeSagaEventHandler - attempt the current step of stuck saga again. Step is attempted only if the order is
+// still in status which the step expects, otherwise saga stays stuck.
+func (s *bookingService) ResumeSagaEventHandler(ctx context.Context, event events.ResumeSagaEvent) {
+	saga, err := s.storage.GetSagaRepo().GetSaga(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.ResumeSagaEventHandler] Failed to get saga of order %v: %v", event.OrderID, err)
+		return
+	}
+
+	if err = saga.Resume(s.now(), stepTimeouts[saga.Step]); err != nil {
+		s.log.Error("[bookingService.ResumeSagaEventHandler] Saga of order %v can not be resumed: %v", event.OrderID, err)
+		return
+	}
+
+	if err = s.storage.GetSagaRepo().UpdateSaga(ctx, saga); err != nil {
+		s.log.Error("[bookingService.ResumeSagaEventHandler] Failed to update saga of order %v: %v", event.OrderID, err)
+		return
+	}
+
+	s.log.Info("[bookingService.ResumeSagaEventHandler] Resumed %s step of saga of order %v, attempt %d", saga.Step, saga.OrderID, saga.Attempts)
+
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err == nil {
+		err = s.resumeStep(ctx, saga, order)
+	}
+
+	if err != nil {
+		s.log.Error("[bookingService.ResumeSagaEventHandler] Failed to resume saga of order %v: %v", event.OrderID, err)
+		s.updateSaga(ctx, event.OrderID, func(saga *model.Saga) bool {
+			saga.MarkStuck(s.now(), err.Error())
+			return true
+		})
+	}
+}
+
+// resumeStep - attempt step of saga of the order again. Error if the order is not in status which the step expects.
+// Payment is requested again with the same ID, so it is not charged twice: payment service publishes again result
+// of settled payment.
+func (s *bookingService) resumeStep(ctx context.Context, saga model.Saga, order ReservationOrder) error {
+	_, paid := paidNotification(order.Status)
+
+	switch {
+	case saga.Step == model.BookStep && order.Status == model.New:
+		s.bookNewOrder(ctx, order)
+	case saga.Step == model.PaymentStep && order.Status == model.Booked:
+		s.requestPayment(ctx, order, saga.PaymentID)
+	case saga.Step == model.PaymentStep && paid:
+		s.paymentStepDone(ctx, order)
+	case saga.Step == model.NotifyStep && paid:
+		s.notifyStep(ctx, order)
+	case saga.Step == model.BalanceStep && order.Status == model.DepositPaid:
+		s.resumeBalance(ctx, order)
+	case saga.Step == model.BalanceStep && order.Status == model.Paid:
+		s.finishSaga(ctx, order.ID, model.SagaCompleted, "balance is paid")
+	case saga.Step == model.ReleaseStep && order.Status == model.Booked && saga.ReleaseStatus == model.Expired:
+		s.expireOrder(ctx, order)
+	case saga.Step == model.ReleaseStep && order.Status == model.Booked:
+		s.failPayment(ctx, order, saga.CompensationReason)
+	case saga.Step == model.ReleaseStep && order.Status == saga.ReleaseStatus:
+		s.finishSaga(ctx, order.ID, model.SagaCompensated, "booking is released")
+	case saga.Step == model.RefundStep:
+		s.resumeRefund(ctx, saga, order)
+	default:
+		return fmt.Errorf("%s step can not be attempted for order in status %s", saga.Step, order.Status)
+	}
+
+	return nil
+}
+
+// startSaga - store saga of new order, redelivered order keeps its saga.
+func (s *bookingService) startSaga(ctx context.Context, order ReservationOrder) {
+	saga := model.NewSaga(order.ID, s.now(), stepTimeouts[model.BookStep])
+
+	if err := s.storage.GetSagaRepo().StoreSaga(ctx, saga); err != nil {
+		s.log.Error("[bookingService.startSaga] Failed to store saga of order %v: %v", order.ID, err)
+	}
+}
+
+// bookStepDone - next step of saga of processed order: payment is requested for booked order, waitlisted order
+// waits for rooms without timeout, saga of order which can not be booked fails.
+func (s *bookingService) bookStepDone(ctx context.Context, order ReservationOrder) {
+	switch {
+	case order.Status == model.Booked:
+		paymentID := paymentRequestID(order)
+
+		s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+			saga.MoveTo(model.PaymentStep, model.SagaRunning, s.now(), stepTimeouts[model.PaymentStep], "order is booked")
+			saga.PaymentID = paymentID
+			return true
+		})
+
+		s.requestPayment(ctx, order, paymentID)
+	case order.Status == model.NoRooms && order.Waitlist:
+		s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+			saga.MoveTo(model.BookStep, model.SagaRunning, s.now(), 0, "order is waitlisted")
+			return true
+		})
+	default:
+		detail := "order is " + string(order.Status)
+		if order.FailureReason != "" {
+			detail += ": " + order.FailureReason
+		}
+
+		s.finishSaga(ctx, order.ID, model.SagaFailed, detail)
+	}
+}
+
+// paymentStepRestarted - saga of modified booked order waits for payment of its new price, which is requested.
+func (s *bookingService) paymentStepRestarted(ctx context.Context, order ReservationOrder) {
+	paymentID := paymentRequestID(order)
+
+	s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+		saga.MoveTo(model.PaymentStep, model.SagaRunning, s.now(), stepTimeouts[model.PaymentStep], "order is modified, payment of new price is requested")
+		saga.PaymentID = paymentID
+		return true
+	})
+
+	s.requestPayment(ctx, order, paymentID)
+}
+
+// paymentStepDone - saga of paid order moves to notification step.
+func (s *bookingService) paymentStepDone(ctx context.Context, order ReservationOrder) {
+	s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+		saga.MoveTo(model.NotifyStep, model.SagaRunning, s.now(), stepTimeouts[model.NotifyStep], "order is "+string(order.Status))
+		return true
+	})
+
+	s.notifyStep(ctx, order)
+}
+
+// notifyStep - request notification about payment of the order. Saga of paid order is completed when it is
+// requested, saga of deposit paid order waits for balance.
+func (s *bookingService) notifyStep(ctx context.Context, order ReservationOrder) {
+	kind, _ := paidNotification(order.Status)
+
+	if err := s.notify(ctx, order, kind); err != nil {
+		s.failSagaStep(ctx, order.ID, err)
+		return
+	}
+
+	if order.Status == model.DepositPaid {
+		s.balanceStep(ctx, order, "guest is notified, balance is due")
+		return
+	}
+
+	s.finishSaga(ctx, order.ID, model.SagaCompleted, "guest is notified")
+}
+
+// balanceStep - saga of deposit paid order waits for balance until the last installment is due and every attempt
+// of its payment is made. Saga moves to notification step when balance is paid, it is finished when the order is
+// cancelled, and it is stuck if balance is neither paid nor failed in time.
+func (s *bookingService) balanceStep(ctx context.Context, order ReservationOrder, detail string) {
+	var dueAt time.Time
+	for _, installment := range order.PaymentSchedule {
+		if !installment.IsPaid() && installment.DueAt.After(dueAt) {
+			dueAt = installment.DueAt
+		}
+	}
+
+	timeout := max(dueAt.Sub(s.now()), 0) + stepTimeouts[model.BalanceStep]
+
+	s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+		if saga.IsFinished() || saga.Step != model.NotifyStep && saga.Step != model.BalanceStep {
+			return false
+		}
+
+		saga.MoveTo(model.BalanceStep, model.SagaRunning, s.now(), timeout, detail)
+		return true
+	})
+}
+
+// resumeBalance - requested installments of deposit paid order are requested again with the same IDs: lost request
+// is charged, result of settled payment is published again. Not requested installments are requested when due.
+func (s *bookingService) resumeBalance(ctx context.Context, order ReservationOrder) {
+	for _, installment := range order.PaymentSchedule {
+		if installment.IsPaid() || installment.RequestedAt.IsZero() {
+			continue
+		}
+
+		if err := s.publishPaymentRequest(ctx, order, installment.PaymentID, installment.Amount); err != nil {
+			s.failSagaStep(ctx, order.ID, err)
+			return
+		}
+	}
+}
+
+// paidNotification - notification about payment of order in the status, false if order is not paid.
+func paidNotification(status model.Status) (model.NotificationKind, bool) {
+	switch status {
+	case model.DepositPaid:
+		return model.OrderDepositPaidNotification, true
+	case model.Paid:
+		return model.OrderPaidNotification, true
+	default:
+		return "", false
+	}
+}
+
+// releaseNotPaid - compensation of booked order which is not paid: booking is released and the order gets final
+// status. Saga stays in release step if booking is not released.
+func (s *bookingService) releaseNotPaid(ctx context.Context, order ReservationOrder, status model.Status, reason string) (ReservationOrder, error) {
+	s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+		if saga.Step != model.ReleaseStep || saga.IsFinished() {
+			saga.MoveTo(model.ReleaseStep, model.SagaCompensating, s.now(), stepTimeouts[model.ReleaseStep], reason)
+		}
+		saga.CompensationReason = reason
+		saga.ReleaseStatus = status
+		return true
+	})
+
+	releasedOrder, err := s.releaseBooking(ctx, order, status, reason)
+	if err != nil {
+		s.failSagaStep(ctx, order.ID, err)
+		return order, err
+	}
+
+	s.finishSaga(ctx, order.ID, model.SagaCompensated, "booking is released")
+
+	return releasedOrder, nil
+}
+
+// refundLatePayment - compensation of payment which succeeded after booking of the order was released or failed:
+// payment is recorded on the order and its amount is refunded.
+func (s *bookingService) refundLatePayment(ctx context.Context, order ReservationOrder, event events.SuccessPaymentEvent) {
+	if saga, err := s.storage.GetSagaRepo().GetSaga(ctx, order.ID); err == nil && saga.Step == model.RefundStep && saga.PaymentID == event.PaymentID {
+		s.log.Info("[bookingService.refundLatePayment] Payment %v of order %v is already refunded", event.PaymentID, order.ID)
+		return
+	}
+
+	paidOrder, err := order.ApplyPayment(event.PaymentID, event.Amount, event.PaidAt)
+	if errors.Is(err, model.ErrDuplicatePayment) {
+		s.log.Info("[bookingService.refundLatePayment] Payment %v of order %v is already recorded", event.PaymentID, order.ID)
+		return
+	}
+	if err != nil && !errors.Is(err, model.ErrReplacedPayment) {
+		s.log.Error("[bookingService.refundLatePayment] Failed to add payment of order %v: %v", order.ID, err)
+		return
+	}
+	paidOrder.UpdatedAt = s.now()
+
+	if err = s.storage.GetOrderRepo().UpdateOrder(ctx, paidOrder.ID, paidOrder); err != nil {
+		s.log.Error("[bookingService.refundLatePayment] Failed to update order: %v", err)
+		return
+	}
+
+	reason := fmt.Sprintf("payment succeeded for order in status %s", order.Status)
+	s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+		saga.MoveTo(model.RefundStep, model.SagaCompensating, s.now(), stepTimeouts[model.RefundStep], reason)
+		saga.CompensationReason = reason
+		saga.PaymentID = event.PaymentID
+		saga.RefundID = uuid.Nil
+		saga.RefundAmount = event.Amount
+		return true
+	})
+
+	s.refundStep(ctx, paidOrder, event.Amount)
+}
+
+// refundStep - request refund of compensated payment, saga waits for the refund to be processed.
+func (s *bookingService) refundStep(ctx context.Context, order ReservationOrder, amount model.Money) {
+	refundedOrder := s.requestRefund(ctx, order, amount, model.CompensationRefund)
+	if len(refundedOrder.Refunds) == len(order.Refunds) {
+		s.failSagaStep(ctx, order.ID, errors.New("refund is not recorded"))
+		return
+	}
+
+	refundID := refundedOrder.Refunds[len(refundedOrder.Refunds)-1].ID
+	s.updateSaga(ctx, order.ID, func(saga *model.Saga) bool {
+		saga.RefundID = refundID
+		saga.RefundAmount = amount
+		return true
+	})
+}
+
+// refundStepDone - saga waiting for the refund is compensated when refund succeeded, failed refund makes it stuck.
+func (s *bookingService) refundStepDone(ctx context.Context, refund events.RefundProcessedEvent) {
+	s.updateSaga(ctx, refund.OrderID, func(saga *model.Saga) bool {
+		if saga.Step != model.RefundStep || saga.RefundID != refund.ID || saga.IsFinished() {
+			return false // refund of cancellation or modification
+		}
+
+		switch refund.Status {
+		case model.RefundFailed:
+			saga.MarkStuck(s.now(), "refund failed: "+refund.FailureReason)
+			return true
+		case model.RefundPartial:
+			saga.MarkStuck(s.now(), fmt.Sprintf("refund is made partially, %v of %v: %s", refund.RefundedAmount, refund.Amount, refund.FailureReason))
+			return true
+		}
+
+		saga.Finish(model.SagaCompensated, s.now(), "payment is refunded")
+		return true
+	})
+}
+
+// resumeRefund - pending refund of saga is requested again, failed one is replaced by new refund,
+// partial one by new refund of the rest.
+func (s *bookingService) resumeRefund(ctx context.Context, saga model.Saga, order ReservationOrder) {
+	for _, refund := range order.Refunds {
+		if refund.ID != saga.RefundID {
+			continue
+		}
+
+		switch refund.Status {
+		case model.RefundSucceeded:
+			s.finishSaga(ctx, order.ID, model.SagaCompensated, "payment is refunded")
+		case model.RefundPending:
+			s.publishRefund(ctx, refund)
+		case model.RefundPartial:
+			rest := saga.RefundAmount
+			rest.Amount -= refund.RefundedAmount.Amount
+			s.refundStep(ctx, order, rest)
+		default:
+			s.refundStep(ctx, order, saga.RefundAmount)
+		}
+
+		return
+	}
+
+	s.refundStep(ctx, order, saga.RefundAmount)
+}
+
+// handleSagaTimeouts - booking of order which payment step timed out is released, saga with other timed out step
+// is stuck until it is resumed.
+func (s *bookingService) handleSagaTimeouts(ctx context.Context, at time.Time) {
+	sagas, err := s.storage.GetSagaRepo().GetListSagas(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.handleSagaTimeouts] Failed to get sagas: %v", err)
+		return
+	}
+
+	for _, saga := range sagas {
+		if !saga.IsTimedOut(at) {
+			continue
+		}
+
+		reason := fmt.Sprintf("%s step timed out", saga.Step)
+
+		if saga.Step == model.PaymentStep {
+			order, err := s.storage.GetOrderRepo().GetOrder(ctx, saga.OrderID)
+			if err == nil && order.Status == model.Booked {
+				s.expireOrder(ctx, order)
+				continue
+			}
+
+			if err == nil {
+				reason += fmt.Sprintf(", order is %s", order.Status)
+			}
+		}
+
+		s.log.Error("[bookingService.handleSagaTimeouts] Saga of order %v is stuck: %s", saga.OrderID, reason)
+		s.updateSaga(ctx, saga.OrderID, func(saga *model.Saga) bool {
+			saga.MarkStuck(s.now(), reason)
+			return true
+		})
+	}
+}
+
+// finishSaga - end not finished saga of the order.
+func (s *bookingService) finishSaga(ctx context.Context, orderID ReservationOrderID, status model.SagaStatus, detail string) {
+	s.updateSaga(ctx, orderID, func(saga *model.Saga) bool {
+		if saga.IsFinished() {
+			return false
+		}
+
+		saga.Finish(status, s.now(), detail)
+		return true
+	})
+}
+
+// failSagaStep - record failed attempt of the current step of saga, it is stuck if step is not done before deadline.
+func (s *bookingService) failSagaStep(ctx context.Context, orderID ReservationOrderID, err error) {
+	s.updateSaga(ctx, orderID, func(saga *model.Saga) bool {
+		saga.Fail(s.now(), err.Error())
+		return true
+	})
+}
+
+// updateSaga - apply change to saga of the order and store it, nothing is stored if change returns false.
+func (s *bookingService) updateSaga(ctx context.Context, orderID ReservationOrderID, change func(*model.Saga) bool) {
+	saga, err := s.storage.GetSagaRepo().GetSaga(ctx, orderID)
+	if err != nil {
+		s.log.Error("[bookingService.updateSaga] Failed to get saga of order %v: %v", orderID, err)
+		return
+	}
+
+	if !change(&saga) {
+		return
+	}
+
+	if err = s.storage.GetSagaRepo().UpdateSaga(ctx, saga); err != nil {
+		s.log.Error("[bookingService.updateSaga] Failed to update saga of order %v: %v", orderID, err)
+	}
+}
+
+func (s *bookingService) GetSaga(ctx context.Context, orderID ReservationOrderID) (model.Saga, error) {
+	return s.storage.GetSagaRepo().GetSaga(ctx, orderID)
+}
+
+// GetListSagas returns sagas ordered by creation time, only stuck ones if stuck is set.
+func (s *bookingService) GetListSagas(ctx context.Context, stuck bool) ([]model.Saga, error) {
+	sagas, err := s.storage.GetSagaRepo().GetListSagas(ctx)
+	if err != nil || !stuck {
+		return sagas, err
+	}
+
+	stuckSagas := make([]model.Saga, 0, len(sagas))
+	for _, saga := range sagas {
+		if saga.IsStuck() {
+			stuckSagas = append(stuckSagas, saga)
+		}
+	}
+
+	return stuckSagas, nil
+}
@@ -0,0 +1,148 @@
+package booking
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+const (
+	balanceAttempts   = 3              // failed balance payments after which the order is cancelled
+	balanceRetryDelay = 24 * time.Hour // delay of the next request of failed balance payment
+)
+
+// paymentSchedule - split price of booked order into deposit and balance by payment terms of its rate plan.
+// Deposit is requested at once, balance is requested when it is due. Nil if total price is paid at once.
+func (s *bookingService) paymentSchedule(order ReservationOrder) []model.Installment {
+	if order.Price.DepositPercent <= 0 || order.CheckInAt.IsZero() {
+		return nil
+	}
+
+	balanceDueAt := order.CheckInAt.AddDate(0, 0, -order.Price.BalanceDueDays)
+
+	schedule := model.NewPaymentSchedule(order.Price.Total, order.Price.DepositPercent, s.now(), balanceDueAt)
+	if len(schedule) > 0 {
+		schedule[0].RequestedAt = s.now()
+	}
+
+	return schedule
+}
+
+// failInstallment - record failed payment of installment of deposit paid order. Payment is requested again
+// with new ID after balanceRetryDelay, when it fails balanceAttempts times the order is cancelled.
+// Returns false if the payment is not an installment of the order.
+func (s *bookingService) failInstallment(ctx context.Context, order ReservationOrder, paymentID uuid.UUID, reason string) bool {
+	failedOrder := order
+	failedOrder.PaymentSchedule = append([]model.Installment(nil), order.PaymentSchedule...)
+
+	i := slices.IndexFunc(failedOrder.PaymentSchedule, func(installment model.Installment) bool {
+		return installment.PaymentID == paymentID && !installment.IsPaid()
+	})
+	if i < 0 {
+		return false
+	}
+
+	installment := &failedOrder.PaymentSchedule[i]
+	installment.FailureReason = reason
+	installment.Failures++
+
+	if installment.Failures >= balanceAttempts {
+		s.log.Info("[bookingService.failInstallment] Balance of order %v failed %d times, order is cancelled", order.ID, installment.Failures)
+		s.cancelOrder(ctx, failedOrder, "balance payment failed: "+reason)
+		return true
+	}
+
+	installment.PaymentID = uuid.New()
+	installment.RequestedAt = time.Time{}
+	installment.DueAt = s.now().Add(balanceRetryDelay)
+	failedOrder.UpdatedAt = s.now()
+
+	if err := s.storage.GetOrderRepo().UpdateOrder(ctx, failedOrder.ID, failedOrder); err != nil {
+		s.log.Error("[bookingService.failInstallment] Failed to update order: %v", err)
+		return true
+	}
+
+	s.log.Info("[bookingService.failInstallment] Balance of order %v failed (%s), it is requested again at %v", order.ID, reason, installment.DueAt)
+
+	return true
+}
+
+// requestDueBalances - request payment of balance of deposit paid orders which due date has come.
+// Installment is marked as requested only when its request is published, otherwise it is requested
+// by the next tick. Request with the same ID is charged only once, so it can be repeated.
+func (s *bookingService) requestDueBalances(ctx context.Context, orders []ReservationOrder, at time.Time) {
+	for _, order := range orders {
+		if order.Status != model.DepositPaid {
+			continue
+		}
+
+		requestedOrder := order
+		requestedOrder.PaymentSchedule = append([]model.Installment(nil), order.PaymentSchedule...)
+
+		requested := false
+		for i, installment := range requestedOrder.PaymentSchedule {
+			if installment.IsPaid() || !installment.RequestedAt.IsZero() || installment.DueAt.After(at) {
+				continue
+			}
+
+			s.log.Info("[bookingService.requestDueBalances] Balance of order %v is due: %v", order.ID, installment.Amount)
+			if err := s.publishPaymentRequest(ctx, requestedOrder, installment.PaymentID, installment.Amount); err != nil {
+				continue
+			}
+
+			requestedOrder.PaymentSchedule[i].RequestedAt = at
+			requested = true
+		}
+
+		if !requested {
+			continue
+		}
+
+		requestedOrder.UpdatedAt = s.now()
+		if err := s.storage.GetOrderRepo().UpdateOrder(ctx, requestedOrder.ID, requestedOrder); err != nil {
+			s.log.Error("[bookingService.requestDueBalances] Failed to update order %v: %v", order.ID, err)
+		}
+	}
+}
+
+// rescheduleBalance - set not requested balance of deposit paid order to the rest of its new price and due date.
+// If paid amount already covers new price, order becomes paid and overpaid amount is returned for refund.
+// Returns false if balance is already requested, then price difference is settled as for paid order.
+func (s *bookingService) rescheduleBalance(order ReservationOrder) (ReservationOrder, model.Money, bool) {
+	balance := -1
+	for i, installment := range order.PaymentSchedule {
+		if installment.Kind == model.BalanceInstallment && !installment.IsPaid() {
+			balance = i
+		}
+	}
+
+	if balance < 0 || !order.PaymentSchedule[balance].RequestedAt.IsZero() {
+		return order, model.Money{}, false
+	}
+
+	rest, err := order.Price.Total.Sub(order.PaidAmount)
+	if err != nil {
+		s.log.Error("[bookingService.rescheduleBalance] Failed to calculate balance of order %v: %v", order.ID, err)
+		return order, model.Money{}, false
+	}
+
+	schedule := append([]model.Installment(nil), order.PaymentSchedule...)
+
+	if rest.Amount <= 0 {
+		order.PaymentSchedule = append(schedule[:balance], schedule[balance+1:]...)
+		order.Status = model.Paid
+
+		rest.Amount = -rest.Amount
+		return order, rest, true
+	}
+
+	schedule[balance].Amount = rest
+	schedule[balance].DueAt = order.CheckInAt.AddDate(0, 0, -order.Price.BalanceDueDays)
+	order.PaymentSchedule = schedule
+
+	return order, model.Money{}, true
+}
@@ -0,0 +1,59 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"aplication-design-test-task/internal/core/util"
+)
+
+var errEmptyStay = errors.New("stay must have at least one night")
+
+// withLocalStay - set stay of order by requested arrival and departure timestamps converted to hotel local dates.
+// Conversion must be done once per request, stored From and To are already local dates.
+func (s *bookingService) withLocalStay(ctx context.Context, order ReservationOrder, from, to time.Time) (ReservationOrder, error) {
+	hotel, err := s.storage.GetHotelRepo().GetHotel(ctx, order.HotelID)
+	if err != nil {
+		return order, fmt.Errorf("unknown hotel %d: %w", order.HotelID, err)
+	}
+
+	checkIn, checkOut, err := hotel.StayDates(from, to)
+	if err != nil {
+		return order, err
+	}
+
+	if !checkIn.Before(checkOut) {
+		return order, errEmptyStay
+	}
+
+	if order.CheckInAt, err = hotel.CheckInAt(checkIn); err != nil {
+		return order, err
+	}
+
+	if order.CheckOutAt, err = hotel.CheckOutAt(checkOut); err != nil {
+		return order, err
+	}
+
+	order.From, order.To = checkIn, checkOut
+
+	return order, nil
+}
+
+// localToday - current date in hotel timezone.
+func (s *bookingService) localToday(ctx context.Context, hotelID int) util.Day {
+	hotel, err := s.storage.GetHotelRepo().GetHotel(ctx, hotelID)
+	if err != nil {
+		s.log.Error("[bookingService.localToday] Failed to get hotel %d, UTC date is used: %v", hotelID, err)
+		return util.ToDay(s.now())
+	}
+
+	today, err := hotel.LocalDay(s.now())
+	if err != nil {
+		s.log.Error("[bookingService.localToday] Failed to get local date of hotel %d, UTC date is used: %v", hotelID, err)
+		return util.ToDay(s.now())
+	}
+
+	return today
+}
@@ -0,0 +1,202 @@
+package booking
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// JoinWaitlistEventHandler - put order which ended without rooms into waitlist of its hotel room type and period.
+func (s *bookingService) JoinWaitlistEventHandler(ctx context.Context, event events.JoinWaitlistEvent) {
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, event.OrderID)
+	if err != nil {
+		s.log.Error("[bookingService.JoinWaitlistEventHandler] Failed to get order %v: %v", event.OrderID, err)
+		return
+	}
+
+	if order.Status != model.NoRooms {
+		s.log.Error("[bookingService.JoinWaitlistEventHandler] Order %v can not join waitlist in status: %s", order.ID, order.Status)
+		return
+	}
+
+	if _, err = s.joinWaitlist(ctx, order); err != nil {
+		s.log.Error("[bookingService.JoinWaitlistEventHandler] Failed to join waitlist: %v", err)
+	}
+}
+
+func (s *bookingService) GetListWaitlist(ctx context.Context) ([]model.WaitlistEntry, error) {
+	return s.storage.GetWaitlistRepo().GetListEntries(ctx)
+}
+
+// joinWaitlist - store waiting entry for the order, existing waiting entry is returned as is.
+func (s *bookingService) joinWaitlist(ctx context.Context, order ReservationOrder) (model.WaitlistEntry, error) {
+	entries, err := s.storage.GetWaitlistRepo().GetOrderEntries(ctx, order.ID)
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.Status == model.WaitlistWaiting {
+			return entry, nil
+		}
+	}
+
+	entry := model.WaitlistEntry{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		UserEmail:  order.UserEmail,
+		HotelID:    order.HotelID,
+		RoomTypeID: order.RoomTypeID,
+		From:       order.From,
+		To:         order.To,
+		Status:     model.WaitlistWaiting,
+		CreatedAt:  s.now(),
+		UpdatedAt:  s.now(),
+	}
+
+	if err = s.storage.GetWaitlistRepo().StoreEntry(ctx, entry); err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	s.log.Info("[bookingService.joinWaitlist] Order joined waitlist: %+v", entry)
+
+	return entry, nil
+}
+
+// processWaitlist - re-evaluate waiting orders of hotel room type which stay intersects period with released quota.
+// The earliest entries are served first, entry keeps waiting while there is no quota for its whole stay.
+func (s *bookingService) processWaitlist(ctx context.Context, hotelID, roomTypeID int, from, to time.Time) {
+	entries, err := s.storage.GetWaitlistRepo().GetWaitingEntries(ctx, hotelID, roomTypeID, from, to)
+	if err != nil {
+		s.log.Error("[bookingService.processWaitlist] Failed to get waitlist: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.From.Before(s.localToday(ctx, entry.HotelID)) {
+			s.closeWaitlistEntry(ctx, entry, model.WaitlistExpired)
+			s.finishSaga(ctx, entry.OrderID, model.SagaFailed, "stay is started while order is waitlisted")
+			continue
+		}
+
+		order, err := s.storage.GetOrderRepo().GetOrder(ctx, entry.OrderID)
+		if err != nil {
+			s.log.Error("[bookingService.processWaitlist] Failed to get order %v: %v", entry.OrderID, err)
+			continue
+		}
+
+		if order.Status != model.NoRooms {
+			s.closeWaitlistEntry(ctx, entry, model.WaitlistFailed)
+			continue
+		}
+
+		processedOrder := s.processOrder(ctx, order)
+		if processedOrder.Status == model.NoRooms {
+			continue // still no quota for whole stay, keep waiting
+		}
+
+		if err = s.storage.GetOrderRepo().UpdateOrder(ctx, processedOrder.ID, processedOrder); err != nil {
+			s.log.Error("[bookingService.processWaitlist] Failed to update processed order: %v", err)
+			s.undoBooking(ctx, processedOrder)
+			continue // order keeps waiting with nothing booked
+		}
+
+		s.statusChanged(ctx, order.Status, processedOrder)
+		s.bookStepDone(ctx, processedOrder)
+
+		if processedOrder.Status != model.Booked {
+			s.closeWaitlistEntry(ctx, entry, model.WaitlistFailed)
+			continue
+		}
+
+		s.closeWaitlistEntry(ctx, entry, model.WaitlistFulfilled)
+		s.log.Info("[bookingService.processWaitlist] Waitlisted order is booked: %v", processedOrder)
+
+		s.notify(ctx, processedOrder, model.WaitlistBookedNotification)
+	}
+}
+
+// undoBooking - return quota, promo code and loyalty points taken by booking of the order which could not be stored
+// as booked, so waiting order does not hold them and booking it again does not take them twice.
+func (s *bookingService) undoBooking(ctx context.Context, order ReservationOrder) {
+	if order.Status != model.Booked {
+		return
+	}
+
+	tx, err := s.storage.BeginTx(ctx)
+	if err != nil {
+		s.log.Error("[bookingService.undoBooking] Failed to start transaction: %v", err)
+		return
+	}
+
+	if err = s.releaseOrderQuota(ctx, tx, order, order.From, order.To); err != nil {
+		s.log.Error("[bookingService.undoBooking] Failed to release quota of order %v: %v", order.ID, err)
+		return // nothing executed yet, operations run only on commit
+	}
+
+	s.releaseOrderBenefits(ctx, tx, order)
+
+	if err = tx.Commit(); err != nil {
+		s.log.Error("[bookingService.undoBooking] Failed to release booking of order %v: %v", order.ID, err)
+		return
+	}
+
+	s.log.Info("[bookingService.undoBooking] Booking of not stored order %v is released", order.ID)
+}
+
+func (s *bookingService) closeWaitlistEntry(ctx context.Context, entry model.WaitlistEntry, status model.WaitlistStatus) {
+	entry.Status = status
+	entry.UpdatedAt = s.now()
+
+	if err := s.storage.GetWaitlistRepo().UpdateEntry(ctx, entry); err != nil {
+		s.log.Error("[bookingService.closeWaitlistEntry] Failed to update waitlist entry %v: %v", entry.ID, err)
+	}
+}
+
+// notify - publish notification request for guest of the order.
+func (s *bookingService) notify(ctx context.Context, order ReservationOrder, kind model.NotificationKind) error {
+	notificationMsg := events.NotificationRequest{
+		ID:        uuid.New(),
+		OrderID:   order.ID,
+		UserEmail: order.UserEmail,
+		Guest:     order.Guest,
+		Kind:      kind,
+		CreatedAt: s.now(),
+		Order:     order,
+	}
+	if err := s.q.AsyncPublish(ctx, queue.NotificationRequest, notificationMsg); err != nil {
+		s.log.Error("[bookingService.notify] Failed to publish NotificationRequest msg: %v", err)
+		return err
+	}
+
+	s.log.Info("[bookingService.notify] Published NotificationRequest msg: %v", notificationMsg)
+
+	return nil
+}
+
+// statusChanged - publish status change of the order for partner webhooks, nothing is published if status is the same.
+func (s *bookingService) statusChanged(ctx context.Context, previous model.Status, order ReservationOrder) {
+	if previous == order.Status {
+		return
+	}
+
+	changeMsg := events.OrderStatusChangedEvent{
+		EventID:        uuid.New(),
+		OrderID:        order.ID,
+		PreviousStatus: previous,
+		Status:         order.Status,
+		ChangedAt:      s.now(),
+		Order:          order,
+	}
+	if err := s.q.AsyncPublish(ctx, queue.OrderStatusChanged, changeMsg); err != nil {
+		s.log.Error("[bookingService.statusChanged] Failed to publish OrderStatusChanged msg: %v", err)
+		return
+	}
+
+	s.log.Info("[bookingService.statusChanged] Published OrderStatusChanged msg: %s -> %s of order %v", previous, order.Status, order.ID)
+}
@@ -12,8 +12,17 @@ import (
 
 type eventHandler interface {
 	ReservationOrderEventHandler(context.Context, events.ReservationOrderEvent)
+	ModifyOrderEventHandler(context.Context, events.ModifyOrderEvent)
+	CancelOrderEventHandler(context.Context, events.CancelOrderEvent)
 	SuccessPaymentEventHandler(context.Context, events.SuccessPaymentEvent)
 	FailedPaymentEventHandler(context.Context, events.FailedPaymentEvent)
+	RefundProcessedEventHandler(context.Context, events.RefundProcessedEvent)
+	InventoryUpdateEventHandler(context.Context, events.InventoryUpdateEvent)
+	StayRestrictionsUpdateEventHandler(context.Context, events.StayRestrictionsUpdateEvent)
+	ExpireOrdersEventHandler(context.Context, events.ExpireOrdersEvent)
+	JoinWaitlistEventHandler(context.Context, events.JoinWaitlistEvent)
+	AllotmentEventHandler(context.Context, events.AllotmentEvent)
+	ResumeSagaEventHandler(context.Context, events.ResumeSagaEvent)
 }
 
 type worker struct {
@@ -26,7 +35,11 @@ func New(log logger.Logger, eh eventHandler) *worker {
 	return &worker{id: uuid.New(), log: log, eventHandler: eh}
 }
 
-func (w *worker) Run(ctx context.Context, ch <-chan queue.Msg) {
+// Run starts processing messages from all given channels.
+// Messages are handled one by one in a single goroutine, so handlers never race with each other.
+func (w *worker) Run(ctx context.Context, chs ...<-chan queue.Msg) {
+	ch := merge(ctx, chs...)
+
 	go func() {
 		for {
 			select {
@@ -39,12 +52,39 @@ func (w *worker) Run(ctx context.Context, ch <-chan queue.Msg) {
 				case events.ReservationOrderEvent:
 					w.log.Info("[bookingWorker: %v] received ReservationOrderEvent: %+v", w.id, event)
 					w.ReservationOrderEventHandler(ctx, event)
+				case events.ModifyOrderEvent:
+					w.log.Info("[bookingWorker: %v] received ModifyOrderEvent: %+v", w.id, event)
+					w.ModifyOrderEventHandler(ctx, event)
+				case events.CancelOrderEvent:
+					w.log.Info("[bookingWorker: %v] received CancelOrderEvent: %+v", w.id, event)
+					w.CancelOrderEventHandler(ctx, event)
 				case events.SuccessPaymentEvent:
 					w.log.Info("[bookingWorker: %v] received SuccessPaymentEvent: %+v", w.id, event)
 					w.SuccessPaymentEventHandler(ctx, event)
 				case events.FailedPaymentEvent:
 					w.log.Info("[bookingWorker: %v] received FailedPaymentEvent: %+v", w.id, event)
 					w.FailedPaymentEventHandler(ctx, event)
+				case events.RefundProcessedEvent:
+					w.log.Info("[bookingWorker: %v] received RefundProcessedEvent: %+v", w.id, event)
+					w.RefundProcessedEventHandler(ctx, event)
+				case events.InventoryUpdateEvent:
+					w.log.Info("[bookingWorker: %v] received InventoryUpdateEvent: %+v", w.id, event)
+					w.InventoryUpdateEventHandler(ctx, event)
+				case events.StayRestrictionsUpdateEvent:
+					w.log.Info("[bookingWorker: %v] received StayRestrictionsUpdateEvent: %+v", w.id, event)
+					w.StayRestrictionsUpdateEventHandler(ctx, event)
+				case events.ExpireOrdersEvent:
+					w.log.Info("[bookingWorker: %v] received ExpireOrdersEvent: %+v", w.id, event)
+					w.ExpireOrdersEventHandler(ctx, event)
+				case events.JoinWaitlistEvent:
+					w.log.Info("[bookingWorker: %v] received JoinWaitlistEvent: %+v", w.id, event)
+					w.JoinWaitlistEventHandler(ctx, event)
+				case events.AllotmentEvent:
+					w.log.Info("[bookingWorker: %v] received AllotmentEvent: %+v", w.id, event)
+					w.AllotmentEventHandler(ctx, event)
+				case events.ResumeSagaEvent:
+					w.log.Info("[bookingWorker: %v] received ResumeSagaEvent: %+v", w.id, event)
+					w.ResumeSagaEventHandler(ctx, event)
 				case nil:
 					continue
 				default:
@@ -54,3 +94,34 @@ func (w *worker) Run(ctx context.Context, ch <-chan queue.Msg) {
 		}
 	}()
 }
+
+// merge - fan-in several topic channels into one channel.
+func merge(ctx context.Context, chs ...<-chan queue.Msg) <-chan queue.Msg {
+	if len(chs) == 1 {
+		return chs[0]
+	}
+
+	out := make(chan queue.Msg)
+	for _, ch := range chs {
+		go func(ch <-chan queue.Msg) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg, ok := <-ch:
+					if !ok {
+						return // topic was deleted or queue closed
+					}
+
+					select {
+					case out <- msg:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}(ch)
+	}
+
+	return out
+}
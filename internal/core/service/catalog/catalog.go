@@ -0,0 +1,126 @@
+package catalog
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type catalogService struct {
+	storage storage.Storage
+}
+
+func New(s storage.Storage) *catalogService {
+	return &catalogService{storage: s}
+}
+
+func (s *catalogService) CreateHotel(ctx context.Context, hotel model.Hotel) error {
+	if err := hotel.Validate(); err != nil {
+		return err
+	}
+
+	return s.storage.GetHotelRepo().StoreHotel(ctx, hotel)
+}
+
+func (s *catalogService) UpdateHotel(ctx context.Context, hotel model.Hotel) error {
+	if err := hotel.Validate(); err != nil {
+		return err
+	}
+
+	return s.storage.GetHotelRepo().UpdateHotel(ctx, hotel)
+}
+
+// DeleteHotel - only hotel without room types can be deleted, room types must be deleted first.
+func (s *catalogService) DeleteHotel(ctx context.Context, id model.HotelID) error {
+	if _, err := s.storage.GetHotelRepo().GetHotel(ctx, id); err != nil {
+		return err
+	}
+
+	roomTypes, err := s.storage.GetRoomTypeRepo().GetHotelRoomTypes(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(roomTypes) > 0 {
+		return model.ErrHotelInUse
+	}
+
+	return s.storage.GetHotelRepo().DeleteHotel(ctx, id)
+}
+
+func (s *catalogService) GetHotel(ctx context.Context, id model.HotelID) (model.Hotel, error) {
+	return s.storage.GetHotelRepo().GetHotel(ctx, id)
+}
+
+func (s *catalogService) GetListHotels(ctx context.Context) ([]model.Hotel, error) {
+	hotels, err := s.storage.GetHotelRepo().GetListHotels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(hotels, func(a, b model.Hotel) int { return cmp.Compare(a.ID, b.ID) })
+
+	return hotels, nil
+}
+
+func (s *catalogService) CreateRoomType(ctx context.Context, roomType model.RoomType) error {
+	if err := roomType.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.storage.GetHotelRepo().GetHotel(ctx, roomType.HotelID); err != nil {
+		return err
+	}
+
+	return s.storage.GetRoomTypeRepo().StoreRoomType(ctx, roomType)
+}
+
+func (s *catalogService) UpdateRoomType(ctx context.Context, roomType model.RoomType) error {
+	if err := roomType.Validate(); err != nil {
+		return err
+	}
+
+	return s.storage.GetRoomTypeRepo().UpdateRoomType(ctx, roomType)
+}
+
+func (s *catalogService) DeleteRoomType(ctx context.Context, hotelID model.HotelID, id model.RoomTypeID) error {
+	return s.storage.GetRoomTypeRepo().DeleteRoomType(ctx, hotelID, id)
+}
+
+func (s *catalogService) GetRoomType(ctx context.Context, hotelID model.HotelID, id model.RoomTypeID) (model.RoomType, error) {
+	return s.storage.GetRoomTypeRepo().GetRoomType(ctx, hotelID, id)
+}
+
+func (s *catalogService) GetListRoomTypes(ctx context.Context, hotelID model.HotelID) ([]model.RoomType, error) {
+	if _, err := s.storage.GetHotelRepo().GetHotel(ctx, hotelID); err != nil {
+		return nil, err
+	}
+
+	return s.storage.GetRoomTypeRepo().GetHotelRoomTypes(ctx, hotelID)
+}
+
+// ValidateOrder checks that hotel and room type of order exist in catalog and guests fit the room type.
+func (s *catalogService) ValidateOrder(ctx context.Context, order model.Order) error {
+	_, err := s.storage.GetHotelRepo().GetHotel(ctx, order.HotelID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%w: %d", model.ErrUnknownHotel, order.HotelID)
+	}
+	if err != nil {
+		return err
+	}
+
+	roomType, err := s.storage.GetRoomTypeRepo().GetRoomType(ctx, order.HotelID, order.RoomTypeID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%w: %d in hotel %d", model.ErrUnknownRoomType, order.RoomTypeID, order.HotelID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return roomType.CheckOccupancy(order.Occupancy)
+}
@@ -0,0 +1,47 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/migration"
+)
+
+func TestCatalogService(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	require.NoError(t, migration.InitializeStorage(ctx, store))
+
+	s := New(store)
+
+	assert.NoError(t, s.ValidateOrder(ctx, model.Order{HotelID: 1, RoomTypeID: 3}))
+	assert.ErrorIs(t, s.ValidateOrder(ctx, model.Order{HotelID: 3, RoomTypeID: 1}), model.ErrUnknownHotel)
+	assert.ErrorIs(t, s.ValidateOrder(ctx, model.Order{HotelID: 1, RoomTypeID: 4}), model.ErrUnknownRoomType)
+	assert.ErrorIs(t, s.ValidateOrder(ctx, model.Order{HotelID: 1, RoomTypeID: 1, Occupancy: model.Occupancy{Adults: 3}}), model.ErrOccupancyExceeded)
+
+	hotel := model.Hotel{ID: 3, Name: "Alster Lake", Timezone: "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00", Currency: "EUR"}
+	require.NoError(t, s.CreateHotel(ctx, hotel))
+	assert.ErrorIs(t, s.CreateHotel(ctx, hotel), storage.ErrDuplicateConstraint)
+	assert.ErrorIs(t, s.CreateHotel(ctx, model.Hotel{ID: 4}), model.ErrHotelInvalid)
+
+	hotels, err := s.GetListHotels(ctx)
+	require.NoError(t, err)
+	require.Len(t, hotels, 3)
+	assert.Equal(t, 3, hotels[2].ID)
+
+	assert.ErrorIs(t, s.CreateRoomType(ctx, model.RoomType{ID: 1, HotelID: 404, Name: "Standard", Capacity: 2}), storage.ErrNotFound)
+	assert.ErrorIs(t, s.CreateRoomType(ctx, model.RoomType{ID: 1, HotelID: 3, Name: "Standard"}), model.ErrRoomTypeInvalid)
+	require.NoError(t, s.CreateRoomType(ctx, model.RoomType{ID: 1, HotelID: 3, Name: "Standard", Capacity: 2}))
+	assert.NoError(t, s.ValidateOrder(ctx, model.Order{HotelID: 3, RoomTypeID: 1}))
+
+	assert.ErrorIs(t, s.DeleteHotel(ctx, 3), model.ErrHotelInUse)
+	require.NoError(t, s.DeleteRoomType(ctx, 3, 1))
+	require.NoError(t, s.DeleteHotel(ctx, 3))
+	assert.ErrorIs(t, s.ValidateOrder(ctx, model.Order{HotelID: 3, RoomTypeID: 1}), model.ErrUnknownHotel)
+}
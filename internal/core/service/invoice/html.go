@@ -0,0 +1,54 @@
+package invoice
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"date":  func(t time.Time) string { return t.Format("02 Jan 2006") },
+	"money": func(m model.Money) string { return m.String() },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.amount, th.amount { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Issued: {{date .IssuedAt}}<br>Order: {{.OrderID}}<br>Stay: {{date .From}} &ndash; {{date .To}}</p>
+<table>
+<tr><th>Seller</th><th>Buyer</th></tr>
+<tr>
+<td>{{.Seller.Name}}{{with .Seller.Address}}<br>{{.}}{{end}}{{with .Seller.TaxID}}<br>VAT ID: {{.}}{{end}}</td>
+<td>{{.Buyer.Name}}{{with .Buyer.Email}}<br>{{.}}{{end}}</td>
+</tr>
+</table>
+<br>
+<table>
+<tr><th>Description</th><th class="amount">Net</th><th class="amount">VAT {{.TaxPercent}}%</th><th class="amount">Total</th></tr>
+{{- range .Lines}}
+<tr><td>{{.Description}}</td><td class="amount">{{money .Net}}</td><td class="amount">{{money .Tax}}</td><td class="amount">{{money .Gross}}</td></tr>
+{{- end}}
+<tr><th>Total</th><th class="amount">{{money .Net}}</th><th class="amount">{{money .Tax}}</th><th class="amount">{{money .Total}}</th></tr>
+</table>
+{{- with .Conversion}}
+<p>Total in {{.To.Currency}}: {{money .To}} at rate {{.Rate}}.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTML writes invoice as printable HTML page.
+func RenderHTML(w io.Writer, invoice model.Invoice) error {
+	return htmlTemplate.Execute(w, invoice)
+}
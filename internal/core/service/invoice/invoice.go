@@ -0,0 +1,114 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type invoiceService struct {
+	storage storage.Storage
+}
+
+func New(s storage.Storage) *invoiceService {
+	return &invoiceService{storage: s}
+}
+
+// Issue builds and stores numbered invoice of paid order. Invoice is issued once, repeated call returns the same one.
+func (s *invoiceService) Issue(ctx context.Context, order model.Order, issuedAt time.Time) (model.Invoice, error) {
+	if order.Status != model.Paid {
+		return model.Invoice{}, fmt.Errorf("order %v is not paid: %s", order.ID, order.Status)
+	}
+
+	if invoice, err := s.storage.GetInvoiceRepo().GetInvoice(ctx, order.ID); err == nil {
+		return invoice, nil
+	}
+
+	invoice, err := s.build(ctx, order, issuedAt)
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	return s.storage.GetInvoiceRepo().IssueInvoice(ctx, invoice)
+}
+
+// Reissue replaces invoice of changed paid order: current invoice is cancelled by credit note and new invoice
+// is issued. Invoice is issued as by Issue if the order has none, the current one is kept if the order is not changed.
+func (s *invoiceService) Reissue(ctx context.Context, order model.Order, issuedAt time.Time) (model.Invoice, error) {
+	current, err := s.storage.GetInvoiceRepo().GetInvoice(ctx, order.ID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return s.Issue(ctx, order, issuedAt)
+	}
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	if order.Status != model.Paid {
+		return model.Invoice{}, fmt.Errorf("order %v is not paid: %s", order.ID, order.Status)
+	}
+
+	invoice, err := s.build(ctx, order, issuedAt)
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	if invoice.Total == current.Total && invoice.From.Equal(current.From) && invoice.To.Equal(current.To) &&
+		slices.Equal(invoice.Lines, current.Lines) {
+		return current, nil
+	}
+
+	if _, err = s.storage.GetInvoiceRepo().IssueCreditNote(ctx, model.NewCreditNote(current, issuedAt), true); err != nil {
+		return model.Invoice{}, err
+	}
+
+	return s.storage.GetInvoiceRepo().IssueInvoice(ctx, invoice)
+}
+
+// Credit issues credit note of money refunded to the buyer of current invoice of the order.
+// storage.ErrNotFound if the order has no invoice.
+func (s *invoiceService) Credit(ctx context.Context, orderID model.OrderID, refunded model.Money, reason model.RefundReason, issuedAt time.Time) (model.Invoice, error) {
+	current, err := s.storage.GetInvoiceRepo().GetInvoice(ctx, orderID)
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	note, err := model.NewRefundCreditNote(current, refunded, reason, issuedAt)
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	return s.storage.GetInvoiceRepo().IssueCreditNote(ctx, note, false)
+}
+
+// build - not numbered invoice of the order by its hotel and room type.
+func (s *invoiceService) build(ctx context.Context, order model.Order, issuedAt time.Time) (model.Invoice, error) {
+	hotel, err := s.storage.GetHotelRepo().GetHotel(ctx, order.HotelID)
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	roomType, err := s.storage.GetRoomTypeRepo().GetRoomType(ctx, order.HotelID, order.RoomTypeID)
+	if err != nil {
+		return model.Invoice{}, err
+	}
+
+	return model.NewInvoice(order, hotel, roomType, issuedAt)
+}
+
+func (s *invoiceService) GetInvoice(ctx context.Context, orderID model.OrderID) (model.Invoice, error) {
+	return s.storage.GetInvoiceRepo().GetInvoice(ctx, orderID)
+}
+
+func (s *invoiceService) GetListOrderInvoices(ctx context.Context, orderID model.OrderID) ([]model.Invoice, error) {
+	return s.storage.GetInvoiceRepo().GetListOrderInvoices(ctx, orderID)
+}
+
+func (s *invoiceService) RenderHTML(w io.Writer, invoice model.Invoice) error {
+	return RenderHTML(w, invoice)
+}
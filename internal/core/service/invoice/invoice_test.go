@@ -0,0 +1,109 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/migration"
+)
+
+func TestInvoiceService_Issue(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	require.NoError(t, migration.InitializeStorage(ctx, store))
+
+	s := New(store)
+	issuedAt := time.Date(2024, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	paidOrder := func(hotelID model.HotelID, roomTypeID model.RoomTypeID, currency string) model.Order {
+		night := model.Money{Amount: 100_00, Currency: currency}
+		return model.Order{
+			ID: uuid.New(), HotelID: hotelID, RoomTypeID: roomTypeID, Status: model.Paid, UserEmail: "guest@example.com",
+			From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 2),
+			Price: model.Price{
+				Nights:   []model.NightPrice{{Date: util.NewDay(2024, 4, 1), Price: night}},
+				Subtotal: night, Discount: model.Money{Currency: currency}, Total: night,
+			},
+		}
+	}
+
+	first, err := s.Issue(ctx, paidOrder(1, 1, "EUR"), issuedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "1-000001", first.Number)
+	assert.Equal(t, 7, first.TaxPercent)
+	assert.Equal(t, "Spree Riverside", first.Seller.Name)
+
+	second, err := s.Issue(ctx, paidOrder(1, 2, "EUR"), issuedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "1-000002", second.Number)
+
+	other, err := s.Issue(ctx, paidOrder(2, 3, "USD"), issuedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "2-000001", other.Number, "numbering is per hotel")
+	assert.Equal(t, 20, other.TaxPercent)
+
+	again, err := s.Issue(ctx, model.Order{ID: first.OrderID, Status: model.Paid}, issuedAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, first, again, "invoice is issued once")
+
+	stored, err := s.GetInvoice(ctx, second.OrderID)
+	require.NoError(t, err)
+	assert.Equal(t, second, stored)
+
+	// changed order gets new invoice, the old one is cancelled by credit note
+	changed := paidOrder(1, 2, "EUR")
+	changed.ID = second.OrderID
+	changed.Price.Total.Amount = 90_00
+	changed.Price.Nights[0].Price.Amount = 90_00
+	replaced, err := s.Reissue(ctx, changed, issuedAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "1-000004", replaced.Number)
+	assert.Equal(t, changed.Price.Total, replaced.Total)
+
+	again, err = s.Reissue(ctx, changed, issuedAt.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, replaced, again, "invoice of not changed order is kept")
+
+	stored, err = s.GetInvoice(ctx, second.OrderID)
+	require.NoError(t, err)
+	assert.Equal(t, replaced, stored)
+
+	// refunded money is credited
+	credited, err := s.Credit(ctx, second.OrderID, model.Money{Amount: 40_00, Currency: "EUR"}, model.CancellationRefund, issuedAt.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "1-000005", credited.Number)
+	assert.Equal(t, replaced.Number, credited.Corrects)
+
+	documents, err := s.GetListOrderInvoices(ctx, second.OrderID)
+	require.NoError(t, err)
+	require.Len(t, documents, 4)
+	assert.Equal(t, []string{"1-000002", "1-000003", "1-000004", "1-000005"},
+		[]string{documents[0].Number, documents[1].Number, documents[2].Number, documents[3].Number})
+	assert.Equal(t, model.CreditNote, documents[1].Kind)
+	assert.Equal(t, second.Number, documents[1].Corrects)
+	assert.Equal(t, model.Money{Amount: -second.Total.Amount, Currency: "EUR"}, documents[1].Total)
+
+	_, err = s.Credit(ctx, uuid.New(), model.Money{Amount: 40_00, Currency: "EUR"}, model.CancellationRefund, issuedAt)
+	assert.ErrorIs(t, err, storage.ErrNotFound, "order without invoice has nothing to credit")
+
+	booked := paidOrder(1, 1, "EUR")
+	booked.Status = model.Booked
+	_, err = s.Issue(ctx, booked, issuedAt)
+	assert.ErrorContains(t, err, "is not paid")
+
+	var html bytes.Buffer
+	require.NoError(t, s.RenderHTML(&html, first))
+	assert.Contains(t, html.String(), "<title>Invoice 1-000001</title>")
+	assert.Contains(t, html.String(), "VAT ID: DE123456789")
+	assert.Contains(t, html.String(), "<td class=\"amount\">93.46 EUR</td>")
+}
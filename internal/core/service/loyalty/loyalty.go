@@ -0,0 +1,28 @@
+package loyalty
+
+import (
+	"context"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type loyaltyService struct {
+	storage storage.Storage
+}
+
+func New(s storage.Storage) *loyaltyService {
+	return &loyaltyService{storage: s}
+}
+
+func (s *loyaltyService) GetAccount(ctx context.Context, email string) (model.LoyaltyAccount, error) {
+	return s.storage.GetLoyaltyRepo().GetAccount(ctx, email)
+}
+
+func (s *loyaltyService) GetLedger(ctx context.Context, email string) ([]model.LoyaltyEntry, error) {
+	if _, err := s.storage.GetLoyaltyRepo().GetAccount(ctx, email); err != nil {
+		return nil, err
+	}
+
+	return s.storage.GetLoyaltyRepo().GetLedger(ctx, email)
+}
@@ -1,3 +1,190 @@
 package notification
 
-// todo
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/port/gateway"
+	"aplication-design-test-task/internal/core/service/invoice"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/logger"
+)
+
+const (
+	maxSendAttempts = 3
+	sendTimeout     = 10 * time.Second // of one attempt
+	retryBackoff    = time.Second      // grows linearly with attempt
+)
+
+type notificationService struct {
+	log     logger.Logger
+	q       queue.Queue
+	storage storage.Storage
+	mailer  gateway.Mailer
+
+	backoff time.Duration
+}
+
+func New(log logger.Logger, q queue.Queue, s storage.Storage, mailer gateway.Mailer) *notificationService {
+	return &notificationService{
+		log:     log,
+		q:       q,
+		storage: s,
+		mailer:  mailer,
+		backoff: retryBackoff,
+	}
+}
+
+// Run starts consuming notification requests. Emails are sent one by one in a single goroutine.
+func (s *notificationService) Run(ctx context.Context) error {
+	ch, err := s.q.Subscribe(ctx, queue.NotificationRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to topic %s. err: %v", queue.NotificationRequest, err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.Info("[notificationService] ctx.Done(). finished")
+				return
+
+			case msg, ok := <-ch:
+				if !ok {
+					return // topic was deleted or queue closed
+				}
+
+				switch event := msg.(type) {
+				case events.NotificationRequest:
+					s.log.Info("[notificationService] received NotificationRequest: %+v", event)
+					s.NotificationRequestHandler(ctx, event)
+				default:
+					s.log.Error("[notificationService] received unknown msg: %+v", msg)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// NotificationRequestHandler - render email of notification kind and send it to guest. Not accepted email is retried
+// maxSendAttempts times, after that notification is dropped.
+func (s *notificationService) NotificationRequestHandler(ctx context.Context, notification events.NotificationRequest) {
+	email, err := s.render(ctx, notification)
+	if err != nil {
+		s.log.Error("[notificationService.NotificationRequestHandler] Failed to render notification %v: %v", notification.ID, err)
+		return
+	}
+
+	if err = s.send(ctx, email); err != nil {
+		s.log.Error("[notificationService.NotificationRequestHandler] Failed to send notification %v: %v", notification.ID, err)
+		return
+	}
+
+	s.log.Info("[notificationService.NotificationRequestHandler] Sent %s notification of order %v", notification.Kind, notification.OrderID)
+}
+
+func (s *notificationService) render(ctx context.Context, notification events.NotificationRequest) (model.Email, error) {
+	tmpl, ok := templates[notification.Kind]
+	if !ok {
+		return model.Email{}, fmt.Errorf("no template for notification kind %q", notification.Kind)
+	}
+
+	if notification.UserEmail == "" {
+		return model.Email{}, fmt.Errorf("no email of order %v", notification.OrderID)
+	}
+
+	order := notification.Order
+	if order.ID == (model.OrderID{}) {
+		order.ID = notification.OrderID
+	}
+
+	hotel, err := s.storage.GetHotelRepo().GetHotel(ctx, order.HotelID)
+	if err != nil {
+		s.log.Error("[notificationService.render] Failed to get hotel %d: %v", order.HotelID, err)
+		hotel = model.Hotel{ID: order.HotelID, Name: fmt.Sprintf("hotel #%d", order.HotelID)}
+	}
+
+	guest := notification.Guest
+	if guest.Name == "" {
+		guest.Name = "guest"
+	}
+
+	data := emailData{
+		Guest:  guest,
+		Hotel:  hotel,
+		Order:  order,
+		Nights: len(util.NightsBetween(order.From, order.To)),
+	}
+
+	var attachments []model.Attachment
+	if notification.Kind == model.OrderPaidNotification {
+		if data.Invoice, attachments, err = s.invoiceAttachment(ctx, order.ID); err != nil {
+			s.log.Error("[notificationService.render] Email is sent without invoice of order %v: %v", order.ID, err)
+		}
+	}
+
+	var subject, body strings.Builder
+	if err = tmpl.subject.Execute(&subject, data); err != nil {
+		return model.Email{}, err
+	}
+	if err = tmpl.body.Execute(&body, data); err != nil {
+		return model.Email{}, err
+	}
+
+	return model.Email{To: notification.UserEmail, Subject: subject.String(), Body: body.String(), Attachments: attachments}, nil
+}
+
+// invoiceAttachment - invoice of the order rendered as HTML file.
+func (s *notificationService) invoiceAttachment(ctx context.Context, orderID model.OrderID) (*model.Invoice, []model.Attachment, error) {
+	inv, err := s.storage.GetInvoiceRepo().GetInvoice(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var content bytes.Buffer
+	if err = invoice.RenderHTML(&content, inv); err != nil {
+		return nil, nil, err
+	}
+
+	return &inv, []model.Attachment{{
+		Filename:    "invoice-" + inv.Number + ".html",
+		ContentType: "text/html; charset=UTF-8",
+		Content:     content.Bytes(),
+	}}, nil
+}
+
+func (s *notificationService) send(ctx context.Context, email model.Email) error {
+	var err error
+	for attempt := 1; attempt <= maxSendAttempts; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = s.mailer.Send(sendCtx, email)
+		cancel()
+
+		if err == nil {
+			return nil
+		}
+
+		s.log.Error("[notificationService.send] Attempt %d of %d to send email to %s failed: %v", attempt, maxSendAttempts, email.To, err)
+
+		if attempt == maxSendAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * s.backoff):
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,165 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/gateway/smtp"
+	"aplication-design-test-task/internal/adapters/gateway/smtp/smtptest"
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/queue/gochanqueue"
+	inmemory "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/logger"
+	"aplication-design-test-task/migration"
+)
+
+func newTestService(t *testing.T, ctx context.Context, server *smtptest.Server) (*notificationService, queue.Queue) {
+	log := logger.New()
+	q := gochanqueue.NewChanQueue(log)
+	for _, topic := range queue.AllTopics {
+		require.NoError(t, q.CreateTopic(ctx, topic))
+	}
+
+	store := inmemory.NewStorage()
+	require.NoError(t, migration.InitializeStorage(ctx, store))
+
+	s := New(log, q, store, smtp.New(smtp.Config{Addr: server.Addr, From: "booking@example.com"}))
+	s.backoff = time.Millisecond
+
+	return s, q
+}
+
+func bookedNotification(kind model.NotificationKind) events.NotificationRequest {
+	order := model.Order{
+		ID:        uuid.New(),
+		HotelID:   1,
+		UserEmail: "guest@example.com",
+		From:      util.NewDay(2024, 4, 1),
+		To:        util.NewDay(2024, 4, 3),
+		Guest:     model.Guest{Name: "Ars"},
+		Price:     model.Price{Total: model.Money{Amount: 200_50, Currency: "EUR"}},
+		Status:    model.Booked,
+	}
+
+	return events.NotificationRequest{
+		ID:        uuid.New(),
+		OrderID:   order.ID,
+		UserEmail: order.UserEmail,
+		Guest:     order.Guest,
+		Kind:      kind,
+		Order:     order,
+	}
+}
+
+func TestNotificationService_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	s, q := newTestService(t, ctx, server)
+	require.NoError(t, s.Run(ctx))
+
+	notification := bookedNotification(model.OrderBookedNotification)
+	require.NoError(t, q.Publish(ctx, queue.NotificationRequest, notification))
+
+	require.Eventually(t, func() bool { return len(server.Messages()) == 1 }, 3*time.Second, 10*time.Millisecond)
+
+	message := server.Messages()[0]
+	assert.Equal(t, []string{"guest@example.com"}, message.To)
+	assert.Contains(t, message.Data, "Subject: Your booking at Spree Riverside is confirmed")
+	assert.Contains(t, message.Data, "Dear Ars,")
+	assert.Contains(t, message.Data, "Please pay 200.50 EUR")
+	assert.Contains(t, message.Data, "Check-in:  Mon, 01 Apr 2024 from 15:00")
+	assert.Contains(t, message.Data, "Nights:    2")
+	assert.Contains(t, message.Data, notification.OrderID.String())
+}
+
+func TestNotificationService_Templates(t *testing.T) {
+	ctx := context.Background()
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	s, _ := newTestService(t, ctx, server)
+
+	for kind, expected := range map[model.NotificationKind]string{
+		model.OrderBookedNotification:    "is confirmed",
+		model.WaitlistBookedNotification: "became available",
+		model.OrderPaidNotification:      "Payment received",
+		model.OrderCancelledNotification: "is cancelled",
+		model.OrderFailedNotification:    "could not book",
+	} {
+		notification := bookedNotification(kind)
+		notification.Order.FailureReason = "payment failed: insufficient funds"
+
+		email, err := s.render(ctx, notification)
+		require.NoError(t, err, kind)
+		assert.Contains(t, email.Subject, expected, kind)
+		assert.Contains(t, email.Body, "Spree Riverside", kind)
+
+		if kind == model.OrderFailedNotification {
+			assert.Contains(t, email.Body, "insufficient funds")
+		}
+	}
+
+	_, err := s.render(ctx, bookedNotification("unknown"))
+	assert.Error(t, err)
+
+	notification := bookedNotification(model.OrderBookedNotification)
+	notification.UserEmail = ""
+	_, err = s.render(ctx, notification)
+	assert.Error(t, err, "nowhere to send")
+}
+
+func TestNotificationService_Retry(t *testing.T) {
+	ctx := context.Background()
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	s, _ := newTestService(t, ctx, server)
+
+	server.FailNext(maxSendAttempts - 1)
+	s.NotificationRequestHandler(ctx, bookedNotification(model.OrderPaidNotification))
+	assert.Len(t, server.Messages(), 1, "delivered by last attempt")
+
+	server.FailNext(maxSendAttempts)
+	s.NotificationRequestHandler(ctx, bookedNotification(model.OrderPaidNotification))
+	assert.Len(t, server.Messages(), 1, "dropped after all attempts")
+}
+
+func TestNotificationService_InvoiceAttachment(t *testing.T) {
+	ctx := context.Background()
+	server := smtptest.NewServer()
+	defer server.Close()
+
+	s, _ := newTestService(t, ctx, server)
+
+	notification := bookedNotification(model.OrderPaidNotification)
+	email, err := s.render(ctx, notification)
+	require.NoError(t, err)
+	assert.Empty(t, email.Attachments, "invoice is not issued yet")
+
+	_, err = s.storage.GetInvoiceRepo().IssueInvoice(ctx, model.Invoice{HotelID: 1, OrderID: notification.OrderID})
+	require.NoError(t, err)
+
+	email, err = s.render(ctx, notification)
+	require.NoError(t, err)
+	assert.Contains(t, email.Body, "Invoice 1-000001 is attached.")
+	require.Len(t, email.Attachments, 1)
+	assert.Equal(t, "invoice-1-000001.html", email.Attachments[0].Filename)
+	assert.Contains(t, string(email.Attachments[0].Content), "<h1>Invoice 1-000001</h1>")
+
+	s.NotificationRequestHandler(ctx, notification)
+	require.Len(t, server.Messages(), 1)
+	assert.Contains(t, server.Messages()[0].Data, "Content-Type: multipart/mixed; boundary=")
+	assert.Contains(t, server.Messages()[0].Data, `Content-Disposition: attachment; filename=invoice-1-000001.html`)
+}
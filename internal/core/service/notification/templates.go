@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"text/template"
+	"time"
+
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+// emailTemplate - subject and plain text body of notification email, both are rendered with emailData.
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+type emailData struct {
+	Guest  model.Guest
+	Hotel  model.Hotel
+	Order  model.Order
+	Nights int
+
+	Invoice *model.Invoice // attached to email of paid order
+}
+
+var templateFuncs = template.FuncMap{
+	"date":  func(t time.Time) string { return t.Format("Mon, 02 Jan 2006") },
+	"money": func(m model.Money) string { return m.String() },
+}
+
+const stayDetails = `
+Hotel:     {{.Hotel.Name}}{{with .Hotel.Address}}, {{.}}{{end}}
+Check-in:  {{date .Order.From}}{{with .Hotel.CheckInTime}} from {{.}}{{end}}
+Check-out: {{date .Order.To}}{{with .Hotel.CheckOutTime}} until {{.}}{{end}}
+Nights:    {{.Nights}}
+Order:     {{.Order.ID}}
+`
+
+var templates = map[model.NotificationKind]emailTemplate{
+	model.OrderBookedNotification: newEmailTemplate(
+		`Your booking at {{.Hotel.Name}} is confirmed`,
+		`Dear {{.Guest.Name}},
+
+your room is booked. Please pay {{money .Order.AmountDue}} to keep the booking.
+{{- with .Order.Balance}}
+The balance of {{money .Amount}} will be charged on {{date .DueAt}}.{{end}}
+{{- with .Order.Conversion}}
+Total price is {{money .From}}, that is {{money .To}} at rate {{.Rate}}.{{end}}
+`+stayDetails),
+
+	model.WaitlistBookedNotification: newEmailTemplate(
+		`A room at {{.Hotel.Name}} became available for you`,
+		`Dear {{.Guest.Name}},
+
+good news: a room became available and we booked it from the waitlist.
+Please pay {{money .Order.AmountDue}} to keep the booking.
+{{- with .Order.Balance}}
+The balance of {{money .Amount}} will be charged on {{date .DueAt}}.{{end}}
+`+stayDetails),
+
+	model.OrderDepositPaidNotification: newEmailTemplate(
+		`Deposit received for your stay at {{.Hotel.Name}}`,
+		`Dear {{.Guest.Name}},
+
+we received your deposit of {{money .Order.PaidAmount}}.
+{{- with .Order.Balance}} The balance of {{money .Amount}} will be charged on {{date .DueAt}}.{{end}}
+`+stayDetails),
+
+	model.OrderPaidNotification: newEmailTemplate(
+		`Payment received for your stay at {{.Hotel.Name}}`,
+		`Dear {{.Guest.Name}},
+
+we received your payment of {{money .Order.Price.Total}}. We are looking forward to your stay.
+{{- with .Invoice}}
+Invoice {{.Number}} is attached.{{end}}
+`+stayDetails),
+
+	model.OrderCancelledNotification: newEmailTemplate(
+		`Your booking at {{.Hotel.Name}} is cancelled`,
+		`Dear {{.Guest.Name}},
+
+your booking is cancelled.
+{{- range .Order.Refunds}}{{if eq .Reason "cancellation"}} {{money .Amount}} will be refunded to you.{{end}}{{end}}
+`+stayDetails),
+
+	model.OrderFailedNotification: newEmailTemplate(
+		`We could not book your stay at {{.Hotel.Name}}`,
+		`Dear {{.Guest.Name}},
+
+unfortunately your order could not be completed{{with .Order.FailureReason}}: {{.}}{{end}}.
+`+stayDetails),
+}
+
+func newEmailTemplate(subject, body string) emailTemplate {
+	return emailTemplate{
+		subject: template.Must(template.New("subject").Funcs(templateFuncs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(templateFuncs).Parse(body)),
+	}
+}
@@ -0,0 +1,86 @@
+package overbooking
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+type overbookingService struct {
+	storage storage.Storage
+}
+
+func New(s storage.Storage) *overbookingService {
+	return &overbookingService{storage: s}
+}
+
+func (s *overbookingService) CreateRule(ctx context.Context, rule model.OverbookingRule) (model.OverbookingRule, error) {
+	if err := rule.Validate(); err != nil {
+		return model.OverbookingRule{}, err
+	}
+
+	rule.ID = uuid.New()
+
+	if err := s.storage.GetOverbookingRepo().StoreRule(ctx, rule); err != nil {
+		return model.OverbookingRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (s *overbookingService) DeleteRule(ctx context.Context, id model.OverbookingRuleID) error {
+	if _, err := s.storage.GetOverbookingRepo().GetRule(ctx, id); err != nil {
+		return err
+	}
+
+	return s.storage.GetOverbookingRepo().DeleteRule(ctx, id)
+}
+
+func (s *overbookingService) GetListRules(ctx context.Context) ([]model.OverbookingRule, error) {
+	return s.storage.GetOverbookingRepo().GetListRules(ctx)
+}
+
+// GetOversoldReport returns room-days which are sold over physical capacity, hotelID 0 - all hotels.
+func (s *overbookingService) GetOversoldReport(ctx context.Context, hotelID int) ([]model.OversoldNight, error) {
+	rooms, err := s.storage.GetRoomRepo().GetListRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.storage.GetOverbookingRepo().GetListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nights := make([]model.OversoldNight, 0)
+	for _, room := range rooms {
+		if room.Quota >= 0 || (hotelID != 0 && room.HotelID != hotelID) {
+			continue
+		}
+
+		nights = append(nights, model.OversoldNight{
+			HotelID:    room.HotelID,
+			RoomTypeID: room.RoomTypeID,
+			Date:       room.Date,
+			Capacity:   room.Capacity,
+			Sold:       room.Sold(),
+			Oversold:   -room.Quota,
+			Allowance:  model.OverbookingAllowance(rules, room),
+		})
+	}
+
+	slices.SortFunc(nights, func(a, b model.OversoldNight) int {
+		return cmp.Or(
+			cmp.Compare(a.HotelID, b.HotelID),
+			a.Date.Compare(b.Date),
+			cmp.Compare(a.RoomTypeID, b.RoomTypeID),
+		)
+	})
+
+	return nights, nil
+}
@@ -0,0 +1,57 @@
+package overbooking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/migration"
+)
+
+func TestOverbookingService_GetOversoldReport(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	require.NoError(t, migration.InitializeStorage(ctx, store))
+
+	s := New(store)
+
+	rule, err := s.CreateRule(ctx, model.OverbookingRule{HotelID: 2, LimitType: model.PercentOverbooking, Limit: 20})
+	require.NoError(t, err)
+	assert.NotZero(t, rule.ID)
+
+	_, err = s.CreateRule(ctx, model.OverbookingRule{HotelID: 2, LimitType: "rooms"})
+	assert.ErrorIs(t, err, model.ErrOverbookingRuleInvalid)
+
+	oversell := func(hotelID int, day util.Day, quota int) {
+		rooms, err := store.GetRoomRepo().GetRoomsForHotelByRoomTypeAndDate(ctx, hotelID, 1, day, day.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		require.Len(t, rooms, 1)
+
+		rooms[0].Quota = quota
+		require.NoError(t, store.GetRoomRepo().UpdateRoom(ctx, rooms[0].ID, rooms[0]))
+	}
+
+	oversell(2, util.NewDay(2024, 4, 3), -2)
+	oversell(2, util.NewDay(2024, 4, 2), -1)
+	oversell(1, util.NewDay(2024, 4, 1), 0) // sold out, but not oversold
+
+	nights, err := s.GetOversoldReport(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []model.OversoldNight{
+		{HotelID: 2, RoomTypeID: 1, Date: util.NewDay(2024, 4, 2), Capacity: 10, Sold: 11, Oversold: 1, Allowance: 2},
+		{HotelID: 2, RoomTypeID: 1, Date: util.NewDay(2024, 4, 3), Capacity: 10, Sold: 12, Oversold: 2, Allowance: 2},
+	}, nights)
+
+	nights, err = s.GetOversoldReport(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, nights)
+
+	require.NoError(t, s.DeleteRule(ctx, rule.ID))
+	assert.ErrorIs(t, s.DeleteRule(ctx, rule.ID), storage.ErrNotFound)
+}
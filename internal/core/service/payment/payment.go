@@ -1,3 +1,262 @@
 package payment
 
-// todo
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/port/gateway"
+	"aplication-design-test-task/internal/logger"
+)
+
+const chargeTimeout = 30 * time.Second // payment is failed if gateway does not answer in time
+
+type paymentService struct {
+	log     logger.Logger
+	q       queue.Queue
+	storage storage.Storage
+	gateway gateway.PaymentGateway
+
+	timeout time.Duration
+	now     func() time.Time
+
+	// settleMu serialises settlement of payments: gateway answers, provider callbacks and reconciliation
+	// come from different goroutines, and a payment must be settled once.
+	settleMu sync.Mutex
+}
+
+func New(log logger.Logger, q queue.Queue, s storage.Storage, g gateway.PaymentGateway) *paymentService {
+	return &paymentService{
+		log:     log,
+		q:       q,
+		storage: s,
+		gateway: g,
+		timeout: chargeTimeout,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run starts consuming payment and refund requests. They are processed one by one in a single goroutine.
+func (s *paymentService) Run(ctx context.Context) error {
+	ch, err := s.q.Subscribe(ctx, queue.PaymentRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to topic %s. err: %v", queue.PaymentRequest, err)
+	}
+
+	refunds, err := s.q.Subscribe(ctx, queue.RefundRequest)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to topic %s. err: %v", queue.RefundRequest, err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.Info("[paymentService] ctx.Done(). finished")
+				return
+
+			case msg, ok := <-ch:
+				if !ok {
+					return // topic was deleted or queue closed
+				}
+
+				switch event := msg.(type) {
+				case events.PaymentRequest:
+					s.log.Info("[paymentService] received PaymentRequest: %+v", event)
+					s.PaymentRequestHandler(ctx, event)
+				default:
+					s.log.Error("[paymentService] received unknown msg: %+v", msg)
+				}
+
+			case msg, ok := <-refunds:
+				if !ok {
+					return
+				}
+
+				switch event := msg.(type) {
+				case events.RefundRequest:
+					s.log.Info("[paymentService] received RefundRequest: %+v", event)
+					s.RefundRequestHandler(ctx, event)
+				default:
+					s.log.Error("[paymentService] received unknown msg: %+v", msg)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// PaymentRequestHandler - store payment, charge it by gateway and publish result for booking service.
+// Result of pending payment is published when provider sends callback. Repeated request of the same payment is not
+// charged again, result of settled payment is published again.
+func (s *paymentService) PaymentRequestHandler(ctx context.Context, payment events.PaymentRequest) {
+	if err := s.storage.GetPaymentRepo().StorePayment(ctx, payment); err != nil {
+		if errors.Is(err, storage.ErrDuplicateConstraint) {
+			s.resendPaymentResult(ctx, payment)
+			return
+		}
+
+		s.log.Error("[paymentService.PaymentRequestHandler] Failed to store payment %v: %v", payment.ID, err)
+		return
+	}
+
+	chargeCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	charge, err := s.gateway.Charge(chargeCtx, payment)
+	cancel()
+
+	s.settleMu.Lock()
+	defer s.settleMu.Unlock()
+
+	if stored, getErr := s.storage.GetPaymentRepo().GetPayment(ctx, payment.ID); getErr == nil && model.IsSettled(stored) {
+		s.log.Info("[paymentService.PaymentRequestHandler] Payment %v is settled while charged, gateway answer is ignored", payment.ID)
+		return
+	}
+
+	switch {
+	case errors.Is(err, model.ErrPaymentPending):
+		s.log.Info("[paymentService.PaymentRequestHandler] Payment %v waits for provider callback", payment.ID)
+	case errors.Is(err, model.ErrPaymentDeclined):
+		s.log.Info("[paymentService.PaymentRequestHandler] Payment %v is declined: %v", payment.ID, err)
+		s.fail(ctx, payment, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		reason := fmt.Sprintf("payment gateway did not answer in %s", s.timeout)
+		s.log.Error("[paymentService.PaymentRequestHandler] Payment %v: %s", payment.ID, reason)
+		s.fail(ctx, payment, reason)
+	case err != nil:
+		s.log.Error("[paymentService.PaymentRequestHandler] Failed to charge payment %v: %v", payment.ID, err)
+		s.fail(ctx, payment, err.Error())
+	default:
+		s.log.Info("[paymentService.PaymentRequestHandler] Payment %v is charged: %+v", payment.ID, charge)
+		s.succeed(ctx, payment, charge)
+	}
+}
+
+// CallbackHandler - settle payment by asynchronous result of provider. Callback is matched to order by stored payment.
+// Redelivered callback and callback of already settled payment are accepted without effect, callback is recorded
+// after its effect, so it is not lost if settlement is interrupted. Success with another amount than of the payment
+// is rejected by model.ErrPaymentAmountMismatch.
+func (s *paymentService) CallbackHandler(ctx context.Context, callback model.PaymentCallback) error {
+	if err := callback.Validate(); err != nil {
+		return err
+	}
+
+	s.settleMu.Lock()
+	defer s.settleMu.Unlock()
+
+	payment, err := s.storage.GetPaymentRepo().GetPayment(ctx, callback.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	callback.OrderID = payment.OrderID
+	callback.ReceivedAt = s.now()
+
+	switch {
+	case model.IsSettled(payment):
+		s.log.Info("[paymentService.CallbackHandler] Payment %v is already settled, callback %s is ignored", payment.ID, callback.EventID)
+	case callback.Status == model.PaymentFailed:
+		s.fail(ctx, payment, callback.Reason)
+	case callback.Amount != payment.Amount:
+		return fmt.Errorf("%w: callback %s is for %s, payment %v is for %s",
+			model.ErrPaymentAmountMismatch, callback.EventID, callback.Amount, payment.ID, payment.Amount)
+	default:
+		s.succeed(ctx, payment, model.Charge{
+			TransactionID: callback.TransactionID,
+			PaymentID:     payment.ID,
+			Amount:        callback.Amount,
+			ChargedAt:     callback.OccurredAt,
+		})
+	}
+
+	if err = s.storage.GetPaymentRepo().StoreCallback(ctx, callback); err != nil {
+		if errors.Is(err, storage.ErrDuplicateConstraint) {
+			s.log.Info("[paymentService.CallbackHandler] Callback %s is already processed", callback.EventID)
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// resendPaymentResult - publish again result of repeated payment request, e.g. of resumed saga which lost the result.
+// Payment which is still charged or waits for callback is ignored.
+func (s *paymentService) resendPaymentResult(ctx context.Context, payment events.PaymentRequest) {
+	stored, err := s.storage.GetPaymentRepo().GetPayment(ctx, payment.ID)
+	if err != nil {
+		s.log.Error("[paymentService.resendPaymentResult] Failed to get payment %v: %v", payment.ID, err)
+		return
+	}
+
+	switch {
+	case stored.IsPaid:
+		s.log.Info("[paymentService.resendPaymentResult] Payment %v is already paid, result is published again", payment.ID)
+		s.publish(ctx, queue.SuccessPaymentProcess, events.SuccessPaymentEvent{
+			PaymentID: stored.ID,
+			OrderID:   stored.OrderID,
+			Amount:    stored.Amount,
+			PaidAt:    stored.PaidAt,
+		})
+	case stored.FailureReason != "":
+		s.log.Info("[paymentService.resendPaymentResult] Payment %v is already failed, result is published again", payment.ID)
+		s.publish(ctx, queue.FailedPaymentProcess, events.FailedPaymentEvent{
+			PaymentID: stored.ID,
+			OrderID:   stored.OrderID,
+			Reason:    stored.FailureReason,
+			FailedAt:  stored.SettledAt,
+		})
+	default:
+		s.log.Info("[paymentService.resendPaymentResult] Payment %v is already requested", payment.ID)
+	}
+}
+
+func (s *paymentService) succeed(ctx context.Context, payment model.Payment, charge model.Charge) {
+	payment.IsPaid = true
+	payment.PaidAt = charge.ChargedAt
+	payment.SettledAt = s.now()
+	payment.TransactionID = charge.TransactionID
+
+	if err := s.storage.GetPaymentRepo().UpdatePayment(ctx, payment); err != nil {
+		s.log.Error("[paymentService.succeed] Failed to update payment %v: %v", payment.ID, err)
+	}
+
+	s.publish(ctx, queue.SuccessPaymentProcess, events.SuccessPaymentEvent{
+		PaymentID: payment.ID,
+		OrderID:   payment.OrderID,
+		Amount:    charge.Amount,
+		PaidAt:    charge.ChargedAt,
+	})
+}
+
+func (s *paymentService) fail(ctx context.Context, payment model.Payment, reason string) {
+	payment.FailureReason = reason
+	payment.SettledAt = s.now()
+
+	if err := s.storage.GetPaymentRepo().UpdatePayment(ctx, payment); err != nil {
+		s.log.Error("[paymentService.fail] Failed to update payment %v: %v", payment.ID, err)
+	}
+
+	s.publish(ctx, queue.FailedPaymentProcess, events.FailedPaymentEvent{
+		PaymentID: payment.ID,
+		OrderID:   payment.OrderID,
+		Reason:    reason,
+		FailedAt:  s.now(),
+	})
+}
+
+func (s *paymentService) publish(ctx context.Context, topic queue.Topic, msg queue.Msg) {
+	if err := s.q.Publish(ctx, topic, msg); err != nil {
+		s.log.Error("[paymentService.publish] Failed to publish %s msg: %v", topic, err)
+		return
+	}
+
+	s.log.Info("[paymentService.publish] Published %s msg: %+v", topic, msg)
+}
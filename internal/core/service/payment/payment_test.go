@@ -0,0 +1,453 @@
+package payment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/gateway/fake"
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/queue/gochanqueue"
+	"aplication-design-test-task/internal/adapters/storage"
+	inmemory "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/logger"
+)
+
+func TestPaymentService_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	log := logger.New()
+	q := gochanqueue.NewChanQueue(log)
+	for _, topic := range queue.AllTopics {
+		require.NoError(t, q.CreateTopic(ctx, topic))
+	}
+
+	success, err := q.Subscribe(ctx, queue.SuccessPaymentProcess)
+	require.NoError(t, err)
+	failed, err := q.Subscribe(ctx, queue.FailedPaymentProcess)
+	require.NoError(t, err)
+
+	s := New(log, q, inmemory.NewStorage(), fake.NewPaymentGateway(
+		fake.WithDecline("declined@example.com", "insufficient funds"),
+		fake.WithLatency("slow@example.com", time.Minute),
+	))
+	s.timeout = 50 * time.Millisecond
+	require.NoError(t, s.Run(ctx))
+
+	paid := events.PaymentRequest{ID: uuid.New(), OrderID: uuid.New(), Amount: model.Money{Amount: 200_00, Currency: "EUR"}, PayerEmail: "guest@example.com"}
+	require.NoError(t, q.Publish(ctx, queue.PaymentRequest, paid))
+
+	select {
+	case msg := <-success:
+		event, ok := msg.(events.SuccessPaymentEvent)
+		require.True(t, ok)
+		assert.Equal(t, paid.ID, event.PaymentID)
+		assert.Equal(t, paid.OrderID, event.OrderID)
+		assert.Equal(t, paid.Amount, event.Amount)
+	case <-ctx.Done():
+		t.Fatal("payment is not processed")
+	}
+
+	for email, reason := range map[string]string{
+		"Declined@example.com": "insufficient funds",
+		"slow@example.com":     "did not answer in 50ms",
+	} {
+		payment := events.PaymentRequest{ID: uuid.New(), OrderID: uuid.New(), PayerEmail: email}
+		require.NoError(t, q.Publish(ctx, queue.PaymentRequest, payment))
+
+		select {
+		case msg := <-failed:
+			event, ok := msg.(events.FailedPaymentEvent)
+			require.True(t, ok)
+			assert.Equal(t, payment.OrderID, event.OrderID)
+			assert.Contains(t, event.Reason, reason)
+		case <-ctx.Done():
+			t.Fatalf("payment of %s is not processed", email)
+		}
+	}
+}
+
+func TestPaymentService_CallbackHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	log := logger.New()
+	q := gochanqueue.NewChanQueue(log)
+	for _, topic := range queue.AllTopics {
+		require.NoError(t, q.CreateTopic(ctx, topic))
+	}
+
+	success, err := q.Subscribe(ctx, queue.SuccessPaymentProcess)
+	require.NoError(t, err)
+	failed, err := q.Subscribe(ctx, queue.FailedPaymentProcess)
+	require.NoError(t, err)
+
+	store := inmemory.NewStorage()
+	s := New(log, q, store, fake.NewPaymentGateway(fake.WithPending("callback@example.com")))
+
+	payment := events.PaymentRequest{ID: uuid.New(), OrderID: uuid.New(), Amount: model.Money{Amount: 200_00, Currency: "EUR"}, PayerEmail: "callback@example.com"}
+	s.PaymentRequestHandler(ctx, payment)
+	s.PaymentRequestHandler(ctx, payment) // redelivered request does not charge twice
+
+	stored, err := store.GetPaymentRepo().GetPayment(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.False(t, model.IsSettled(stored), "pending payment waits for callback")
+
+	err = s.CallbackHandler(ctx, model.PaymentCallback{EventID: "evt_0", PaymentID: uuid.New(), Status: model.PaymentSucceeded})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	err = s.CallbackHandler(ctx, model.PaymentCallback{EventID: "evt_0", PaymentID: payment.ID, Status: "refunded"})
+	assert.ErrorIs(t, err, model.ErrPaymentCallbackInvalid)
+
+	err = s.CallbackHandler(ctx, model.PaymentCallback{
+		EventID: "evt_0", PaymentID: payment.ID, Status: model.PaymentSucceeded, Amount: model.Money{Amount: 1, Currency: "EUR"},
+	})
+	assert.ErrorIs(t, err, model.ErrPaymentAmountMismatch)
+	stored, err = store.GetPaymentRepo().GetPayment(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.False(t, model.IsSettled(stored), "payment is not settled by callback with another amount")
+
+	paidAt := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	callback := model.PaymentCallback{
+		EventID:       "evt_1",
+		PaymentID:     payment.ID,
+		Status:        model.PaymentSucceeded,
+		TransactionID: "psp_1",
+		Amount:        payment.Amount,
+		OccurredAt:    paidAt,
+	}
+	for range 3 {
+		require.NoError(t, s.CallbackHandler(ctx, callback), "redelivery is accepted")
+	}
+
+	select {
+	case msg := <-success:
+		event, ok := msg.(events.SuccessPaymentEvent)
+		require.True(t, ok)
+		assert.Equal(t, payment.OrderID, event.OrderID)
+		assert.Equal(t, paidAt, event.PaidAt)
+	case <-ctx.Done():
+		t.Fatal("callback is not published")
+	}
+
+	// late failure of already settled payment is ignored
+	require.NoError(t, s.CallbackHandler(ctx, model.PaymentCallback{EventID: "evt_2", PaymentID: payment.ID, Status: model.PaymentFailed, Reason: "expired"}))
+
+	select {
+	case msg := <-success:
+		t.Fatalf("redelivered callback is published again: %+v", msg)
+	case msg := <-failed:
+		t.Fatalf("callback of settled payment is published: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stored, err = store.GetPaymentRepo().GetPayment(ctx, payment.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsPaid)
+	assert.Equal(t, "psp_1", stored.TransactionID)
+
+	// repeated request of settled payment, e.g. of resumed saga, is not charged, its result is published again
+	s.PaymentRequestHandler(ctx, payment)
+
+	select {
+	case msg := <-success:
+		assert.Equal(t, events.SuccessPaymentEvent{PaymentID: payment.ID, OrderID: payment.OrderID, Amount: payment.Amount, PaidAt: paidAt}, msg)
+	case <-ctx.Done():
+		t.Fatal("result of settled payment is not published again")
+	}
+}
+
+func TestPaymentService_RefundRequestHandler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	log := logger.New()
+	q := gochanqueue.NewChanQueue(log)
+	for _, topic := range queue.AllTopics {
+		require.NoError(t, q.CreateTopic(ctx, topic))
+	}
+
+	processed, err := q.Subscribe(ctx, queue.RefundProcessed)
+	require.NoError(t, err)
+
+	next := func() events.RefundProcessedEvent {
+		select {
+		case msg := <-processed:
+			event, ok := msg.(events.RefundProcessedEvent)
+			require.True(t, ok)
+			return event
+		case <-ctx.Done():
+			t.Fatal("refund is not processed")
+			return events.RefundProcessedEvent{}
+		}
+	}
+
+	eur := func(amount int64) model.Money { return model.Money{Amount: amount, Currency: "EUR"} }
+
+	store := inmemory.NewStorage()
+	s := New(log, q, store, fake.NewPaymentGateway(fake.WithRefundDecline("declined@example.com", "account closed")))
+
+	orderID := uuid.New()
+	deposit := events.PaymentRequest{ID: uuid.New(), OrderID: orderID, Amount: eur(60_00), PayerEmail: "guest@example.com"}
+	balance := events.PaymentRequest{ID: uuid.New(), OrderID: orderID, Amount: eur(140_00), PayerEmail: "guest@example.com"}
+	s.PaymentRequestHandler(ctx, deposit)
+	s.PaymentRequestHandler(ctx, balance) // charged after deposit
+
+	// the latest payment is refunded first
+	partial := events.RefundRequest{ID: uuid.New(), OrderID: orderID, Amount: eur(100_00)}
+	s.RefundRequestHandler(ctx, partial)
+
+	event := next()
+	assert.Equal(t, partial.ID, event.ID)
+	assert.Equal(t, model.RefundSucceeded, event.Status)
+	assert.Len(t, event.TransactionIDs, 1)
+	assert.Equal(t, partial.Amount, event.RefundedAmount)
+	assert.False(t, event.RefundedAt.IsZero())
+
+	// redelivered request does not refund twice, its result is published again
+	s.RefundRequestHandler(ctx, partial)
+	assert.Equal(t, event, next())
+
+	stored, err := store.GetPaymentRepo().GetPayment(ctx, balance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, eur(100_00), stored.RefundedAmount)
+
+	// the rest is split between payments
+	rest := events.RefundRequest{ID: uuid.New(), OrderID: orderID, Amount: eur(100_00)}
+	s.RefundRequestHandler(ctx, rest)
+
+	event = next()
+	assert.Equal(t, model.RefundSucceeded, event.Status)
+	assert.Len(t, event.TransactionIDs, 2)
+
+	stored, err = store.GetPaymentRepo().GetPayment(ctx, deposit.ID)
+	require.NoError(t, err)
+	assert.Equal(t, eur(60_00), stored.RefundedAmount)
+
+	// nothing is left to refund
+	excess := events.RefundRequest{ID: uuid.New(), OrderID: orderID, Amount: eur(1)}
+	s.RefundRequestHandler(ctx, excess)
+
+	event = next()
+	assert.Equal(t, model.RefundFailed, event.Status)
+	assert.Contains(t, event.FailureReason, "not covered by payments")
+
+	refund, err := store.GetPaymentRepo().GetRefund(ctx, excess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.RefundFailed, refund.Status)
+
+	// gateway declines refund
+	declined := events.PaymentRequest{ID: uuid.New(), OrderID: uuid.New(), Amount: eur(50_00), PayerEmail: "declined@example.com"}
+	s.PaymentRequestHandler(ctx, declined)
+	s.RefundRequestHandler(ctx, events.RefundRequest{ID: uuid.New(), OrderID: declined.OrderID, Amount: eur(50_00)})
+
+	event = next()
+	assert.Equal(t, model.RefundFailed, event.Status)
+	assert.Contains(t, event.FailureReason, "account closed")
+
+	// refunded parts are reported when refund of other payment fails
+	mixed := uuid.New()
+	s.PaymentRequestHandler(ctx, events.PaymentRequest{ID: uuid.New(), OrderID: mixed, Amount: eur(30_00), PayerEmail: "declined@example.com"})
+	s.PaymentRequestHandler(ctx, events.PaymentRequest{ID: uuid.New(), OrderID: mixed, Amount: eur(20_00), PayerEmail: "guest@example.com"})
+	s.RefundRequestHandler(ctx, events.RefundRequest{ID: uuid.New(), OrderID: mixed, Amount: eur(50_00)})
+
+	event = next()
+	assert.Equal(t, model.RefundPartial, event.Status)
+	assert.Equal(t, eur(20_00), event.RefundedAmount)
+	assert.Len(t, event.TransactionIDs, 1)
+	assert.Contains(t, event.FailureReason, "account closed")
+
+	select {
+	case msg := <-processed:
+		t.Fatalf("unexpected refund result: %+v", msg)
+	default:
+	}
+}
+
+func TestPaymentService_Reconcile(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	log := logger.New()
+	q := gochanqueue.NewChanQueue(log)
+	for _, topic := range queue.AllTopics {
+		require.NoError(t, q.CreateTopic(ctx, topic))
+	}
+
+	success, err := q.Subscribe(ctx, queue.SuccessPaymentProcess)
+	require.NoError(t, err)
+	failed, err := q.Subscribe(ctx, queue.FailedPaymentProcess)
+	require.NoError(t, err)
+
+	eur := func(amount int64) model.Money { return model.Money{Amount: amount, Currency: "EUR"} }
+	at := func(hour int) time.Time { return time.Date(2024, 3, 20, hour, 0, 0, 0, time.UTC) }
+
+	store := inmemory.NewStorage()
+	s := New(log, q, store, fake.NewPaymentGateway())
+
+	// payment in given state of order in given status, order requested the payment and got it when it is paid
+	newPayment := func(status model.Status, paid bool, failureReason string) model.Payment {
+		payment := model.Payment{ID: uuid.New(), OrderID: uuid.New(), Amount: eur(200_00), IsPaid: paid, FailureReason: failureReason}
+		if paid {
+			payment.PaidAt = at(11)
+			payment.TransactionID = "psp_" + payment.ID.String()
+		}
+		if paid || failureReason != "" {
+			payment.SettledAt = at(11)
+		}
+
+		requested := model.OrderPayment{ID: payment.ID, Amount: payment.Amount, RequestedAt: at(9)}
+		if status == model.Paid {
+			requested.PaidAt = payment.PaidAt
+		}
+
+		require.NoError(t, store.GetPaymentRepo().StorePayment(ctx, payment))
+		require.NoError(t, store.GetOrderRepo().StoreOrder(ctx, model.Order{ID: payment.OrderID, Status: status, Payments: []model.OrderPayment{requested}}))
+
+		return payment
+	}
+
+	settled := func(payment model.Payment, amount model.Money) model.SettlementRecord {
+		return model.SettlementRecord{TransactionID: "psp_" + payment.ID.String(), PaymentID: payment.ID, Status: model.SettlementSettled, Amount: amount, SettledAt: at(10)}
+	}
+
+	matched := newPayment(model.Paid, true, "")
+	stuckPayment := newPayment(model.Booked, false, "")
+	stuckOrder := newPayment(model.Booked, true, "")
+	shortSettled := newPayment(model.Paid, true, "")
+	shortStuckPayment := newPayment(model.Booked, false, "")
+	shortStuckOrder := newPayment(model.Booked, true, "")
+	failedSettled := newPayment(model.FailedPay, false, "card declined")
+	expiredSettled := newPayment(model.Expired, true, "")
+	notSettled := newPayment(model.Paid, true, "")
+	stuckFailure := newPayment(model.Booked, false, "")
+	unknown := uuid.New()
+
+	// result of recently settled payment may still wait in queue of booking service
+	inQueue := newPayment(model.Booked, true, "")
+	inQueue.SettledAt = s.now()
+	require.NoError(t, store.GetPaymentRepo().UpdatePayment(ctx, inQueue))
+
+	// order of cancelled balance installment does not request the payment any more
+	notRequested := newPayment(model.DepositPaid, true, "")
+	require.NoError(t, store.GetOrderRepo().UpdateOrder(ctx, notRequested.OrderID, model.Order{ID: notRequested.OrderID, Status: model.DepositPaid}))
+
+	records := []model.SettlementRecord{
+		settled(matched, eur(200_00)),
+		settled(stuckPayment, eur(200_00)),
+		settled(stuckOrder, eur(200_00)),
+		settled(shortSettled, eur(190_00)),
+		settled(shortStuckPayment, eur(190_00)),
+		settled(shortStuckOrder, eur(190_00)),
+		settled(failedSettled, eur(200_00)),
+		settled(expiredSettled, eur(200_00)),
+		settled(inQueue, eur(200_00)),
+		settled(notRequested, eur(200_00)),
+		{TransactionID: "psp_x", PaymentID: unknown, Status: model.SettlementSettled, Amount: eur(50_00), SettledAt: at(12)},
+		{PaymentID: stuckFailure.ID, Status: model.SettlementFailed, Amount: eur(200_00), SettledAt: at(12), Reason: "expired card"},
+	}
+
+	type found struct {
+		kind      model.DiscrepancyKind
+		paymentID uuid.UUID
+		fixable   bool
+	}
+	expected := []found{
+		{model.StuckPaymentDiscrepancy, stuckPayment.ID, true},
+		{model.StuckOrderDiscrepancy, stuckOrder.ID, true},
+		{model.AmountDiscrepancy, shortSettled.ID, false},
+		{model.AmountDiscrepancy, shortStuckPayment.ID, false},
+		{model.AmountDiscrepancy, shortStuckOrder.ID, false},
+		{model.StuckOrderDiscrepancy, shortStuckOrder.ID, false},
+		{model.StatusDiscrepancy, failedSettled.ID, false},
+		{model.StatusDiscrepancy, expiredSettled.ID, false},
+		{model.StatusDiscrepancy, notRequested.ID, false},
+		{model.UnknownPaymentDiscrepancy, unknown, false},
+		{model.StuckPaymentDiscrepancy, stuckFailure.ID, true},
+		{model.NotSettledDiscrepancy, notSettled.ID, false},
+	}
+	discrepancies := func(report model.ReconciliationReport) []found {
+		var actual []found
+		for _, d := range report.Discrepancies {
+			actual = append(actual, found{d.Kind, d.PaymentID, d.Fixable})
+		}
+		return actual
+	}
+
+	// dry run only reports discrepancies
+	report, err := s.Reconcile(ctx, "settlement-2024-03-20.csv", records, false)
+	require.NoError(t, err)
+	assert.Equal(t, "settlement-2024-03-20.csv", report.Source)
+	assert.Equal(t, at(10), report.From)
+	assert.Equal(t, at(12), report.To)
+	assert.Equal(t, len(records), report.Records)
+	assert.Equal(t, 2, report.Matched)
+	assert.Zero(t, report.Fixed)
+	assert.Equal(t, expected, discrepancies(report))
+
+	select {
+	case msg := <-success:
+		t.Fatalf("dry run published %v", msg)
+	case msg := <-failed:
+		t.Fatalf("dry run published %v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	stored, err := s.GetReconciliationReport(ctx, report.ID)
+	require.NoError(t, err)
+	assert.Equal(t, report.ID, stored.ID)
+
+	// fix emits missing results of stuck payments and orders
+	report, err = s.Reconcile(ctx, "settlement-2024-03-20.csv", records, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Fixed)
+	assert.Equal(t, expected, discrepancies(report))
+
+	paidOrders := map[model.OrderID]bool{}
+	for range 2 {
+		select {
+		case msg := <-success:
+			event, ok := msg.(events.SuccessPaymentEvent)
+			require.True(t, ok)
+			assert.Equal(t, eur(200_00), event.Amount)
+			paidOrders[event.OrderID] = true
+		case <-ctx.Done():
+			t.Fatal("success of stuck payment is not published")
+		}
+	}
+	assert.Equal(t, map[model.OrderID]bool{stuckPayment.OrderID: true, stuckOrder.OrderID: true}, paidOrders)
+
+	select {
+	case msg := <-failed:
+		event, ok := msg.(events.FailedPaymentEvent)
+		require.True(t, ok)
+		assert.Equal(t, stuckFailure.OrderID, event.OrderID)
+		assert.Equal(t, "expired card", event.Reason)
+	case <-ctx.Done():
+		t.Fatal("failure of stuck payment is not published")
+	}
+
+	payment, err := store.GetPaymentRepo().GetPayment(ctx, stuckPayment.ID)
+	require.NoError(t, err)
+	assert.True(t, payment.IsPaid)
+	assert.Equal(t, "psp_"+stuckPayment.ID.String(), payment.TransactionID)
+
+	payment, err = store.GetPaymentRepo().GetPayment(ctx, shortStuckPayment.ID)
+	require.NoError(t, err)
+	assert.False(t, model.IsSettled(payment), "payment is not settled by other amount")
+
+	reports, err := s.GetListReconciliationReports(ctx)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, report.ID, reports[0].ID, "newest first")
+
+	_, err = s.Reconcile(ctx, "bad.csv", []model.SettlementRecord{{PaymentID: unknown, Status: "refunded"}}, false)
+	assert.ErrorIs(t, err, model.ErrSettlementRecordInvalid)
+}
@@ -0,0 +1,304 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// reconcileGrace - order is checked only when result of its payment is published so long ago, a recent result may
+// still wait in queue of booking service.
+const reconcileGrace = 15 * time.Minute
+
+// Reconcile compares settlement report of provider with payments and orders and stores discrepancy report.
+// If fix is set, stuck payments and orders get missing result: payment is settled as provider did and success or
+// failure event is published for booking service. Other discrepancies are only reported.
+func (s *paymentService) Reconcile(ctx context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error) {
+	report := model.ReconciliationReport{
+		ID:            uuid.New(),
+		Source:        source,
+		CreatedAt:     s.now(),
+		Records:       len(records),
+		Discrepancies: []model.Discrepancy{},
+	}
+
+	reported := make(map[uuid.UUID]bool, len(records))
+	for _, record := range records {
+		if err := record.Validate(); err != nil {
+			return model.ReconciliationReport{}, err
+		}
+
+		if report.From.IsZero() || record.SettledAt.Before(report.From) {
+			report.From = record.SettledAt
+		}
+		if record.SettledAt.After(report.To) {
+			report.To = record.SettledAt
+		}
+
+		reported[record.PaymentID] = true
+
+		discrepancies, err := s.reconcileRecord(ctx, record, fix)
+		if err != nil {
+			return model.ReconciliationReport{}, err
+		}
+
+		if len(discrepancies) == 0 {
+			report.Matched++
+		}
+
+		report.Discrepancies = append(report.Discrepancies, discrepancies...)
+	}
+
+	notSettled, err := s.notSettledPayments(ctx, report, reported)
+	if err != nil {
+		return model.ReconciliationReport{}, err
+	}
+	report.Discrepancies = append(report.Discrepancies, notSettled...)
+
+	for _, d := range report.Discrepancies {
+		if d.Fix != "" {
+			report.Fixed++
+		}
+	}
+
+	if err = s.storage.GetReconciliationRepo().StoreReport(ctx, report); err != nil {
+		return model.ReconciliationReport{}, err
+	}
+
+	s.log.Info("[paymentService.Reconcile] Reconciled %s: %d records, %d matched, %d discrepancies, %d fixed",
+		source, report.Records, report.Matched, len(report.Discrepancies), report.Fixed)
+
+	return report, nil
+}
+
+func (s *paymentService) GetReconciliationReport(ctx context.Context, id uuid.UUID) (model.ReconciliationReport, error) {
+	return s.storage.GetReconciliationRepo().GetReport(ctx, id)
+}
+
+func (s *paymentService) GetListReconciliationReports(ctx context.Context) ([]model.ReconciliationReport, error) {
+	return s.storage.GetReconciliationRepo().GetListReports(ctx)
+}
+
+// reconcileRecord - discrepancies of payment of settlement record and of its order. The payment is read and fixed
+// under settlement lock, so a gateway answer or callback arriving meanwhile does not settle it twice.
+func (s *paymentService) reconcileRecord(ctx context.Context, record model.SettlementRecord, fix bool) ([]model.Discrepancy, error) {
+	s.settleMu.Lock()
+	defer s.settleMu.Unlock()
+
+	payment, err := s.storage.GetPaymentRepo().GetPayment(ctx, record.PaymentID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return []model.Discrepancy{{
+			Kind:          model.UnknownPaymentDiscrepancy,
+			PaymentID:     record.PaymentID,
+			TransactionID: record.TransactionID,
+			Detail:        fmt.Sprintf("provider %s %v of unknown payment", record.Status, record.Amount),
+		}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancy := model.Discrepancy{PaymentID: payment.ID, OrderID: payment.OrderID, TransactionID: record.TransactionID}
+
+	switch {
+	case record.Status == model.SettlementSettled && payment.FailureReason != "":
+		discrepancy.Kind = model.StatusDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("payment is failed (%s), but provider settled %v", payment.FailureReason, record.Amount)
+		return []model.Discrepancy{discrepancy}, nil
+
+	case record.Status == model.SettlementFailed && payment.IsPaid:
+		discrepancy.Kind = model.StatusDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("payment is paid, but provider failed it: %s", record.Reason)
+		return []model.Discrepancy{discrepancy}, nil
+
+	case !model.IsSettled(payment) && record.Status == model.SettlementSettled && record.Amount != payment.Amount:
+		// payment is not settled by other amount, as callback of the amount would be rejected
+		discrepancy.Kind = model.AmountDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("payment of %v waits for result, provider settled %v", payment.Amount, record.Amount)
+		return []model.Discrepancy{discrepancy}, nil
+
+	case !model.IsSettled(payment):
+		discrepancy.Kind = model.StuckPaymentDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("payment waits for result, provider %s it", record.Status)
+		discrepancy.Fixable = true
+
+		if fix {
+			discrepancy.Fix = s.settleStuckPayment(ctx, payment, record)
+		}
+
+		return []model.Discrepancy{discrepancy}, nil
+	}
+
+	var discrepancies []model.Discrepancy
+	if record.Status == model.SettlementSettled && record.Amount != payment.Amount {
+		discrepancy.Kind = model.AmountDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("charged %v, provider settled %v", payment.Amount, record.Amount)
+		discrepancies = append(discrepancies, discrepancy)
+	}
+
+	orderDiscrepancy, err := s.reconcileOrder(ctx, payment, record, fix)
+	if err != nil {
+		return nil, err
+	}
+	if orderDiscrepancy != nil {
+		discrepancies = append(discrepancies, *orderDiscrepancy)
+	}
+
+	return discrepancies, nil
+}
+
+// reconcileOrder - check that order got result of its settled payment. Nil if order agrees with payment or result
+// of payment is published less than reconcileGrace ago, then it may still wait in queue of booking service.
+func (s *paymentService) reconcileOrder(ctx context.Context, payment model.Payment, record model.SettlementRecord, fix bool) (*model.Discrepancy, error) {
+	if s.now().Sub(payment.SettledAt) < reconcileGrace {
+		return nil, nil
+	}
+
+	discrepancy := &model.Discrepancy{PaymentID: payment.ID, OrderID: payment.OrderID, TransactionID: record.TransactionID}
+
+	order, err := s.storage.GetOrderRepo().GetOrder(ctx, payment.OrderID)
+	if errors.Is(err, storage.ErrNotFound) {
+		discrepancy.Kind = model.MissingOrderDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("provider %s %v of unknown order", record.Status, record.Amount)
+		return discrepancy, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applied, requested := paymentState(order, payment.ID)
+
+	if record.Status == model.SettlementFailed {
+		if order.Status != model.Booked || !requested {
+			return nil, nil // failed payment of booked order only ends booking
+		}
+
+		discrepancy.Kind = model.StuckOrderDiscrepancy
+		discrepancy.Detail = "payment is failed, but order is still booked"
+		discrepancy.Fixable = true
+
+		if fix {
+			discrepancy.Fix = s.publishResult(ctx, queue.FailedPaymentProcess, events.FailedPaymentEvent{
+				PaymentID: payment.ID,
+				OrderID:   payment.OrderID,
+				Reason:    payment.FailureReason,
+				FailedAt:  s.now(),
+			})
+		}
+
+		return discrepancy, nil
+	}
+
+	switch {
+	case applied:
+		return nil, nil
+	case !requested:
+		discrepancy.Kind = model.StatusDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("payment is not requested by order, but provider settled %v", record.Amount)
+		return discrepancy, nil
+	case order.Status == model.Booked || order.Status == model.DepositPaid:
+		discrepancy.Kind = model.StuckOrderDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("payment is settled, but order is %s", order.Status)
+		// order does not get other amount than of the payment, mismatch is reported separately
+		discrepancy.Fixable = record.Amount == payment.Amount
+
+		if fix && discrepancy.Fixable {
+			discrepancy.Fix = s.publishResult(ctx, queue.SuccessPaymentProcess, events.SuccessPaymentEvent{
+				PaymentID: payment.ID,
+				OrderID:   payment.OrderID,
+				Amount:    record.Amount,
+				PaidAt:    payment.PaidAt,
+			})
+		}
+
+		return discrepancy, nil
+	default:
+		discrepancy.Kind = model.StatusDiscrepancy
+		discrepancy.Detail = fmt.Sprintf("order is %s, but provider settled %v which is not applied to it", order.Status, record.Amount)
+		return discrepancy, nil
+	}
+}
+
+// paymentState reports whether the payment is requested by the order as installment of its schedule or as payment
+// apart from it, and whether the order got it.
+func paymentState(order model.Order, paymentID uuid.UUID) (applied, requested bool) {
+	for _, installment := range order.PaymentSchedule {
+		if installment.PaymentID == paymentID {
+			return installment.IsPaid(), true
+		}
+	}
+
+	for _, payment := range order.Payments {
+		if payment.ID == paymentID {
+			return !payment.PaidAt.IsZero(), true
+		}
+	}
+
+	return false, false
+}
+
+// settleStuckPayment - settle payment as provider did, booking service gets the result as usual. Returns fix.
+func (s *paymentService) settleStuckPayment(ctx context.Context, payment model.Payment, record model.SettlementRecord) string {
+	if record.Status == model.SettlementFailed {
+		reason := record.Reason
+		if reason == "" {
+			reason = "failed by provider settlement"
+		}
+
+		s.fail(ctx, payment, reason)
+		return string(queue.FailedPaymentProcess)
+	}
+
+	s.succeed(ctx, payment, model.Charge{
+		TransactionID: record.TransactionID,
+		PaymentID:     payment.ID,
+		Amount:        record.Amount,
+		ChargedAt:     record.SettledAt,
+	})
+
+	return string(queue.SuccessPaymentProcess)
+}
+
+// publishResult - publish missing result of settled payment. Returns fix.
+func (s *paymentService) publishResult(ctx context.Context, topic queue.Topic, msg queue.Msg) string {
+	s.publish(ctx, topic, msg)
+
+	return string(topic)
+}
+
+// notSettledPayments - paid payments of report period which provider did not settle.
+func (s *paymentService) notSettledPayments(ctx context.Context, report model.ReconciliationReport, reported map[uuid.UUID]bool) ([]model.Discrepancy, error) {
+	if report.Records == 0 {
+		return nil, nil
+	}
+
+	payments, err := s.storage.GetPaymentRepo().GetListPayments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var discrepancies []model.Discrepancy
+	for _, payment := range payments {
+		if !payment.IsPaid || reported[payment.ID] || payment.PaidAt.Before(report.From) || payment.PaidAt.After(report.To) {
+			continue
+		}
+
+		discrepancies = append(discrepancies, model.Discrepancy{
+			Kind:          model.NotSettledDiscrepancy,
+			PaymentID:     payment.ID,
+			OrderID:       payment.OrderID,
+			TransactionID: payment.TransactionID,
+			Detail:        fmt.Sprintf("payment of %v is paid at %s, but it is not in settlement report", payment.Amount, payment.PaidAt.Format("2006-01-02T15:04:05Z07:00")),
+		})
+	}
+
+	return discrepancies, nil
+}
@@ -0,0 +1,153 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+)
+
+// RefundRequestHandler - return refund amount from charged payments of the order through gateway, the latest payments
+// are refunded first. Result is published for booking service. Repeated request of the same refund is not refunded
+// again, result of settled refund is published again.
+func (s *paymentService) RefundRequestHandler(ctx context.Context, refund events.RefundRequest) {
+	refund.Status = model.RefundPending
+	refund.RefundedAmount = model.Money{Currency: refund.Amount.Currency}
+
+	if err := s.storage.GetPaymentRepo().StoreRefund(ctx, refund); err != nil {
+		if errors.Is(err, storage.ErrDuplicateConstraint) {
+			s.resendRefundResult(ctx, refund)
+			return
+		}
+
+		s.log.Error("[paymentService.RefundRequestHandler] Failed to store refund %v: %v", refund.ID, err)
+		return
+	}
+
+	payments, err := s.storage.GetPaymentRepo().GetListOrderPayments(ctx, refund.OrderID)
+	if err != nil {
+		s.log.Error("[paymentService.RefundRequestHandler] Failed to get payments of order %v: %v", refund.OrderID, err)
+		s.settleRefund(ctx, refund, err.Error())
+		return
+	}
+
+	parts, err := refundParts(payments, refund.Amount)
+	if err != nil {
+		s.log.Error("[paymentService.RefundRequestHandler] Refund %v can not be made: %v", refund.ID, err)
+		s.settleRefund(ctx, refund, err.Error())
+		return
+	}
+
+	for _, part := range parts {
+		refundCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		ref, err := s.gateway.Refund(refundCtx, part.payment, part.amount)
+		cancel()
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("payment gateway did not answer in %s", s.timeout)
+		}
+		if err != nil {
+			// already refunded parts stay on payments and are reported by partial refund
+			s.log.Error("[paymentService.RefundRequestHandler] Failed to refund payment %v: %v", part.payment.ID, err)
+			s.settleRefund(ctx, refund, err.Error())
+			return
+		}
+
+		payment := part.payment
+		if payment.RefundedAmount, err = payment.RefundedAmount.Add(part.amount); err != nil {
+			s.log.Error("[paymentService.RefundRequestHandler] Failed to add refunded amount: %v", err)
+		}
+		if err = s.storage.GetPaymentRepo().UpdatePayment(ctx, payment); err != nil {
+			s.log.Error("[paymentService.RefundRequestHandler] Failed to update payment %v: %v", payment.ID, err)
+		}
+
+		refund.TransactionIDs = append(refund.TransactionIDs, ref)
+		refund.RefundedAmount.Amount += part.amount.Amount
+	}
+
+	s.log.Info("[paymentService.RefundRequestHandler] Refund %v is made: %v", refund.ID, refund.TransactionIDs)
+	s.settleRefund(ctx, refund, "")
+}
+
+// resendRefundResult - publish again result of repeated refund request, its result could be lost.
+// Refund which is still in progress is ignored.
+func (s *paymentService) resendRefundResult(ctx context.Context, refund events.RefundRequest) {
+	stored, err := s.storage.GetPaymentRepo().GetRefund(ctx, refund.ID)
+	if err != nil {
+		s.log.Error("[paymentService.resendRefundResult] Failed to get refund %v: %v", refund.ID, err)
+		return
+	}
+
+	if !stored.IsSettled() {
+		s.log.Info("[paymentService.resendRefundResult] Refund %v is already requested", refund.ID)
+		return
+	}
+
+	s.log.Info("[paymentService.resendRefundResult] Refund %v is already %s, result is published again", refund.ID, stored.Status)
+	s.publish(ctx, queue.RefundProcessed, stored)
+}
+
+type refundPart struct {
+	payment model.Payment
+	amount  model.Money
+}
+
+// refundParts - split amount between payments by their not refunded amount. Error if payments do not cover amount.
+func refundParts(payments []model.Payment, amount model.Money) ([]refundPart, error) {
+	var parts []refundPart
+
+	rest := amount
+	for _, payment := range payments {
+		if rest.Amount <= 0 {
+			break
+		}
+
+		left, err := payment.Amount.Sub(payment.RefundedAmount)
+		if err != nil {
+			return nil, err
+		}
+		if left.Amount <= 0 {
+			continue
+		}
+		if left.Currency != rest.Currency {
+			return nil, fmt.Errorf("%w: payment %v is in %s", model.ErrCurrencyMismatch, payment.ID, left.Currency)
+		}
+
+		left.Amount = min(left.Amount, rest.Amount)
+		parts = append(parts, refundPart{payment: payment, amount: left})
+		rest.Amount -= left.Amount
+	}
+
+	if rest.Amount > 0 {
+		return nil, fmt.Errorf("%w: %d of %d %s is not covered by payments", model.ErrRefundDeclined, rest.Amount, amount.Amount, amount.Currency)
+	}
+
+	return parts, nil
+}
+
+// settleRefund - store final status of refund and publish it. Refund is failed if reason is not empty,
+// or partial if some of its parts are already refunded.
+func (s *paymentService) settleRefund(ctx context.Context, refund model.Refund, reason string) {
+	switch {
+	case reason == "":
+		refund.Status = model.RefundSucceeded
+		refund.RefundedAt = s.now()
+	case len(refund.TransactionIDs) > 0:
+		refund.Status = model.RefundPartial
+		refund.RefundedAt = s.now()
+		refund.FailureReason = reason
+	default:
+		refund.Status = model.RefundFailed
+		refund.FailureReason = reason
+	}
+
+	if err := s.storage.GetPaymentRepo().UpdateRefund(ctx, refund); err != nil {
+		s.log.Error("[paymentService.settleRefund] Failed to update refund %v: %v", refund.ID, err)
+	}
+
+	s.publish(ctx, queue.RefundProcessed, refund)
+}
@@ -0,0 +1,162 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+)
+
+var (
+	ErrNoRate          = errors.New("no rate for some night of stay")
+	ErrUnknownRatePlan = errors.New("rate plan is not available for hotel")
+)
+
+type pricingService struct {
+	storage storage.Storage
+	now     func() time.Time
+}
+
+func New(s storage.Storage) *pricingService {
+	return &pricingService{storage: s, now: time.Now}
+}
+
+// Quote calculates price breakdown of the order: nightly rates adjusted by rate plan minus promo code discount.
+// Price is in currency of the hotel, every rate must be in it too.
+func (s *pricingService) Quote(ctx context.Context, order model.Order) (model.Price, error) {
+	plan, err := s.ratePlan(ctx, order)
+	if err != nil {
+		return model.Price{}, err
+	}
+
+	hotel, err := s.storage.GetHotelRepo().GetHotel(ctx, order.HotelID)
+	if err != nil {
+		return model.Price{}, err
+	}
+
+	rates, err := s.storage.GetRateRepo().GetRatesForHotelByRoomTypeAndDate(ctx, order.HotelID, order.RoomTypeID, order.From, order.To)
+	if err != nil {
+		return model.Price{}, err
+	}
+
+	ratesByDay := make(map[util.Day]model.Rate, len(rates))
+	for _, rate := range rates {
+		ratesByDay[util.ToDay(rate.Date)] = rate
+	}
+
+	price := model.Price{
+		Refundable:     plan.Refundable,
+		DepositPercent: plan.DepositPercent,
+		BalanceDueDays: plan.BalanceDueDays,
+		Cancellation:   plan.Cancellation,
+	}
+	for _, day := range util.NightsBetween(order.From, order.To) {
+		rate, ok := ratesByDay[day]
+		if !ok {
+			return model.Price{}, fmt.Errorf("%w: %s", ErrNoRate, day.Format("2006-01-02"))
+		}
+
+		if rate.Price.Currency != hotel.Currency {
+			return model.Price{}, fmt.Errorf("%w: rate of %s is in %s, hotel uses %s",
+				model.ErrCurrencyMismatch, day.Format("2006-01-02"), rate.Price.Currency, hotel.Currency)
+		}
+
+		nightPrice := rate.Price.Percent(100 + plan.AdjustmentPercent)
+		price.Nights = append(price.Nights, model.NightPrice{Date: day, Price: nightPrice})
+
+		if price.Subtotal, err = price.Subtotal.Add(nightPrice); err != nil {
+			return model.Price{}, err
+		}
+	}
+
+	price.Discount = model.Money{Currency: price.Subtotal.Currency}
+	if order.PromoCode != "" {
+		promo, err := s.storage.GetPromoRepo().GetPromoCode(ctx, order.PromoCode)
+		if errors.Is(err, storage.ErrNotFound) {
+			return model.Price{}, fmt.Errorf("%w: unknown code %s", model.ErrPromoInvalid, order.PromoCode)
+		}
+		if err != nil {
+			return model.Price{}, err
+		}
+
+		price.Discount = discount(promo, price.Subtotal)
+	}
+
+	if price.Total, err = price.Subtotal.Sub(price.Discount); err != nil {
+		return model.Price{}, err
+	}
+
+	// loyalty points can cover the rest of price, but not more.
+	if price.PointsDiscount, price.RedeemedPoints, err = s.redeemPoints(ctx, order.LoyaltyPoints, price.Total); err != nil {
+		return model.Price{}, err
+	}
+	if price.Total, err = price.Total.Sub(price.PointsDiscount); err != nil {
+		return model.Price{}, err
+	}
+
+	return price, nil
+}
+
+// GetListRatePlans returns rate plans of the hotel, best available rate is always offered besides them.
+func (s *pricingService) GetListRatePlans(ctx context.Context, hotelID model.HotelID) ([]model.RatePlan, error) {
+	plans, err := s.storage.GetRateRepo().GetListRatePlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hotelPlans := make([]model.RatePlan, 0, len(plans))
+	for _, plan := range plans {
+		if plan.HotelID == hotelID {
+			hotelPlans = append(hotelPlans, plan)
+		}
+	}
+
+	slices.SortFunc(hotelPlans, func(a, b model.RatePlan) int { return a.ID - b.ID })
+
+	return hotelPlans, nil
+}
+
+// redeemPoints - discount of loyalty points on amount by FX rate of loyalty currency effective now.
+func (s *pricingService) redeemPoints(ctx context.Context, points int64, amount model.Money) (model.Money, int64, error) {
+	var rate model.FXRate
+	if points > 0 && amount.Currency != model.LoyaltyCurrency {
+		var err error
+		if rate, err = s.storage.GetFXRepo().GetRate(ctx, model.LoyaltyCurrency, amount.Currency, s.now()); err != nil {
+			return model.Money{}, 0, err
+		}
+	}
+
+	return model.RedeemPoints(points, amount, rate)
+}
+
+func (s *pricingService) ratePlan(ctx context.Context, order model.Order) (model.RatePlan, error) {
+	if order.RatePlanID == 0 {
+		return model.DefaultRatePlan, nil
+	}
+
+	plan, err := s.storage.GetRateRepo().GetRatePlan(ctx, order.RatePlanID)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && plan.HotelID != order.HotelID) {
+		return model.RatePlan{}, ErrUnknownRatePlan
+	}
+
+	return plan, err
+}
+
+// discount - promo code discount, never more than subtotal.
+func discount(promo model.PromoCode, subtotal model.Money) model.Money {
+	d := model.Money{Amount: int64(promo.DiscountValue), Currency: subtotal.Currency}
+	if promo.DiscountType == model.PercentDiscount {
+		d = subtotal.Percent(promo.DiscountValue)
+	}
+
+	if d.Amount > subtotal.Amount {
+		d.Amount = subtotal.Amount
+	}
+
+	return d
+}
@@ -0,0 +1,116 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/migration"
+)
+
+func TestPricingService_Quote(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	require.NoError(t, migration.InitializeStorage(ctx, store))
+
+	s := New(store)
+
+	// 2024-04-01 is Monday, Friday and Saturday nights are 20% more expensive
+	week := model.Order{HotelID: 1, RoomTypeID: 1, From: util.NewDay(2024, 4, 1), To: util.NewDay(2024, 4, 8)}
+	eur := func(amount int64) model.Money { return model.Money{Amount: amount, Currency: "EUR"} }
+
+	tests := []struct {
+		name        string
+		order       func(o model.Order) model.Order
+		expected    model.Price
+		expectedErr error
+	}{
+		{
+			name:  "Best available rate",
+			order: func(o model.Order) model.Order { return o },
+			expected: model.Price{
+				Subtotal: eur(740_00), Discount: eur(0), Total: eur(740_00), Refundable: true,
+			},
+		},
+		{
+			name:  "Non-refundable plan",
+			order: func(o model.Order) model.Order { o.RatePlanID = 2; return o },
+			expected: model.Price{
+				Subtotal: eur(666_00), Discount: eur(0), Total: eur(666_00), Refundable: false,
+			},
+		},
+		{
+			name:  "Percent promo code",
+			order: func(o model.Order) model.Order { o.PromoCode = "WELCOME10"; return o },
+			expected: model.Price{
+				Subtotal: eur(740_00), Discount: eur(74_00), Total: eur(666_00), Refundable: true,
+			},
+		},
+		{
+			name: "Fixed promo code is limited by subtotal",
+			order: func(o model.Order) model.Order {
+				o.From, o.To, o.PromoCode = util.NewDay(2024, 4, 1), util.NewDay(2024, 4, 3), "SPRING500"
+				return o
+			},
+			expected: model.Price{
+				Subtotal: eur(200_00), Discount: eur(200_00), Total: eur(0), Refundable: true,
+			},
+		},
+		{
+			name:        "Rate plan of other hotel",
+			order:       func(o model.Order) model.Order { o.RatePlanID = 3; return o },
+			expectedErr: ErrUnknownRatePlan,
+		},
+		{
+			name:        "No rates for period",
+			order:       func(o model.Order) model.Order { o.To = util.NewDay(2024, 4, 9); return o },
+			expectedErr: ErrNoRate,
+		},
+		{
+			name:        "Unknown promo code",
+			order:       func(o model.Order) model.Order { o.PromoCode = "UNKNOWN"; return o },
+			expectedErr: model.ErrPromoInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := s.Quote(ctx, tt.order(week))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Subtotal, price.Subtotal)
+			assert.Equal(t, tt.expected.Discount, price.Discount)
+			assert.Equal(t, tt.expected.Total, price.Total)
+			assert.Equal(t, tt.expected.Refundable, price.Refundable)
+			assert.NotEmpty(t, price.Nights)
+		})
+	}
+}
+
+func TestPricingService_GetListRatePlans(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	require.NoError(t, migration.InitializeStorage(ctx, store))
+
+	plans, err := New(store).GetListRatePlans(ctx, 2)
+	require.NoError(t, err)
+
+	codes := make([]string, 0, len(plans))
+	for _, plan := range plans {
+		codes = append(codes, plan.Code)
+	}
+	assert.Equal(t, []string{"FLEX", "NONREF", "DEPOSIT"}, codes)
+
+	plans, err = New(store).GetListRatePlans(ctx, 404)
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+}
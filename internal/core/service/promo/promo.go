@@ -0,0 +1,75 @@
+package promo
+
+import (
+	"context"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/logger"
+)
+
+type promoService struct {
+	log     logger.Logger
+	storage storage.Storage
+
+	now func() time.Time
+}
+
+func New(log logger.Logger, s storage.Storage) *promoService {
+	return &promoService{
+		log:     log,
+		storage: s,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *promoService) CreatePromoCode(ctx context.Context, promo model.PromoCode) error {
+	promo.Code = model.NormalizePromoCode(promo.Code)
+	promo.Used = 0
+
+	if err := promo.Validate(); err != nil {
+		return err
+	}
+
+	return s.storage.GetPromoRepo().StorePromoCode(ctx, promo)
+}
+
+func (s *promoService) GetPromoCode(ctx context.Context, code string) (model.PromoCode, error) {
+	return s.storage.GetPromoRepo().GetPromoCode(ctx, model.NormalizePromoCode(code))
+}
+
+func (s *promoService) GetListPromoCodes(ctx context.Context) ([]model.PromoCode, error) {
+	return s.storage.GetPromoRepo().GetListPromoCodes(ctx)
+}
+
+// ValidatePromoCode checks that promo code can be applied to order, code is not redeemed here.
+// The final check is done by booking service, when code is redeemed together with booking.
+func (s *promoService) ValidatePromoCode(ctx context.Context, order model.Order) (model.PromoCode, error) {
+	code := model.NormalizePromoCode(order.PromoCode)
+
+	promo, err := s.storage.GetPromoRepo().GetPromoCode(ctx, code)
+	if err != nil {
+		return model.PromoCode{}, err
+	}
+
+	usedByEmail, err := s.storage.GetPromoRepo().CountRedemptions(ctx, code, order.UserEmail)
+	if err != nil {
+		return model.PromoCode{}, err
+	}
+
+	redemption := model.PromoRedemption{
+		OrderID:    order.ID,
+		Code:       code,
+		UserEmail:  order.UserEmail,
+		HotelID:    order.HotelID,
+		RoomTypeID: order.RoomTypeID,
+		RedeemedAt: s.now(),
+	}
+	if err = promo.CheckRedemption(redemption, usedByEmail); err != nil {
+		s.log.Info("[promoService.ValidatePromoCode] Promo code %s can not be applied: %v", code, err)
+		return model.PromoCode{}, err
+	}
+
+	return promo, nil
+}
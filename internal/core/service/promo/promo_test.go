@@ -0,0 +1,115 @@
+package promo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/logger"
+)
+
+func TestPromoService_CreatePromoCode(t *testing.T) {
+	ctx := context.Background()
+	s := New(logger.New(), instorage.NewStorage())
+
+	err := s.CreatePromoCode(ctx, model.PromoCode{Code: " summer ", DiscountType: model.PercentDiscount, DiscountValue: 15, Used: 100})
+	require.NoError(t, err)
+
+	promo, err := s.GetPromoCode(ctx, "Summer")
+	require.NoError(t, err)
+	assert.Equal(t, "SUMMER", promo.Code)
+	assert.Zero(t, promo.Used, "usage counter must not be set by client")
+
+	err = s.CreatePromoCode(ctx, model.PromoCode{Code: "summer", DiscountType: model.PercentDiscount, DiscountValue: 15})
+	assert.ErrorIs(t, err, storage.ErrDuplicateConstraint)
+
+	err = s.CreatePromoCode(ctx, model.PromoCode{Code: "broken", DiscountType: model.PercentDiscount, DiscountValue: 0})
+	assert.ErrorIs(t, err, model.ErrPromoInvalid)
+}
+
+func TestPromoService_ValidatePromoCode(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	s := New(logger.New(), store)
+	s.now = func() time.Time { return util.NewDay(2024, 4, 1) }
+
+	require.NoError(t, s.CreatePromoCode(ctx, model.PromoCode{
+		Code:          "ONCE",
+		DiscountType:  model.FixedDiscount,
+		DiscountValue: 100,
+		PerEmailLimit: 1,
+		HotelIDs:      []int{1},
+	}))
+
+	order := model.Order{ID: uuid.New(), HotelID: 1, RoomTypeID: 1, UserEmail: "guest@mail.ru", PromoCode: "once"}
+
+	_, err := s.ValidatePromoCode(ctx, order)
+	require.NoError(t, err)
+
+	_, err = s.ValidatePromoCode(ctx, model.Order{HotelID: 2, UserEmail: "guest@mail.ru", PromoCode: "once"})
+	assert.ErrorIs(t, err, model.ErrPromoNotApplicable)
+
+	_, err = s.ValidatePromoCode(ctx, model.Order{HotelID: 1, UserEmail: "guest@mail.ru", PromoCode: "unknown"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, store.GetPromoRepo().Redeem(ctx, model.PromoRedemption{
+		OrderID: order.ID, Code: "ONCE", UserEmail: order.UserEmail, HotelID: 1, RoomTypeID: 1, RedeemedAt: s.now(),
+	}))
+
+	_, err = s.ValidatePromoCode(ctx, model.Order{HotelID: 1, UserEmail: "GUEST@mail.ru", PromoCode: "once"})
+	assert.ErrorIs(t, err, model.ErrPromoEmailLimit, "email limit must be case-insensitive")
+}
+
+func TestPromoRepository_ConcurrentRedeem(t *testing.T) {
+	ctx := context.Background()
+	store := instorage.NewStorage()
+	s := New(logger.New(), store)
+
+	const usageLimit = 5
+	require.NoError(t, s.CreatePromoCode(ctx, model.PromoCode{
+		Code:          "LIMITED",
+		DiscountType:  model.PercentDiscount,
+		DiscountValue: 50,
+		UsageLimit:    usageLimit,
+	}))
+
+	var (
+		wg       sync.WaitGroup
+		redeemed atomic.Int32
+	)
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.GetPromoRepo().Redeem(ctx, model.PromoRedemption{
+				OrderID:    uuid.New(),
+				Code:       "LIMITED",
+				UserEmail:  fmt.Sprintf("guest%d@mail.ru", i),
+				RedeemedAt: time.Now(),
+			})
+			if err == nil {
+				redeemed.Add(1)
+			} else {
+				assert.ErrorIs(t, err, model.ErrPromoUsageLimit)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, usageLimit, redeemed.Load())
+
+	promo, err := s.GetPromoCode(ctx, "LIMITED")
+	require.NoError(t, err)
+	assert.Equal(t, usageLimit, promo.Used)
+}
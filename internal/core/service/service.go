@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
 
 	"aplication-design-test-task/internal/core/domain/model"
 )
@@ -10,16 +14,88 @@ type (
 	BookingService interface {
 		Run(context.Context) error
 		GetOrder(context.Context, model.OrderID) (model.Order, error)
+		CheckAvailability(context.Context, model.Order) ([]time.Time, error)
+		SearchAvailability(context.Context, model.AvailabilityQuery) ([]model.AvailabilityOffer, error)
 
 		GetListOrders(ctx context.Context) ([]model.Order, error)
 		GetListRooms(ctx context.Context) ([]model.RoomAvailability, error)
+		GetListWaitlist(ctx context.Context) ([]model.WaitlistEntry, error)
+
+		GetAllotment(ctx context.Context, code string) (model.Allotment, error)
+		GetListAllotments(ctx context.Context) ([]model.Allotment, error)
+
+		GetSaga(context.Context, model.OrderID) (model.Saga, error)
+		GetListSagas(ctx context.Context, stuck bool) ([]model.Saga, error)
+	}
+
+	CatalogService interface {
+		CreateHotel(context.Context, model.Hotel) error
+		UpdateHotel(context.Context, model.Hotel) error
+		DeleteHotel(context.Context, model.HotelID) error
+		GetHotel(context.Context, model.HotelID) (model.Hotel, error)
+		GetListHotels(context.Context) ([]model.Hotel, error)
+
+		CreateRoomType(context.Context, model.RoomType) error
+		UpdateRoomType(context.Context, model.RoomType) error
+		DeleteRoomType(context.Context, model.HotelID, model.RoomTypeID) error
+		GetRoomType(context.Context, model.HotelID, model.RoomTypeID) (model.RoomType, error)
+		GetListRoomTypes(context.Context, model.HotelID) ([]model.RoomType, error)
+
+		ValidateOrder(context.Context, model.Order) error
+	}
+
+	PromoService interface {
+		CreatePromoCode(context.Context, model.PromoCode) error
+		GetPromoCode(ctx context.Context, code string) (model.PromoCode, error)
+		GetListPromoCodes(ctx context.Context) ([]model.PromoCode, error)
+
+		ValidatePromoCode(context.Context, model.Order) (model.PromoCode, error)
+	}
+
+	PricingService interface {
+		Quote(context.Context, model.Order) (model.Price, error)
+		GetListRatePlans(context.Context, model.HotelID) ([]model.RatePlan, error)
+	}
+
+	LoyaltyService interface {
+		GetAccount(ctx context.Context, email string) (model.LoyaltyAccount, error)
+		GetLedger(ctx context.Context, email string) ([]model.LoyaltyEntry, error)
+	}
+
+	OverbookingService interface {
+		CreateRule(context.Context, model.OverbookingRule) (model.OverbookingRule, error)
+		DeleteRule(context.Context, model.OverbookingRuleID) error
+		GetListRules(context.Context) ([]model.OverbookingRule, error)
+
+		GetOversoldReport(ctx context.Context, hotelID int) ([]model.OversoldNight, error)
 	}
 
 	PaymentService interface {
 		Run(context.Context) error
+		CallbackHandler(context.Context, model.PaymentCallback) error
+		Reconcile(ctx context.Context, source string, records []model.SettlementRecord, fix bool) (model.ReconciliationReport, error)
+		GetReconciliationReport(context.Context, uuid.UUID) (model.ReconciliationReport, error)
+		GetListReconciliationReports(context.Context) ([]model.ReconciliationReport, error)
+	}
+
+	InvoiceService interface {
+		GetInvoice(context.Context, model.OrderID) (model.Invoice, error)
+		GetListOrderInvoices(context.Context, model.OrderID) ([]model.Invoice, error)
+		RenderHTML(io.Writer, model.Invoice) error
 	}
 
 	Notification interface {
 		Run(context.Context) error
 	}
+
+	WebhookService interface {
+		Run(context.Context) error
+
+		CreateSubscription(context.Context, model.WebhookSubscription) (model.WebhookSubscription, error)
+		DeleteSubscription(context.Context, model.WebhookSubscriptionID) error
+		GetListSubscriptions(context.Context) ([]model.WebhookSubscription, error)
+
+		GetListDeliveries(context.Context, model.WebhookSubscriptionID) ([]model.WebhookDelivery, error)
+		Redeliver(ctx context.Context, deliveryID uuid.UUID) (model.WebhookDelivery, error)
+	}
 )
@@ -0,0 +1,339 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/port/gateway"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/logger"
+)
+
+const (
+	maxDeliveryAttempts = 6
+	deliveryTimeout     = 10 * time.Second // of one attempt
+	retryBaseDelay      = 10 * time.Second // doubled after every failed attempt
+	retryMaxDelay       = time.Hour
+	retryInterval       = time.Second // how often due deliveries are looked for
+)
+
+// Headers of webhook request, signature is made like one of payment callbacks: HMAC-SHA256 of "<timestamp>.<body>".
+const (
+	DeliveryIDHeader         = "X-Webhook-Delivery-ID"
+	EventIDHeader            = "X-Webhook-Event-ID"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureHeader          = "X-Signature"
+)
+
+type webhookService struct {
+	log     logger.Logger
+	q       queue.Queue
+	storage storage.Storage
+	sender  gateway.WebhookSender
+
+	backoff time.Duration
+	now     func() time.Time
+}
+
+func New(log logger.Logger, q queue.Queue, s storage.Storage, sender gateway.WebhookSender) *webhookService {
+	return &webhookService{
+		log:     log,
+		q:       q,
+		storage: s,
+		sender:  sender,
+		backoff: retryBaseDelay,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run starts consuming order status changes and sending deliveries. Changes are only stored as deliveries,
+// they are sent by another single goroutine, so a slow endpoint does not hold up the topic and a delivery
+// is never attempted concurrently.
+func (s *webhookService) Run(ctx context.Context) error {
+	ch, err := s.q.Subscribe(ctx, queue.OrderStatusChanged)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to topic %s. err: %v", queue.OrderStatusChanged, err)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				s.log.Info("[webhookService] ctx.Done(). finished")
+				return
+
+			case msg, ok := <-ch:
+				if !ok {
+					return // topic was deleted or queue closed
+				}
+
+				switch event := msg.(type) {
+				case events.OrderStatusChangedEvent:
+					s.log.Info("[webhookService] received OrderStatusChanged: %s -> %s of order %v", event.PreviousStatus, event.Status, event.OrderID)
+					s.OrderStatusChangedEventHandler(ctx, event)
+				default:
+					s.log.Error("[webhookService] received unknown msg: %+v", msg)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(retryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sendDueDeliveries(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (model.WebhookSubscription, error) {
+	if err := subscription.Validate(); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return model.WebhookSubscription{}, err
+		}
+		subscription.Secret = "whsec_" + hex.EncodeToString(secret)
+	}
+
+	subscription.ID = uuid.New()
+	subscription.CreatedAt = s.now()
+
+	if err := s.storage.GetWebhookRepo().StoreSubscription(ctx, subscription); err != nil {
+		return model.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// DeleteSubscription - pending deliveries of subscription are failed on their next attempt.
+func (s *webhookService) DeleteSubscription(ctx context.Context, id model.WebhookSubscriptionID) error {
+	if _, err := s.storage.GetWebhookRepo().GetSubscription(ctx, id); err != nil {
+		return err
+	}
+
+	return s.storage.GetWebhookRepo().DeleteSubscription(ctx, id)
+}
+
+func (s *webhookService) GetListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	return s.storage.GetWebhookRepo().GetListSubscriptions(ctx)
+}
+
+// GetListDeliveries returns delivery log of subscription.
+func (s *webhookService) GetListDeliveries(ctx context.Context, subscriptionID model.WebhookSubscriptionID) ([]model.WebhookDelivery, error) {
+	if _, err := s.storage.GetWebhookRepo().GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.storage.GetWebhookRepo().GetListDeliveries(ctx, subscriptionID)
+}
+
+// Redeliver - schedule new delivery of the same payload, e.g. after partner fixed its endpoint. It is attempted
+// by worker together with other retries, its result can be found in delivery log.
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID uuid.UUID) (model.WebhookDelivery, error) {
+	original, err := s.storage.GetWebhookRepo().GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	if _, err = s.storage.GetWebhookRepo().GetSubscription(ctx, original.SubscriptionID); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	now := s.now()
+	delivery := model.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		OrderID:        original.OrderID,
+		Payload:        original.Payload,
+		RedeliveryOf:   original.ID,
+		Status:         model.DeliveryPending,
+		CreatedAt:      now,
+		NextAttemptAt:  now,
+	}
+
+	if err = s.storage.GetWebhookRepo().StoreDelivery(ctx, delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// OrderStatusChangedEventHandler - create delivery for every matching subscription of the partner which the order
+// is booked by, orders of guests are not sent to partners. Deliveries are sent by sendDueDeliveries.
+func (s *webhookService) OrderStatusChangedEventHandler(ctx context.Context, event events.OrderStatusChangedEvent) {
+	partner, err := s.orderPartner(ctx, event.Order)
+	if err != nil {
+		s.log.Error("[webhookService.OrderStatusChangedEventHandler] Failed to get partner of order %v: %v", event.OrderID, err)
+		return
+	}
+	if partner == "" {
+		return
+	}
+
+	subscriptions, err := s.storage.GetWebhookRepo().GetListSubscriptions(ctx)
+	if err != nil {
+		s.log.Error("[webhookService.OrderStatusChangedEventHandler] Failed to get subscriptions: %v", err)
+		return
+	}
+
+	payload, err := json.Marshal(model.NewOrderWebhook(event))
+	if err != nil {
+		s.log.Error("[webhookService.OrderStatusChangedEventHandler] Failed to encode event %v: %v", event.EventID, err)
+		return
+	}
+
+	now := s.now()
+	for _, subscription := range subscriptions {
+		if subscription.Partner != partner || !subscription.Matches(event.Status) {
+			continue
+		}
+
+		delivery := model.WebhookDelivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.EventID,
+			OrderID:        event.OrderID,
+			Payload:        payload,
+			Status:         model.DeliveryPending,
+			CreatedAt:      now,
+			NextAttemptAt:  now,
+		}
+
+		if err = s.storage.GetWebhookRepo().StoreDelivery(ctx, delivery); err != nil {
+			s.log.Error("[webhookService.OrderStatusChangedEventHandler] Failed to store delivery: %v", err)
+		}
+	}
+}
+
+// orderPartner - partner of allotment which the order is booked from, empty if the order is booked by a guest.
+func (s *webhookService) orderPartner(ctx context.Context, order model.Order) (string, error) {
+	if order.BlockCode == "" {
+		return "", nil
+	}
+
+	allotment, err := s.storage.GetAllotmentRepo().GetAllotment(ctx, order.BlockCode)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return allotment.Partner, nil
+}
+
+// sendDueDeliveries - attempt pending deliveries which next attempt time has come.
+func (s *webhookService) sendDueDeliveries(ctx context.Context) {
+	deliveries, err := s.storage.GetWebhookRepo().GetListDeliveries(ctx, uuid.Nil)
+	if err != nil {
+		s.log.Error("[webhookService.sendDueDeliveries] Failed to get deliveries: %v", err)
+		return
+	}
+
+	now := s.now()
+	for _, delivery := range deliveries {
+		if delivery.Status != model.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		subscription, err := s.storage.GetWebhookRepo().GetSubscription(ctx, delivery.SubscriptionID)
+		if errors.Is(err, storage.ErrNotFound) {
+			delivery.Status = model.DeliveryFailed
+			delivery.LastError = "subscription is deleted"
+			delivery.NextAttemptAt = time.Time{}
+			s.updateDelivery(ctx, delivery)
+			continue
+		}
+		if err != nil {
+			s.log.Error("[webhookService.sendDueDeliveries] Failed to get subscription %v: %v", delivery.SubscriptionID, err)
+			continue
+		}
+
+		s.attempt(ctx, subscription, delivery)
+	}
+}
+
+// attempt - send delivery once. Failed delivery is retried with exponential backoff until maxDeliveryAttempts.
+func (s *webhookService) attempt(ctx context.Context, subscription model.WebhookSubscription, delivery model.WebhookDelivery) {
+	timestamp := s.now().Unix()
+	headers := map[string]string{
+		DeliveryIDHeader:         delivery.ID.String(),
+		EventIDHeader:            delivery.EventID.String(),
+		SignatureTimestampHeader: strconv.FormatInt(timestamp, 10),
+		SignatureHeader:          util.Sign([]byte(subscription.Secret), timestamp, delivery.Payload),
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	code, err := s.sender.Send(sendCtx, subscription.URL, headers, delivery.Payload)
+	cancel()
+
+	delivery.Attempts++
+	delivery.ResponseCode = code
+
+	switch {
+	case err == nil && code >= 200 && code < 300:
+		delivery.Status = model.DeliveryDelivered
+		delivery.DeliveredAt = s.now()
+		delivery.NextAttemptAt = time.Time{}
+		delivery.LastError = ""
+	case err == nil:
+		err = fmt.Errorf("endpoint answered %d", code)
+		fallthrough
+	default:
+		delivery.LastError = err.Error()
+
+		if delivery.Attempts >= maxDeliveryAttempts {
+			delivery.Status = model.DeliveryFailed
+			delivery.NextAttemptAt = time.Time{}
+			break
+		}
+
+		delivery.NextAttemptAt = s.now().Add(s.retryDelay(delivery.Attempts))
+	}
+
+	s.log.Info("[webhookService.attempt] Delivery %v to %s, attempt %d: %s %s", delivery.ID, subscription.Partner, delivery.Attempts, delivery.Status, delivery.LastError)
+
+	s.updateDelivery(ctx, delivery)
+}
+
+// retryDelay - delay after attempt: backoff, 2*backoff, 4*backoff... but not longer than retryMaxDelay.
+func (s *webhookService) retryDelay(attempt int) time.Duration {
+	delay := s.backoff
+	for i := 1; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, retryMaxDelay)
+}
+
+func (s *webhookService) updateDelivery(ctx context.Context, delivery model.WebhookDelivery) {
+	if err := s.storage.GetWebhookRepo().UpdateDelivery(ctx, delivery); err != nil {
+		s.log.Error("[webhookService.updateDelivery] Failed to update delivery %v: %v", delivery.ID, err)
+	}
+}
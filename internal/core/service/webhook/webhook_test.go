@@ -0,0 +1,305 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sender "aplication-design-test-task/internal/adapters/gateway/webhook"
+	"aplication-design-test-task/internal/adapters/queue"
+	"aplication-design-test-task/internal/adapters/queue/gochanqueue"
+	"aplication-design-test-task/internal/adapters/storage"
+	inmemory "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+	"aplication-design-test-task/internal/core/port/events"
+	"aplication-design-test-task/internal/core/util"
+	"aplication-design-test-task/internal/logger"
+)
+
+// receiver - partner endpoint which verifies signatures and fails first requests if asked.
+type receiver struct {
+	*httptest.Server
+
+	m        sync.Mutex
+	secret   string
+	failures int
+	received []model.OrderWebhook
+	eventIDs []string
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		r.m.Lock()
+		defer r.m.Unlock()
+
+		timestamp, _ := strconv.ParseInt(req.Header.Get(SignatureTimestampHeader), 10, 64)
+		if !util.VerifySignature([]byte(r.secret), timestamp, body, req.Header.Get(SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var change model.OrderWebhook
+		require.NoError(t, json.Unmarshal(body, &change))
+		r.received = append(r.received, change)
+		r.eventIDs = append(r.eventIDs, req.Header.Get(EventIDHeader))
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func (r *receiver) fail(n int) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.failures = n
+}
+
+func (r *receiver) changes() []model.OrderWebhook {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return append([]model.OrderWebhook(nil), r.received...)
+}
+
+func newTestService(t *testing.T, ctx context.Context) (*webhookService, queue.Queue, *time.Time) {
+	log := logger.New()
+	q := gochanqueue.NewChanQueue(log)
+	for _, topic := range queue.AllTopics {
+		require.NoError(t, q.CreateTopic(ctx, topic))
+	}
+
+	store := inmemory.NewStorage()
+	require.NoError(t, store.GetAllotmentRepo().StoreAllotment(ctx, model.Allotment{Code: "OTA", Partner: "ota", Status: model.AllotmentActive}))
+
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	s := New(log, q, store, sender.NewSender(time.Second, sender.WithPrivateNetworks()))
+	s.now = func() time.Time { return now }
+
+	return s, q, &now
+}
+
+// subscribe - receiver is stored directly, as its loopback URL is rejected by validation of subscriptions.
+func (s *webhookService) subscribe(t *testing.T, r *receiver, statuses ...model.Status) model.WebhookSubscription {
+	subscription := model.WebhookSubscription{ID: uuid.New(), Partner: "ota", URL: r.URL, Secret: "whsec_test", Statuses: statuses}
+	require.NoError(t, s.storage.GetWebhookRepo().StoreSubscription(context.Background(), subscription))
+	r.secret = subscription.Secret
+
+	return subscription
+}
+
+func (s *webhookService) deliveries(t *testing.T, subscriptionID model.WebhookSubscriptionID) []model.WebhookDelivery {
+	deliveries, err := s.GetListDeliveries(context.Background(), subscriptionID)
+	require.NoError(t, err)
+	return deliveries
+}
+
+func statusChange(previous, status model.Status) events.OrderStatusChangedEvent {
+	orderID := uuid.New()
+	return events.OrderStatusChangedEvent{
+		EventID:        uuid.New(),
+		OrderID:        orderID,
+		PreviousStatus: previous,
+		Status:         status,
+		Order:          model.Order{ID: orderID, Status: status, BlockCode: "OTA", UserEmail: "guest@example.com"},
+	}
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestService(t, ctx)
+
+	created, err := s.CreateSubscription(ctx, model.WebhookSubscription{Partner: "ota", URL: "https://ota.example.com/hooks"})
+	require.NoError(t, err)
+	assert.Contains(t, created.Secret, "whsec_")
+	require.NoError(t, s.DeleteSubscription(ctx, created.ID))
+
+	_, err = s.CreateSubscription(ctx, model.WebhookSubscription{Partner: "ota", URL: "http://169.254.169.254/latest"})
+	assert.ErrorIs(t, err, model.ErrWebhookSubscriptionInvalid, "endpoint in private network is rejected")
+
+	r := newReceiver(t)
+	subscription := s.subscribe(t, r, model.Paid, model.Cancelled)
+
+	s.OrderStatusChangedEventHandler(ctx, statusChange(model.New, model.Booked))
+	guestOrder := statusChange(model.Booked, model.Paid)
+	guestOrder.Order.BlockCode = ""
+	s.OrderStatusChangedEventHandler(ctx, guestOrder)
+	assert.Empty(t, s.deliveries(t, subscription.ID), "status is filtered out, order of guest is not sent to partner")
+
+	paid := statusChange(model.Booked, model.Paid)
+	s.OrderStatusChangedEventHandler(ctx, paid)
+	assert.Empty(t, r.changes(), "delivery is sent by worker, not by consumer of changes")
+
+	s.sendDueDeliveries(ctx)
+
+	changes := r.changes()
+	require.Len(t, changes, 1)
+	assert.Equal(t, paid.EventID, changes[0].EventID)
+	assert.Equal(t, model.Booked, changes[0].PreviousStatus)
+	assert.Equal(t, model.Paid, changes[0].Status)
+	assert.Equal(t, "OTA", changes[0].BlockCode)
+
+	deliveries := s.deliveries(t, subscription.ID)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, model.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseCode)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.NotContains(t, string(deliveries[0].Payload), "guest@example.com", "guest data is not sent")
+
+	_, err = s.GetListDeliveries(ctx, uuid.New())
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestWebhookService_OtherPartner(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestService(t, ctx)
+
+	require.NoError(t, s.storage.GetAllotmentRepo().StoreAllotment(ctx, model.Allotment{Code: "FAIR", Partner: "fair", Status: model.AllotmentActive}))
+
+	r := newReceiver(t)
+	subscription := s.subscribe(t, r)
+
+	fair := statusChange(model.New, model.Booked)
+	fair.Order.BlockCode = "FAIR"
+	s.OrderStatusChangedEventHandler(ctx, fair)
+	s.sendDueDeliveries(ctx)
+
+	assert.Empty(t, s.deliveries(t, subscription.ID), "order of another partner is not sent")
+	assert.Empty(t, r.changes())
+}
+
+func TestWebhookService_Retry(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestService(t, ctx)
+
+	r := newReceiver(t)
+	subscription := s.subscribe(t, r)
+
+	r.fail(2)
+	s.OrderStatusChangedEventHandler(ctx, statusChange(model.Booked, model.Cancelled))
+	s.sendDueDeliveries(ctx)
+
+	delivery := s.deliveries(t, subscription.ID)[0]
+	assert.Equal(t, model.DeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseCode)
+	assert.Equal(t, now.Add(retryBaseDelay), delivery.NextAttemptAt)
+
+	s.sendDueDeliveries(ctx)
+	assert.Equal(t, 1, s.deliveries(t, subscription.ID)[0].Attempts, "retry is not due yet")
+
+	*now = delivery.NextAttemptAt
+	s.sendDueDeliveries(ctx)
+	delivery = s.deliveries(t, subscription.ID)[0]
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, now.Add(2*retryBaseDelay), delivery.NextAttemptAt, "backoff is doubled")
+
+	*now = delivery.NextAttemptAt
+	s.sendDueDeliveries(ctx)
+	delivery = s.deliveries(t, subscription.ID)[0]
+	assert.Equal(t, model.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+	assert.Len(t, r.changes(), 1)
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestService(t, ctx)
+
+	r := newReceiver(t)
+	subscription := s.subscribe(t, r)
+
+	r.fail(maxDeliveryAttempts)
+	change := statusChange(model.Booked, model.Expired)
+	s.OrderStatusChangedEventHandler(ctx, change)
+	for range maxDeliveryAttempts {
+		*now = now.Add(retryMaxDelay)
+		s.sendDueDeliveries(ctx)
+	}
+
+	failed := s.deliveries(t, subscription.ID)[0]
+	assert.Equal(t, model.DeliveryFailed, failed.Status)
+	assert.Equal(t, maxDeliveryAttempts, failed.Attempts)
+	assert.Contains(t, failed.LastError, "503")
+	assert.Empty(t, r.changes())
+
+	redelivery, err := s.Redeliver(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, failed.ID, redelivery.RedeliveryOf)
+	assert.Equal(t, model.DeliveryPending, redelivery.Status)
+
+	s.sendDueDeliveries(ctx)
+
+	deliveries := s.deliveries(t, subscription.ID)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, model.DeliveryFailed, deliveries[0].Status, "original delivery is kept in log")
+	assert.Equal(t, model.DeliveryDelivered, deliveries[1].Status)
+	assert.Equal(t, []string{change.EventID.String()}, r.eventIDs, "redelivery has the same event ID")
+
+	_, err = s.Redeliver(ctx, uuid.New())
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestWebhookService_DeletedSubscription(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestService(t, ctx)
+
+	r := newReceiver(t)
+	subscription := s.subscribe(t, r)
+
+	r.fail(1)
+	s.OrderStatusChangedEventHandler(ctx, statusChange(model.New, model.Booked))
+	delivery := s.deliveries(t, subscription.ID)[0]
+
+	require.NoError(t, s.DeleteSubscription(ctx, subscription.ID))
+	assert.ErrorIs(t, s.DeleteSubscription(ctx, subscription.ID), storage.ErrNotFound)
+
+	*now = now.Add(retryMaxDelay)
+	s.sendDueDeliveries(ctx)
+
+	delivery, err := s.storage.GetWebhookRepo().GetDelivery(ctx, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DeliveryFailed, delivery.Status)
+	assert.Equal(t, "subscription is deleted", delivery.LastError)
+}
+
+func TestWebhookService_Run(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s, q, _ := newTestService(t, ctx)
+	r := newReceiver(t)
+	s.subscribe(t, r)
+
+	require.NoError(t, s.Run(ctx))
+	require.NoError(t, q.Publish(ctx, queue.OrderStatusChanged, statusChange(model.New, model.Booked)))
+
+	assert.Eventually(t, func() bool { return len(r.changes()) == 1 }, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestWebhookService_RetryDelay(t *testing.T) {
+	s := New(logger.New(), nil, nil, nil)
+
+	assert.Equal(t, retryBaseDelay, s.retryDelay(1))
+	assert.Equal(t, 4*retryBaseDelay, s.retryDelay(3))
+	assert.Equal(t, retryMaxDelay, s.retryDelay(30))
+	assert.Equal(t, retryMaxDelay, s.retryDelay(100), "no overflow")
+}
@@ -0,0 +1,12 @@
+package util
+
+import "net/netip"
+
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10") // carrier-grade NAT, RFC 6598
+
+// IsPublicAddr reports whether addr is reachable in public internet. Loopback, private, link-local, multicast
+// and unspecified addresses are not public.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Sign returns hex encoded HMAC-SHA256 of "<timestamp>.<body>". Timestamp is a part of signed payload,
+// so a captured request can not be replayed later with a fresh timestamp.
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	return hex.EncodeToString(sign(secret, timestamp, body))
+}
+
+// VerifySignature reports whether signature is made by Sign with the same secret, timestamp and body.
+func VerifySignature(secret []byte, timestamp int64, body []byte, signature string) bool {
+	received, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(received, sign(secret, timestamp, body))
+}
+
+func sign(secret []byte, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
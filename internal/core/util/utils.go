@@ -23,22 +23,30 @@ type (
 	Days = []Day
 )
 
-func IsDayBetween(day Day, from Day, to Day) bool {
+// IsNightBetween reports whether night of day is a part of stay [checkIn, checkOut).
+func IsNightBetween(day Day, checkIn Day, checkOut Day) bool {
 	day = ToDay(day)
-	return !day.Before(ToDay(from)) && !day.After(ToDay(to))
+	return !day.Before(ToDay(checkIn)) && day.Before(ToDay(checkOut))
 }
 
-func DaysBetween(from time.Time, to time.Time) Days {
-	if from.After(to) {
-		return nil
-	}
+// IsStaysIntersect reports whether stays [checkIn1, checkOut1) and [checkIn2, checkOut2) have common nights.
+func IsStaysIntersect(checkIn1 Day, checkOut1 Day, checkIn2 Day, checkOut2 Day) bool {
+	return ToDay(checkIn1).Before(ToDay(checkOut2)) && ToDay(checkIn2).Before(ToDay(checkOut1))
+}
 
-	days := make([]time.Time, 0)
-	for d := ToDay(from); !d.After(ToDay(to)); d = d.AddDate(0, 0, 1) {
-		days = append(days, d)
+// NightsBetween returns nights of stay [checkIn, checkOut), the checkout day is not occupied.
+func NightsBetween(checkIn time.Time, checkOut time.Time) Days {
+	nights := make([]time.Time, 0)
+	for d := ToDay(checkIn); d.Before(ToDay(checkOut)); d = d.AddDate(0, 0, 1) {
+		nights = append(nights, d)
 	}
 
-	return days
+	return nights
+}
+
+// ToLocalDay returns date of timestamp in location.
+func ToLocalDay(timestamp time.Time, loc *time.Location) Day {
+	return ToDay(timestamp.In(loc))
 }
 
 func ToDay(timestamp time.Time) Day {
@@ -1,7 +1,9 @@
 package util
 
 import (
+	"net/netip"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -30,31 +32,64 @@ func TestCheckSliceForDuplicates(t *testing.T) {
 	}
 }
 
-func TestIsDayBetween(t *testing.T) {
-	from := NewDay(2024, 1, 1)
-	to := NewDay(2024, 1, 10)
-	dayInside := NewDay(2024, 1, 5)
-	dayOutside := NewDay(2024, 1, 15)
+func TestIsNightBetween(t *testing.T) {
+	checkIn := NewDay(2024, 1, 1)
+	checkOut := NewDay(2024, 1, 3)
 
-	assert.True(t, IsDayBetween(dayInside, from, to))
-	assert.False(t, IsDayBetween(dayOutside, from, to))
+	assert.True(t, IsNightBetween(NewDay(2024, 1, 1), checkIn, checkOut))
+	assert.True(t, IsNightBetween(NewDay(2024, 1, 2), checkIn, checkOut))
+	assert.False(t, IsNightBetween(NewDay(2024, 1, 3), checkIn, checkOut), "checkout day is not occupied")
 }
 
-func TestDaysBetween(t *testing.T) {
-	from := NewDay(2024, 1, 1)
-	to := NewDay(2024, 1, 3)
-	expectedDays := []Day{
-		NewDay(2024, 1, 1),
-		NewDay(2024, 1, 2),
-		NewDay(2024, 1, 3),
-	}
+func TestIsStaysIntersect(t *testing.T) {
+	checkIn := NewDay(2024, 1, 1)
+	checkOut := NewDay(2024, 1, 10)
+
+	assert.True(t, IsStaysIntersect(checkIn, checkOut, NewDay(2024, 1, 9), NewDay(2024, 1, 12)))
+	assert.True(t, IsStaysIntersect(checkIn, checkOut, NewDay(2023, 12, 1), NewDay(2024, 2, 1)))
+	assert.False(t, IsStaysIntersect(checkIn, checkOut, NewDay(2024, 1, 10), NewDay(2024, 1, 12)), "checkout day is free")
+}
+
+func TestNightsBetween(t *testing.T) {
+	assert.Equal(t, []Day{NewDay(2024, 1, 1)}, NightsBetween(NewDay(2024, 1, 1), NewDay(2024, 1, 2)), "one night stay")
+	assert.Equal(t, []Day{NewDay(2024, 1, 1), NewDay(2024, 1, 2)}, NightsBetween(NewDay(2024, 1, 1), NewDay(2024, 1, 3)))
+	assert.Empty(t, NightsBetween(NewDay(2024, 1, 3), NewDay(2024, 1, 3)))
+	assert.Empty(t, NightsBetween(NewDay(2024, 1, 3), NewDay(2024, 1, 1)))
+}
+
+func TestToLocalDay(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	timestamp := time.Date(2024, 4, 1, 20, 0, 0, 0, time.UTC)
 
-	result := DaysBetween(from, to)
-	assert.Equal(t, expectedDays, result)
+	assert.Equal(t, NewDay(2024, 4, 2), ToLocalDay(timestamp, tokyo))
+	assert.Equal(t, NewDay(2024, 4, 1), ToLocalDay(timestamp, newYork))
 }
 
-func TestDaysBetween_EmptySliceWhenFromAfterTo(t *testing.T) {
-	from := NewDay(2024, 1, 3)
-	to := NewDay(2024, 1, 1)
-	assert.Empty(t, DaysBetween(from, to))
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"event_id":"evt_1"}`)
+	signature := Sign(secret, 1700000000, body)
+
+	assert.True(t, VerifySignature(secret, 1700000000, body, signature))
+	assert.False(t, VerifySignature(secret, 1700000001, body, signature), "timestamp is signed")
+	assert.False(t, VerifySignature(secret, 1700000000, []byte(`{"event_id":"evt_2"}`), signature), "body is signed")
+	assert.False(t, VerifySignature([]byte("other"), 1700000000, body, signature), "other secret")
+	assert.False(t, VerifySignature(secret, 1700000000, body, "not hex"))
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+
+	for _, addr := range []string{
+		"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0",
+		"::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "224.0.0.1",
+	} {
+		assert.False(t, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
 }
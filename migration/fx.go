@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"aplication-design-test-task/internal/adapters/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+//go:embed fx_rates.csv
+var defaultFXRates []byte
+
+// fxHeader - columns of FX rates file, effective_from is date (2006-01-02, UTC) or RFC 3339 timestamp.
+var fxHeader = []string{"base", "quote", "rate", "effective_from"}
+
+// LoadFXRates stores FX rates from CSV with fxHeader columns. Rate of a pair with the same effective date
+// is replaced, so a file can correct default rates.
+func LoadFXRates(ctx context.Context, store storage.Storage, r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(fxHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read FX rates header: %w", err)
+	}
+
+	if strings.Join(header, ",") != strings.Join(fxHeader, ",") {
+		return fmt.Errorf("%w: header must be %q", model.ErrFXRateInvalid, strings.Join(fxHeader, ","))
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read FX rates: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		effectiveFrom, err := parseEffectiveFrom(record[3])
+		if err != nil {
+			return fmt.Errorf("%w: line %d: %v", model.ErrFXRateInvalid, line, err)
+		}
+
+		rate := model.FXRate{Base: record[0], Quote: record[1], Rate: record[2], EffectiveFrom: effectiveFrom}
+		if err = rate.Validate(); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err = store.GetFXRepo().StoreRate(ctx, rate); err != nil {
+			return err
+		}
+	}
+}
+
+// LoadFXRatesFile stores FX rates from CSV file, see LoadFXRates.
+func LoadFXRatesFile(ctx context.Context, store storage.Storage, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return LoadFXRates(ctx, store, f)
+}
+
+func parseEffectiveFrom(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func initializeFXRates(ctx context.Context, store storage.Storage) error {
+	return LoadFXRates(ctx, store, bytes.NewReader(defaultFXRates))
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	instorage "aplication-design-test-task/internal/adapters/storage/inmemory/storage"
+	"aplication-design-test-task/internal/core/domain/model"
+)
+
+func TestLoadFXRates(t *testing.T) {
+	const header = "base,quote,rate,effective_from\n"
+
+	tests := []struct {
+		name    string
+		csv     string
+		want    []model.FXRate // stored rates of SEK
+		wantErr error
+		line    string // line reported by error
+	}{
+		{
+			name: "Date",
+			csv:  header + "EUR,SEK,11.25,2024-02-01\n",
+			want: []model.FXRate{
+				{Base: "EUR", Quote: "SEK", Rate: "11.25", EffectiveFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "Timestamp",
+			csv:  header + "EUR,SEK,11.25,2024-02-01\nEUR, SEK, 11.30, 2024-02-01T12:00:00+02:00\n",
+			want: []model.FXRate{
+				{Base: "EUR", Quote: "SEK", Rate: "11.25", EffectiveFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+				{Base: "EUR", Quote: "SEK", Rate: "11.30", EffectiveFrom: time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name: "Same effective date replaces rate",
+			csv:  header + "EUR,SEK,11.25,2024-02-01\nEUR,SEK,11.40,2024-02-01T00:00:00Z\n",
+			want: []model.FXRate{
+				{Base: "EUR", Quote: "SEK", Rate: "11.40", EffectiveFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:    "Wrong header",
+			csv:     "base,quote,rate,from\nEUR,SEK,11.25,2024-02-01\n",
+			wantErr: model.ErrFXRateInvalid,
+		},
+		{
+			name:    "Columns in other order",
+			csv:     "quote,base,rate,effective_from\n",
+			wantErr: model.ErrFXRateInvalid,
+		},
+		{
+			name:    "Bad effective date",
+			csv:     header + "EUR,SEK,11.25,2024-02-01\nEUR,SEK,11.30,01.02.2024\n",
+			wantErr: model.ErrFXRateInvalid,
+			line:    "line 3",
+		},
+		{
+			name:    "Bad rate",
+			csv:     header + "EUR,SEK,-11.25,2024-02-01\n",
+			wantErr: model.ErrFXRateInvalid,
+			line:    "line 2",
+		},
+		{
+			name:    "Same currencies",
+			csv:     header + "EUR,SEK,11.25,2024-02-01\n\nSEK,SEK,1,2024-02-01\n",
+			wantErr: model.ErrFXRateInvalid,
+			line:    "line 4",
+		},
+		{
+			name: "Missing column",
+			csv:  header + "EUR,SEK,11.25\n",
+			line: "line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := instorage.NewStorage()
+			defer store.Close(ctx)
+
+			err := LoadFXRates(ctx, store, strings.NewReader(tt.csv))
+
+			rates, listErr := store.GetFXRepo().GetListRates(ctx)
+			require.NoError(t, listErr)
+			var stored []model.FXRate
+			for _, rate := range rates {
+				if rate.Quote == "SEK" {
+					rate.EffectiveFrom = rate.EffectiveFrom.UTC()
+					stored = append(stored, rate)
+				}
+			}
+
+			if tt.wantErr == nil && tt.line == "" {
+				require.NoError(t, err)
+				assert.ElementsMatch(t, tt.want, stored)
+				return
+			}
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.ErrorContains(t, err, tt.line)
+		})
+	}
+}
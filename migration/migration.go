@@ -2,6 +2,7 @@ package migration
 
 import (
 	"context"
+	"time"
 
 	"aplication-design-test-task/internal/adapters/storage"
 	"aplication-design-test-task/internal/core/domain/model"
@@ -17,6 +18,46 @@ const (
 func InitializeStorage(ctx context.Context, store storage.Storage) error {
 
 	const quotaTen = 10
+
+	hotels := []model.Hotel{
+		{
+			ID:        firstHotelID,
+			Name:      "Spree Riverside",
+			Address:   "Friedrichstraße 1, 10117 Berlin",
+			Amenities: []string{"wifi", "breakfast", "gym"},
+			Geo:       &model.GeoPoint{Lat: 52.5170, Lon: 13.3889},
+			Timezone:  "Europe/Berlin", CheckInTime: "15:00", CheckOutTime: "11:00",
+			Currency: "EUR", TaxPercent: 7, TaxID: "DE123456789",
+		},
+		{
+			ID:        secondHotelID,
+			Name:      "Thames View",
+			Address:   "1 Southbank, London SE1 7PB",
+			Amenities: []string{"wifi", "spa"},
+			Geo:       &model.GeoPoint{Lat: 51.5055, Lon: -0.1160},
+			Timezone:  "Europe/London", CheckInTime: "14:00", CheckOutTime: "12:00",
+			Currency: "USD", TaxPercent: 20, TaxID: "GB123456789",
+		},
+	}
+
+	for _, hotel := range hotels {
+		if err := store.GetHotelRepo().StoreHotel(ctx, hotel); err != nil {
+			return err
+		}
+
+		roomTypes := []model.RoomType{
+			{ID: 1, HotelID: hotel.ID, Name: "Standard", Capacity: 2, Amenities: []string{"shower"}},
+			{ID: 2, HotelID: hotel.ID, Name: "Superior", Capacity: 3, Amenities: []string{"bath", "city view"}},
+			{ID: 3, HotelID: hotel.ID, Name: "Suite", Capacity: 4, Amenities: []string{"bath", "balcony", "minibar"}},
+		}
+
+		for _, roomType := range roomTypes {
+			if err := store.GetRoomTypeRepo().StoreRoomType(ctx, roomType); err != nil {
+				return err
+			}
+		}
+	}
+
 	roomsHotelOne := []model.RoomAvailability{
 		// ONE WEEK - ONE HOTEL WITH 1 types rooms and 10 quota
 		{HotelID: firstHotelID, RoomTypeID: 1, Date: util.NewDay(2024, 4, 1), Quota: quotaTen},
@@ -67,12 +108,84 @@ func InitializeStorage(ctx context.Context, store storage.Storage) error {
 		{HotelID: secondHotelID, RoomTypeID: 3, Date: util.NewDay(2024, 4, 7), Quota: quotaTen},
 	}
 
+	// second hotel: minimum 2 nights through the weekend, no arrivals on Saturday.
+	for i, room := range roomsHotelTwo {
+		switch room.Date.Weekday() {
+		case time.Friday:
+			roomsHotelTwo[i].MinStay = 2
+		case time.Saturday:
+			roomsHotelTwo[i].MinStay = 2
+			roomsHotelTwo[i].ClosedToArrival = true
+		}
+	}
+
 	for id, room := range append(roomsHotelOne, roomsHotelTwo...) {
 		room.ID = id
+		room.Capacity = room.Quota
 		if err := store.GetRoomRepo().StoreRoom(ctx, room); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	hotelCurrencies := make(map[int]string, len(hotels))
+	for _, hotel := range hotels {
+		hotelCurrencies[hotel.ID] = hotel.Currency
+	}
+
+	baseRates := map[int]int64{1: 100_00, 2: 150_00, 3: 250_00} // by room type
+
+	for id, room := range append(roomsHotelOne, roomsHotelTwo...) {
+		price := model.Money{Amount: baseRates[room.RoomTypeID], Currency: hotelCurrencies[room.HotelID]}
+		if wd := room.Date.Weekday(); wd == time.Friday || wd == time.Saturday {
+			price = price.Percent(120)
+		}
+
+		rate := model.Rate{ID: id, HotelID: room.HotelID, RoomTypeID: room.RoomTypeID, Date: room.Date, Price: price}
+		if err := store.GetRateRepo().StoreRate(ctx, rate); err != nil {
+			return err
+		}
+	}
+
+	ratePlans := []model.RatePlan{
+		{ID: 1, HotelID: firstHotelID, Code: "FLEX", Name: "Flexible", Refundable: true,
+			Cancellation: model.CancellationPolicy{FreeCancellationDays: 2, LateFeePercent: 50}},
+		{ID: 2, HotelID: firstHotelID, Code: "NONREF", Name: "Non-refundable", AdjustmentPercent: -10},
+		{ID: 3, HotelID: secondHotelID, Code: "FLEX", Name: "Flexible", Refundable: true,
+			Cancellation: model.CancellationPolicy{FreeCancellationDays: 1, LateFeePercent: 100}},
+		{ID: 4, HotelID: secondHotelID, Code: "NONREF", Name: "Non-refundable", AdjustmentPercent: -15},
+		{ID: 5, HotelID: secondHotelID, Code: "DEPOSIT", Name: "Deposit", Refundable: true, DepositPercent: 30, BalanceDueDays: 7},
+	}
+
+	for _, plan := range ratePlans {
+		if err := store.GetRateRepo().StoreRatePlan(ctx, plan); err != nil {
+			return err
+		}
+	}
+
+	promoCodes := []model.PromoCode{
+		{
+			Code:          "WELCOME10",
+			DiscountType:  model.PercentDiscount,
+			DiscountValue: 10,
+			ValidFrom:     util.NewDay(2024, 1, 1),
+			PerEmailLimit: 1,
+		},
+		{
+			Code:          "SPRING500",
+			DiscountType:  model.FixedDiscount,
+			DiscountValue: 500_00,
+			ValidFrom:     util.NewDay(2024, 3, 1),
+			ValidTo:       util.NewDay(2024, 5, 31),
+			UsageLimit:    100,
+			HotelIDs:      []int{firstHotelID},
+		},
+	}
+
+	for _, promo := range promoCodes {
+		if err := store.GetPromoRepo().StorePromoCode(ctx, promo); err != nil {
+			return err
+		}
+	}
+
+	return initializeFXRates(ctx, store)
 }